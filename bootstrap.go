@@ -2,7 +2,11 @@ package main
 
 import (
 	"github.com/imulab/go-scim/cmd/api"
+	"github.com/imulab/go-scim/cmd/bench"
 	"github.com/imulab/go-scim/cmd/groupsync"
+	"github.com/imulab/go-scim/cmd/lint"
+	"github.com/imulab/go-scim/cmd/transfer"
+	"github.com/imulab/go-scim/cmd/validate"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
@@ -15,6 +19,11 @@ func main() {
 		Commands: []*cli.Command{
 			api.Command(),
 			groupsync.Command(),
+			lint.Command(),
+			validate.Command(),
+			transfer.ExportCommand(),
+			transfer.ImportCommand(),
+			bench.Command(),
 		},
 		HideVersion: true,
 		Authors: []*cli.Author{
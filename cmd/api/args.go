@@ -7,11 +7,18 @@ import (
 
 func newArgs() *arguments {
 	return &arguments{
-		Scim:     new(args.Scim),
-		MemoryDB: new(args.MemoryDB),
-		MongoDB:  new(args.MongoDB),
-		RabbitMQ: new(args.RabbitMQ),
-		Logging:  new(args.Logging),
+		Scim:      new(args.Scim),
+		MemoryDB:  new(args.MemoryDB),
+		MongoDB:   new(args.MongoDB),
+		RabbitMQ:  new(args.RabbitMQ),
+		Logging:   new(args.Logging),
+		RateLimit: new(args.RateLimit),
+		Tenant:    new(args.Tenant),
+		Metrics:   new(args.Metrics),
+		Password:  new(args.Password),
+		Bulk:      new(args.Bulk),
+		Webhook:   new(args.Webhook),
+		Http:      new(args.Http),
 	}
 }
 
@@ -21,6 +28,13 @@ type arguments struct {
 	*args.MongoDB
 	*args.RabbitMQ
 	*args.Logging
+	*args.RateLimit
+	*args.Tenant
+	*args.Metrics
+	*args.Password
+	*args.Bulk
+	*args.Webhook
+	*args.Http
 	httpPort int
 }
 
@@ -40,6 +54,13 @@ func (arg *arguments) Flags() []cli.Flag {
 	flags = append(flags, arg.MongoDB.Flags()...)
 	flags = append(flags, arg.RabbitMQ.Flags()...)
 	flags = append(flags, arg.Logging.Flags()...)
+	flags = append(flags, arg.RateLimit.Flags()...)
+	flags = append(flags, arg.Tenant.Flags()...)
+	flags = append(flags, arg.Metrics.Flags()...)
+	flags = append(flags, arg.Password.Flags()...)
+	flags = append(flags, arg.Bulk.Flags()...)
+	flags = append(flags, arg.Webhook.Flags()...)
+	flags = append(flags, arg.Http.Flags()...)
 	return flags
 }
 
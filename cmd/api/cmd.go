@@ -2,6 +2,9 @@ package api
 
 import (
 	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/julienschmidt/httprouter"
 	"github.com/urfave/cli/v2"
 	"net/http"
@@ -19,37 +22,122 @@ func Command() *cli.Command {
 			defer app.Close()
 
 			app.ensureSchemaRegistered()
+			app.ensureWebhookRelay()
+
+			if args.Scim.LenientJson {
+				logger := app.Logger()
+				scimjson.SetLeniency(scimjson.Leniency{
+					StringNumbers:  true,
+					StringBooleans: true,
+					ObjectForArray: true,
+					OnWarning: func(warning string) {
+						logger.Warn().Msg(warning)
+					},
+				})
+			}
 
 			var router = httprouter.New()
 			{
 				router.GET("/ServiceProviderConfig", ServiceProviderConfigHandler(app.ServiceProviderConfig()))
-				router.GET("/Schemas", SchemasHandler())
-				router.GET("/Schemas/:id", SchemaByIdHandler())
-				router.GET("/ResourceTypes", ResourceTypesHandler(app.UserResourceType(), app.GroupResourceType()))
-				router.GET("/ResourceTypes/:id", ResourceTypeByIdHandler(app.userResourceType, app.GroupResourceType()))
+				router.GET("/Schemas", SchemasHandler(app.SchemasService()))
+				router.GET("/Schemas/:id", SchemaByIdHandler(app.SchemasService()))
+				router.GET("/ResourceTypes", ResourceTypesHandler(app.ResourceTypesService()))
+				router.GET("/ResourceTypes/:id", ResourceTypeByIdHandler(app.ResourceTypesService()))
 
 				router.GET("/Users/:id", GetHandler(app.UserGetService(), app.Logger()))
 				router.GET("/Users", SearchHandler(app.UserQueryService(), app.Logger()))
-				router.POST("/Users", CreateHandler(app.UserCreateService(), app.Logger()))
-				router.PUT("/Users/:id", ReplaceHandler(app.UserReplaceService(), app.Logger()))
-				router.PATCH("/Users/:id", PatchHandler(app.UserPatchService(), app.Logger()))
-				router.DELETE("/Users/:id", DeleteHandler(app.UserDeleteService(), app.Logger()))
+				router.POST("/Users", CreateHandler(events.PublishingCreate(app.UserCreateService(), app.EventPublisher()), app.Logger()))
+				router.PUT("/Users/:id", ReplaceHandler(events.PublishingReplace(app.UserReplaceService(), app.EventPublisher()), app.Logger()))
+				router.PATCH("/Users/:id", PatchHandler(events.PublishingPatch(app.UserPatchService(), app.EventPublisher()), app.Config(), app.Logger()))
+				router.DELETE("/Users/:id", DeleteHandler(events.PublishingDelete(app.UserDeleteService(), app.EventPublisher()), app.Logger()))
+				router.POST("/Users/:id/rollback", RollbackHandler(app.UserRollbackService(), app.Logger()))
 
 				router.GET("/Groups/:id", GetHandler(app.GroupGetService(), app.Logger()))
 				router.GET("/Groups", SearchHandler(app.GroupQueryService(), app.Logger()))
-				router.POST("/Groups", CreateHandler(app.GroupCreateService(), app.Logger()))
-				router.PUT("/Groups/:id", ReplaceHandler(app.GroupReplaceService(), app.Logger()))
-				router.PATCH("/Groups/:id", PatchHandler(app.GroupPatchService(), app.Logger()))
-				router.DELETE("/Groups/:id", DeleteHandler(app.GroupDeleteService(), app.Logger()))
+				router.POST("/Groups", CreateHandler(events.PublishingCreate(app.GroupCreateService(), app.EventPublisher()), app.Logger()))
+				router.PUT("/Groups/:id", ReplaceHandler(events.PublishingReplace(app.GroupReplaceService(), app.EventPublisher()), app.Logger()))
+				router.PATCH("/Groups/:id", PatchHandler(events.PublishingPatch(app.GroupPatchService(), app.EventPublisher()), app.Config(), app.Logger()))
+				router.DELETE("/Groups/:id", DeleteHandler(events.PublishingDelete(app.GroupDeleteService(), app.EventPublisher()), app.Logger()))
+				router.POST("/Groups/:id/rollback", RollbackHandler(app.GroupRollbackService(), app.Logger()))
+				router.GET("/Groups/:id/members", ValuesHandler(app.GroupMembersService(), "members", app.Logger()))
+
+				if app.ServiceProviderConfig().Bulk.Supported {
+					router.POST("/Bulk", BulkHandler(app.BulkService(), app.Logger()))
+				}
+
+				if len(args.Scim.OpenAPIPath) > 0 {
+					router.GET(args.Scim.OpenAPIPath, OpenAPIHandler(app.ServiceProviderConfig(), app.UserResourceType(), app.GroupResourceType()))
+				}
 
 				router.GET("/health", HealthHandler(app.MongoClient(), app.RabbitMQConnection()))
+				router.GET("/healthz", HealthzHandler())
+				router.GET("/readyz", ReadyzHandler(app.ReadinessChecks()...))
+
+				if collector := app.MetricsCollector(); collector != nil {
+					router.GET("/metrics", MetricsHandler(collector))
+				}
+			}
+
+			var handler http.Handler = ClientScopeHandler(router)
+			handler = handlerutil.MutationLimitMiddleware(handler, args.Http.MutationLimit)
+			handler = handlerutil.BodyLimitMiddleware(handler, args.Http.BodyLimit,
+				handlerutil.WithBulkBodyLimit(args.Http.BulkBodyLimit))
+			{
+				logger := app.Logger()
+				handler = handlerutil.LoggerMiddleware(handler, app.StructuredLogger())
+				handler = handlerutil.RecoveryMiddleware(handler, func(correlationId string, recovered interface{}, stack []byte) {
+					logger.Error().
+						Str("correlationId", correlationId).
+						Interface("panic", recovered).
+						Bytes("stack", stack).
+						Msg("Recovered from panic while serving request.")
+				})
+				handler = handlerutil.LoggingMiddleware(handler, func(entry handlerutil.RequestLogEntry) {
+					fields := map[string]interface{}{
+						"method":   entry.Method,
+						"path":     entry.Path,
+						"status":   entry.Status,
+						"duration": entry.Duration.String(),
+					}
+					if len(entry.Filter) > 0 {
+						fields["filter"] = entry.Filter
+					}
+					if len(entry.ResourceID) > 0 {
+						fields["resourceId"] = entry.ResourceID
+					}
+					if len(entry.RequestId) > 0 {
+						fields["requestId"] = entry.RequestId
+					}
+					logger.Info().Fields(fields).Msg("Request handled.")
+				})
+			}
+			if app.MetricsCollector() != nil {
+				handler = MetricsMiddleware(handler)
+			}
+			if args.Tenant.Enabled() {
+				handler = TenantHandler(args.Tenant.Mode, args.Tenant.Header, handler)
+			}
+			if limiter := app.RateLimiter(); limiter != nil {
+				handler = RateLimitHandler(limiter, handler)
+			}
+			{
+				var options []handlerutil.RequestIdOption
+				if args.Scim.RequestIdInErrorDetail {
+					options = append(options, handlerutil.WithRequestIdInErrorDetail())
+				}
+				handler = handlerutil.RequestIdMiddleware(handler, options...)
 			}
 
 			app.Logger().Info().Fields(map[string]interface{}{
 				"port": args.httpPort,
 			}).Msg("Listening for incoming requests.")
 
-			return http.ListenAndServe(fmt.Sprintf(":%d", args.httpPort), router)
+			server := &http.Server{
+				Addr:              fmt.Sprintf(":%d", args.httpPort),
+				Handler:           handler,
+				ReadHeaderTimeout: args.Http.ReadHeaderTimeout,
+			}
+			return server.ListenAndServe()
 		},
 	}
 }
@@ -2,17 +2,30 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"github.com/imulab/go-scim/cmd/internal/groupsync"
 	scimmongo "github.com/imulab/go-scim/mongo/v2"
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/history"
+	"github.com/imulab/go-scim/pkg/v2/lifecycle"
+	"github.com/imulab/go-scim/pkg/v2/log"
+	"github.com/imulab/go-scim/pkg/v2/metrics"
+	"github.com/imulab/go-scim/pkg/v2/password"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+	"github.com/imulab/go-scim/pkg/v2/webhook"
 	"github.com/rs/zerolog"
 	"github.com/streadway/amqp"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,6 +34,7 @@ type applicationContext struct {
 	args                      *arguments
 	logger                    *zerolog.Logger
 	serviceProviderConfig     *spec.ServiceProviderConfig
+	scimConfig                *scim.Config
 	registerSchemaOnce        sync.Once
 	userResourceType          *spec.ResourceType
 	groupResourceType         *spec.ResourceType
@@ -30,6 +44,7 @@ type applicationContext struct {
 	registerMongoMetadataOnce sync.Once
 	rabbitMqConn              *amqp.Connection
 	rabbitMqChannel           *amqp.Channel
+	writeLock                 service.ResourceLock
 	userCreateService         service.Create
 	groupCreateService        service.Create
 	userReplaceService        service.Replace
@@ -42,6 +57,19 @@ type applicationContext struct {
 	groupGetService           service.Get
 	userQueryService          service.Query
 	groupQueryService         service.Query
+	groupMembersService       service.Values
+	rateLimiter               *rateLimiter
+	metricsCollector          *prometheusCollector
+	hasher                    password.Hasher
+	bulkService               service.Bulk
+	schemasService            service.Schemas
+	resourceTypesService      service.ResourceTypes
+	eventPublisher            *events.InProcessPublisher
+	startWebhookRelayOnce     sync.Once
+	userHistoryStore          history.Store
+	groupHistoryStore         history.Store
+	userRollbackService       history.Rollback
+	groupRollbackService      history.Rollback
 }
 
 func (ctx *applicationContext) Logger() *zerolog.Logger {
@@ -52,6 +80,26 @@ func (ctx *applicationContext) Logger() *zerolog.Logger {
 	return ctx.logger
 }
 
+// StructuredLogger adapts the zerolog logger to the log.Logger abstraction consumed by the library
+// packages, so that records emitted inside pkg/v2 surface through the same configured logger.
+func (ctx *applicationContext) StructuredLogger() log.Logger {
+	logger := ctx.Logger()
+	return log.Func(func(level log.Level, msg string, fields map[string]interface{}) {
+		var ev *zerolog.Event
+		switch level {
+		case log.LevelDebug:
+			ev = logger.Debug()
+		case log.LevelInfo:
+			ev = logger.Info()
+		case log.LevelWarn:
+			ev = logger.Warn()
+		default:
+			ev = logger.Error()
+		}
+		ev.Fields(fields).Msg(msg)
+	})
+}
+
 func (ctx *applicationContext) ServiceProviderConfig() *spec.ServiceProviderConfig {
 	if ctx.serviceProviderConfig == nil {
 		spc, err := ctx.args.ParseServiceProviderConfig()
@@ -65,6 +113,31 @@ func (ctx *applicationContext) ServiceProviderConfig() *spec.ServiceProviderConf
 	return ctx.serviceProviderConfig
 }
 
+// Config returns the consolidated scim.Config consumed by the services, combining the service
+// provider config document with operational knobs derived from the command line arguments.
+func (ctx *applicationContext) Config() *scim.Config {
+	if ctx.scimConfig == nil {
+		options := []scim.Option{
+			scim.WithServiceProviderConfig(ctx.ServiceProviderConfig()),
+			scim.WithBulkConcurrency(ctx.args.Bulk.Concurrency),
+			scim.WithOperationTimeout(ctx.args.Scim.OperationTimeout),
+			scim.WithPageSize(ctx.args.Scim.DefaultPageSize, ctx.args.Scim.MaxPageSize),
+		}
+		if ctx.args.Tenant.Enabled() {
+			options = append(options, scim.WithLocationFormatter(func(c context.Context, resourceType *spec.ResourceType, id string) string {
+				location := strings.TrimSuffix(resourceType.Endpoint(), "/") + "/" + id
+				if tenantId := tenant.FromContext(c); len(tenantId) > 0 {
+					location = "/" + tenantId + location
+				}
+				return location
+			}))
+		}
+		ctx.scimConfig = scim.NewConfig(options...)
+		ctx.logInitialized("scim config")
+	}
+	return ctx.scimConfig
+}
+
 func (ctx *applicationContext) UserResourceType() *spec.ResourceType {
 	ctx.ensureSchemaRegistered()
 	if ctx.userResourceType == nil {
@@ -124,17 +197,14 @@ func (ctx *applicationContext) MongoClient() *mongo.Client {
 
 func (ctx *applicationContext) UserDatabase() db.DB {
 	if ctx.userDatabase == nil {
-		if ctx.args.UseMemoryDB {
-			ctx.userDatabase = db.Memory()
-			ctx.logInitialized("in-memory user database")
+		if ctx.args.Tenant.Enabled() {
+			ctx.userDatabase = db.Partition(func(tenantId string) db.DB {
+				return ctx.newDatabase(ctx.UserResourceType(), tenantId)
+			})
+			ctx.logInitialized("tenant partitioned user database")
 		} else {
-			ctx.ensureMongoMetadata()
-			resourceType := ctx.UserResourceType()
-			collection := ctx.MongoClient().
-				Database(ctx.args.MongoDB.Database, options.Database()).
-				Collection(resourceType.Name(), options.Collection())
-			ctx.userDatabase = scimmongo.DB(resourceType, collection, scimmongo.Options().IgnoreProjection())
-			ctx.logInitialized("mongo user database")
+			ctx.userDatabase = ctx.newDatabase(ctx.UserResourceType(), "")
+			ctx.logInitialized("user database")
 		}
 	}
 	return ctx.userDatabase
@@ -142,22 +212,92 @@ func (ctx *applicationContext) UserDatabase() db.DB {
 
 func (ctx *applicationContext) GroupDatabase() db.DB {
 	if ctx.groupDatabase == nil {
-		if ctx.args.UseMemoryDB {
-			ctx.groupDatabase = db.Memory()
-			ctx.logInitialized("in-memory group database")
+		if ctx.args.Tenant.Enabled() {
+			ctx.groupDatabase = db.Partition(func(tenantId string) db.DB {
+				return ctx.newDatabase(ctx.GroupResourceType(), tenantId)
+			})
+			ctx.logInitialized("tenant partitioned group database")
 		} else {
-			ctx.ensureMongoMetadata()
-			resourceType := ctx.GroupResourceType()
-			collection := ctx.MongoClient().
-				Database(ctx.args.MongoDB.Database, options.Database()).
-				Collection(resourceType.Name(), options.Collection())
-			ctx.groupDatabase = scimmongo.DB(resourceType, collection, scimmongo.Options().IgnoreProjection())
-			ctx.logInitialized("mongo group database")
+			ctx.groupDatabase = ctx.newDatabase(ctx.GroupResourceType(), "")
+			ctx.logInitialized("group database")
 		}
 	}
 	return ctx.groupDatabase
 }
 
+// newDatabase creates a db.DB for the resource type, scoped to the given tenant. An empty tenant id produces
+// the default single-tenant database. Tenant scoped MongoDB databases store resources in a collection prefixed
+// with the tenant id, keeping each tenant's directory isolated.
+func (ctx *applicationContext) newDatabase(resourceType *spec.ResourceType, tenantId string) db.DB {
+	if ctx.args.UseMemoryDB {
+		database := ctx.newMemoryDatabase(resourceType, tenantId)
+		if ctx.args.Metrics.Enabled {
+			database = db.Instrumented(database)
+		}
+		return database
+	}
+
+	ctx.ensureMongoMetadata()
+	collectionName := resourceType.Name()
+	if len(tenantId) > 0 {
+		collectionName = fmt.Sprintf("%s_%s", tenantId, collectionName)
+	}
+	collection := ctx.MongoClient().
+		Database(ctx.args.MongoDB.Database, options.Database()).
+		Collection(collectionName, options.Collection())
+	database := db.DB(scimmongo.DB(resourceType, collection, scimmongo.Options().IgnoreProjection()))
+	if ctx.args.Metrics.Enabled {
+		database = db.Instrumented(database)
+	}
+	return database
+}
+
+// newMemoryDatabase creates the in-memory database for the resource type, with snapshot persistence
+// when a snapshot directory is configured.
+func (ctx *applicationContext) newMemoryDatabase(resourceType *spec.ResourceType, tenantId string) db.DB {
+	if len(ctx.args.MemoryDB.SnapshotDir) == 0 {
+		return db.Memory()
+	}
+
+	name := resourceType.Name()
+	if len(tenantId) > 0 {
+		name = fmt.Sprintf("%s_%s", tenantId, name)
+	}
+	options := db.SnapshotOptions(resourceType, filepath.Join(ctx.args.MemoryDB.SnapshotDir, name+".jsonl"))
+	if ctx.args.MemoryDB.SnapshotInterval > 0 {
+		options.Every(ctx.args.MemoryDB.SnapshotInterval)
+	}
+	if ctx.args.MemoryDB.SnapshotFSync {
+		options.FSync()
+	}
+
+	database, err := db.MemoryWithSnapshot(options)
+	if err != nil {
+		ctx.logInitFailure("memory database snapshot", err)
+		panic(err)
+	}
+	return database
+}
+
+// metaFilter returns the meta filter used by mutating services. In multi-tenant deployments, the
+// meta.location URL is prefixed with the tenant id so that it mirrors the path based routing layout.
+func (ctx *applicationContext) metaFilter() filter.ByResource {
+	if !ctx.args.Tenant.Enabled() {
+		return filter.MetaFilter()
+	}
+	return filter.MetaFilterWithLocation(ctx.Config().LocationOf)
+}
+
+// validationFilter returns the validation stage used by mutating services. When aggregation is
+// enabled, all validation violations of a payload are reported in one error response instead of
+// failing fast on the first.
+func (ctx *applicationContext) validationFilter(database db.DB) filter.ByResource {
+	if ctx.args.Scim.AggregateValidationErrors {
+		return filter.AggregateValidation(filter.ValidationFilter(database))
+	}
+	return filter.ByPropertyToByResource(filter.ValidationFilter(database))
+}
+
 func (ctx *applicationContext) ensureMongoMetadata() {
 	ctx.registerMongoMetadataOnce.Do(func() {
 		if err := ctx.args.MongoDB.RegisterMetadata(); err != nil {
@@ -168,17 +308,72 @@ func (ctx *applicationContext) ensureMongoMetadata() {
 	})
 }
 
+// createReadOnlyFilter returns the readOnly property filter used during resource creation, which trusts client
+// supplied resource ids when the trusted-client-id flag is enabled.
+func (ctx *applicationContext) createReadOnlyFilter() filter.ByProperty {
+	if ctx.args.Scim.TrustedClientId {
+		return filter.ReadOnlyFilterWithTrustedId()
+	}
+	return filter.ReadOnlyFilter()
+}
+
+// modifyReadOnlyFilter returns the readOnly property filter used during resource modification, which rejects
+// attempts to change readOnly values when the strict-read-only flag is enabled, and silently restores the
+// stored values otherwise.
+func (ctx *applicationContext) modifyReadOnlyFilter() filter.ByProperty {
+	if ctx.args.Scim.StrictReadOnly {
+		return filter.StrictReadOnlyFilter()
+	}
+	return filter.ReadOnlyFilter()
+}
+
+// userQuotaFilter returns the quota enforcement stage for User resources. With no quota configured
+// the stage enforces nothing.
+func (ctx *applicationContext) userQuotaFilter() filter.ByResource {
+	return filter.QuotaFilter(ctx.UserDatabase(), filter.UniformQuota(filter.Quota{
+		MaxResources: ctx.args.Scim.UserQuota,
+	}))
+}
+
+// groupQuotaFilter returns the quota enforcement stage for Group resources, capping both the total
+// number of Groups and the number of members per Group. With no quota configured the stage enforces
+// nothing.
+func (ctx *applicationContext) groupQuotaFilter() filter.ByResource {
+	return filter.QuotaFilter(ctx.GroupDatabase(), filter.UniformQuota(filter.Quota{
+		MaxResources: ctx.args.Scim.GroupQuota,
+		MaxValues:    map[string]int{"members": ctx.args.Scim.GroupMemberQuota},
+	}))
+}
+
+// idFilter returns the identifier generating property filter reflecting the configured id format.
+func (ctx *applicationContext) idFilter() filter.ByProperty {
+	if ctx.args.Scim.IdFormat == "ulid" {
+		return filter.IdFilter(filter.ULIDGenerator())
+	}
+	return filter.UUIDFilter()
+}
+
 func (ctx *applicationContext) UserCreateService() service.Create {
 	if ctx.userCreateService == nil {
-		ctx.userCreateService = service.CreateService(ctx.UserResourceType(), ctx.UserDatabase(), []filter.ByResource{
+		created := service.CreateService(ctx.Config(), ctx.UserResourceType(), ctx.UserDatabase(), []filter.ByResource{
+			ctx.userQuotaFilter(),
 			filter.ByPropertyToByResource(
-				filter.ReadOnlyFilter(),
-				filter.UUIDFilter(),
-				filter.BCryptFilter(),
+				ctx.createReadOnlyFilter(),
+				ctx.idFilter(),
+				filter.PasswordFilter(ctx.passwordHasher()),
 			),
-			filter.MetaFilter(),
-			filter.ByPropertyToByResource(filter.ValidationFilter(ctx.UserDatabase())),
+			filter.EnterpriseManagerFilter(ctx.UserDatabase()),
+			ctx.metaFilter(),
+			filter.SchemaFilter(),
+			ctx.validationFilter(ctx.UserDatabase()),
 		})
+		if ctx.args.Scim.ExternalIdUpsert {
+			created = service.UpsertCreateService(ctx.Config(), ctx.UserResourceType(), ctx.UserDatabase(), created, ctx.UserReplaceService())
+		}
+		if ttl := ctx.args.Scim.IdempotencyKeyTTL; ttl > 0 {
+			created = service.IdempotentCreateService(service.MemoryIdempotencyStore(ttl), created)
+		}
+		ctx.userCreateService = created
 		ctx.logInitialized("user create service")
 	}
 	return ctx.userCreateService
@@ -186,35 +381,56 @@ func (ctx *applicationContext) UserCreateService() service.Create {
 
 func (ctx *applicationContext) GroupCreateService() service.Create {
 	if ctx.groupCreateService == nil {
-		ctx.groupCreateService = &groupCreated{
-			service: service.CreateService(ctx.GroupResourceType(), ctx.GroupDatabase(), []filter.ByResource{
+		var created service.Create = &groupCreated{
+			service: service.CreateService(ctx.Config(), ctx.GroupResourceType(), ctx.GroupDatabase(), []filter.ByResource{
+				ctx.groupQuotaFilter(),
 				filter.ByPropertyToByResource(
-					filter.ReadOnlyFilter(),
-					filter.UUIDFilter(),
+					ctx.createReadOnlyFilter(),
+					ctx.idFilter(),
 				),
-				filter.MetaFilter(),
-				filter.ByPropertyToByResource(filter.ValidationFilter(ctx.GroupDatabase())),
+				ctx.metaFilter(),
+				filter.SchemaFilter(),
+				ctx.validationFilter(ctx.GroupDatabase()),
 			}),
 			sender: &groupSyncSender{
 				channel: ctx.RabbitMQChannel(),
 				logger:  ctx.Logger(),
 			},
 		}
+		if ctx.args.Scim.ExternalIdUpsert {
+			created = service.UpsertCreateService(ctx.Config(), ctx.GroupResourceType(), ctx.GroupDatabase(), created, ctx.GroupReplaceService())
+		}
+		if ttl := ctx.args.Scim.IdempotencyKeyTTL; ttl > 0 {
+			created = service.IdempotentCreateService(service.MemoryIdempotencyStore(ttl), created)
+		}
+		ctx.groupCreateService = created
 		ctx.logInitialized("group create service")
 	}
 	return ctx.groupCreateService
 }
 
+// resourceWriteLock returns the process-wide keyed lock shared by the patch and replace services of
+// both resource types, so that concurrent writes to the same resource are serialized instead of one
+// silently overwriting the other when no version pre condition guards them.
+func (ctx *applicationContext) resourceWriteLock() service.ResourceLock {
+	if ctx.writeLock == nil {
+		ctx.writeLock = service.NewResourceLock()
+	}
+	return ctx.writeLock
+}
+
 func (ctx *applicationContext) UserReplaceService() service.Replace {
 	if ctx.userReplaceService == nil {
-		ctx.userReplaceService = service.ReplaceService(ctx.ServiceProviderConfig(), ctx.UserResourceType(), ctx.UserDatabase(), []filter.ByResource{
+		ctx.userReplaceService = service.SerializedReplaceService(ctx.resourceWriteLock(), lifecycle.HookedReplace(service.ReplaceService(ctx.Config(), ctx.UserResourceType(), ctx.UserDatabase(), []filter.ByResource{
 			filter.ByPropertyToByResource(
-				filter.ReadOnlyFilter(),
-				filter.BCryptFilter(),
+				ctx.modifyReadOnlyFilter(),
+				filter.PasswordFilter(ctx.passwordHasher()),
 			),
-			filter.ByPropertyToByResource(filter.ValidationFilter(ctx.UserDatabase())),
-			filter.MetaFilter(),
-		})
+			filter.EnterpriseManagerFilter(ctx.UserDatabase()),
+			filter.SchemaFilter(),
+			ctx.validationFilter(ctx.UserDatabase()),
+			ctx.metaFilter(),
+		}), ctx.deactivationHooks()...))
 		ctx.logInitialized("user replace service")
 	}
 	return ctx.userReplaceService
@@ -222,19 +438,21 @@ func (ctx *applicationContext) UserReplaceService() service.Replace {
 
 func (ctx *applicationContext) GroupReplaceService() service.Replace {
 	if ctx.groupReplaceService == nil {
-		ctx.groupReplaceService = &groupReplaced{
-			service: service.ReplaceService(ctx.ServiceProviderConfig(), ctx.GroupResourceType(), ctx.GroupDatabase(), []filter.ByResource{
+		ctx.groupReplaceService = service.SerializedReplaceService(ctx.resourceWriteLock(), &groupReplaced{
+			service: service.ReplaceService(ctx.Config(), ctx.GroupResourceType(), ctx.GroupDatabase(), []filter.ByResource{
+				ctx.groupQuotaFilter(),
 				filter.ByPropertyToByResource(
-					filter.ReadOnlyFilter(),
+					ctx.modifyReadOnlyFilter(),
 				),
-				filter.ByPropertyToByResource(filter.ValidationFilter(ctx.UserDatabase())),
-				filter.MetaFilter(),
+				filter.SchemaFilter(),
+				ctx.validationFilter(ctx.UserDatabase()),
+				ctx.metaFilter(),
 			}),
 			sender: &groupSyncSender{
 				channel: ctx.RabbitMQChannel(),
 				logger:  ctx.Logger(),
 			},
-		}
+		})
 		ctx.logInitialized("group replace service")
 	}
 	return ctx.groupReplaceService
@@ -242,14 +460,16 @@ func (ctx *applicationContext) GroupReplaceService() service.Replace {
 
 func (ctx *applicationContext) UserPatchService() service.Patch {
 	if ctx.userPatchService == nil {
-		ctx.userPatchService = service.PatchService(ctx.ServiceProviderConfig(), ctx.UserDatabase(), []filter.ByResource{}, []filter.ByResource{
+		ctx.userPatchService = service.SerializedPatchService(ctx.resourceWriteLock(), lifecycle.HookedPatch(service.PatchService(ctx.Config(), ctx.UserDatabase(), []filter.ByResource{}, []filter.ByResource{
 			filter.ByPropertyToByResource(
-				filter.ReadOnlyFilter(),
-				filter.BCryptFilter(),
+				ctx.modifyReadOnlyFilter(),
+				filter.PasswordFilter(ctx.passwordHasher()),
 			),
-			filter.ByPropertyToByResource(filter.ValidationFilter(ctx.UserDatabase())),
-			filter.MetaFilter(),
-		})
+			filter.EnterpriseManagerFilter(ctx.UserDatabase()),
+			filter.SchemaFilter(),
+			ctx.validationFilter(ctx.UserDatabase()),
+			ctx.metaFilter(),
+		}), ctx.deactivationHooks()...))
 		ctx.logInitialized("user patch service")
 	}
 	return ctx.userPatchService
@@ -257,19 +477,21 @@ func (ctx *applicationContext) UserPatchService() service.Patch {
 
 func (ctx *applicationContext) GroupPatchService() service.Patch {
 	if ctx.groupPatchService == nil {
-		ctx.groupPatchService = &groupPatched{
-			service: service.PatchService(ctx.ServiceProviderConfig(), ctx.GroupDatabase(), []filter.ByResource{}, []filter.ByResource{
+		ctx.groupPatchService = service.SerializedPatchService(ctx.resourceWriteLock(), &groupPatched{
+			service: service.PatchService(ctx.Config(), ctx.GroupDatabase(), []filter.ByResource{}, []filter.ByResource{
+				ctx.groupQuotaFilter(),
 				filter.ByPropertyToByResource(
-					filter.ReadOnlyFilter(),
+					ctx.modifyReadOnlyFilter(),
 				),
-				filter.ByPropertyToByResource(filter.ValidationFilter(ctx.GroupDatabase())),
-				filter.MetaFilter(),
+				filter.SchemaFilter(),
+				ctx.validationFilter(ctx.GroupDatabase()),
+				ctx.metaFilter(),
 			}),
 			sender: &groupSyncSender{
 				channel: ctx.RabbitMQChannel(),
 				logger:  ctx.Logger(),
 			},
-		}
+		})
 		ctx.logInitialized("group patch service")
 	}
 	return ctx.groupPatchService
@@ -277,16 +499,41 @@ func (ctx *applicationContext) GroupPatchService() service.Patch {
 
 func (ctx *applicationContext) UserDeleteService() service.Delete {
 	if ctx.userDeleteService == nil {
-		ctx.userDeleteService = service.DeleteService(ctx.ServiceProviderConfig(), ctx.UserDatabase())
+		if ctx.args.Scim.SoftDelete {
+			ctx.userDeleteService = lifecycle.SoftDelete(ctx.Config(), ctx.UserDatabase(), []filter.ByResource{
+				ctx.metaFilter(),
+			}, ctx.deactivationHooks()...)
+		} else {
+			ctx.userDeleteService = service.DeleteService(ctx.Config(), ctx.UserDatabase())
+		}
 		ctx.logInitialized("user delete service")
 	}
 	return ctx.userDeleteService
 }
 
+// deactivationHooks returns the side effects fired when a user transitions to inactive state. A
+// deactivation webhook is notified when one is configured; the hook logs delivery failures as the
+// deactivation has already committed.
+func (ctx *applicationContext) deactivationHooks() []lifecycle.Hook {
+	if len(ctx.args.Scim.DeactivationWebhook) == 0 {
+		return nil
+	}
+
+	var (
+		url    = ctx.args.Scim.DeactivationWebhook
+		logger = ctx.Logger()
+	)
+	return []lifecycle.Hook{
+		lifecycle.WebhookHook(url, nil, func(err error) {
+			logger.Err(err).Str("webhook", url).Msg("Failed to deliver deactivation webhook.")
+		}),
+	}
+}
+
 func (ctx *applicationContext) GroupDeleteService() service.Delete {
 	if ctx.groupDeleteService == nil {
 		ctx.groupDeleteService = &groupDeleted{
-			service: service.DeleteService(ctx.ServiceProviderConfig(), ctx.GroupDatabase()),
+			service: service.DeleteService(ctx.Config(), ctx.GroupDatabase()),
 			sender: &groupSyncSender{
 				channel: ctx.RabbitMQChannel(),
 				logger:  ctx.Logger(),
@@ -297,9 +544,36 @@ func (ctx *applicationContext) GroupDeleteService() service.Delete {
 	return ctx.groupDeleteService
 }
 
+// BulkService returns the bulk processing service, dispatching operations to the user and group services.
+func (ctx *applicationContext) BulkService() service.Bulk {
+	if ctx.bulkService == nil {
+		ctx.bulkService = service.BulkService(ctx.Config(),
+			&service.BulkBackend{
+				ResourceType: ctx.UserResourceType(),
+				Create:       ctx.UserCreateService(),
+				Replace:      ctx.UserReplaceService(),
+				Patch:        ctx.UserPatchService(),
+				Delete:       ctx.UserDeleteService(),
+			},
+			&service.BulkBackend{
+				ResourceType: ctx.GroupResourceType(),
+				Create:       ctx.GroupCreateService(),
+				Replace:      ctx.GroupReplaceService(),
+				Patch:        ctx.GroupPatchService(),
+				Delete:       ctx.GroupDeleteService(),
+			},
+		)
+		ctx.logInitialized("bulk service")
+	}
+	return ctx.bulkService
+}
+
 func (ctx *applicationContext) UserGetService() service.Get {
 	if ctx.userGetService == nil {
-		ctx.userGetService = service.GetService(ctx.UserDatabase())
+		ctx.userGetService = &normalizedProjectionGet{
+			resourceType: ctx.UserResourceType(),
+			service:      service.GetService(ctx.Config(), ctx.UserDatabase()),
+		}
 		ctx.logInitialized("user get service")
 	}
 	return ctx.userGetService
@@ -307,7 +581,10 @@ func (ctx *applicationContext) UserGetService() service.Get {
 
 func (ctx *applicationContext) GroupGetService() service.Get {
 	if ctx.groupGetService == nil {
-		ctx.groupGetService = service.GetService(ctx.GroupDatabase())
+		ctx.groupGetService = &normalizedProjectionGet{
+			resourceType: ctx.GroupResourceType(),
+			service:      service.GetService(ctx.Config(), ctx.GroupDatabase()),
+		}
 		ctx.logInitialized("group get service")
 	}
 	return ctx.groupGetService
@@ -315,7 +592,13 @@ func (ctx *applicationContext) GroupGetService() service.Get {
 
 func (ctx *applicationContext) UserQueryService() service.Query {
 	if ctx.userQueryService == nil {
-		ctx.userQueryService = service.QueryService(ctx.ServiceProviderConfig(), ctx.UserDatabase())
+		ctx.userQueryService = &normalizedProjectionQuery{
+			resourceType: ctx.UserResourceType(),
+			service:      service.QueryService(ctx.Config(), ctx.UserDatabase()),
+		}
+		if ctx.args.Scim.RelativeTimeFilter {
+			ctx.userQueryService = &relativeTimeQuery{service: ctx.userQueryService}
+		}
 		ctx.logInitialized("user query service")
 	}
 	return ctx.userQueryService
@@ -323,12 +606,198 @@ func (ctx *applicationContext) UserQueryService() service.Query {
 
 func (ctx *applicationContext) GroupQueryService() service.Query {
 	if ctx.groupQueryService == nil {
-		ctx.groupQueryService = service.QueryService(ctx.ServiceProviderConfig(), ctx.GroupDatabase())
+		ctx.groupQueryService = &normalizedProjectionQuery{
+			resourceType: ctx.GroupResourceType(),
+			service:      service.QueryService(ctx.Config(), ctx.GroupDatabase()),
+		}
+		if ctx.args.Scim.RelativeTimeFilter {
+			ctx.groupQueryService = &relativeTimeQuery{service: ctx.groupQueryService}
+		}
 		ctx.logInitialized("group query service")
 	}
 	return ctx.groupQueryService
 }
 
+func (ctx *applicationContext) GroupMembersService() service.Values {
+	if ctx.groupMembersService == nil {
+		ctx.groupMembersService = service.ValuesService(ctx.Config(), ctx.GroupDatabase())
+		ctx.logInitialized("group members service")
+	}
+	return ctx.groupMembersService
+}
+
+func (ctx *applicationContext) SchemasService() service.Schemas {
+	if ctx.schemasService == nil {
+		ctx.schemasService = service.SchemasService(ctx.Config(), nil)
+		ctx.logInitialized("schemas service")
+	}
+	return ctx.schemasService
+}
+
+func (ctx *applicationContext) ResourceTypesService() service.ResourceTypes {
+	if ctx.resourceTypesService == nil {
+		ctx.resourceTypesService = service.ResourceTypesService(ctx.Config(), nil, ctx.UserResourceType(), ctx.GroupResourceType())
+		ctx.logInitialized("resource types service")
+	}
+	return ctx.resourceTypesService
+}
+
+// MetricsCollector returns the Prometheus metrics collector, or nil when metrics are disabled. On first
+// call, the collector is also registered as the global collector of the metrics package.
+func (ctx *applicationContext) MetricsCollector() *prometheusCollector {
+	if ctx.metricsCollector == nil && ctx.args.Metrics.Enabled {
+		ctx.metricsCollector = newPrometheusCollector()
+		metrics.SetCollector(ctx.metricsCollector)
+		ctx.logInitialized("metrics collector")
+	}
+	return ctx.metricsCollector
+}
+
+// passwordHasher returns the password hasher selected by the --password-algorithm option.
+func (ctx *applicationContext) passwordHasher() password.Hasher {
+	if ctx.hasher == nil {
+		switch ctx.args.Password.Algorithm {
+		case "argon2id":
+			ctx.hasher = password.Argon2id()
+		default:
+			ctx.hasher = password.BCrypt(ctx.args.Password.BCryptCost)
+		}
+		ctx.logInitialized("password hasher")
+	}
+	return ctx.hasher
+}
+
+// RateLimiter returns the per-client rate limiter, or nil when rate limiting is disabled.
+func (ctx *applicationContext) RateLimiter() *rateLimiter {
+	if ctx.rateLimiter == nil && ctx.args.RateLimit.RequestsPerSecond > 0 {
+		ctx.rateLimiter = newRateLimiter(ctx.args.RateLimit.RequestsPerSecond, ctx.args.RateLimit.Burst)
+		ctx.logInitialized("rate limiter")
+	}
+	return ctx.rateLimiter
+}
+
+// ReadinessChecks returns the readiness probes for the configured backends: the schema registry must
+// hold the schemas of both resource types, and the database backend must be reachable. The in-memory
+// database is always ready once constructed; MongoDB is probed with a ping.
+func (ctx *applicationContext) ReadinessChecks() []ReadinessCheck {
+	checks := []ReadinessCheck{
+		{
+			Name: "schemas",
+			Check: func(_ context.Context) error {
+				for _, resourceType := range []*spec.ResourceType{ctx.UserResourceType(), ctx.GroupResourceType()} {
+					if _, ok := spec.Schemas().Get(resourceType.Schema().ID()); !ok {
+						return fmt.Errorf("schema '%s' is not registered", resourceType.Schema().ID())
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	if ctx.args.UseMemoryDB {
+		checks = append(checks, ReadinessCheck{
+			Name:  "database",
+			Check: func(_ context.Context) error { return nil },
+		})
+	} else {
+		checks = append(checks, ReadinessCheck{
+			Name: "database",
+			Check: func(probeCtx context.Context) error {
+				return ctx.MongoClient().Ping(probeCtx, readpref.Primary())
+			},
+		})
+	}
+
+	return checks
+}
+
+// EventPublisher returns the in-process publisher fanning resource change events out to consumers
+// living in the same process, i.e. the history stores.
+func (ctx *applicationContext) EventPublisher() *events.InProcessPublisher {
+	if ctx.eventPublisher == nil {
+		ctx.eventPublisher = events.InProcess(64)
+		ctx.logInitialized("event publisher")
+	}
+	return ctx.eventPublisher
+}
+
+// ensureWebhookRelay starts the webhook relay on its own goroutine when webhook endpoints are
+// configured, delivering resource change notifications to them. Deliveries that exhaust their
+// attempts are logged as dead letters.
+func (ctx *applicationContext) ensureWebhookRelay() {
+	ctx.startWebhookRelayOnce.Do(func() {
+		if !ctx.args.Webhook.Enabled() {
+			return
+		}
+
+		var endpoints []webhook.Endpoint
+		for _, url := range ctx.args.Webhook.UrlList() {
+			endpoints = append(endpoints, webhook.Endpoint{URL: url, Secret: ctx.args.Webhook.Secret})
+		}
+
+		go webhook.Relay(context.Background(), ctx.EventPublisher().Subscribe(), endpoints, &webhook.Options{
+			MaxAttempts: ctx.args.Webhook.MaxAttempts,
+			Logger:      ctx.StructuredLogger(),
+		})
+		ctx.logInitialized("webhook relay")
+	})
+}
+
+// UserHistoryStore returns the version history store for user resources, mirrored from resource
+// change events on its own goroutine.
+func (ctx *applicationContext) UserHistoryStore() history.Store {
+	if ctx.userHistoryStore == nil {
+		ctx.userHistoryStore = ctx.newHistoryStore(ctx.UserResourceType())
+		ctx.logInitialized("user history store")
+	}
+	return ctx.userHistoryStore
+}
+
+// GroupHistoryStore returns the version history store for group resources, mirrored from resource
+// change events on its own goroutine.
+func (ctx *applicationContext) GroupHistoryStore() history.Store {
+	if ctx.groupHistoryStore == nil {
+		ctx.groupHistoryStore = ctx.newHistoryStore(ctx.GroupResourceType())
+		ctx.logInitialized("group history store")
+	}
+	return ctx.groupHistoryStore
+}
+
+func (ctx *applicationContext) newHistoryStore(resourceType *spec.ResourceType) history.Store {
+	store := history.Memory(0)
+	logger := ctx.Logger()
+	go history.Mirror(context.Background(), resourceType, ctx.EventPublisher().Subscribe(), store, func(err error) {
+		logger.Err(err).Msg("Failed to record resource version history.")
+	})
+	return store
+}
+
+func (ctx *applicationContext) UserRollbackService() history.Rollback {
+	if ctx.userRollbackService == nil {
+		ctx.userRollbackService = history.PublishingRollback(
+			history.RollbackService(ctx.Config(), ctx.UserResourceType(), ctx.UserDatabase(), ctx.UserHistoryStore(), []filter.ByResource{
+				ctx.metaFilter(),
+			}),
+			ctx.EventPublisher(),
+		)
+		ctx.logInitialized("user rollback service")
+	}
+	return ctx.userRollbackService
+}
+
+func (ctx *applicationContext) GroupRollbackService() history.Rollback {
+	if ctx.groupRollbackService == nil {
+		ctx.groupRollbackService = history.PublishingRollback(
+			history.RollbackService(ctx.Config(), ctx.GroupResourceType(), ctx.GroupDatabase(), ctx.GroupHistoryStore(), []filter.ByResource{
+				ctx.metaFilter(),
+			}),
+			ctx.EventPublisher(),
+		)
+		ctx.logInitialized("group rollback service")
+	}
+	return ctx.groupRollbackService
+}
+
 func (ctx *applicationContext) RabbitMQConnection() *amqp.Connection {
 	if ctx.rabbitMqConn == nil {
 		connectCtx, cancelFunc := context.WithTimeout(context.Background(), 30*time.Second)
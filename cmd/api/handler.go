@@ -4,8 +4,11 @@ import (
 	gojson "encoding/json"
 	"errors"
 	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/handlerutil"
 	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/julienschmidt/httprouter"
@@ -14,7 +17,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"net/http"
-	"net/http/httptest"
+	"strconv"
 )
 
 // CreateHandler returns a route handler function for creating SCIM resources.
@@ -28,12 +31,20 @@ func CreateHandler(svc service.Create, log *zerolog.Logger) func(rw http.Respons
 			log.
 				Err(err).
 				Msg("error when creating resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
-		log.Info().Msg("resource created")
-		rw.WriteHeader(201)
+		if resp.Replayed {
+			log.Info().Msg("resource creation replayed by idempotency key")
+			rw.WriteHeader(201)
+		} else if resp.Replaced {
+			log.Info().Msg("resource updated in place by externalId")
+			rw.WriteHeader(200)
+		} else {
+			log.Info().Msg("resource created")
+			rw.WriteHeader(201)
+		}
 		_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
 	}
 }
@@ -47,7 +58,7 @@ func GetHandler(svc service.Get, log *zerolog.Logger) func(rw http.ResponseWrite
 			log.
 				Err(err).
 				Msg("error receiving get request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -56,7 +67,7 @@ func GetHandler(svc service.Get, log *zerolog.Logger) func(rw http.ResponseWrite
 			log.
 				Err(err).
 				Msg("error parsing getting request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -68,7 +79,12 @@ func GetHandler(svc service.Get, log *zerolog.Logger) func(rw http.ResponseWrite
 			log.
 				Err(err).
 				Msg("error when getting resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		if !handlerutil.ModifiedSinceCriteria(r)(resp.Resource) {
+			rw.WriteHeader(http.StatusNotModified)
 			return
 		}
 
@@ -95,7 +111,7 @@ func DeleteHandler(svc service.Delete, log *zerolog.Logger) func(rw http.Respons
 			log.
 				Err(err).
 				Msg("error receiving delete request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -104,7 +120,7 @@ func DeleteHandler(svc service.Delete, log *zerolog.Logger) func(rw http.Respons
 			log.
 				Err(err).
 				Msg("error when deleting resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -112,6 +128,50 @@ func DeleteHandler(svc service.Delete, log *zerolog.Logger) func(rw http.Respons
 	}
 }
 
+// BulkHandler returns a route handler function for processing SCIM bulk requests. The bulk response
+// is streamed to the client as operations complete; errors that occur after the first byte has been
+// written can only be logged.
+func BulkHandler(svc service.Bulk, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		defer func() { _ = r.Body.Close() }()
+
+		sink := &bulkResponseSink{rw: rw}
+		resp, err := svc.Do(r.Context(), &service.BulkRequest{
+			PayloadSource: r.Body,
+			ResponseSink:  handlerutil.BulkIdRewriter(sink),
+		})
+		if err != nil {
+			log.
+				Err(err).
+				Msg("error when processing bulk request")
+			if !sink.started {
+				_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			}
+			return
+		}
+
+		log.Info().
+			Int("operations", resp.Operations).
+			Int("failures", resp.Failures).
+			Msg("bulk request processed")
+	}
+}
+
+// bulkResponseSink adapts http.ResponseWriter as the response sink of the bulk service, setting the
+// Content-Type header just before the first byte is written.
+type bulkResponseSink struct {
+	rw      http.ResponseWriter
+	started bool
+}
+
+func (s *bulkResponseSink) Write(p []byte) (int, error) {
+	if !s.started {
+		s.rw.Header().Set("Content-Type", spec.ApplicationScimJson)
+		s.started = true
+	}
+	return s.rw.Write(p)
+}
+
 // ReplaceHandler returns a route handler function for replacing SCIM resource.
 func ReplaceHandler(svc service.Replace, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -121,7 +181,7 @@ func ReplaceHandler(svc service.Replace, log *zerolog.Logger) func(rw http.Respo
 			log.
 				Err(err).
 				Msg("error receiving replace request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -133,7 +193,7 @@ func ReplaceHandler(svc service.Replace, log *zerolog.Logger) func(rw http.Respo
 			log.
 				Err(err).
 				Msg("error when replacing resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -146,8 +206,10 @@ func ReplaceHandler(svc service.Replace, log *zerolog.Logger) func(rw http.Respo
 	}
 }
 
-// PatchHandler returns a route handler function for patching SCIM resource.
-func PatchHandler(svc service.Patch, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+// PatchHandler returns a route handler function for patching SCIM resource. A successful patch normally
+// responds with 200 and the updated resource, honoring the attributes and excludedAttributes parameters;
+// when the service provider config sets patch.noContent, it responds with 204 No Content instead.
+func PatchHandler(svc service.Patch, config *scim.Config, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
 		id := params.ByName("id")
 		if len(id) == 0 {
@@ -155,7 +217,16 @@ func PatchHandler(svc service.Patch, log *zerolog.Logger) func(rw http.ResponseW
 			log.
 				Err(err).
 				Msg("error receiving patching request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		projection, err := handlerutil.GetRequestProjection(r)
+		if err != nil {
+			log.
+				Err(err).
+				Msg("error parsing patching request")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -167,16 +238,25 @@ func PatchHandler(svc service.Patch, log *zerolog.Logger) func(rw http.ResponseW
 			log.
 				Err(err).
 				Msg("error when patching resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
-		if !resp.Patched {
-			rw.WriteHeader(204)
-			return
+		var opt []json.Options
+		if projection != nil {
+			if len(projection.Attributes) > 0 {
+				opt = append(opt, json.Include(projection.Attributes...))
+			}
+			if len(projection.ExcludedAttributes) > 0 {
+				opt = append(opt, json.Exclude(projection.ExcludedAttributes...))
+			}
 		}
 
-		_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
+		var patched *prop.Resource
+		if resp.Patched {
+			patched = resp.Resource
+		}
+		_ = handlerutil.WritePatchedResourceToResponse(rw, patched, config, opt...)
 	}
 }
 
@@ -203,7 +283,7 @@ func SearchHandler(svc service.Query, log *zerolog.Logger) func(rw http.Response
 			log.
 				Err(err).
 				Msg("error when parsing search request")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
@@ -216,7 +296,12 @@ func SearchHandler(svc service.Query, log *zerolog.Logger) func(rw http.Response
 			log.
 				Err(err).
 				Msg("error when searching resource")
-			_ = handlerutil.WriteError(rw, err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		if !anyModifiedSince(r, resp) {
+			rw.WriteHeader(http.StatusNotModified)
 			return
 		}
 
@@ -230,10 +315,82 @@ func SearchHandler(svc service.Query, log *zerolog.Logger) func(rw http.Response
 			}
 		}
 
-		_ = handlerutil.WriteSearchResultToResponse(rw, resp)
+		crw, finish := handlerutil.NegotiateCompression(rw, r)
+		defer func() { _ = finish() }()
+		_ = handlerutil.WriteSearchResultToResponse(crw, resp)
 	}
 }
 
+// ValuesHandler returns a http route handler to page through the elements of the multiValued attribute
+// at path of the resource identified by the id route parameter. The page is controlled by the
+// startIndex and count query parameters and rendered in the shape of a ListResponse.
+func ValuesHandler(svc service.Values, path string, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		id := params.ByName("id")
+		if len(id) == 0 {
+			err := fmt.Errorf("%w: id is empty", spec.ErrInvalidSyntax)
+			log.
+				Err(err).
+				Msg("error receiving values request")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		pagination, err := paginationFromRequest(r)
+		if err != nil {
+			log.
+				Err(err).
+				Msg("error parsing values request")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		resp, err := svc.Do(r.Context(), &service.ValuesRequest{
+			ResourceID: id,
+			Path:       path,
+			Pagination: pagination,
+		})
+		if err != nil {
+			log.
+				Err(err).
+				Msg("error when paging attribute values")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		_ = handlerutil.WriteValuesResultToResponse(rw, resp)
+	}
+}
+
+// paginationFromRequest parses the startIndex and count query parameters, returning nil when both
+// are omitted.
+func paginationFromRequest(r *http.Request) (*crud.Pagination, error) {
+	startIndexValue, countValue := r.URL.Query().Get("startIndex"), r.URL.Query().Get("count")
+	if len(startIndexValue) == 0 && len(countValue) == 0 {
+		return nil, nil
+	}
+
+	pagination := &crud.Pagination{StartIndex: 1}
+
+	if len(startIndexValue) > 0 {
+		i, err := strconv.Atoi(startIndexValue)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("%w: parameter startIndex must be a 1-based integer", spec.ErrInvalidSyntax)
+		}
+		pagination.StartIndex = i
+	}
+
+	if len(countValue) > 0 {
+		c, err := strconv.Atoi(countValue)
+		if err != nil || c < 0 {
+			return nil, fmt.Errorf("%w: parameter count must be a non-negative integer", spec.ErrInvalidSyntax)
+		}
+		pagination.Count = c
+	}
+
+	return pagination, nil
+}
+
 // ServiceProviderConfigHandler returns a http route handler to write service provider config info.
 func ServiceProviderConfigHandler(config *spec.ServiceProviderConfig) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	raw, err := gojson.Marshal(config)
@@ -248,108 +405,132 @@ func ServiceProviderConfigHandler(config *spec.ServiceProviderConfig) func(rw ht
 }
 
 // ResourceTypesHandler returns a route handler function for getting all defined ResourceType.
-func ResourceTypesHandler(resourceTypes ...*spec.ResourceType) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	result := &service.QueryResponse{
-		TotalResults: len(resourceTypes),
-		StartIndex:   1,
-		ItemsPerPage: len(resourceTypes),
-		Resources:    []json.Serializable{},
-	}
-	for _, resourceType := range resourceTypes {
-		result.Resources = append(result.Resources, json.ResourceTypeToSerializable(resourceType))
-	}
+func ResourceTypesHandler(svc service.ResourceTypes) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		projection, err := handlerutil.GetRequestProjection(r)
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
 
-	// use recorder to cache render result
-	recorder := httptest.NewRecorder()
-	if err := handlerutil.WriteSearchResultToResponse(recorder, result); err != nil {
-		panic(err)
-	}
+		resp, err := svc.Do(r.Context(), &service.ResourceTypesRequest{Projection: projection})
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
 
-	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-		rw.Header().Set("Content-Type", recorder.Header().Get("Content-Type"))
-		_, _ = rw.Write(recorder.Body.Bytes())
+		_ = handlerutil.WriteSearchResultToResponse(rw, resp, projectionOptions(resp.Projection)...)
 	}
 }
 
 // ResourceTypeByIdHandler returns a route handler function get ResourceType by its id.
-func ResourceTypeByIdHandler(resourceTypes ...*spec.ResourceType) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	cache := map[string]gojson.RawMessage{}
-	for _, resourceType := range resourceTypes {
-		raw, err := json.Serialize(json.ResourceTypeToSerializable(resourceType))
+func ResourceTypeByIdHandler(svc service.ResourceTypes) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		projection, err := handlerutil.GetRequestProjection(r)
 		if err != nil {
-			panic(err)
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
 		}
-		cache[resourceType.ID()] = raw
-	}
 
-	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-		raw, ok := cache[params.ByName("id")]
-		if !ok {
-			_ = handlerutil.WriteError(rw, fmt.Errorf("%w: resource type is not found", spec.ErrNotFound))
+		resp, err := svc.Do(r.Context(), &service.ResourceTypesRequest{
+			ResourceTypeID: params.ByName("id"),
+			Projection:     projection,
+		})
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
 			return
 		}
 
-		rw.Header().Set("Content-Type", spec.ApplicationScimJson)
-		_, _ = rw.Write(raw)
+		writeSerializable(r, rw, resp.Resources[0], projectionOptions(resp.Projection))
 	}
 }
 
 // SchemasHandler returns a route handler function for getting all defined Schema.
-func SchemasHandler() func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	result := &service.QueryResponse{StartIndex: 1, Resources: []json.Serializable{}}
-	if err := spec.Schemas().ForEachSchema(func(schema *spec.Schema) error {
-		if schema.ID() == spec.CoreSchemaId {
-			return nil
-		}
-		result.Resources = append(result.Resources, json.SchemaToSerializable(schema))
-		return nil
-	}); err != nil {
-		panic(err)
-	}
-	result.TotalResults = len(result.Resources)
-	result.ItemsPerPage = len(result.Resources)
+func SchemasHandler(svc service.Schemas) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		projection, err := handlerutil.GetRequestProjection(r)
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
 
-	// use recorder to cache render result
-	recorder := httptest.NewRecorder()
-	if err := handlerutil.WriteSearchResultToResponse(recorder, result); err != nil {
-		panic(err)
-	}
+		resp, err := svc.Do(r.Context(), &service.SchemasRequest{Projection: projection})
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
 
-	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-		rw.Header().Set("Content-Type", recorder.Header().Get("Content-Type"))
-		_, _ = rw.Write(recorder.Body.Bytes())
+		_ = handlerutil.WriteSearchResultToResponse(rw, resp, projectionOptions(resp.Projection)...)
 	}
 }
 
 // SchemaByIdHandler returns a route handler function get Schema by its id.
-func SchemaByIdHandler() func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	cache := map[string]gojson.RawMessage{}
-	if err := spec.Schemas().ForEachSchema(func(schema *spec.Schema) error {
-		if schema.ID() == spec.CoreSchemaId {
-			return nil
+func SchemaByIdHandler(svc service.Schemas) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		projection, err := handlerutil.GetRequestProjection(r)
+		if err != nil {
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
 		}
 
-		raw, err := json.Serialize(json.SchemaToSerializable(schema))
+		resp, err := svc.Do(r.Context(), &service.SchemasRequest{
+			SchemaID:   params.ByName("id"),
+			Projection: projection,
+		})
 		if err != nil {
-			return err
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
 		}
-		cache[schema.ID()] = raw
 
-		return nil
-	}); err != nil {
-		panic(err)
+		writeSerializable(r, rw, resp.Resources[0], projectionOptions(resp.Projection))
 	}
+}
 
-	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
-		raw, ok := cache[params.ByName("id")]
-		if !ok {
-			_ = handlerutil.WriteError(rw, fmt.Errorf("%w: schema is not found", spec.ErrNotFound))
-			return
+// anyModifiedSince reports whether the search result counts as modified with respect to the
+// If-Modified-Since header of the request. The result is unmodified only when every resource in it
+// was last modified at or before the header instant; deletions since that instant are not detected.
+// An absent or unparsable header counts as modified, so that the response is served normally.
+func anyModifiedSince(r *http.Request, resp *service.QueryResponse) bool {
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); len(ifModifiedSince) > 0 {
+		if _, err := http.ParseTime(ifModifiedSince); err == nil {
+			modified := handlerutil.ModifiedSinceCriteria(r)
+			for _, each := range resp.Resources {
+				resource, ok := each.(*prop.Resource)
+				if !ok || modified(resource) {
+					return true
+				}
+			}
+			return false
 		}
+	}
+	return true
+}
 
-		rw.Header().Set("Content-Type", spec.ApplicationScimJson)
-		_, _ = rw.Write(raw)
+// projectionOptions translates the attributes or excludedAttributes of the projection to JSON
+// serialization options.
+func projectionOptions(projection *crud.Projection) []json.Options {
+	var opt []json.Options
+	if projection != nil {
+		if len(projection.Attributes) > 0 {
+			opt = append(opt, json.Include(projection.Attributes...))
+		}
+		if len(projection.ExcludedAttributes) > 0 {
+			opt = append(opt, json.Exclude(projection.ExcludedAttributes...))
+		}
+	}
+	return opt
+}
+
+// writeSerializable renders the serializable with the options and writes it as a single SCIM object.
+func writeSerializable(r *http.Request, rw http.ResponseWriter, serializable json.Serializable, options []json.Options) {
+	raw, err := json.Serialize(serializable, options...)
+	if err != nil {
+		_ = handlerutil.WriteErrorForRequest(r, rw, err)
+		return
 	}
+
+	rw.Header().Set("Content-Type", spec.ApplicationScimJson)
+	_, _ = rw.Write(raw)
 }
 
 // HealthHandler returns a http handler to report service health status.
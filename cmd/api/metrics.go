@@ -0,0 +1,178 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/metrics"
+	"github.com/julienschmidt/httprouter"
+)
+
+// histogramBuckets are the upper bounds (in the observed unit) used by all histograms exposed by the
+// Prometheus collector.
+var histogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func newPrometheusCollector() *prometheusCollector {
+	return &prometheusCollector{
+		counters:   make(map[string]*counterSeries),
+		histograms: make(map[string]*histogramSeries),
+	}
+}
+
+// prometheusCollector is a metrics.Collector implementation that aggregates counters and histograms
+// in memory and renders them in the Prometheus text exposition format.
+type prometheusCollector struct {
+	sync.Mutex
+	counters   map[string]*counterSeries
+	histograms map[string]*histogramSeries
+}
+
+type counterSeries struct {
+	name  string
+	tags  map[string]string
+	value float64
+}
+
+type histogramSeries struct {
+	name    string
+	tags    map[string]string
+	buckets []int
+	sum     float64
+	count   int
+}
+
+func (c *prometheusCollector) Count(name string, delta int, tags map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := seriesKey(name, tags)
+	series, ok := c.counters[key]
+	if !ok {
+		series = &counterSeries{name: name, tags: tags}
+		c.counters[key] = series
+	}
+	series.value += float64(delta)
+}
+
+func (c *prometheusCollector) Observe(name string, value float64, tags map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := seriesKey(name, tags)
+	series, ok := c.histograms[key]
+	if !ok {
+		series = &histogramSeries{name: name, tags: tags, buckets: make([]int, len(histogramBuckets))}
+		c.histograms[key] = series
+	}
+	for i, bound := range histogramBuckets {
+		if value <= bound {
+			series.buckets[i]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+// render writes all aggregated series in the Prometheus text exposition format.
+func (c *prometheusCollector) render(sb *strings.Builder) {
+	c.Lock()
+	defer c.Unlock()
+
+	counterKeys := make([]string, 0, len(c.counters))
+	for key := range c.counters {
+		counterKeys = append(counterKeys, key)
+	}
+	sort.Strings(counterKeys)
+	for _, key := range counterKeys {
+		series := c.counters[key]
+		sb.WriteString(fmt.Sprintf("%s%s %g\n", series.name, renderTags(series.tags, "", 0), series.value))
+	}
+
+	histogramKeys := make([]string, 0, len(c.histograms))
+	for key := range c.histograms {
+		histogramKeys = append(histogramKeys, key)
+	}
+	sort.Strings(histogramKeys)
+	for _, key := range histogramKeys {
+		series := c.histograms[key]
+		for i, bound := range histogramBuckets {
+			sb.WriteString(fmt.Sprintf("%s_bucket%s %d\n", series.name, renderTags(series.tags, "le", bound), series.buckets[i]))
+		}
+		sb.WriteString(fmt.Sprintf("%s_bucket%s %d\n", series.name, renderTags(series.tags, "le", "+Inf"), series.count))
+		sb.WriteString(fmt.Sprintf("%s_sum%s %g\n", series.name, renderTags(series.tags, "", 0), series.sum))
+		sb.WriteString(fmt.Sprintf("%s_count%s %d\n", series.name, renderTags(series.tags, "", 0), series.count))
+	}
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	return name + renderTags(tags, "", 0)
+}
+
+func renderTags(tags map[string]string, extraKey string, extraValue interface{}) string {
+	parts := make([]string, 0, len(tags)+1)
+	for key, value := range tags {
+		parts = append(parts, fmt.Sprintf("%s=%q", key, value))
+	}
+	sort.Strings(parts)
+	if len(extraKey) > 0 {
+		parts = append(parts, fmt.Sprintf("%s=\"%v\"", extraKey, extraValue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// MetricsHandler returns a route handler function that renders the collected metrics in the Prometheus
+// text exposition format.
+func MetricsHandler(collector *prometheusCollector) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		var sb strings.Builder
+		collector.render(&sb)
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = rw.Write([]byte(sb.String()))
+	}
+}
+
+// MetricsMiddleware wraps the next handler to record a request counter and a latency histogram for each
+// request, tagged by method, endpoint and response status. Resource ids are normalized out of the endpoint
+// tag to keep series cardinality bounded.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: rw, status: 200}
+		next.ServeHTTP(recorder, r)
+
+		tags := map[string]string{
+			"method":   r.Method,
+			"endpoint": normalizeEndpoint(r.URL.Path),
+			"status":   fmt.Sprintf("%d", recorder.status),
+		}
+		metrics.Count("scim_http_requests_total", 1, tags)
+		metrics.Observe("scim_http_request_seconds", time.Since(start).Seconds(), tags)
+	})
+}
+
+// normalizeEndpoint replaces the resource id segment of well known endpoints with a placeholder.
+func normalizeEndpoint(path string) string {
+	for _, prefix := range []string{"/Users/", "/Groups/", "/Schemas/", "/ResourceTypes/"} {
+		if strings.HasPrefix(path, prefix) {
+			return prefix + ":id"
+		}
+	}
+	return path
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,307 @@
+package api
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/julienschmidt/httprouter"
+)
+
+// OpenAPIHandler returns a handler serving an OpenAPI 3 document that describes the SCIM endpoints
+// mounted for the given resource types. Paths and request/response bodies are generated from the
+// registered resource types and their schemas, so the served contract always matches the attribute
+// definitions the server actually enforces. The document is generated once on first request and
+// cached, since resource types do not change while the server runs.
+func OpenAPIHandler(serviceProviderConfig *spec.ServiceProviderConfig, resourceTypes ...*spec.ResourceType) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	var (
+		once sync.Once
+		raw  []byte
+		err  error
+	)
+	return func(rw http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		once.Do(func() {
+			raw, err = gojson.Marshal(openAPIDocument(serviceProviderConfig, resourceTypes))
+		})
+		if err != nil {
+			rw.WriteHeader(500)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write(raw)
+	}
+}
+
+// openAPIDocument assembles the OpenAPI 3 document structure.
+func openAPIDocument(serviceProviderConfig *spec.ServiceProviderConfig, resourceTypes []*spec.ResourceType) map[string]interface{} {
+	paths := map[string]interface{}{}
+	schemas := map[string]interface{}{
+		"Error":        errorSchema(),
+		"ListResponse": listResponseSchema(resourceTypes),
+		"PatchOp":      patchOpSchema(),
+	}
+
+	for _, resourceType := range resourceTypes {
+		schemas[resourceType.Name()] = resourceSchema(resourceType)
+		paths[resourceType.Endpoint()] = collectionPathItem(resourceType)
+		paths[resourceType.Endpoint()+"/{id}"] = resourcePathItem(resourceType, serviceProviderConfig)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "SCIM API",
+			"version": "2.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func collectionPathItem(resourceType *spec.ResourceType) map[string]interface{} {
+	item := map[string]interface{}{
+		"get": map[string]interface{}{
+			"summary":    fmt.Sprintf("Query %s resources", resourceType.Name()),
+			"parameters": queryParameters(),
+			"responses": map[string]interface{}{
+				"200": jsonResponse("the matching resources", schemaRef("ListResponse")),
+				"400": jsonResponse("invalid query", schemaRef("Error")),
+			},
+		},
+		"post": map[string]interface{}{
+			"summary":     fmt.Sprintf("Create a %s resource", resourceType.Name()),
+			"requestBody": jsonRequestBody(schemaRef(resourceType.Name())),
+			"responses": map[string]interface{}{
+				"201": jsonResponse("the created resource", schemaRef(resourceType.Name())),
+				"400": jsonResponse("invalid payload", schemaRef("Error")),
+				"409": jsonResponse("uniqueness conflict", schemaRef("Error")),
+			},
+		},
+	}
+	return item
+}
+
+func resourcePathItem(resourceType *spec.ResourceType, serviceProviderConfig *spec.ServiceProviderConfig) map[string]interface{} {
+	item := map[string]interface{}{
+		"parameters": []interface{}{
+			map[string]interface{}{
+				"name":     "id",
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			},
+		},
+		"get": map[string]interface{}{
+			"summary": fmt.Sprintf("Get a %s resource by id", resourceType.Name()),
+			"responses": map[string]interface{}{
+				"200": jsonResponse("the resource", schemaRef(resourceType.Name())),
+				"404": jsonResponse("resource not found", schemaRef("Error")),
+			},
+		},
+		"put": map[string]interface{}{
+			"summary":     fmt.Sprintf("Replace a %s resource", resourceType.Name()),
+			"requestBody": jsonRequestBody(schemaRef(resourceType.Name())),
+			"responses": map[string]interface{}{
+				"200": jsonResponse("the replaced resource", schemaRef(resourceType.Name())),
+				"400": jsonResponse("invalid payload", schemaRef("Error")),
+				"404": jsonResponse("resource not found", schemaRef("Error")),
+			},
+		},
+		"delete": map[string]interface{}{
+			"summary": fmt.Sprintf("Delete a %s resource", resourceType.Name()),
+			"responses": map[string]interface{}{
+				"204": map[string]interface{}{"description": "the resource was deleted"},
+				"404": jsonResponse("resource not found", schemaRef("Error")),
+			},
+		},
+	}
+	if serviceProviderConfig.Patch.Supported {
+		item["patch"] = map[string]interface{}{
+			"summary":     fmt.Sprintf("Patch a %s resource", resourceType.Name()),
+			"requestBody": jsonRequestBody(schemaRef("PatchOp")),
+			"responses": map[string]interface{}{
+				"200": jsonResponse("the patched resource", schemaRef(resourceType.Name())),
+				"204": map[string]interface{}{"description": "the patch produced no change"},
+				"400": jsonResponse("invalid payload", schemaRef("Error")),
+				"404": jsonResponse("resource not found", schemaRef("Error")),
+			},
+		}
+	}
+	return item
+}
+
+// resourceSchema generates the object schema of a resource type from its super attribute, which
+// already merges the core schema, the main schema and all schema extensions.
+func resourceSchema(resourceType *spec.ResourceType) map[string]interface{} {
+	return attributeSchema(resourceType.SuperAttribute(true))
+}
+
+// attributeSchema maps a SCIM attribute definition onto an OpenAPI schema object.
+func attributeSchema(attr *spec.Attribute) map[string]interface{} {
+	if attr.MultiValued() {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": attributeSchema(attr.DeriveElementAttribute()),
+		}
+	}
+
+	var schema map[string]interface{}
+	switch attr.Type() {
+	case spec.TypeComplex:
+		properties := map[string]interface{}{}
+		required := make([]string, 0)
+		_ = attr.ForEachSubAttribute(func(subAttribute *spec.Attribute) error {
+			properties[subAttribute.Name()] = attributeSchema(subAttribute)
+			if subAttribute.Required() {
+				required = append(required, subAttribute.Name())
+			}
+			return nil
+		})
+		schema = map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	case spec.TypeInteger:
+		schema = map[string]interface{}{"type": "integer", "format": "int64"}
+	case spec.TypeDecimal:
+		schema = map[string]interface{}{"type": "number"}
+	case spec.TypeBoolean:
+		schema = map[string]interface{}{"type": "boolean"}
+	case spec.TypeDateTime:
+		schema = map[string]interface{}{"type": "string", "format": "date-time"}
+	case spec.TypeReference:
+		schema = map[string]interface{}{"type": "string", "format": "uri"}
+	case spec.TypeBinary:
+		schema = map[string]interface{}{"type": "string", "format": "byte"}
+	default:
+		schema = map[string]interface{}{"type": "string"}
+	}
+
+	if description := attr.Description(); len(description) > 0 {
+		schema["description"] = description
+	}
+	if attr.CountCanonicalValues() > 0 && attr.Type() == spec.TypeString {
+		values := make([]string, 0, attr.CountCanonicalValues())
+		attr.ForEachCanonicalValues(func(canonicalValue string) {
+			values = append(values, canonicalValue)
+		})
+		schema["enum"] = values
+	}
+	switch attr.Mutability() {
+	case spec.MutabilityReadOnly:
+		schema["readOnly"] = true
+	case spec.MutabilityWriteOnly:
+		schema["writeOnly"] = true
+	}
+
+	return schema
+}
+
+func listResponseSchema(resourceTypes []*spec.ResourceType) map[string]interface{} {
+	anyResource := make([]interface{}, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		anyResource = append(anyResource, schemaRef(resourceType.Name()))
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"schemas":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"totalResults": map[string]interface{}{"type": "integer"},
+			"startIndex":   map[string]interface{}{"type": "integer"},
+			"itemsPerPage": map[string]interface{}{"type": "integer"},
+			"Resources": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"oneOf": anyResource},
+			},
+		},
+	}
+}
+
+func patchOpSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"schemas": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"Operations": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"op":    map[string]interface{}{"type": "string", "enum": []string{"add", "replace", "remove"}},
+						"path":  map[string]interface{}{"type": "string"},
+						"value": map[string]interface{}{},
+					},
+					"required": []string{"op"},
+				},
+			},
+		},
+		"required": []string{"schemas", "Operations"},
+	}
+}
+
+func errorSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"schemas":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"status":   map[string]interface{}{"type": "integer"},
+			"scimType": map[string]interface{}{"type": "string"},
+			"detail":   map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+func queryParameters() []interface{} {
+	stringParam := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		}
+	}
+	intParam := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "integer"},
+		}
+	}
+	return []interface{}{
+		stringParam("filter"),
+		stringParam("sortBy"),
+		stringParam("sortOrder"),
+		intParam("startIndex"),
+		intParam("count"),
+		stringParam("attributes"),
+		stringParam("excludedAttributes"),
+	}
+}
+
+func jsonRequestBody(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			spec.ApplicationScimJson: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			spec.ApplicationScimJson: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
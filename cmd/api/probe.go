@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	gojson "encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ReadinessCheck probes one dependency of the server. Name identifies the dependency in the probe
+// response; Check returns nil when the dependency is ready to serve traffic.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthzHandler returns the liveness probe handler. It reports 200 as long as the process is able to
+// serve requests; dependency state is reported by the readiness probe instead, so that Kubernetes does
+// not restart the process over a transient backend outage.
+func HealthzHandler() func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(200)
+		_ = gojson.NewEncoder(rw).Encode(map[string]string{"status": "up"})
+	}
+}
+
+// ReadyzHandler returns the readiness probe handler. Each check runs with a shared short timeout; the
+// handler reports 200 when all dependencies are ready, or 503 with the state of each dependency when
+// any is not, so that Kubernetes stops routing traffic to the instance until it recovers.
+func ReadyzHandler(checks ...ReadinessCheck) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		probeCtx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		var (
+			ready  = true
+			result = map[string]string{}
+		)
+		for _, check := range checks {
+			if err := check.Check(probeCtx); err != nil {
+				ready = false
+				result[check.Name] = "down"
+			} else {
+				result[check.Name] = "up"
+			}
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if ready {
+			rw.WriteHeader(200)
+		} else {
+			rw.WriteHeader(503)
+		}
+		_ = gojson.NewEncoder(rw).Encode(result)
+	}
+}
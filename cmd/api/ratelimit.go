@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// headerClientId is the request header inspected to identify the calling client. When absent, the
+// client is identified by the host portion of its remote address.
+const headerClientId = "X-Client-Id"
+
+func newRateLimiter(requestsPerSecond int, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rps:     float64(requestsPerSecond),
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// rateLimiter maintains a token bucket for each client so that a single misbehaving client cannot
+// exhaust the filter compiler and the database with retry storms.
+type rateLimiter struct {
+	sync.Mutex
+	rps     float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take attempts to consume one token from the bucket belonging to the client identified by key. If
+// no token is available, it returns false along with the duration after which the client may retry.
+func (rl *rateLimiter) take(key string) (ok bool, retryAfter time.Duration) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := time.Now()
+	bucket, found := rl.buckets[key]
+	if !found {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.last).Seconds() * rl.rps
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / rl.rps * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, 0
+}
+
+func clientKey(r *http.Request) string {
+	if clientId := r.Header.Get(headerClientId); len(clientId) > 0 {
+		return clientId
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// RateLimitHandler wraps the next handler with the per-client rate limiter. Requests exceeding the
+// limit are rejected with a SCIM error of status 429 and a Retry-After header hinting when the
+// client may attempt the request again.
+func RateLimitHandler(limiter *rateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if ok, retryAfter := limiter.take(clientKey(r)); !ok {
+			rw.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			_ = handlerutil.WriteErrorForRequest(r, rw, fmt.Errorf("%w: rate limit exceeded", spec.ErrTooManyRequests))
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	"github.com/imulab/go-scim/pkg/v2/history"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog"
+)
+
+// RollbackHandler returns a route handler function for restoring a SCIM resource to a previously
+// recorded version. This is an administrative operation outside the SCIM protocol: the request body
+// carries the target version as {"version": "..."}; the restored resource is returned on success, or
+// 204 when the resource is already at the requested version.
+func RollbackHandler(svc history.Rollback, log *zerolog.Logger) func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	return func(rw http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		id := params.ByName("id")
+		if len(id) == 0 {
+			err := fmt.Errorf("%w: id is empty", spec.ErrInvalidSyntax)
+			log.
+				Err(err).
+				Msg("error receiving rollback request")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		var payload struct {
+			Version string `json:"version"`
+		}
+		if err := gojson.NewDecoder(r.Body).Decode(&payload); err != nil {
+			err = fmt.Errorf("%w: invalid rollback payload", spec.ErrInvalidSyntax)
+			log.
+				Err(err).
+				Msg("error parsing rollback request")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		resp, err := svc.Do(r.Context(), &history.RollbackRequest{
+			ResourceID: id,
+			Version:    payload.Version,
+		})
+		if err != nil {
+			log.
+				Err(err).
+				Msg("error when rolling back resource")
+			_ = handlerutil.WriteErrorForRequest(r, rw, err)
+			return
+		}
+
+		if !resp.Rolled {
+			log.Info().Msg("resource already at requested version")
+			rw.WriteHeader(204)
+			return
+		}
+
+		log.Info().Msg("resource rolled back")
+		rw.WriteHeader(200)
+		_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/scope"
+)
+
+// ClientScopeHandler returns a http.Handler that resolves the provisioning client id from the
+// X-Client-Id header and associates it with the request context, so that downstream components
+// (i.e. externalId uniqueness and lookup) can scope their behavior to the calling client.
+func ClientScopeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if clientId := r.Header.Get(headerClientId); len(clientId) > 0 {
+			r = r.WithContext(scope.WithClient(r.Context(), clientId))
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	job "github.com/imulab/go-scim/cmd/internal/groupsync"
 	"github.com/imulab/go-scim/pkg/v2/groupsync"
-	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
 	"github.com/imulab/go-scim/pkg/v2/service"
+	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/rs/zerolog"
 	uuid "github.com/satori/go.uuid"
 	"github.com/streadway/amqp"
@@ -26,7 +27,7 @@ func (s *groupCreated) Do(ctx context.Context, req *service.CreateRequest) (resp
 		return
 	}
 
-	s.sender.Send(resp.Resource, groupsync.Compare(nil, resp.Resource))
+	s.sender.Send(resp.Resource.IdOrEmpty(), groupsync.Compare(nil, resp.Resource))
 	return
 }
 
@@ -43,7 +44,7 @@ func (s *groupReplaced) Do(ctx context.Context, req *service.ReplaceRequest) (re
 		return
 	}
 
-	s.sender.Send(resp.Resource, groupsync.Compare(resp.Ref, resp.Resource))
+	s.sender.Send(resp.Resource.IdOrEmpty(), groupsync.Compare(resp.Ref, resp.Resource))
 	return
 }
 
@@ -60,7 +61,13 @@ func (s *groupPatched) Do(ctx context.Context, req *service.PatchRequest) (resp
 		return
 	}
 
-	s.sender.Send(resp.Resource, groupsync.Compare(resp.Ref, resp.Resource))
+	if resp.Resource != nil {
+		s.sender.Send(resp.Resource.IdOrEmpty(), groupsync.Compare(resp.Ref, resp.Resource))
+		return
+	}
+
+	// the member fast path renders no resource snapshots; it reports the member delta instead
+	s.sender.Send(req.ResourceID, groupsync.Changes(resp.MembersAdded, resp.MembersRemoved))
 	return
 }
 
@@ -77,10 +84,60 @@ func (s *groupDeleted) Do(ctx context.Context, req *service.DeleteRequest) (resp
 		return
 	}
 
-	s.sender.Send(resp.Deleted, groupsync.Compare(resp.Deleted, nil))
+	s.sender.Send(resp.Deleted.IdOrEmpty(), groupsync.Compare(resp.Deleted, nil))
 	return
 }
 
+// relativeTimeQuery is a wrapper implementation of service.Query that translates non-standard relative time tokens
+// (i.e. now-1h) in the filter to dateTime literals before the query is compiled. It is only installed when the
+// relative-time-filter flag is enabled.
+type relativeTimeQuery struct {
+	service service.Query
+}
+
+func (s *relativeTimeQuery) Do(ctx context.Context, req *service.QueryRequest) (*service.QueryResponse, error) {
+	if len(req.Filter) > 0 {
+		req.Filter = handlerutil.TranslateRelativeTimes(req.Filter)
+	}
+	return s.service.Do(ctx, req)
+}
+
+func (s *relativeTimeQuery) Explain(ctx context.Context, req *service.QueryRequest) (*service.QueryExplainResponse, error) {
+	if len(req.Filter) > 0 {
+		req.Filter = handlerutil.TranslateRelativeTimes(req.Filter)
+	}
+	return s.service.Explain(ctx, req)
+}
+
+// normalizedProjectionGet is a wrapper implementation of service.Get that normalizes the attribute references in the
+// request projection against the resource type before delegating, so that the database field pushdown and the response
+// rendering address the same attributes regardless of whether the client qualified them with a schema namespace.
+type normalizedProjectionGet struct {
+	resourceType *spec.ResourceType
+	service      service.Get
+}
+
+func (s *normalizedProjectionGet) Do(ctx context.Context, req *service.GetRequest) (*service.GetResponse, error) {
+	req.Projection.Normalize(s.resourceType)
+	return s.service.Do(ctx, req)
+}
+
+// normalizedProjectionQuery is the service.Query counterpart of normalizedProjectionGet.
+type normalizedProjectionQuery struct {
+	resourceType *spec.ResourceType
+	service      service.Query
+}
+
+func (s *normalizedProjectionQuery) Do(ctx context.Context, req *service.QueryRequest) (*service.QueryResponse, error) {
+	req.Projection.Normalize(s.resourceType)
+	return s.service.Do(ctx, req)
+}
+
+func (s *normalizedProjectionQuery) Explain(ctx context.Context, req *service.QueryRequest) (*service.QueryExplainResponse, error) {
+	req.Projection.Normalize(s.resourceType)
+	return s.service.Explain(ctx, req)
+}
+
 // groupSyncSender is an service that sends group sync messages for the groupsync.Diff object computed asynchronously
 // to AMQP message brokers.
 type groupSyncSender struct {
@@ -88,7 +145,7 @@ type groupSyncSender struct {
 	logger  *zerolog.Logger
 }
 
-func (s *groupSyncSender) Send(group *prop.Resource, diff *groupsync.Diff) {
+func (s *groupSyncSender) Send(groupId string, diff *groupsync.Diff) {
 	if diff.CountLeft()+diff.CountJoined() == 0 {
 		return
 	}
@@ -96,22 +153,22 @@ func (s *groupSyncSender) Send(group *prop.Resource, diff *groupsync.Diff) {
 	messageId := uuid.NewV4().String()
 	s.logger.Info().Fields(map[string]interface{}{
 		"messageId": messageId,
-		"groupId":   group.IdOrEmpty(),
+		"groupId":   groupId,
 	}).Msg("Sending group sync messages.")
 
 	go func(messageId string, diff *groupsync.Diff) {
 		diff.ForEachLeft(func(id string) {
-			s.submitMessage(messageId, group, id)
+			s.submitMessage(messageId, groupId, id)
 		})
 		diff.ForEachJoined(func(id string) {
-			s.submitMessage(messageId, group, id)
+			s.submitMessage(messageId, groupId, id)
 		})
 	}(messageId, diff)
 }
 
-func (s *groupSyncSender) submitMessage(messageId string, group *prop.Resource, memberId string) {
+func (s *groupSyncSender) submitMessage(messageId string, groupId string, memberId string) {
 	msg := job.Message{
-		GroupID:  group.IdOrEmpty(),
+		GroupID:  groupId,
 		MemberID: memberId,
 		Trial:    1,
 	}
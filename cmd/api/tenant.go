@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+	"net/http"
+	"strings"
+)
+
+// TenantHandler wraps the next handler with tenant resolution. In "header" mode, the tenant id is read from
+// the configured request header; in "path" mode, the first path segment is consumed as the tenant id and
+// stripped from the URL before routing. The resolved tenant id is carried on the request context so that
+// tenant aware components downstream (i.e. partitioned databases, location formatters) can scope their work.
+func TenantHandler(mode string, header string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var tenantId string
+		switch mode {
+		case "header":
+			tenantId = r.Header.Get(header)
+		case "path":
+			trimmed := strings.TrimPrefix(r.URL.Path, "/")
+			if idx := strings.Index(trimmed, "/"); idx > 0 {
+				tenantId = trimmed[:idx]
+				r.URL.Path = trimmed[idx:]
+			}
+		}
+
+		if len(tenantId) > 0 {
+			r = r.WithContext(tenant.WithContext(r.Context(), tenantId))
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,437 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/cmd/internal/args"
+	scimmongo "github.com/imulab/go-scim/mongo/v2"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/urfave/cli/v2"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Command returns a cli.Command that generates synthetic resources and measures create, query and
+// patch throughput and latency percentiles, either against a running server through HTTP when --url
+// is set, or directly against a configured database backend otherwise. It exists so that backend
+// configurations can be compared under the same load.
+func Command() *cli.Command {
+	arg := newArguments()
+	return &cli.Command{
+		Name:        "bench",
+		Description: "Measure create, query and patch throughput and latency against a server or a database backend",
+		Flags:       arg.Flags(),
+		Action: func(_ *cli.Context) error {
+			runner, closeRunner, err := arg.initialize()
+			if err != nil {
+				return err
+			}
+			defer closeRunner()
+
+			ids := make([]string, arg.count)
+			for i := range ids {
+				ids[i] = fmt.Sprintf("bench-%d-%d", time.Now().UnixNano(), i)
+			}
+
+			for _, phase := range []struct {
+				name string
+				op   func(ctx context.Context, i int) error
+			}{
+				{
+					name: "create",
+					op: func(ctx context.Context, i int) error {
+						return runner.create(ctx, ids[i])
+					},
+				},
+				{
+					name: "query",
+					op: func(ctx context.Context, i int) error {
+						return runner.query(ctx, ids[i])
+					},
+				},
+				{
+					name: "patch",
+					op: func(ctx context.Context, i int) error {
+						return runner.patch(ctx, ids[i])
+					},
+				},
+			} {
+				result, err := measure(arg.count, arg.concurrency, phase.op)
+				if err != nil {
+					return fmt.Errorf("%s: %s", phase.name, err)
+				}
+				fmt.Println(result.render(phase.name))
+			}
+			return nil
+		},
+	}
+}
+
+// measure runs count operations through concurrency workers and collects per-operation latencies.
+// The first operation error aborts the run.
+func measure(count int, concurrency int, op func(ctx context.Context, i int) error) (*benchResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		latencies = make([]time.Duration, count)
+		indexes   = make(chan int)
+		workers   sync.WaitGroup
+		mutex     sync.Mutex
+		firstErr  error
+	)
+
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				opStart := time.Now()
+				err := op(context.Background(), index)
+				latencies[index] = time.Since(opStart)
+				if err != nil {
+					mutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mutex.Unlock()
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		mutex.Lock()
+		aborted := firstErr != nil
+		mutex.Unlock()
+		if aborted {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	workers.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return &benchResult{latencies: latencies, elapsed: time.Since(start)}, nil
+}
+
+type benchResult struct {
+	latencies []time.Duration
+	elapsed   time.Duration
+}
+
+func (r *benchResult) render(name string) string {
+	sorted := make([]time.Duration, len(r.latencies))
+	copy(sorted, r.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) time.Duration {
+		if len(sorted) == 0 {
+			return 0
+		}
+		index := int(float64(len(sorted)-1) * p)
+		return sorted[index]
+	}
+
+	return fmt.Sprintf(
+		"%s: %d op(s) in %s (%.1f op/s) p50=%s p90=%s p99=%s max=%s",
+		name,
+		len(sorted),
+		r.elapsed.Round(time.Millisecond),
+		float64(len(sorted))/r.elapsed.Seconds(),
+		percentile(0.50).Round(time.Microsecond),
+		percentile(0.90).Round(time.Microsecond),
+		percentile(0.99).Round(time.Microsecond),
+		percentile(1.00).Round(time.Microsecond),
+	)
+}
+
+// runner abstracts the system under measurement. Operations address resources by the synthetic ids
+// generated for the run.
+type runner interface {
+	create(ctx context.Context, id string) error
+	query(ctx context.Context, id string) error
+	patch(ctx context.Context, id string) error
+}
+
+func newArguments() *arguments {
+	return &arguments{
+		Scim:     new(args.Scim),
+		MemoryDB: new(args.MemoryDB),
+		MongoDB:  new(args.MongoDB),
+	}
+}
+
+type arguments struct {
+	*args.Scim
+	*args.MemoryDB
+	*args.MongoDB
+	resource    string
+	serverURL   string
+	count       int
+	concurrency int
+}
+
+func (arg *arguments) Flags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "resource",
+			Usage:       "The resource type to generate, either 'user' or 'group'",
+			Value:       "user",
+			Destination: &arg.resource,
+		},
+		&cli.StringFlag{
+			Name:        "url",
+			Usage:       "Base URL of a running server to benchmark over HTTP. Empty benchmarks the database backend directly",
+			Destination: &arg.serverURL,
+		},
+		&cli.IntFlag{
+			Name:        "count",
+			Usage:       "Number of synthetic resources to run each phase with",
+			Value:       1000,
+			Destination: &arg.count,
+		},
+		&cli.IntFlag{
+			Name:        "concurrency",
+			Usage:       "Number of operations in flight at the same time",
+			Value:       8,
+			Destination: &arg.concurrency,
+		},
+	}
+	flags = append(flags, arg.Scim.Flags()...)
+	flags = append(flags, arg.MemoryDB.Flags()...)
+	flags = append(flags, arg.MongoDB.Flags()...)
+	return flags
+}
+
+func (arg *arguments) initialize() (runner, func(), error) {
+	if err := arg.RegisterSchemas(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		resourceType *spec.ResourceType
+		err          error
+	)
+	switch strings.ToLower(arg.resource) {
+	case "user":
+		resourceType, err = arg.ParseUserResourceType()
+	case "group":
+		resourceType, err = arg.ParseGroupResourceType()
+	default:
+		err = fmt.Errorf("invalid resource '%s': expect 'user' or 'group'", arg.resource)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	crud.Register(resourceType)
+
+	if len(arg.serverURL) > 0 {
+		return &httpRunner{
+			baseURL:      strings.TrimSuffix(arg.serverURL, "/"),
+			resourceType: resourceType,
+			client:       &http.Client{Timeout: 30 * time.Second},
+		}, func() {}, nil
+	}
+
+	database, closeDatabase, err := arg.database(resourceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &dbRunner{resourceType: resourceType, database: database}, closeDatabase, nil
+}
+
+func (arg *arguments) database(resourceType *spec.ResourceType) (db.DB, func(), error) {
+	if arg.UseMemoryDB {
+		return db.Memory(), func() {}, nil
+	}
+
+	if err := arg.MongoDB.RegisterMetadata(); err != nil {
+		return nil, nil, err
+	}
+
+	connectCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := arg.MongoDB.Connect(connectCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collection := client.
+		Database(arg.MongoDB.Database, options.Database()).
+		Collection(resourceType.Name()+"_bench", options.Collection())
+	database := db.DB(scimmongo.DB(resourceType, collection, scimmongo.Options().IgnoreProjection()))
+	return database, func() { _ = client.Disconnect(context.Background()) }, nil
+}
+
+// syntheticPayload renders the synthetic resource payload for the given id. The distinguishing
+// value, userName for users and displayName for groups, is derived from the id so that each
+// resource can be queried back individually.
+func syntheticPayload(resourceType *spec.ResourceType, id string) string {
+	if strings.EqualFold(resourceType.Name(), "Group") {
+		return fmt.Sprintf(`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"id":"%s","displayName":"%s"}`, id, id)
+	}
+	return fmt.Sprintf(
+		`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"%s","userName":"%s","emails":[{"value":"%s@bench.local"}]}`,
+		id, id, id,
+	)
+}
+
+// queryAttribute returns the attribute the synthetic resources are queried back by.
+func queryAttribute(resourceType *spec.ResourceType) string {
+	if strings.EqualFold(resourceType.Name(), "Group") {
+		return "displayName"
+	}
+	return "userName"
+}
+
+// dbRunner measures operations directly against a db.DB, bypassing the service stack, so that the
+// numbers reflect the backend alone.
+type dbRunner struct {
+	resourceType *spec.ResourceType
+	database     db.DB
+}
+
+func (r *dbRunner) create(ctx context.Context, id string) error {
+	resource := prop.NewResource(r.resourceType)
+	if err := scimjson.Deserialize([]byte(syntheticPayload(r.resourceType, id)), resource); err != nil {
+		return err
+	}
+	return r.database.Insert(ctx, resource)
+}
+
+func (r *dbRunner) query(ctx context.Context, id string) error {
+	filter := fmt.Sprintf("%s eq %q", queryAttribute(r.resourceType), id)
+	resources, err := r.database.Query(ctx, filter, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	if len(resources) != 1 {
+		return fmt.Errorf("expected 1 result for '%s', got %d", filter, len(resources))
+	}
+	return nil
+}
+
+func (r *dbRunner) patch(ctx context.Context, id string) error {
+	ref, err := r.database.Get(ctx, id, nil)
+	if err != nil {
+		return err
+	}
+	patched := ref.Clone()
+	if nav := patched.Navigator().Dot(queryAttribute(r.resourceType)).Replace(id + "-patched"); nav.HasError() {
+		return nav.Error()
+	}
+	return r.database.Replace(ctx, ref, patched)
+}
+
+// httpRunner measures operations against a running server, so that the numbers include the full
+// service stack. The server assigns its own resource ids on create, which are remembered keyed by
+// the synthetic id so that the patch phase can address the created resources.
+type httpRunner struct {
+	baseURL      string
+	resourceType *spec.ResourceType
+	client       *http.Client
+	assignedIds  sync.Map
+}
+
+func (r *httpRunner) create(ctx context.Context, id string) error {
+	endpoint := r.baseURL + r.resourceType.Endpoint()
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader([]byte(syntheticPayload(r.resourceType, id))))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", spec.ApplicationScimJson)
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("POST %s: unexpected status %d", endpoint, response.StatusCode)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&created); err != nil {
+		return err
+	}
+	r.assignedIds.Store(id, created.ID)
+	return nil
+}
+
+func (r *httpRunner) query(ctx context.Context, id string) error {
+	filter := url.QueryEscape(fmt.Sprintf("%s eq %q", queryAttribute(r.resourceType), id))
+	endpoint := fmt.Sprintf("%s%s?filter=%s", r.baseURL, r.resourceType.Endpoint(), filter)
+	return r.do(ctx, http.MethodGet, endpoint, "", http.StatusOK)
+}
+
+func (r *httpRunner) patch(ctx context.Context, id string) error {
+	assigned, ok := r.assignedIds.Load(id)
+	if !ok {
+		return fmt.Errorf("no resource was created for '%s'", id)
+	}
+
+	endpoint := fmt.Sprintf("%s%s/%s", r.baseURL, r.resourceType.Endpoint(), assigned)
+	payload := fmt.Sprintf(
+		`{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"%s","value":"%s-patched"}]}`,
+		queryAttribute(r.resourceType), id,
+	)
+	return r.do(ctx, http.MethodPatch, endpoint, payload, 0)
+}
+
+// do issues the request and fails unless the response status matches the expected status, or is any
+// 2xx status when expected is zero.
+func (r *httpRunner) do(ctx context.Context, method string, endpoint string, payload string, expected int) error {
+	var body *bytes.Reader
+	if len(payload) > 0 {
+		body = bytes.NewReader([]byte(payload))
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	request, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	if len(payload) > 0 {
+		request.Header.Set("Content-Type", spec.ApplicationScimJson)
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if expected > 0 && response.StatusCode != expected {
+		return fmt.Errorf("%s %s: unexpected status %d", method, endpoint, response.StatusCode)
+	}
+	if expected == 0 && (response.StatusCode < 200 || response.StatusCode > 299) {
+		return fmt.Errorf("%s %s: unexpected status %d", method, endpoint, response.StatusCode)
+	}
+	return nil
+}
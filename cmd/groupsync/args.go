@@ -22,6 +22,7 @@ type arguments struct {
 	*args.RabbitMQ
 	*args.Logging
 	requeueLimit int
+	watchMongo   bool
 }
 
 func (arg *arguments) Flags() []cli.Flag {
@@ -32,6 +33,13 @@ func (arg *arguments) Flags() []cli.Flag {
 			EnvVars:     []string{"REQUEUE_LIMIT"},
 			Destination: &arg.requeueLimit,
 		},
+		&cli.BoolFlag{
+			Name:        "watch-mongo",
+			Usage:       "Tail MongoDB change streams on the Group collection to detect membership changes, removing the need for the API server to publish them",
+			EnvVars:     []string{"WATCH_MONGO"},
+			Value:       false,
+			Destination: &arg.watchMongo,
+		},
 	}
 	flags = append(flags, arg.Scim.Flags()...)
 	flags = append(flags, arg.MemoryDB.Flags()...)
@@ -21,17 +21,31 @@ func Command() *cli.Command {
 			defer app.Close()
 
 			ctx, cancelFunc := context.WithCancel(context.Background())
+			defer cancelFunc()
+			safeExits := make([]chan struct{}, 0, 2)
+
 			safeExit, err := app.MessageConsumer().Start(ctx)
 			if err != nil {
 				return err
 			}
+			safeExits = append(safeExits, safeExit)
+
+			if args.watchMongo {
+				streamExit, err := app.GroupChangeStream().Start(ctx)
+				if err != nil {
+					return err
+				}
+				safeExits = append(safeExits, streamExit)
+			}
 
 			term := make(chan os.Signal)
 			signal.Notify(term, syscall.SIGINT, syscall.SIGTERM)
 			<-term
 			app.Logger().Info().Msg("received terminate signal, waiting to abort")
 			cancelFunc()
-			<-safeExit
+			for _, safeExit := range safeExits {
+				<-safeExit
+			}
 
 			return nil
 		},
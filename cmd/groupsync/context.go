@@ -2,6 +2,7 @@ package groupsync
 
 import (
 	"context"
+	"errors"
 	gs "github.com/imulab/go-scim/cmd/internal/groupsync"
 	scimmongo "github.com/imulab/go-scim/mongo/v2"
 	"github.com/imulab/go-scim/pkg/v2/db"
@@ -16,6 +17,8 @@ import (
 	"time"
 )
 
+var errMemoryDBChangeStream = errors.New("change streams require MongoDB as the backing store")
+
 type applicationContext struct {
 	args                      *arguments
 	logger                    *zerolog.Logger
@@ -31,6 +34,7 @@ type applicationContext struct {
 	rabbitMqChannel           *amqp.Channel
 	userSyncService           *groupsync.SyncService
 	messageConsumer           *consumer
+	groupChangeStream         *changeStream
 }
 
 func (ctx *applicationContext) Logger() *zerolog.Logger {
@@ -212,6 +216,29 @@ func (ctx *applicationContext) MessageConsumer() *consumer {
 	return ctx.messageConsumer
 }
 
+// GroupChangeStream returns the change stream source tailing the Group collection. It is only
+// available when MongoDB is the backing store.
+func (ctx *applicationContext) GroupChangeStream() *changeStream {
+	if ctx.groupChangeStream == nil {
+		if ctx.args.UseMemoryDB {
+			ctx.logInitFailure("group change stream", errMemoryDBChangeStream)
+			panic(errMemoryDBChangeStream)
+		}
+		resourceType := ctx.GroupResourceType()
+		collection := ctx.MongoClient().
+			Database(ctx.args.MongoDB.Database, options.Database()).
+			Collection(resourceType.Name(), options.Collection())
+		ctx.groupChangeStream = &changeStream{
+			collection: collection,
+			publish:    ctx.MessageConsumer().send,
+			logger:     ctx.Logger(),
+			groups:     map[string]*groupMembership{},
+		}
+		ctx.logInitialized("group change stream")
+	}
+	return ctx.groupChangeStream
+}
+
 func (ctx *applicationContext) Close() {
 	if ctx.mongoClient != nil {
 		_ = ctx.mongoClient.Disconnect(context.Background())
@@ -0,0 +1,178 @@
+package groupsync
+
+import (
+	"context"
+	"fmt"
+
+	job "github.com/imulab/go-scim/cmd/internal/groupsync"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeStream tails the MongoDB change stream on the Group collection and feeds the membership
+// differences into the sync engine as group sync messages, so that membership changes are picked up
+// at the persistence layer and the API server does not need to publish events itself when MongoDB
+// is the backing store.
+//
+// Change events do not carry the previous state of a document, so the stream keeps an in-memory
+// snapshot of each group's membership, seeded from the collection at start. Changes to groups whose
+// snapshot is missing, such as groups deleted across a restart, only report the members still
+// present in the event.
+type changeStream struct {
+	collection *mongo.Collection
+	publish    func(message *job.Message)
+	logger     *zerolog.Logger
+	groups     map[string]*groupMembership
+}
+
+// groupMembership is the membership snapshot of a single group, keyed in changeStream.groups by the
+// document's internal MongoDB id, which is the only identification a delete event carries.
+type groupMembership struct {
+	groupID string
+	members map[string]struct{}
+}
+
+func (s *changeStream) Start(ctx context.Context) (safeExit chan struct{}, err error) {
+	if err = s.seed(ctx); err != nil {
+		s.logger.Err(err).Msg("failed to seed group membership snapshots")
+		return
+	}
+
+	stream, err := s.collection.Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		s.logger.Err(err).Msg("failed to open change stream on group collection")
+		return
+	}
+
+	s.logger.Info().Msg("group change stream starts to listen for membership changes")
+
+	safeExit = make(chan struct{}, 1)
+
+	go func() {
+		defer func() {
+			_ = stream.Close(context.Background())
+			safeExit <- struct{}{}
+		}()
+		for stream.Next(ctx) {
+			var event struct {
+				OperationType string `bson:"operationType"`
+				DocumentKey   struct {
+					ID interface{} `bson:"_id"`
+				} `bson:"documentKey"`
+				FullDocument bson.M `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&event); err != nil {
+				s.logger.Err(err).Msg("failed to decode group change event")
+				continue
+			}
+			s.handle(documentKey(event.DocumentKey.ID), event.OperationType, event.FullDocument)
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			s.logger.Err(err).Msg("group change stream aborted")
+		}
+	}()
+
+	return
+}
+
+// seed takes the initial membership snapshot of every group in the collection.
+func (s *changeStream) seed(ctx context.Context) error {
+	cursor, err := s.collection.Find(ctx, bson.D{}, options.Find())
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return err
+		}
+		s.groups[documentKey(document["_id"])] = snapshotOf(document)
+	}
+	return cursor.Err()
+}
+
+func (s *changeStream) handle(key string, operationType string, document bson.M) {
+	before := s.groups[key]
+
+	var after *groupMembership
+	switch operationType {
+	case "insert", "replace", "update":
+		if document == nil {
+			s.logger.Warn().Msg("group change event carries no document, skipping")
+			return
+		}
+		after = snapshotOf(document)
+		s.groups[key] = after
+	case "delete":
+		if before == nil {
+			s.logger.Warn().Msg("deleted group was not in membership snapshot, unable to report leaving members")
+			return
+		}
+		delete(s.groups, key)
+	default:
+		return
+	}
+
+	groupID, changed := diffMembership(before, after)
+	for memberID := range changed {
+		s.publish(&job.Message{
+			GroupID:  groupID,
+			MemberID: memberID,
+			Trial:    1,
+		})
+	}
+}
+
+// diffMembership reports the group id and the set of member ids that joined or left between the two
+// membership snapshots, either of which may be nil.
+func diffMembership(before *groupMembership, after *groupMembership) (groupID string, changed map[string]struct{}) {
+	changed = map[string]struct{}{}
+	if before != nil {
+		groupID = before.groupID
+		for memberID := range before.members {
+			if after == nil {
+				changed[memberID] = struct{}{}
+			} else if _, ok := after.members[memberID]; !ok {
+				changed[memberID] = struct{}{}
+			}
+		}
+	}
+	if after != nil {
+		groupID = after.groupID
+		for memberID := range after.members {
+			if before == nil {
+				changed[memberID] = struct{}{}
+			} else if _, ok := before.members[memberID]; !ok {
+				changed[memberID] = struct{}{}
+			}
+		}
+	}
+	return
+}
+
+// snapshotOf extracts the group id and member ids off the raw group document. The "id" field and
+// the "value" sub-field of "members" are stored under their SCIM names.
+func snapshotOf(document bson.M) *groupMembership {
+	snapshot := &groupMembership{members: map[string]struct{}{}}
+	if id, ok := document["id"].(string); ok {
+		snapshot.groupID = id
+	}
+	if members, ok := document["members"].(bson.A); ok {
+		for _, member := range members {
+			if element, ok := member.(bson.M); ok {
+				if value, ok := element["value"].(string); ok {
+					snapshot.members[value] = struct{}{}
+				}
+			}
+		}
+	}
+	return snapshot
+}
+
+func documentKey(id interface{}) string {
+	return fmt.Sprintf("%v", id)
+}
@@ -0,0 +1,23 @@
+package args
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Bulk is the configuration options related to bulk request processing.
+type Bulk struct {
+	// Concurrency is the number of independent bulk operations processed at the same time.
+	Concurrency int
+}
+
+func (arg *Bulk) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "bulk-concurrency",
+			Usage:       "Number of independent bulk operations processed at the same time",
+			EnvVars:     []string{"BULK_CONCURRENCY"},
+			Value:       4,
+			Destination: &arg.Concurrency,
+		},
+	}
+}
@@ -11,11 +11,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // MemoryDB is the configuration options related to a in-memory db.DB implementation.
 type MemoryDB struct {
 	UseMemoryDB bool
+	// SnapshotDir is the directory that memory database snapshots are written to. Empty disables snapshots.
+	SnapshotDir string
+	// SnapshotInterval is the interval of periodic background snapshots. Zero disables background snapshots.
+	SnapshotInterval time.Duration
+	// SnapshotFSync syncs snapshot files to stable storage before they are put in place.
+	SnapshotFSync bool
 }
 
 func (arg *MemoryDB) Flags() []cli.Flag {
@@ -26,6 +33,24 @@ func (arg *MemoryDB) Flags() []cli.Flag {
 			Value:       false,
 			Destination: &arg.UseMemoryDB,
 		},
+		&cli.StringFlag{
+			Name:        "memory-snapshot-dir",
+			Usage:       "Directory that memory database snapshots are written to and reloaded from. Empty disables snapshots",
+			EnvVars:     []string{"MEMORY_SNAPSHOT_DIR"},
+			Destination: &arg.SnapshotDir,
+		},
+		&cli.DurationFlag{
+			Name:        "memory-snapshot-interval",
+			Usage:       "Interval of periodic background memory database snapshots. Zero disables background snapshots",
+			EnvVars:     []string{"MEMORY_SNAPSHOT_INTERVAL"},
+			Destination: &arg.SnapshotInterval,
+		},
+		&cli.BoolFlag{
+			Name:        "memory-snapshot-fsync",
+			Usage:       "Sync memory database snapshot files to stable storage before they are put in place",
+			EnvVars:     []string{"MEMORY_SNAPSHOT_FSYNC"},
+			Destination: &arg.SnapshotFSync,
+		},
 	}
 }
 
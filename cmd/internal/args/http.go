@@ -0,0 +1,53 @@
+package args
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Http is the configuration options related to the HTTP server transport: request body size limits
+// and protections against slow or abusive clients.
+type Http struct {
+	// Maximum request body size in bytes, zero or negative disables the limit
+	BodyLimit int64
+	// Maximum request body size in bytes for the bulk endpoint, zero or negative falls back to BodyLimit
+	BulkBodyLimit int64
+	// Maximum duration allowed to read request headers, protecting against slow clients holding connections open
+	ReadHeaderTimeout time.Duration
+	// Maximum number of concurrent in-flight mutations per resource id, zero or negative for unlimited
+	MutationLimit int
+}
+
+func (arg *Http) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.Int64Flag{
+			Name:        "max-body-size",
+			Usage:       "Maximum request body size in `bytes`, requests over the limit are rejected with a SCIM 413 error. Value `0` disables the limit",
+			EnvVars:     []string{"MAX_BODY_SIZE"},
+			Value:       1 << 20,
+			Destination: &arg.BodyLimit,
+		},
+		&cli.Int64Flag{
+			Name:        "max-bulk-body-size",
+			Usage:       "Maximum request body size in `bytes` for the bulk endpoint. Value `0` falls back to the general limit",
+			EnvVars:     []string{"MAX_BULK_BODY_SIZE"},
+			Value:       10 << 20,
+			Destination: &arg.BulkBodyLimit,
+		},
+		&cli.DurationFlag{
+			Name:        "read-header-timeout",
+			Usage:       "Maximum duration allowed to read request headers. Value `0` disables the timeout",
+			EnvVars:     []string{"READ_HEADER_TIMEOUT"},
+			Value:       10 * time.Second,
+			Destination: &arg.ReadHeaderTimeout,
+		},
+		&cli.IntFlag{
+			Name:        "max-inflight-mutations",
+			Usage:       "Maximum number of concurrent in-flight mutations per resource id, requests over the limit are rejected with a SCIM 429 error. Value `0` disables the limit",
+			EnvVars:     []string{"MAX_INFLIGHT_MUTATIONS"},
+			Value:       0,
+			Destination: &arg.MutationLimit,
+		},
+	}
+}
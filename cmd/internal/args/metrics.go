@@ -0,0 +1,23 @@
+package args
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Metrics is the configuration options related to metrics collection.
+type Metrics struct {
+	// Whether metrics collection and the /metrics endpoint are enabled
+	Enabled bool
+}
+
+func (arg *Metrics) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:        "metrics",
+			Usage:       "Collect operational metrics and expose them on the /metrics endpoint in Prometheus format",
+			EnvVars:     []string{"METRICS"},
+			Value:       false,
+			Destination: &arg.Enabled,
+		},
+	}
+}
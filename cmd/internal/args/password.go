@@ -0,0 +1,32 @@
+package args
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Password is the configuration options related to password hashing.
+type Password struct {
+	// Algorithm used to hash password values (i.e. bcrypt, argon2id)
+	Algorithm string
+	// Cost parameter for the bcrypt algorithm
+	BCryptCost int
+}
+
+func (arg *Password) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "password-algorithm",
+			Usage:       "Algorithm used to hash password values (bcrypt or argon2id)",
+			EnvVars:     []string{"PASSWORD_ALGORITHM"},
+			Value:       "bcrypt",
+			Destination: &arg.Algorithm,
+		},
+		&cli.IntFlag{
+			Name:        "password-bcrypt-cost",
+			Usage:       "Cost parameter used when hashing passwords with bcrypt",
+			EnvVars:     []string{"PASSWORD_BCRYPT_COST"},
+			Value:       10,
+			Destination: &arg.BCryptCost,
+		},
+	}
+}
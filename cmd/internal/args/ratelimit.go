@@ -0,0 +1,32 @@
+package args
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// RateLimit is the configuration options related to per-client rate limiting.
+type RateLimit struct {
+	// Number of requests replenished per second for each client. A zero value disables rate limiting.
+	RequestsPerSecond int
+	// Maximum number of requests a client may burst before replenishment catches up
+	Burst int
+}
+
+func (arg *RateLimit) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "rate-limit-rps",
+			Usage:       "Number of requests per second allowed for each client. Value `0` disables rate limiting",
+			EnvVars:     []string{"RATE_LIMIT_RPS"},
+			Value:       0,
+			Destination: &arg.RequestsPerSecond,
+		},
+		&cli.IntFlag{
+			Name:        "rate-limit-burst",
+			Usage:       "Maximum number of requests a client may burst above the steady rate",
+			EnvVars:     []string{"RATE_LIMIT_BURST"},
+			Value:       10,
+			Destination: &arg.Burst,
+		},
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Scim the configuration options related to the core SCIM specification.
@@ -19,6 +20,42 @@ type Scim struct {
 	GroupResourceTypePath string
 	// Path to the directory containing all schema JSON file
 	SchemasDirectory string
+	// Maximum duration a single service operation may run, zero means no timeout
+	OperationTimeout time.Duration
+	// Accept common non-conformant payload quirks (numbers and booleans as strings, single object for array)
+	LenientJson bool
+	// Report all validation violations of a payload in one error response instead of failing fast on the first
+	AggregateValidationErrors bool
+	// Treat a create whose externalId already exists as an update in place of the existing resource
+	ExternalIdUpsert bool
+	// Path at which the generated OpenAPI 3 document is served, empty disables it
+	OpenAPIPath string
+	// Translate user deletion into setting active=false instead of removing the resource
+	SoftDelete bool
+	// URL notified with the resource JSON when a user transitions to inactive state, empty disables it
+	DeactivationWebhook string
+	// Enable non-standard relative time tokens (i.e. now-1h) in filter values
+	RelativeTimeFilter bool
+	// Trust clients to supply the resource id on create instead of always generating one
+	TrustedClientId bool
+	// Format of generated resource ids: "uuid" (random version 4 UUID) or "ulid" (k-ordered)
+	IdFormat string
+	// Reject attempts to change readOnly attribute values on replace and patch instead of silently ignoring them
+	StrictReadOnly bool
+	// Maximum number of User resources per tenant; zero or negative for unlimited
+	UserQuota int
+	// Maximum number of Group resources per tenant; zero or negative for unlimited
+	GroupQuota int
+	// Maximum number of members per Group resource; zero or negative for unlimited
+	GroupMemberQuota int
+	// Page size applied to queries that omit pagination entirely; zero or negative leaves them unpaginated
+	DefaultPageSize int
+	// Maximum page size a client may request; zero or negative for no cap
+	MaxPageSize int
+	// Retention of create results recorded under an Idempotency-Key header, zero disables replay protection
+	IdempotencyKeyTTL time.Duration
+	// Include the request id in the detail of error responses
+	RequestIdInErrorDetail bool
 }
 
 // ParseServiceProviderConfig returns an instance of spec.ServiceProviderConfig from the JSON definition at
@@ -123,5 +160,130 @@ func (arg *Scim) Flags() []cli.Flag {
 			Required:    true,
 			Destination: &arg.ServiceProviderConfigPath,
 		},
+		&cli.BoolFlag{
+			Name:        "lenient-json",
+			Usage:       "Accept common non-conformant payload quirks, i.e. numbers and booleans sent as strings, a single object sent for an array",
+			EnvVars:     []string{"LENIENT_JSON"},
+			Value:       false,
+			Destination: &arg.LenientJson,
+		},
+		&cli.BoolFlag{
+			Name:        "aggregate-validation-errors",
+			Usage:       "Report all validation violations of a payload in one error response instead of failing fast on the first",
+			EnvVars:     []string{"AGGREGATE_VALIDATION_ERRORS"},
+			Value:       false,
+			Destination: &arg.AggregateValidationErrors,
+		},
+		&cli.BoolFlag{
+			Name:        "external-id-upsert",
+			Usage:       "Update the existing resource in place (responding 200) when a create carries an externalId that already exists",
+			EnvVars:     []string{"EXTERNAL_ID_UPSERT"},
+			Value:       false,
+			Destination: &arg.ExternalIdUpsert,
+		},
+		&cli.BoolFlag{
+			Name:        "soft-delete",
+			Usage:       "Translate user deletion into setting active=false instead of removing the resource",
+			EnvVars:     []string{"SOFT_DELETE"},
+			Value:       false,
+			Destination: &arg.SoftDelete,
+		},
+		&cli.StringFlag{
+			Name:        "deactivation-webhook",
+			Usage:       "URL notified with the resource JSON when a user transitions to inactive state, empty disables the notification",
+			EnvVars:     []string{"DEACTIVATION_WEBHOOK"},
+			Destination: &arg.DeactivationWebhook,
+		},
+		&cli.BoolFlag{
+			Name:        "relative-time-filter",
+			Usage:       "Enable non-standard relative time tokens (i.e. now-1h) in filter values, translated to timestamps before compilation",
+			EnvVars:     []string{"RELATIVE_TIME_FILTER"},
+			Value:       false,
+			Destination: &arg.RelativeTimeFilter,
+		},
+		&cli.BoolFlag{
+			Name:        "trusted-client-id",
+			Usage:       "Trust clients (i.e. migration tools) to supply the resource id on create instead of always generating one; absent ids are still generated",
+			EnvVars:     []string{"TRUSTED_CLIENT_ID"},
+			Value:       false,
+			Destination: &arg.TrustedClientId,
+		},
+		&cli.StringFlag{
+			Name:        "id-format",
+			Usage:       "Format of generated resource ids: 'uuid' for random version 4 UUIDs, 'ulid' for k-ordered ULIDs suited to stores that sort and shard by id",
+			EnvVars:     []string{"ID_FORMAT"},
+			Value:       "uuid",
+			Destination: &arg.IdFormat,
+		},
+		&cli.BoolFlag{
+			Name:        "strict-read-only",
+			Usage:       "Reject modification payloads that attempt to change readOnly attribute values instead of silently ignoring them; omitted or unchanged readOnly values still pass",
+			EnvVars:     []string{"STRICT_READ_ONLY"},
+			Value:       false,
+			Destination: &arg.StrictReadOnly,
+		},
+		&cli.IntFlag{
+			Name:        "user-quota",
+			Usage:       "Maximum number of User resources per tenant, exceeding creations are rejected; zero or negative for unlimited",
+			EnvVars:     []string{"USER_QUOTA"},
+			Value:       0,
+			Destination: &arg.UserQuota,
+		},
+		&cli.IntFlag{
+			Name:        "group-quota",
+			Usage:       "Maximum number of Group resources per tenant, exceeding creations are rejected; zero or negative for unlimited",
+			EnvVars:     []string{"GROUP_QUOTA"},
+			Value:       0,
+			Destination: &arg.GroupQuota,
+		},
+		&cli.IntFlag{
+			Name:        "group-member-quota",
+			Usage:       "Maximum number of members per Group resource, enforced on create, replace and patch; zero or negative for unlimited",
+			EnvVars:     []string{"GROUP_MEMBER_QUOTA"},
+			Value:       0,
+			Destination: &arg.GroupMemberQuota,
+		},
+		&cli.IntFlag{
+			Name:        "default-page-size",
+			Usage:       "Page size applied to queries that omit pagination entirely. Value `0` leaves such queries unpaginated",
+			EnvVars:     []string{"DEFAULT_PAGE_SIZE"},
+			Value:       0,
+			Destination: &arg.DefaultPageSize,
+		},
+		&cli.IntFlag{
+			Name:        "max-page-size",
+			Usage:       "Maximum page size a client may request, larger requests are clamped. Value `0` disables the cap",
+			EnvVars:     []string{"MAX_PAGE_SIZE"},
+			Value:       0,
+			Destination: &arg.MaxPageSize,
+		},
+		&cli.StringFlag{
+			Name:        "openapi-path",
+			Usage:       "Path at which a generated OpenAPI 3 document describing the SCIM endpoints is served, empty disables it",
+			EnvVars:     []string{"OPENAPI_PATH"},
+			Value:       "/openapi.json",
+			Destination: &arg.OpenAPIPath,
+		},
+		&cli.DurationFlag{
+			Name:        "idempotency-key-ttl",
+			Usage:       "Retention of create results recorded under an Idempotency-Key header, retried creates within the window replay the original result, 0 disables replay protection",
+			EnvVars:     []string{"IDEMPOTENCY_KEY_TTL"},
+			Value:       0,
+			Destination: &arg.IdempotencyKeyTTL,
+		},
+		&cli.BoolFlag{
+			Name:        "request-id-in-error-detail",
+			Usage:       "Include the X-Request-Id of the request in the detail of error responses, easing correlation of client reported failures with server logs",
+			EnvVars:     []string{"REQUEST_ID_IN_ERROR_DETAIL"},
+			Value:       true,
+			Destination: &arg.RequestIdInErrorDetail,
+		},
+		&cli.DurationFlag{
+			Name:        "operation-timeout",
+			Usage:       "Maximum duration a single service operation may run, 0 disables the timeout",
+			EnvVars:     []string{"OPERATION_TIMEOUT"},
+			Value:       0,
+			Destination: &arg.OperationTimeout,
+		},
 	}
 }
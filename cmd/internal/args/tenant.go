@@ -0,0 +1,37 @@
+package args
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// Tenant is the configuration options related to multi-tenancy.
+type Tenant struct {
+	// Tenant resolution mode. One of "none", "header" and "path".
+	Mode string
+	// Name of the request header carrying the tenant id, effective in "header" mode
+	Header string
+}
+
+// Enabled returns true when a tenant resolution mode other than "none" is configured.
+func (arg *Tenant) Enabled() bool {
+	return arg.Mode == "header" || arg.Mode == "path"
+}
+
+func (arg *Tenant) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "tenant-mode",
+			Usage:       "Tenant resolution mode to `[none|header|path]`. Value defaults to `none`",
+			EnvVars:     []string{"TENANT_MODE"},
+			Value:       "none",
+			Destination: &arg.Mode,
+		},
+		&cli.StringFlag{
+			Name:        "tenant-header",
+			Usage:       "Request header carrying the tenant id, effective when tenant-mode is `header`",
+			EnvVars:     []string{"TENANT_HEADER"},
+			Value:       "X-Tenant-Id",
+			Destination: &arg.Header,
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package args
+
+import (
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Webhook is the configuration options related to webhook notifications of resource changes.
+type Webhook struct {
+	// Comma separated URLs of the endpoints that resource change notifications are POSTed to
+	Urls string
+	// HMAC-SHA256 signing secret shared with the webhook receivers; empty disables signing
+	Secret string
+	// Total number of delivery attempts per endpoint, including the first
+	MaxAttempts int
+}
+
+// Enabled returns true if at least one webhook endpoint is configured.
+func (arg *Webhook) Enabled() bool {
+	return len(arg.UrlList()) > 0
+}
+
+// UrlList returns the configured webhook endpoint URLs, with surrounding whitespace and empty
+// entries dropped.
+func (arg *Webhook) UrlList() []string {
+	var urls []string
+	for _, url := range strings.Split(arg.Urls, ",") {
+		if url = strings.TrimSpace(url); len(url) > 0 {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func (arg *Webhook) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "webhook-urls",
+			Usage:       "Comma separated URLs of webhook endpoints that resource change notifications are POSTed to",
+			EnvVars:     []string{"WEBHOOK_URLS"},
+			Destination: &arg.Urls,
+		},
+		&cli.StringFlag{
+			Name:        "webhook-secret",
+			Usage:       "HMAC-SHA256 signing secret shared with the webhook receivers. Empty value disables signing",
+			EnvVars:     []string{"WEBHOOK_SECRET"},
+			Destination: &arg.Secret,
+		},
+		&cli.IntFlag{
+			Name:        "webhook-max-attempts",
+			Usage:       "Total number of delivery attempts per webhook endpoint, including the first; failed deliveries back off exponentially in between",
+			EnvVars:     []string{"WEBHOOK_MAX_ATTEMPTS"},
+			Value:       3,
+			Destination: &arg.MaxAttempts,
+		},
+	}
+}
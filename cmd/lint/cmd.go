@@ -0,0 +1,57 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+// Command returns a cli.Command that checks SCIM schema JSON definitions for common problems.
+// It prints one line per detected problem and exits with an error when any is found, so that it
+// can gate schema changes in CI pipelines.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:        "lint",
+		Description: "Check SCIM schema JSON definitions for common problems",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "schema",
+				Usage:    "Path to a schema JSON definition to check, may be specified multiple times",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			problems := 0
+			for _, path := range c.StringSlice("schema") {
+				schema, err := parseSchema(path)
+				if err != nil {
+					return err
+				}
+				for _, diagnostic := range spec.Lint(schema) {
+					fmt.Printf("%s: %s\n", path, diagnostic)
+					problems++
+				}
+			}
+			if problems > 0 {
+				return fmt.Errorf("%d problem(s) found", problems)
+			}
+			return nil
+		},
+	}
+}
+
+func parseSchema(path string) (*spec.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	schema := new(spec.Schema)
+	if err := json.NewDecoder(f).Decode(schema); err != nil {
+		return nil, fmt.Errorf("invalid schema definition at %s: %s", path, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,266 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/imulab/go-scim/cmd/internal/args"
+	scimmongo "github.com/imulab/go-scim/mongo/v2"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/urfave/cli/v2"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportPageSize is the number of resources fetched from the database per page during export.
+const exportPageSize = 100
+
+// ExportCommand returns a cli.Command that streams resources from a configured database backend to
+// a newline-delimited JSON file, optionally restricted by a SCIM filter. Together with ImportCommand,
+// it serves backup, migration between backends and seeding of test environments.
+func ExportCommand() *cli.Command {
+	arg := newArguments()
+	return &cli.Command{
+		Name:        "export",
+		Description: "Export resources from a database backend to a newline-delimited JSON file",
+		Flags: append(arg.Flags(),
+			&cli.StringFlag{
+				Name:     "filter",
+				Usage:    "SCIM filter restricting the resources exported, defaults to all resources",
+				Value:    "id pr",
+				Required: false,
+			},
+		),
+		Action: func(c *cli.Context) error {
+			_, database, closeDatabase, err := arg.initialize()
+			if err != nil {
+				return err
+			}
+			defer closeDatabase()
+
+			out, err := os.Create(arg.file)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			exported, err := export(context.Background(), database, c.String("filter"), out)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("exported %d resource(s) to %s\n", exported, arg.file)
+			return nil
+		},
+	}
+}
+
+// ImportCommand returns a cli.Command that streams resources from a newline-delimited JSON file
+// into a configured database backend.
+func ImportCommand() *cli.Command {
+	arg := newArguments()
+	return &cli.Command{
+		Name:        "import",
+		Description: "Import resources from a newline-delimited JSON file into a database backend",
+		Flags:       arg.Flags(),
+		Action: func(_ *cli.Context) error {
+			resourceType, database, closeDatabase, err := arg.initialize()
+			if err != nil {
+				return err
+			}
+			defer closeDatabase()
+
+			in, err := os.Open(arg.file)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			imported, err := importResources(context.Background(), database, resourceType, in)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("imported %d resource(s) from %s\n", imported, arg.file)
+			return nil
+		},
+	}
+}
+
+// export pages through the database and writes one serialized resource per line to the writer.
+func export(ctx context.Context, database db.DB, filter string, out io.Writer) (int, error) {
+	var (
+		exported   = 0
+		pagination = &crud.Pagination{StartIndex: 1, Count: exportPageSize}
+		sort       = &crud.Sort{By: "id", Order: crud.SortAsc}
+	)
+	writer := bufio.NewWriter(out)
+	for {
+		resources, err := database.Query(ctx, filter, sort, pagination, nil)
+		if err != nil {
+			return exported, err
+		}
+		for _, resource := range resources {
+			raw, err := scimjson.Serialize(resource)
+			if err != nil {
+				return exported, err
+			}
+			if _, err := writer.Write(raw); err != nil {
+				return exported, err
+			}
+			if err := writer.WriteByte('\n'); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if len(resources) < pagination.Count {
+			break
+		}
+		pagination.StartIndex += pagination.Count
+	}
+	return exported, writer.Flush()
+}
+
+// importResources reads one serialized resource per line off the reader and inserts each into the
+// database. It fails on the first invalid line or insertion error, reporting the line number.
+func importResources(ctx context.Context, database db.DB, resourceType *spec.ResourceType, in io.Reader) (int, error) {
+	var (
+		imported = 0
+		line     = 0
+		scanner  = bufio.NewScanner(in)
+	)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if len(raw) == 0 {
+			continue
+		}
+
+		resource := prop.NewResource(resourceType)
+		if err := scimjson.Deserialize([]byte(raw), resource); err != nil {
+			return imported, fmt.Errorf("line %d: %s", line, err)
+		}
+		if err := database.Insert(ctx, resource); err != nil {
+			return imported, fmt.Errorf("line %d: %s", line, err)
+		}
+		imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
+
+func newArguments() *arguments {
+	return &arguments{
+		Scim:     new(args.Scim),
+		MemoryDB: new(args.MemoryDB),
+		MongoDB:  new(args.MongoDB),
+	}
+}
+
+type arguments struct {
+	*args.Scim
+	*args.MemoryDB
+	*args.MongoDB
+	resource string
+	file     string
+}
+
+func (arg *arguments) Flags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:        "resource",
+			Usage:       "The resource type to transfer, either 'user' or 'group'",
+			Value:       "user",
+			Destination: &arg.resource,
+		},
+		&cli.StringFlag{
+			Name:        "file",
+			Usage:       "Path of the newline-delimited JSON file to transfer resources to or from",
+			Required:    true,
+			Destination: &arg.file,
+		},
+	}
+	flags = append(flags, arg.Scim.Flags()...)
+	flags = append(flags, arg.MemoryDB.Flags()...)
+	flags = append(flags, arg.MongoDB.Flags()...)
+	return flags
+}
+
+// initialize registers the schemas and builds the resource type and database backend named by the
+// arguments. The returned close function flushes and releases the backend and must always be called.
+func (arg *arguments) initialize() (*spec.ResourceType, db.DB, func(), error) {
+	if err := arg.RegisterSchemas(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		resourceType *spec.ResourceType
+		err          error
+	)
+	switch strings.ToLower(arg.resource) {
+	case "user":
+		resourceType, err = arg.ParseUserResourceType()
+	case "group":
+		resourceType, err = arg.ParseGroupResourceType()
+	default:
+		err = fmt.Errorf("invalid resource '%s': expect 'user' or 'group'", arg.resource)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	crud.Register(resourceType)
+
+	database, closeDatabase, err := arg.database(resourceType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return resourceType, database, closeDatabase, nil
+}
+
+func (arg *arguments) database(resourceType *spec.ResourceType) (db.DB, func(), error) {
+	if arg.UseMemoryDB {
+		if len(arg.SnapshotDir) == 0 {
+			return nil, nil, fmt.Errorf("transferring with the memory database requires --memory-snapshot-dir")
+		}
+		database, err := db.MemoryWithSnapshot(db.SnapshotOptions(
+			resourceType,
+			arg.snapshotPath(resourceType),
+		))
+		if err != nil {
+			return nil, nil, err
+		}
+		return database, func() { _ = database.Close() }, nil
+	}
+
+	if err := arg.MongoDB.RegisterMetadata(); err != nil {
+		return nil, nil, err
+	}
+
+	connectCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := arg.MongoDB.Connect(connectCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collection := client.
+		Database(arg.MongoDB.Database, options.Database()).
+		Collection(resourceType.Name(), options.Collection())
+	database := db.DB(scimmongo.DB(resourceType, collection, scimmongo.Options().IgnoreProjection()))
+	return database, func() { _ = client.Disconnect(context.Background()) }, nil
+}
+
+func (arg *arguments) snapshotPath(resourceType *spec.ResourceType) string {
+	name := strings.ToLower(resourceType.Name())
+	return filepath.Join(arg.SnapshotDir, name+".jsonl")
+}
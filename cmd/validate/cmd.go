@@ -0,0 +1,131 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns a cli.Command that parses a resource JSON payload against the given schemas and
+// resource type and runs the validation filter chain on it, printing one line per violation. It
+// exits with an error when the payload is invalid, so that CI pipelines generating SCIM payloads
+// can gate on it.
+func Command() *cli.Command {
+	var (
+		resourceTypePath string
+		inputPath        string
+	)
+	return &cli.Command{
+		Name:        "validate",
+		Description: "Validate a resource JSON payload against schemas and a resource type",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "schema",
+				Usage:    "Path to a schema JSON definition to register, may be specified multiple times",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:        "resource-type",
+				Usage:       "Path to the resource type JSON definition to validate against",
+				Required:    true,
+				Destination: &resourceTypePath,
+			},
+			&cli.StringFlag{
+				Name:        "input",
+				Usage:       "Path to the resource JSON payload to validate",
+				Required:    true,
+				Destination: &inputPath,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			for _, path := range c.StringSlice("schema") {
+				if err := registerSchema(path); err != nil {
+					return err
+				}
+			}
+
+			resourceType, err := parseResourceType(resourceTypePath)
+			if err != nil {
+				return err
+			}
+			crud.Register(resourceType)
+
+			resource, err := parseResource(inputPath, resourceType)
+			if err != nil {
+				fmt.Printf("%s: %s\n", inputPath, err)
+				return fmt.Errorf("1 problem(s) found")
+			}
+
+			chain := filter.AggregateValidation(filter.ValidationFilter(db.Memory()))
+			if err := chain.Filter(context.Background(), resource); err != nil {
+				problems := 0
+				var violations filter.ValidationErrors
+				if errors.As(err, &violations) {
+					for _, violation := range violations {
+						fmt.Printf("%s: %s\n", inputPath, violation)
+						problems++
+					}
+				} else {
+					fmt.Printf("%s: %s\n", inputPath, err)
+					problems++
+				}
+				return fmt.Errorf("%d problem(s) found", problems)
+			}
+
+			return nil
+		},
+	}
+}
+
+func registerSchema(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	schema := new(spec.Schema)
+	if err := json.NewDecoder(f).Decode(schema); err != nil {
+		return fmt.Errorf("invalid schema definition at %s: %s", path, err)
+	}
+	spec.Schemas().Register(schema)
+	return nil
+}
+
+func parseResourceType(path string) (*spec.ResourceType, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	resourceType := new(spec.ResourceType)
+	if err := json.NewDecoder(f).Decode(resourceType); err != nil {
+		return nil, fmt.Errorf("invalid resource type definition at %s: %s", path, err)
+	}
+	return resourceType, nil
+}
+
+func parseResource(path string, resourceType *spec.ResourceType) (*prop.Resource, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := prop.NewResource(resourceType)
+	if err := scimjson.Deserialize(raw, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/db/conformance"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// TestConformance runs the shared database conformance suite against the MongoDB implementation,
+// asserting that its filter, sort, pagination and projection semantics are identical to every other
+// db.DB implementation. Each scenario group runs against a fresh collection.
+func (s *MongoDatabaseTestSuite) TestConformance() {
+	client, err := s.newClient()
+	s.Require().Nil(err)
+
+	i := 0
+	conformance.Run(s.T(), func() (*spec.ResourceType, db.DB) {
+		i++
+		coll := client.Database(testMongoDatabaseName).Collection(fmt.Sprintf("%s_%d", s.T().Name(), i))
+		return s.resourceType, DB(s.resourceType, coll, Options())
+	})
+}
@@ -2,6 +2,8 @@ package v2
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/binary"
 	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/crud/expr"
@@ -9,9 +11,15 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"math/rand"
 	"strconv"
+	"time"
 )
 
 // Create a db.DB implementation that persists data in MongoDB. This implementation supports one-to-one correspondence
@@ -22,7 +30,9 @@ import (
 // just an ordinary index. Any index creation error are treated as non-error and simply ignored.
 //
 // This implementation has limited capability of correctly performing field projection according to the specification.
-// It dumbly treats the *crud.Projection parameter as it is without performing any sanitation. As a result, if any
+// The attribute references in the *crud.Projection parameter are normalized through Projection.Normalize, so that
+// namespace qualified and unqualified references address the same fields, but no further sanitation is performed.
+// As a result, if any
 // returned=always field is excluded, it will not be returned; similarly, if any returned=never field is included,
 // it will be returned. It is expected by downstream calls to perform a pre-sanitation on the parameters or perform
 // a post-guard operation to ensure no sensitive information is leaked.
@@ -57,6 +67,7 @@ import (
 // The only reason that id and version failed to match would then because another process modified the resource concurrently.
 // Therefore, conflict seems to be a reasonable error code.
 func DB(resourceType *spec.ResourceType, coll *mongo.Collection, opt *DBOptions) db.DB {
+	coll = opt.configureCollection(coll)
 	d := &mongoDB{
 		resourceType: resourceType,
 		superAttr:    resourceType.SuperAttribute(true),
@@ -64,6 +75,7 @@ func DB(resourceType *spec.ResourceType, coll *mongo.Collection, opt *DBOptions)
 		t:            newTransformer(resourceType),
 		opt:          opt,
 	}
+	d.t.cosmos = opt.cosmosCompatible
 	d.ensureIndex()
 	return d
 }
@@ -76,7 +88,19 @@ type mongoDB struct {
 	opt          *DBOptions
 }
 
+// opContext derives the context every database operation runs under. When an operation timeout is
+// configured, the returned context expires after it; otherwise the caller's context is used as is.
+func (d *mongoDB) opContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.opt.opTimeout > 0 {
+		return context.WithTimeout(ctx, d.opt.opTimeout)
+	}
+	return ctx, func() {}
+}
+
 func (d *mongoDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	_, err := d.coll.InsertOne(ctx, newBsonAdapter(resource), options.InsertOne())
 	if err != nil {
 		return fmt.Errorf("%w: %v", spec.ErrInternal, err)
@@ -84,12 +108,22 @@ func (d *mongoDB) Insert(ctx context.Context, resource *prop.Resource) error {
 	return nil
 }
 
+// QueryPlan implements the optional db.QueryPlanner interface. Filters are translated to MongoDB
+// queries and executed server side, hence the actual strategy depends on the indexes defined on the
+// collection.
+func (d *mongoDB) QueryPlan(_ context.Context, _ string) (string, error) {
+	return fmt.Sprintf("filter translated to a MongoDB query on collection '%s'; execution strategy depends on collection indexes", d.coll.Name()), nil
+}
+
 func (d *mongoDB) Count(ctx context.Context, filter string) (int, error) {
 	tf, err := d.mongoFilter(filter)
 	if err != nil {
 		return 0, err
 	}
 
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	n, err := d.coll.CountDocuments(ctx, tf, options.Count())
 	if err != nil {
 		return 0, fmt.Errorf("%w: %v", spec.ErrInternal, err)
@@ -99,8 +133,12 @@ func (d *mongoDB) Count(ctx context.Context, filter string) (int, error) {
 }
 
 func (d *mongoDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	opt := options.FindOne()
 	if !d.opt.ignoreProjection && projection != nil {
+		projection.Normalize(d.resourceType)
 		opt = opt.SetProjection(d.mongoProjection(projection))
 	}
 
@@ -135,6 +173,9 @@ func (d *mongoDB) Replace(ctx context.Context, ref *prop.Resource, resource *pro
 		return err
 	}
 
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	sr := d.coll.FindOneAndReplace(ctx, tf, newBsonAdapter(resource), options.FindOneAndReplace())
 	if err := sr.Err(); err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -146,6 +187,121 @@ func (d *mongoDB) Replace(ctx context.Context, ref *prop.Resource, resource *pro
 	return nil
 }
 
+// AddMember implements the optional db.MemberUpdater interface by pushing the member onto the
+// members array of the matched document in a single update, relying on MongoDB's per-document
+// atomicity instead of a read-modify-write cycle. $addToSet keeps the operation idempotent when the
+// exact member is already present. The document's meta.lastModified and meta.version are advanced
+// in the same update.
+func (d *mongoDB) AddMember(ctx context.Context, id string, member map[string]interface{}) error {
+	memberDoc, membersName, err := d.mongoMember(member)
+	if err != nil {
+		return err
+	}
+
+	tf, err := d.mongoFilter(fmt.Sprintf("id eq %s", strconv.Quote(id)))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
+	ur, err := d.coll.UpdateOne(ctx, tf, bson.M{
+		"$addToSet": bson.M{membersName: memberDoc},
+		"$set":      d.refreshedMeta(id),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+	if ur.MatchedCount == 0 {
+		return fmt.Errorf("%w: resource not found by id '%s'", spec.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// RemoveMember implements the optional db.MemberUpdater interface by pulling the member with the
+// given value sub attribute from the members array of the matched document in a single update.
+// Removing an absent member is a no-op. The document's meta.lastModified and meta.version are
+// advanced in the same update.
+func (d *mongoDB) RemoveMember(ctx context.Context, id string, memberValue string) error {
+	_, membersName, err := d.mongoMember(nil)
+	if err != nil {
+		return err
+	}
+
+	tf, err := d.mongoFilter(fmt.Sprintf("id eq %s", strconv.Quote(id)))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
+	ur, err := d.coll.UpdateOne(ctx, tf, bson.M{
+		"$pull": bson.M{membersName: bson.M{"value": memberValue}},
+		"$set":  d.refreshedMeta(id),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+	if ur.MatchedCount == 0 {
+		return fmt.Errorf("%w: resource not found by id '%s'", spec.ErrNotFound, id)
+	}
+
+	return nil
+}
+
+// mongoMember translates the member map keyed by SCIM sub attribute names into a document keyed by
+// the corresponding mongo persistence names, and returns it along with the persistence name of the
+// members attribute itself. A nil member yields a nil document.
+func (d *mongoDB) mongoMember(member map[string]interface{}) (bson.M, string, error) {
+	membersAttr := d.superAttr.SubAttributeForName("members")
+	if membersAttr == nil {
+		return nil, "", fmt.Errorf("%w: resource type has no members attribute", spec.ErrInvalidPath)
+	}
+
+	membersName := membersAttr.Name()
+	if md, ok := metadataHub[membersAttr.ID()]; ok {
+		membersName = md.MongoName
+	}
+
+	if member == nil {
+		return nil, membersName, nil
+	}
+
+	elemAttr := membersAttr.DeriveElementAttribute()
+	memberDoc := bson.M{}
+	for k, v := range member {
+		sub := elemAttr.SubAttributeForName(k)
+		if sub == nil {
+			return nil, "", fmt.Errorf("%w: '%s' is not a member sub attribute", spec.ErrInvalidValue, k)
+		}
+		name := sub.Name()
+		if md, ok := metadataHub[sub.ID()]; ok {
+			name = md.MongoName
+		}
+		memberDoc[name] = v
+	}
+
+	return memberDoc, membersName, nil
+}
+
+// refreshedMeta returns the $set document advancing meta.lastModified and meta.version, producing a
+// version in the same format as the meta filter on the service path.
+func (d *mongoDB) refreshedMeta(id string) bson.M {
+	tsBuf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(tsBuf, rand.Uint64())
+	sha := sha1.New()
+	sha.Write([]byte(id))
+	sha.Write(tsBuf)
+
+	return bson.M{
+		"meta.lastModified": primitive.NewDateTimeFromTime(time.Now()),
+		"meta.version":      fmt.Sprintf("W/\"%x\"", sha.Sum(nil)),
+	}
+}
+
 func (d *mongoDB) Delete(ctx context.Context, resource *prop.Resource) error {
 	var (
 		id      = resource.IdOrEmpty()
@@ -156,6 +312,9 @@ func (d *mongoDB) Delete(ctx context.Context, resource *prop.Resource) error {
 		return err
 	}
 
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	sr := d.coll.FindOneAndDelete(ctx, tf, options.FindOneAndDelete())
 	if err := sr.Err(); err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -168,6 +327,9 @@ func (d *mongoDB) Delete(ctx context.Context, resource *prop.Resource) error {
 }
 
 func (d *mongoDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error) {
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
 	opt := options.Find()
 
 	tf, err := d.mongoFilter(filter)
@@ -177,6 +339,9 @@ func (d *mongoDB) Query(ctx context.Context, filter string, sort *crud.Sort, pag
 
 	if sort != nil {
 		opt.SetSort(d.mongoSort(sort))
+		if collation := d.mongoCollation(sort.Collation); collation != nil {
+			opt.SetCollation(collation)
+		}
 	}
 	if pagination != nil {
 		skip, limit := d.mongoPagination(pagination)
@@ -184,6 +349,7 @@ func (d *mongoDB) Query(ctx context.Context, filter string, sort *crud.Sort, pag
 		opt.SetLimit(limit)
 	}
 	if !d.opt.ignoreProjection && projection != nil {
+		projection.Normalize(d.resourceType)
 		opt.SetProjection(d.mongoProjection(projection))
 	}
 
@@ -211,6 +377,37 @@ func (d *mongoDB) Query(ctx context.Context, filter string, sort *crud.Sort, pag
 	return results, nil
 }
 
+// CountPredicates implements the optional db.PredicateCounter interface by translating the structured
+// predicates directly into a parameterized MongoDB query, bypassing filter string compilation.
+func (d *mongoDB) CountPredicates(ctx context.Context, predicates []db.Predicate) (int, error) {
+	query := bson.D{}
+	for _, predicate := range predicates {
+		mp := d.mongoPathFor(predicate.Path)
+		if len(mp) == 0 {
+			return 0, fmt.Errorf("%w: path '%s' does not resolve to an attribute", spec.ErrInvalidPath, predicate.Path)
+		}
+
+		switch predicate.Op {
+		case db.PredicateEq:
+			query = append(query, bson.E{Key: mp, Value: predicate.Value})
+		case db.PredicateNe:
+			query = append(query, bson.E{Key: mp, Value: bson.D{{Key: "$ne", Value: predicate.Value}}})
+		default:
+			return 0, fmt.Errorf("%w: unsupported predicate op '%s'", spec.ErrInternal, predicate.Op)
+		}
+	}
+
+	ctx, cancel := d.opContext(ctx)
+	defer cancel()
+
+	n, err := d.coll.CountDocuments(ctx, query, options.Count())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+
+	return int(n), nil
+}
+
 // Traverse the attributes structure along the tokens in the given path and
 // return the path used in mongoDB persistence.
 //
@@ -221,10 +418,27 @@ func (d *mongoDB) Query(ctx context.Context, filter string, sort *crud.Sort, pag
 //
 // If this method is unable to find a path, or encounters any error, an empty string is returned.
 func (d *mongoDB) mongoPathFor(path string) string {
+	attr := d.attrForPath(path)
+	if attr == nil {
+		return ""
+	}
+	return d.mongoPathForAttr(attr)
+}
+
+func (d *mongoDB) mongoPathForAttr(attr *spec.Attribute) string {
+	mp := attr.Path()
+	if md, ok := metadataHub[attr.ID()]; ok {
+		mp = md.MongoPath
+	}
+	return mp
+}
+
+// Resolve the attribute corresponding to the given SCIM path, or nil when the path does not resolve.
+func (d *mongoDB) attrForPath(path string) *spec.Attribute {
 	curAttr := d.superAttr
 	cursor, err := expr.CompilePath(path)
 	if err != nil {
-		return ""
+		return nil
 	}
 
 	// skip the first token in the path starts with the id of the resource type's default schema.
@@ -233,44 +447,68 @@ func (d *mongoDB) mongoPathFor(path string) string {
 		cursor = cursor.Next()
 	}
 	if cursor == nil {
-		return ""
+		return nil
 	}
 
 	for cursor != nil {
 		curAttr = curAttr.SubAttributeForName(cursor.Token())
 		if curAttr == nil {
-			return ""
+			return nil
 		}
 		cursor = cursor.Next()
 	}
 
-	mp := curAttr.Path()
-	if md, ok := metadataHub[curAttr.ID()]; ok {
-		mp = md.MongoPath
+	return curAttr
+}
+
+// mongoCollation translates the collation options to their MongoDB counterpart. Comparison strength
+// 2 ignores case, matching the default case insensitive comparison of the in-memory sort; the
+// "simple" locale compares by byte values when no locale is requested.
+func (d *mongoDB) mongoCollation(collation *crud.Collation) *options.Collation {
+	if collation == nil {
+		return nil
 	}
 
-	return mp
+	mc := &options.Collation{Locale: collation.Locale}
+	if len(mc.Locale) == 0 {
+		mc.Locale = "simple"
+	}
+	if !collation.CaseSensitive {
+		mc.Strength = 2
+	}
+	return mc
 }
 
 // Convert the crud.Sort structure to MongoDB driver compatible bson.D structure, so that it can be serialized by the
 // driver. The supplied sort parameter must not be nil. If the sort.By is empty, or sort.By cannot resolve its
-// corresponding MongoDB persistence path, sort is done on the internal "_id" field instead.
+// corresponding MongoDB persistence path, sort is done on the internal "_id" field instead. Secondary sort keys
+// listed in sort.Then are appended in order after the primary key.
 func (d *mongoDB) mongoSort(sort *crud.Sort) bson.D {
+	keys := bson.D{d.mongoSortKey(sort.By, sort.Order)}
+	for _, then := range sort.Then {
+		if len(then.By) > 0 {
+			keys = append(keys, d.mongoSortKey(then.By, then.Order))
+		}
+	}
+	return keys
+}
+
+func (d *mongoDB) mongoSortKey(sortBy string, order crud.SortOrder) bson.E {
 	var by string
 	{
-		if len(sort.By) > 0 {
-			by = d.mongoPathFor(sort.By)
+		if len(sortBy) > 0 {
+			by = d.mongoPathFor(sortBy)
 		}
 		if len(by) == 0 {
 			by = "_id"
 		}
 	}
 
-	switch sort.Order {
+	switch order {
 	case crud.SortAsc, crud.SortDefault:
-		return bson.D{{Key: by, Value: 1}}
+		return bson.E{Key: by, Value: 1}
 	case crud.SortDesc:
-		return bson.D{{Key: by, Value: -1}}
+		return bson.E{Key: by, Value: -1}
 	default:
 		panic("invalid sort order")
 	}
@@ -287,22 +525,42 @@ func (d *mongoDB) mongoPagination(pagination *crud.Pagination) (skip int64, limi
 // Convert the crud.Projection parameter to Mongo driver compatible bson.D structure. The supplied projection
 // parameter must not be nil and should conform to the constraint that only one of "attributes" and "excludedAttributes"
 // shall be used. This method does not further check for that constraint. If a given path cannot resolve its MongoDB
-// persistence path, it will be skipped.
+// persistence path, it will be skipped. Attributes whose returned characteristic is "always" are always fetched
+// and never excluded, so that upper layers can honor SCIM return-ability rules on the reconstructed resource.
 func (d *mongoDB) mongoProjection(projection *crud.Projection) bson.D {
 	if len(projection.Attributes) > 0 {
 		include := bson.D{}
+		seen := map[string]struct{}{}
 		for _, p := range projection.Attributes {
 			if mp := d.mongoPathFor(p); len(mp) > 0 {
-				include = append(include, bson.E{Key: mp, Value: 1})
+				if _, ok := seen[mp]; !ok {
+					seen[mp] = struct{}{}
+					include = append(include, bson.E{Key: mp, Value: 1})
+				}
 			}
 		}
+		_ = d.superAttr.ForEachSubAttribute(func(subAttribute *spec.Attribute) error {
+			if subAttribute.Returned() == spec.ReturnedAlways {
+				if mp := d.mongoPathForAttr(subAttribute); len(mp) > 0 {
+					if _, ok := seen[mp]; !ok {
+						seen[mp] = struct{}{}
+						include = append(include, bson.E{Key: mp, Value: 1})
+					}
+				}
+			}
+			return nil
+		})
 		return include
 	}
 
 	if len(projection.ExcludedAttributes) > 0 {
 		exclude := bson.D{}
-		for _, p := range projection.Attributes {
-			if mp := d.mongoPathFor(p); len(mp) > 0 {
+		for _, p := range projection.ExcludedAttributes {
+			attr := d.attrForPath(p)
+			if attr == nil || attr.Returned() == spec.ReturnedAlways {
+				continue
+			}
+			if mp := d.mongoPathForAttr(attr); len(mp) > 0 {
 				exclude = append(exclude, bson.E{Key: mp, Value: 0})
 			}
 		}
@@ -337,6 +595,11 @@ func Options() *DBOptions {
 
 type DBOptions struct {
 	ignoreProjection bool
+	writeConcern     *writeconcern.WriteConcern
+	readConcern      *readconcern.ReadConcern
+	readPref         *readpref.ReadPref
+	opTimeout        time.Duration
+	cosmosCompatible bool
 }
 
 // Ask the database to ignore any projection parameters. This might be reasonable when the downstream services
@@ -346,6 +609,78 @@ func (opt *DBOptions) IgnoreProjection() *DBOptions {
 	return opt
 }
 
+// WriteMajority asks the database to acknowledge writes only after they are durable on a majority of
+// replica set members, waiting at most wtimeout for the acknowledgement (zero waits indefinitely).
+// Without it, the driver default (w:1) acknowledges a write once the primary has it; if that primary
+// fails over before replication, the write is rolled back and the update is lost even though the
+// caller saw success. The version conflict logic does not guard against this: it only detects
+// concurrent modification at the time of the write, not a later rollback.
+func (opt *DBOptions) WriteMajority(wtimeout time.Duration) *DBOptions {
+	opt.writeConcern = writeconcern.New(writeconcern.WMajority(), writeconcern.WTimeout(wtimeout))
+	return opt
+}
+
+// ReadMajority asks the database to only return data that is durable on a majority of replica set
+// members, so that the meta.version read before a Replace or Delete cannot belong to a write that is
+// later rolled back. Reading rolled back versions makes the subsequent id+version filter miss and
+// surfaces as a spurious conflict.
+func (opt *DBOptions) ReadMajority() *DBOptions {
+	opt.readConcern = readconcern.Majority()
+	return opt
+}
+
+// ReadPreference routes reads according to the given preference, e.g. readpref.SecondaryPreferred()
+// to offload queries. Note that reading from secondaries may return stale meta.version values which
+// the id+version filter of Replace and Delete will then report as conflicts; keep reads on the
+// primary when such operations follow.
+func (opt *DBOptions) ReadPreference(pref *readpref.ReadPref) *DBOptions {
+	opt.readPref = pref
+	return opt
+}
+
+// OperationTimeout bounds the duration of every database operation. A zero timeout (the default)
+// leaves the operation bound only by the caller's context.
+//
+// Retryable writes are a client level setting and cannot be enabled per collection; enable them via
+// the connection string ("retryWrites=true") or options.Client().SetRetryWrites(true) when
+// constructing the mongo.Client. Retried writes interplay safely with the version conflict logic:
+// a write whose acknowledgement was lost but which had already applied will not match meta.version
+// on retry and is reported as a conflict rather than applied twice.
+func (opt *DBOptions) OperationTimeout(timeout time.Duration) *DBOptions {
+	opt.opTimeout = timeout
+	return opt
+}
+
+// CosmosCompatibility adapts the database to Azure CosmosDB's Mongo API, which supports only a
+// subset of MongoDB. Filter translation avoids "$nor" and lookahead regular expressions (see
+// filter.go), and index creation leaves index naming to the server. Use this option when the
+// collection is backed by CosmosDB; against genuine MongoDB the standard translation is preferred
+// as it produces simpler queries.
+func (opt *DBOptions) CosmosCompatibility() *DBOptions {
+	opt.cosmosCompatible = true
+	return opt
+}
+
+// configureCollection returns a collection handle with the configured write concern, read concern and
+// read preference applied. When none is configured, the original handle is returned as is.
+func (opt *DBOptions) configureCollection(coll *mongo.Collection) *mongo.Collection {
+	if opt.writeConcern == nil && opt.readConcern == nil && opt.readPref == nil {
+		return coll
+	}
+
+	collOpt := options.Collection()
+	if opt.writeConcern != nil {
+		collOpt.SetWriteConcern(opt.writeConcern)
+	}
+	if opt.readConcern != nil {
+		collOpt.SetReadConcern(opt.readConcern)
+	}
+	if opt.readPref != nil {
+		collOpt.SetReadPreference(opt.readPref)
+	}
+	return coll.Database().Collection(coll.Name(), collOpt)
+}
+
 var (
 	_ db.DB = (*mongoDB)(nil)
 )
@@ -45,6 +45,10 @@ func newTransformer(resourceType *spec.ResourceType) *transformer {
 
 type transformer struct {
 	superAttr *spec.Attribute
+	// cosmos enables the CosmosDB (Mongo API) compatibility mode, which avoids query operators that
+	// CosmosDB does not support: top level "$nor" is replaced by pushing the negation down onto the
+	// operand, and lookahead regular expressions are replaced by field level "$not".
+	cosmos bool
 }
 
 // Transform the filter which is represented by the root to bsonx.Val.
@@ -94,11 +98,48 @@ func (t *transformer) transformNot(root *expr.Expression) (bson.D, error) {
 	if err != nil {
 		return nil, err
 	}
+	if t.cosmos {
+		return t.negate(left), nil
+	}
 	return bson.D{
 		{Key: mongoNot, Value: bson.A{left}},
 	}, nil
 }
 
+// negate returns the logical negation of the given query without using "$nor", which CosmosDB's
+// Mongo API does not support. Logical junctions are negated by De Morgan's laws; field criteria are
+// wrapped in the field level "$not", which accepts both operator expressions and regular expressions.
+func (t *transformer) negate(doc bson.D) bson.D {
+	if len(doc) == 1 {
+		switch doc[0].Key {
+		case mongoAnd, mongoOr:
+			if branches, ok := doc[0].Value.(bson.A); ok {
+				junction := mongoOr
+				if doc[0].Key == mongoOr {
+					junction = mongoAnd
+				}
+				negated := bson.A{}
+				for _, branch := range branches {
+					if bd, ok := branch.(bson.D); ok {
+						negated = append(negated, t.negate(bd))
+					} else {
+						negated = append(negated, branch)
+					}
+				}
+				return bson.D{{Key: junction, Value: negated}}
+			}
+		}
+	}
+
+	negated := bson.D{}
+	for _, criteria := range doc {
+		negated = append(negated, bson.E{Key: criteria.Key, Value: bson.D{
+			{Key: mongoFieldNot, Value: criteria.Value},
+		}})
+	}
+	return negated
+}
+
 func (t *transformer) transformRelational(containerAttr *spec.Attribute, path *expr.Expression, op *expr.Expression, value *expr.Expression) (bson.D, error) {
 	var (
 		cursorAttr = containerAttr
@@ -186,29 +227,61 @@ func (t *transformer) rearrangeForPr(doc bson.D) bson.D {
 	return bson.D{{Key: mongoAnd, Value: newCriterion}}
 }
 
-func (t *transformer) eqValue(attr *spec.Attribute, value *expr.Expression) interface{} {
-	if attr.Type() != spec.TypeString && attr.CaseExact() {
-		return bson.D{
-			{Key: mongoEq, Value: unquote(value.Token())},
+func (t *transformer) eqValue(attr *spec.Attribute, value *expr.Expression) (interface{}, error) {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference, spec.TypeBinary:
+		if attr.CaseExact() {
+			return bson.D{
+				{Key: mongoEq, Value: unquote(value.Token())},
+			}, nil
 		}
-	} else {
 		return primitive.Regex{
 			Pattern: fmt.Sprintf("^%s$", unquote(value.Token())),
 			Options: "i",
+		}, nil
+	default:
+		// non-textual values must be compared in their native type, so that the comparison
+		// matches the typed value persisted by the serializer (i.e. boolean, number, date)
+		v, err := t.parseValue(value.Token(), attr)
+		if err != nil {
+			return nil, err
 		}
+		return bson.D{
+			{Key: mongoEq, Value: v},
+		}, nil
 	}
 }
 
-func (t *transformer) neValue(attr *spec.Attribute, value *expr.Expression) interface{} {
-	if attr.Type() != spec.TypeString || attr.CaseExact() {
-		return bson.D{
-			{Key: mongoNe, Value: unquote(value.Token())},
+func (t *transformer) neValue(attr *spec.Attribute, value *expr.Expression) (interface{}, error) {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference, spec.TypeBinary:
+		if attr.CaseExact() {
+			return bson.D{
+				{Key: mongoNe, Value: unquote(value.Token())},
+			}, nil
+		}
+		if t.cosmos {
+			// CosmosDB's regex engine does not support lookaheads; negate the whole-value match with
+			// the field level "$not" instead.
+			return bson.D{
+				{Key: mongoFieldNot, Value: primitive.Regex{
+					Pattern: fmt.Sprintf("^%s$", unquote(value.Token())),
+					Options: "i",
+				}},
+			}, nil
 		}
-	} else {
 		return primitive.Regex{
 			Pattern: fmt.Sprintf("^((?!%s$).)", unquote(value.Token())),
 			Options: "i",
+		}, nil
+	default:
+		v, err := t.parseValue(value.Token(), attr)
+		if err != nil {
+			return nil, err
 		}
+		return bson.D{
+			{Key: mongoNe, Value: v},
+		}, nil
 	}
 }
 
@@ -245,7 +318,7 @@ func (t *transformer) coValue(attr *spec.Attribute, value *expr.Expression) prim
 		}
 	} else {
 		return primitive.Regex{
-			Pattern: "unquote(value.Token())",
+			Pattern: unquote(value.Token()),
 			Options: "i",
 		}
 	}
@@ -295,7 +368,11 @@ func (t *transformer) prDoc(attr *spec.Attribute) bson.D {
 	criterion := bson.A{}
 	criterion = append(criterion, existsCriteria, nullCriteria)
 	if attr.MultiValued() {
-		criterion = append(criterion, emptyArrayCriteria)
+		if t.cosmos {
+			criterion = append(criterion, cosmosEmptyArrayCriteria)
+		} else {
+			criterion = append(criterion, emptyArrayCriteria)
+		}
 	} else {
 		switch attr.Type() {
 		case spec.TypeString, spec.TypeReference, spec.TypeBinary:
@@ -310,14 +387,23 @@ func (t *transformer) prDoc(attr *spec.Attribute) bson.D {
 func (t *transformer) transformValue(attr *spec.Attribute, op *expr.Expression, value *expr.Expression) (interface{}, error) {
 	switch op.Token() {
 	case expr.Eq:
-		return t.eqValue(attr, value), nil
+		return t.eqValue(attr, value)
 	case expr.Ne:
-		return t.neValue(attr, value), nil
+		return t.neValue(attr, value)
 	case expr.Sw:
+		if err := t.checkStringLikeTarget(attr, op.Token()); err != nil {
+			return nil, err
+		}
 		return t.swValue(attr, value), nil
 	case expr.Ew:
+		if err := t.checkStringLikeTarget(attr, op.Token()); err != nil {
+			return nil, err
+		}
 		return t.ewValue(attr, value), nil
 	case expr.Co:
+		if err := t.checkStringLikeTarget(attr, op.Token()); err != nil {
+			return nil, err
+		}
 		return t.coValue(attr, value), nil
 	case expr.Gt:
 		return t.gtValue(attr, value)
@@ -334,6 +420,18 @@ func (t *transformer) transformValue(attr *spec.Attribute, op *expr.Expression,
 	}
 }
 
+// checkStringLikeTarget rejects the string matching operators (sw, ew, co) on targets that are not
+// string-like, mirroring the restriction enforced by the in-memory evaluator, so that the semantics
+// stay identical across backends.
+func (t transformer) checkStringLikeTarget(attr *spec.Attribute, op string) error {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference:
+		return nil
+	default:
+		return fmt.Errorf("%w: operator '%s' is not applicable to '%s'", spec.ErrInvalidFilter, op, attr.Path())
+	}
+}
+
 func (t transformer) errIncompatibleValue(attr *spec.Attribute) error {
 	return fmt.Errorf("%w: value in filter incompatible with '%s'", spec.ErrInvalidFilter, attr.Path())
 }
@@ -395,12 +493,17 @@ var (
 			bson.D{{Key: mongoSize, Value: 0}},
 		}},
 	}
+	// the "$nor" free variant of emptyArrayCriteria for the CosmosDB compatibility mode
+	cosmosEmptyArrayCriteria = bson.D{
+		{Key: mongoFieldNot, Value: bson.D{{Key: mongoSize, Value: 0}}},
+	}
 )
 
 const (
 	mongoAnd          = "$and"
 	mongoOr           = "$or"
 	mongoNot          = "$nor"
+	mongoFieldNot     = "$not"
 	mongoElementMatch = "$elemMatch"
 	mongoEq           = "$eq"
 	mongoNe           = "$ne"
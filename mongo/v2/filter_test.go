@@ -2,6 +2,7 @@ package v2
 
 import (
 	"encoding/json"
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -149,6 +150,65 @@ func (s *TransformFilterTestSuite) TestTransform() {
 	}
 }
 
+func (s *TransformFilterTestSuite) TestTransformCosmosCompatible() {
+	tests := []struct {
+		name   string
+		filter string
+		expect func(t *testing.T, extJson string, err error)
+	}{
+		{
+			name:   "not is pushed down instead of $nor",
+			filter: "not (userName eq \"imulab\")",
+			expect: func(t *testing.T, extJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"userName":{"$not":{"$regularExpression":{"pattern":"^imulab$","options":"i"}}}}`
+				assert.JSONEq(t, expect, extJson)
+			},
+		},
+		{
+			name:   "not over logical junction applies De Morgan",
+			filter: "not ((userName eq \"imulab\") and (meta.version eq \"v1\"))",
+			expect: func(t *testing.T, extJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"$or":[{"userName":{"$not":{"$regularExpression":{"pattern":"^imulab$","options":"i"}}}},{"meta.version":{"$not":{"$regularExpression":{"pattern":"^v1$","options":"i"}}}}]}`
+				assert.JSONEq(t, expect, extJson)
+			},
+		},
+		{
+			name:   "case insensitive ne avoids lookahead regex",
+			filter: "userName ne \"imulab\"",
+			expect: func(t *testing.T, extJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"userName":{"$not":{"$regularExpression":{"pattern":"^imulab$","options":"i"}}}}`
+				assert.JSONEq(t, expect, extJson)
+			},
+		},
+		{
+			name:   "multiValued pr avoids $nor",
+			filter: "emails pr",
+			expect: func(t *testing.T, extJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"$and":[{"emails":{"$exists":true}},{"emails":{"$ne":null}},{"emails":{"$not":{"$size":{"$numberInt":"0"}}}}]}`
+				assert.JSONEq(t, expect, extJson)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			root, err := expr.CompileFilter(test.filter)
+			require.Nil(t, err)
+			ct := newTransformer(s.resourceType)
+			ct.cosmos = true
+			v, err := ct.transform(root)
+			assert.Nil(t, err)
+			raw, err := bson.MarshalExtJSON(v, true, false)
+			assert.Nil(t, err)
+			test.expect(t, string(raw), err)
+		})
+	}
+}
+
 func (s *TransformFilterTestSuite) SetupSuite() {
 	for _, each := range []struct {
 		filepath  string
@@ -169,6 +229,13 @@ func (s *TransformFilterTestSuite) SetupSuite() {
 				spec.Schemas().Register(parsed.(*spec.Schema))
 			},
 		},
+		{
+			filepath:  "../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
 		{
 			filepath:  "../../public/resource_types/user_resource_type.json",
 			structure: new(spec.ResourceType),
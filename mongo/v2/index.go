@@ -37,7 +37,9 @@ func (d *mongoDB) ensureIndex() {
 		if a.Uniqueness() == spec.UniquenessServer || a.Uniqueness() == spec.UniquenessGlobal {
 			idm.Options.SetUnique(true)
 		}
-		if name := fmt.Sprintf("idx_%s", strings.Replace(path, ".", "_", -1)); len(name) < 127 {
+		if name := fmt.Sprintf("idx_%s", strings.Replace(path, ".", "_", -1)); len(name) < 127 && !d.opt.cosmosCompatible {
+			// CosmosDB derives index names itself and may reject custom names; leave naming to the
+			// server in compatibility mode.
 			// https://docs.mongodb.com/manual/reference/command/createIndexes/
 			// For MongoDB 4.0 and earlier, the index name has a limit of 127 bytes, here we still adhere to this
 			// constraint without checking for server version. If the formed name is greater than 127 bytes, we will
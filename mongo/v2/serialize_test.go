@@ -98,6 +98,13 @@ func (s *MongoSerializerTestSuite) SetupSuite() {
 				spec.Schemas().Register(parsed.(*spec.Schema))
 			},
 		},
+		{
+			filepath:  "../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
 		{
 			filepath:  "../../public/resource_types/user_resource_type.json",
 			structure: new(spec.ResourceType),
@@ -1,4 +1,7 @@
-// This package describes the internally used annotations
+// This package describes the internally used annotations. Annotations are declared on attributes in
+// the schema JSON under "_annotations" and are, by convention, named with a leading "@". The names
+// listed here are reserved for internal processing; users may declare arbitrary additional annotations
+// and drive custom filters off them through the annotation accessors on spec.Attribute.
 package annotation
 
 const (
@@ -7,7 +10,9 @@ const (
 	// can hold the value true.
 	Primary = "@Primary"
 	// @ExclusivePrimary annotates a multiValued complex property, who wishes to have its
-	// @Primary sub property regulated
+	// @Primary sub property regulated. The annotation takes an optional string parameter named
+	// "mode": "unset" (default) silently turns off the other true-valued primary property;
+	// "reject" returns an error instead; "none" disables the regulation altogether.
 	ExclusivePrimary = "@ExclusivePrimary"
 	// @Root annotates the derived super attribute from a resource type. It is where all propagated events end
 	Root = "@Root"
@@ -40,6 +45,10 @@ const (
 	// a integer parameter named "cost". This will determine the strength of the bCrypt hashing. If omitted, default
 	// cost is 10. The value replacement does not trigger event propagation, it is strictly local.
 	BCrypt = "@BCrypt"
+	// @Password annotates a singular string property holding a secret (i.e. password). The value of the
+	// property will be hashed by the configured password.Hasher and replace the original value, so that
+	// plaintext is never persisted. If the property is unassigned, no operation will be carried out.
+	Password = "@Password"
 	// @ReadOnly annotates a readOnly property and indicates how filters should handle its value. Two options are
 	// available. The first a boolean named "reset": if true, filters shall delete the property value; The second
 	// is a boolean named "copy": if true, filters shall copy value from the reference property, if available.
@@ -48,4 +57,12 @@ const (
 	// canonicalValues. The defined values will be treated as strings and compared with respect to the caseExact
 	// setting.
 	Enum = "@Enum"
+	// @BinarySizeLimit annotates a binary property and caps the size of its value. The annotation takes an
+	// integer parameter named "maxBytes". Values whose decoded size exceeds the limit are rejected at
+	// assignment time. If the parameter is omitted or not positive, no limit is enforced.
+	BinarySizeLimit = "@BinarySizeLimit"
+	// @Sensitive annotates a string property whose value deserves at-rest protection. The protect package
+	// transforms (i.e. encrypts, tokenizes) the values of annotated properties on their way into the
+	// database and restores them on their way out.
+	Sensitive = "@Sensitive"
 )
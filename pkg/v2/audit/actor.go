@@ -0,0 +1,27 @@
+package audit
+
+import "context"
+
+type contextKey int
+
+// actorContextKey is the context key under which the actor id is stored.
+const actorContextKey contextKey = iota
+
+// WithActor returns a copy of the parent context carrying the given actor id. The actor id is typically
+// resolved at the transport layer (i.e. from authentication) and identifies the party performing the
+// mutation. An empty id returns the parent context unmodified.
+func WithActor(parent context.Context, actor string) context.Context {
+	if len(actor) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, actorContextKey, actor)
+}
+
+// ActorFromContext extracts the actor id carried on the context. It returns an empty string when no actor
+// was resolved for the request.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey).(string); ok {
+		return actor
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Operation names recorded on audit entries.
+const (
+	OpCreate  = "create"
+	OpReplace = "replace"
+	OpPatch   = "patch"
+	OpDelete  = "delete"
+)
+
+// Entry is a single audit record describing a resource mutation.
+type Entry struct {
+	// Time is the instant the mutation was recorded, in ISO8601 format.
+	Time string `json:"time"`
+	// Actor identifies the party that performed the mutation, extracted from the context. It may be
+	// empty when the transport layer did not resolve an actor.
+	Actor string `json:"actor,omitempty"`
+	// RequestID is the correlation id of the request that performed the mutation, extracted from the
+	// context. It may be empty when the transport layer did not assign one.
+	RequestID string `json:"requestId,omitempty"`
+	// Operation is one of OpCreate, OpReplace, OpPatch and OpDelete.
+	Operation string `json:"operation"`
+	// ResourceID is the id of the mutated resource.
+	ResourceID string `json:"resourceId"`
+	// ResourceType is the name of the resource type of the mutated resource.
+	ResourceType string `json:"resourceType"`
+	// ChangedPaths lists the paths whose values differ between the old and the new state of the resource.
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+	// OldVersion is the meta.version of the resource before the mutation, if any.
+	OldVersion string `json:"oldVersion,omitempty"`
+	// NewVersion is the meta.version of the resource after the mutation, if any.
+	NewVersion string `json:"newVersion,omitempty"`
+}
+
+// Sink receives audit entries. Implementations must be safe for concurrent use.
+type Sink interface {
+	// Record delivers the entry, or returns any error. Errors are expected to be handled (i.e. logged)
+	// by the caller and must not affect the outcome of the mutation that was audited.
+	Record(ctx context.Context, entry *Entry) error
+}
+
+// New assembles an audit entry for the given operation. The actor is extracted from the context; the id,
+// type, versions and changed paths are derived from the old and new states of the resource, either of which
+// may be nil (i.e. old is nil for create, new is nil for delete).
+func New(ctx context.Context, operation string, old *prop.Resource, new *prop.Resource) *Entry {
+	entry := &Entry{
+		Time:      time.Now().Format(spec.ISO8601),
+		Actor:     ActorFromContext(ctx),
+		RequestID: requestid.FromContext(ctx),
+		Operation: operation,
+	}
+
+	if old != nil {
+		entry.ResourceID = old.IdOrEmpty()
+		entry.ResourceType = old.ResourceType().Name()
+		entry.OldVersion = old.MetaVersionOrEmpty()
+	}
+	if new != nil {
+		entry.ResourceID = new.IdOrEmpty()
+		entry.ResourceType = new.ResourceType().Name()
+		entry.NewVersion = new.MetaVersionOrEmpty()
+	}
+	if old != nil && new != nil {
+		entry.ChangedPaths = ChangedPaths(old, new)
+	}
+
+	return entry
+}
+
+// WriterSink returns a Sink that writes each entry as a single line of JSON to the given writer. Writes
+// are serialized internally, hence the writer itself need not be safe for concurrent use.
+func WriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	sync.Mutex
+	w io.Writer
+}
+
+func (s *writerSink) Record(_ context.Context, entry *Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	_, err = s.w.Write(append(raw, '\n'))
+	return err
+}
+
+// WebhookSink returns a Sink that posts each entry as a JSON document to the given URL. A nil client
+// defaults to http.DefaultClient.
+func WebhookSink(url string, client *http.Client) Sink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &webhookSink{url: url, client: client}
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) Record(ctx context.Context, entry *Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook sink received status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"sort"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// ChangedPaths compares the old and new states of a resource and returns the sorted list of paths whose
+// values differ. Top level and nested singular complex attributes are descended into so that changes are
+// reported at sub attribute granularity; multiValued attributes are reported as a whole.
+func ChangedPaths(old *prop.Resource, new *prop.Resource) []string {
+	paths := make([]string, 0)
+	compareProperty("", old.RootProperty(), new.RootProperty(), &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func compareProperty(path string, oldProp prop.Property, newProp prop.Property, paths *[]string) {
+	switch {
+	case oldProp == nil && newProp == nil:
+		return
+	case oldProp == nil || newProp == nil:
+		*paths = append(*paths, path)
+		return
+	}
+
+	if oldProp.Hash() == newProp.Hash() {
+		return
+	}
+
+	attr := newProp.Attribute()
+	if attr.Type() != spec.TypeComplex || attr.MultiValued() {
+		*paths = append(*paths, path)
+		return
+	}
+
+	_ = newProp.ForEachChild(func(_ int, newChild prop.Property) error {
+		name := newChild.Attribute().Name()
+		oldChild := oldProp.FindChild(func(child prop.Property) bool {
+			return child.Attribute().Name() == name
+		})
+
+		childPath := name
+		if len(path) > 0 {
+			childPath = path + "." + name
+		}
+		compareProperty(childPath, oldChild, newChild, paths)
+		return nil
+	})
+}
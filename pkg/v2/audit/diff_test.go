@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChangedPaths(t *testing.T) {
+	s := new(ChangedPathsTestSuite)
+	suite.Run(t, s)
+}
+
+type ChangedPathsTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ChangedPathsTestSuite) TestChangedPaths() {
+	tests := []struct {
+		name   string
+		before map[string]interface{}
+		after  map[string]interface{}
+		expect []string
+	}{
+		{
+			name: "no change",
+			before: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+			},
+			after: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+			},
+			expect: []string{},
+		},
+		{
+			name: "top level change",
+			before: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+			},
+			after: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "renamed",
+			},
+			expect: []string{"userName"},
+		},
+		{
+			name: "nested complex change",
+			before: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+				"name": map[string]interface{}{
+					"givenName":  "Foo",
+					"familyName": "Bar",
+				},
+			},
+			after: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+				"name": map[string]interface{}{
+					"givenName":  "Changed",
+					"familyName": "Bar",
+				},
+			},
+			expect: []string{"name.givenName"},
+		},
+		{
+			name: "multiValued change reported as a whole",
+			before: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+				"emails": []interface{}{
+					map[string]interface{}{"value": "one@test.org"},
+				},
+			},
+			after: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "foobar",
+				"userName": "foobar",
+				"emails": []interface{}{
+					map[string]interface{}{"value": "two@test.org"},
+				},
+			},
+			expect: []string{"emails"},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			before := prop.NewResource(s.resourceType)
+			assert.False(t, before.Navigator().Replace(test.before).HasError())
+
+			after := prop.NewResource(s.resourceType)
+			assert.False(t, after.Navigator().Replace(test.after).HasError())
+
+			assert.Equal(t, test.expect, ChangedPaths(before, after))
+		})
+	}
+}
+
+func (s *ChangedPathsTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+// This package provides a structured audit trail of resource mutations.
+//
+// An audit Entry records who (the actor carried on the context) changed what (the resource id and type, the
+// operation, and the paths whose values changed) and captures the old and new resource versions. Entries are
+// delivered to a pluggable Sink; this package ships sinks writing JSON lines to an io.Writer (i.e. a file) and
+// posting JSON documents to a webhook URL. Message broker sinks can be provided by implementing Sink.
+package audit
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+)
+
+type (
+	// BulkOperation is a single operation within a SCIM bulk request.
+	BulkOperation struct {
+		Method  string          `json:"method"`
+		Path    string          `json:"path"`
+		BulkId  string          `json:"bulkId,omitempty"`
+		Version string          `json:"version,omitempty"`
+		Data    json.RawMessage `json:"data,omitempty"`
+	}
+	// BulkResult is a single operation result within a SCIM bulk response.
+	BulkResult struct {
+		Method   string          `json:"method"`
+		BulkId   string          `json:"bulkId,omitempty"`
+		Location string          `json:"location,omitempty"`
+		Status   string          `json:"status"`
+		Response json.RawMessage `json:"response,omitempty"`
+	}
+)
+
+// Bulk sends the given operations in a single bulk request. failOnErrors less than or equal to zero
+// leaves the decision to the service provider. The per-operation results are returned in order.
+func (c *Client) Bulk(ctx context.Context, failOnErrors int, operations ...BulkOperation) ([]BulkResult, error) {
+	payload := map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:BulkRequest"},
+		"Operations": operations,
+	}
+	if failOnErrors > 0 {
+		payload["failOnErrors"] = failOnErrors
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/Bulk", bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return nil, errorFromResponse(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var bulkResponse struct {
+		Operations []BulkResult `json:"Operations"`
+	}
+	if err := json.Unmarshal(body, &bulkResponse); err != nil {
+		return nil, err
+	}
+	return bulkResponse.Operations, nil
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+const scimContentType = "application/scim+json"
+
+// New returns a Client that talks to the SCIM service provider rooted at baseURL
+// (i.e. "https://example.com/scim/v2").
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		headers:    map[string]string{},
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+type (
+	// Client is a SCIM 2.0 service provider client. Methods are safe for concurrent use as long
+	// as the underlying http.Client is.
+	Client struct {
+		baseURL    string
+		httpClient *http.Client
+		headers    map[string]string
+	}
+	// Option customizes the Client on construction.
+	Option func(c *Client)
+)
+
+// WithHTTPClient sets the http.Client used to carry out requests. Defaults to http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader adds a header (i.e. Authorization) to every outgoing request.
+func WithHeader(name string, value string) Option {
+	return func(c *Client) {
+		c.headers[name] = value
+	}
+}
+
+// Create posts the given resource to the endpoint of its resource type and returns the resource
+// as created by the service provider.
+func (c *Client) Create(ctx context.Context, resource *prop.Resource) (*prop.Resource, error) {
+	raw, err := scimjson.Serialize(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.resourceExchange(ctx, http.MethodPost, resource.ResourceType(),
+		resource.ResourceType().Endpoint(), bytes.NewReader(raw))
+}
+
+// Get fetches the resource with the given id.
+func (c *Client) Get(ctx context.Context, resourceType *spec.ResourceType, id string) (*prop.Resource, error) {
+	return c.resourceExchange(ctx, http.MethodGet, resourceType,
+		resourceType.Endpoint()+"/"+id, nil)
+}
+
+// Replace puts the given resource at its location and returns the resource as updated by the
+// service provider. The resource must carry an id.
+func (c *Client) Replace(ctx context.Context, resource *prop.Resource) (*prop.Resource, error) {
+	id := resource.IdOrEmpty()
+	if len(id) == 0 {
+		return nil, fmt.Errorf("%w: resource has no id", spec.ErrInvalidValue)
+	}
+
+	raw, err := scimjson.Serialize(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.resourceExchange(ctx, http.MethodPut, resource.ResourceType(),
+		resource.ResourceType().Endpoint()+"/"+id, bytes.NewReader(raw))
+}
+
+// Patch sends the given patch operations to the resource with the given id and returns the patched
+// resource, or nil when the service provider responded with no content.
+func (c *Client) Patch(ctx context.Context, resourceType *spec.ResourceType, id string, operations ...crud.PatchOperation) (*prop.Resource, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": operations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.resourceExchange(ctx, http.MethodPatch, resourceType,
+		resourceType.Endpoint()+"/"+id, bytes.NewReader(payload))
+}
+
+// Delete removes the resource with the given id.
+func (c *Client) Delete(ctx context.Context, resourceType *spec.ResourceType, id string) error {
+	resp, err := c.do(ctx, http.MethodDelete, resourceType.Endpoint()+"/"+id, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return errorFromResponse(resp)
+	}
+	return nil
+}
+
+// resourceExchange performs the request and parses a single resource of the given resource type
+// from the response body. A 204 response yields a nil resource.
+func (c *Client) resourceExchange(ctx context.Context, method string, resourceType *spec.ResourceType, path string, body io.Reader) (*prop.Resource, error) {
+	resp, err := c.do(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return nil, errorFromResponse(resp)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := prop.NewResource(resourceType)
+	if err := scimjson.Deserialize(raw, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Accept", scimContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestClient(t *testing.T) {
+	s := new(ClientTestSuite)
+	suite.Run(t, s)
+}
+
+type ClientTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ClientTestSuite) TestGet() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), http.MethodGet, r.Method)
+		assert.Equal(s.T(), "/Users/user001", r.URL.Path)
+		rw.Header().Set("Content-Type", "application/scim+json")
+		_, _ = rw.Write([]byte(`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"user001","userName":"imulab"}`))
+	}))
+	defer server.Close()
+
+	resource, err := New(server.URL).Get(context.Background(), s.resourceType, "user001")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "user001", resource.IdOrEmpty())
+	assert.Equal(s.T(), "imulab", resource.Navigator().Dot("userName").Current().Raw())
+}
+
+func (s *ClientTestSuite) TestQuery() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), "/Users", r.URL.Path)
+		assert.Equal(s.T(), `userName sw "i"`, r.URL.Query().Get("filter"))
+		rw.Header().Set("Content-Type", "application/scim+json")
+		_, _ = rw.Write([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 1,
+  "startIndex": 1,
+  "itemsPerPage": 1,
+  "Resources": [
+    {"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"user001","userName":"imulab"}
+  ]
+}
+`))
+	}))
+	defer server.Close()
+
+	result, err := New(server.URL).Query(context.Background(), s.resourceType, &QueryOptions{
+		Filter: `userName sw "i"`,
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, result.TotalResults)
+	require.Len(s.T(), result.Resources, 1)
+	assert.Equal(s.T(), "user001", result.Resources[0].IdOrEmpty())
+}
+
+func (s *ClientTestSuite) TestPatch() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), http.MethodPatch, r.Method)
+
+		raw, err := ioutil.ReadAll(r.Body)
+		assert.Nil(s.T(), err)
+
+		var payload struct {
+			Schemas    []string              `json:"schemas"`
+			Operations []crud.PatchOperation `json:"Operations"`
+		}
+		assert.Nil(s.T(), json.Unmarshal(raw, &payload))
+		assert.Equal(s.T(), []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"}, payload.Schemas)
+		assert.Len(s.T(), payload.Operations, 1)
+		assert.Equal(s.T(), "replace", payload.Operations[0].Op)
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	resource, err := New(server.URL).Patch(context.Background(), s.resourceType, "user001", crud.PatchOperation{
+		Op:    "replace",
+		Path:  "userName",
+		Value: json.RawMessage(`"imulab2"`),
+	})
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), resource)
+}
+
+func (s *ClientTestSuite) TestErrorMapping() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/scim+json")
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = rw.Write([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"],
+  "status": "404",
+  "scimType": "notFound",
+  "detail": "resource not found"
+}
+`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL).Get(context.Background(), s.resourceType, "user001")
+	assert.True(s.T(), errors.Is(err, spec.ErrNotFound))
+}
+
+func (s *ClientTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// DB returns a db.DB implementation backed by the remote SCIM service provider behind the client. All
+// persistence and look up operations are translated into SCIM HTTP calls against the endpoint of the
+// given resource type, so that go-scim can act as a protocol-normalizing or policy-enforcing proxy in
+// front of another SCIM implementation.
+//
+// Because the remote service provider owns the data, some operations behave differently from a local
+// database: the id and meta of an inserted resource are whatever the upstream assigned; projections
+// passed to Get are ignored so that caller services see the full resource; Count is implemented as a
+// query asking for zero items and reading totalResults off the ListResponse.
+func DB(c *Client, resourceType *spec.ResourceType) db.DB {
+	return &remoteDB{client: c, resourceType: resourceType}
+}
+
+type remoteDB struct {
+	client       *Client
+	resourceType *spec.ResourceType
+}
+
+func (d *remoteDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	_, err := d.client.Create(ctx, resource)
+	return err
+}
+
+func (d *remoteDB) Count(ctx context.Context, filter string) (int, error) {
+	zero := 0
+	result, err := d.client.Query(ctx, d.resourceType, &QueryOptions{
+		Filter: filter,
+		Count:  &zero,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.TotalResults, nil
+}
+
+func (d *remoteDB) Get(ctx context.Context, id string, _ *crud.Projection) (*prop.Resource, error) {
+	return d.client.Get(ctx, d.resourceType, id)
+}
+
+func (d *remoteDB) Replace(ctx context.Context, _ *prop.Resource, replacement *prop.Resource) error {
+	_, err := d.client.Replace(ctx, replacement)
+	return err
+}
+
+func (d *remoteDB) Delete(ctx context.Context, resource *prop.Resource) error {
+	return d.client.Delete(ctx, d.resourceType, resource.IdOrEmpty())
+}
+
+func (d *remoteDB) Query(
+	ctx context.Context,
+	filter string,
+	sort *crud.Sort,
+	pagination *crud.Pagination,
+	projection *crud.Projection,
+) ([]*prop.Resource, error) {
+	options := &QueryOptions{Filter: filter}
+	if sort != nil {
+		options.SortBy = sort.By
+		options.SortOrder = string(sort.Order)
+	}
+	if pagination != nil {
+		options.StartIndex = pagination.StartIndex
+		count := pagination.Count
+		options.Count = &count
+	}
+	if projection != nil {
+		options.Attributes = projection.Attributes
+		options.ExcludedAttributes = projection.ExcludedAttributes
+	}
+
+	result, err := d.client.Query(ctx, d.resourceType, options)
+	if err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRemoteDB(t *testing.T) {
+	s := new(RemoteDBTestSuite)
+	suite.Run(t, s)
+}
+
+type RemoteDBTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *RemoteDBTestSuite) TestQuery() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), "/Users", r.URL.Path)
+		assert.Equal(s.T(), `userName eq "imulab"`, r.URL.Query().Get("filter"))
+		assert.Equal(s.T(), "userName", r.URL.Query().Get("sortBy"))
+		assert.Equal(s.T(), "ascending", r.URL.Query().Get("sortOrder"))
+		assert.Equal(s.T(), "1", r.URL.Query().Get("startIndex"))
+		assert.Equal(s.T(), "10", r.URL.Query().Get("count"))
+		rw.Header().Set("Content-Type", "application/scim+json")
+		_, _ = rw.Write([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 1,
+  "startIndex": 1,
+  "itemsPerPage": 1,
+  "Resources": [
+    {"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"user001","userName":"imulab"}
+  ]
+}
+`))
+	}))
+	defer server.Close()
+
+	database := DB(New(server.URL), s.resourceType)
+	resources, err := database.Query(context.Background(), `userName eq "imulab"`,
+		&crud.Sort{By: "userName", Order: crud.SortAsc},
+		&crud.Pagination{StartIndex: 1, Count: 10},
+		nil,
+	)
+	assert.Nil(s.T(), err)
+	require.Len(s.T(), resources, 1)
+	assert.Equal(s.T(), "user001", resources[0].IdOrEmpty())
+}
+
+func (s *RemoteDBTestSuite) TestCount() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(s.T(), "0", r.URL.Query().Get("count"))
+		rw.Header().Set("Content-Type", "application/scim+json")
+		_, _ = rw.Write([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 42,
+  "Resources": []
+}
+`))
+	}))
+	defer server.Close()
+
+	count, err := DB(New(server.URL), s.resourceType).Count(context.Background(), "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 42, count)
+}
+
+func (s *RemoteDBTestSuite) TestInsertAndDelete() {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			assert.Equal(s.T(), "/Users", r.URL.Path)
+			raw, err := ioutil.ReadAll(r.Body)
+			assert.Nil(s.T(), err)
+			rw.Header().Set("Content-Type", "application/scim+json")
+			rw.WriteHeader(201)
+			_, _ = rw.Write(raw)
+		case http.MethodDelete:
+			assert.Equal(s.T(), "/Users/user001", r.URL.Path)
+			rw.WriteHeader(204)
+		default:
+			s.T().Errorf("unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	database := DB(New(server.URL), s.resourceType)
+
+	resource := prop.NewResource(s.resourceType)
+	err := scimjson.Deserialize([]byte(`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"id":"user001","userName":"imulab"}`), resource)
+	require.Nil(s.T(), err)
+
+	assert.Nil(s.T(), database.Insert(context.Background(), resource))
+	assert.Nil(s.T(), database.Delete(context.Background(), resource))
+}
+
+func (s *RemoteDBTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// This package implements a Go SCIM 2.0 client built on the same spec and prop foundation as the
+// server packages. Responses are parsed directly into prop.Resource using a registered
+// spec.ResourceType, so services consuming remote SCIM APIs do not need to maintain a second model.
+package client
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// errorPrototypes lists the defined spec errors so a remote error payload can be mapped back onto
+// the prototype that produced it, allowing callers to test with errors.Is just like on the server.
+var errorPrototypes = []*spec.Error{
+	spec.ErrInvalidFilter,
+	spec.ErrTooMany,
+	spec.ErrUniqueness,
+	spec.ErrMutability,
+	spec.ErrInvalidSyntax,
+	spec.ErrInvalidPath,
+	spec.ErrNoTarget,
+	spec.ErrInvalidValue,
+	spec.ErrSensitive,
+	spec.ErrNotFound,
+	spec.ErrConflict,
+	spec.ErrTooManyRequests,
+	spec.ErrInternal,
+}
+
+// errorFromResponse parses the SCIM error payload of a non-2xx response and returns an error
+// wrapping the corresponding spec error prototype.
+func errorFromResponse(resp *http.Response) error {
+	var payload struct {
+		Status   string `json:"status"`
+		ScimType string `json:"scimType"`
+		Detail   string `json:"detail"`
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		_ = json.Unmarshal(raw, &payload)
+	}
+
+	for _, prototype := range errorPrototypes {
+		if payload.Status == fmt.Sprintf("%d", prototype.Status) && payload.ScimType == prototype.Type {
+			if len(payload.Detail) > 0 {
+				return fmt.Errorf("%w: %s", prototype, payload.Detail)
+			}
+			return prototype
+		}
+	}
+
+	return fmt.Errorf("%w: service provider returned status %d", spec.ErrInternal, resp.StatusCode)
+}
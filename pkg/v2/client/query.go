@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+type (
+	// QueryOptions carries the query parameters of a SCIM query request.
+	QueryOptions struct {
+		Filter             string
+		SortBy             string
+		SortOrder          string
+		StartIndex         int
+		Count              *int // pointer so that an explicit count=0 can be expressed
+		Attributes         []string
+		ExcludedAttributes []string
+	}
+	// QueryResult is the parsed ListResponse of a SCIM query request.
+	QueryResult struct {
+		TotalResults int
+		StartIndex   int
+		ItemsPerPage int
+		Resources    []*prop.Resource
+	}
+)
+
+// Query performs a query against the endpoint of the given resource type and parses the returned
+// ListResponse. Options may be nil to query without parameters.
+func (c *Client) Query(ctx context.Context, resourceType *spec.ResourceType, options *QueryOptions) (*QueryResult, error) {
+	path := resourceType.Endpoint()
+	if query := options.encode(); len(query) > 0 {
+		path = path + "?" + query
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return nil, errorFromResponse(resp)
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the queried resource type is resolvable by the ListResponse decoder
+	spec.ResourceTypes().Register(resourceType)
+
+	list, err := scimjson.DeserializeListResponse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryResult{
+		TotalResults: list.TotalResults,
+		StartIndex:   list.StartIndex,
+		ItemsPerPage: list.ItemsPerPage,
+		Resources:    list.Resources,
+	}, nil
+}
+
+func (opt *QueryOptions) encode() string {
+	if opt == nil {
+		return ""
+	}
+
+	values := url.Values{}
+	if len(opt.Filter) > 0 {
+		values.Set("filter", opt.Filter)
+	}
+	if len(opt.SortBy) > 0 {
+		values.Set("sortBy", opt.SortBy)
+	}
+	if len(opt.SortOrder) > 0 {
+		values.Set("sortOrder", opt.SortOrder)
+	}
+	if opt.StartIndex > 0 {
+		values.Set("startIndex", strconv.Itoa(opt.StartIndex))
+	}
+	if opt.Count != nil {
+		values.Set("count", strconv.Itoa(*opt.Count))
+	}
+	if len(opt.Attributes) > 0 {
+		values.Set("attributes", strings.Join(opt.Attributes, ","))
+	}
+	if len(opt.ExcludedAttributes) > 0 {
+		values.Set("excludedAttributes", strings.Join(opt.ExcludedAttributes, ","))
+	}
+	return values.Encode()
+}
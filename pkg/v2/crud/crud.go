@@ -2,6 +2,7 @@ package crud
 
 import (
 	"fmt"
+
 	"github.com/imulab/go-scim/pkg/v2/crud/expr"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
@@ -64,7 +65,8 @@ func skipMainSchemaNamespace(resource *prop.Resource, query *expr.Expression) *e
 		return nil
 	}
 
-	if query.IsPath() && query.Token() == resource.ResourceType().Schema().ID() {
+	// URNs are case-insensitive, as are SCIM attribute names
+	if query.IsPath() && spec.SameAttributeName(query.Token(), resource.ResourceType().Schema().ID()) {
 		return query.Next()
 	}
 
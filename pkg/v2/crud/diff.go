@@ -0,0 +1,174 @@
+package crud
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Diff computes the patch operations that transform the value of the old resource into the value of
+// the new resource. The returned operations use the add, replace and remove semantics of RFC 7644
+// Section 3.5.2 and are minimal in the sense that unchanged properties produce no operations:
+// singular complex properties are descended into, and multiValued properties are compared element
+// wise, emitting element additions and filtered element removals where the element values allow it.
+// Properties whose mutability is readOnly are skipped, as they cannot be patched. Applying the
+// returned operations to the old resource through ApplyPatch yields the patchable portion of the
+// new resource. The two resources must share the same resource type.
+//
+// Diff is used internally to describe modifications for auditing and eventing purposes, and is
+// exposed for clients that reconcile a desired state against the actual state of a resource.
+func Diff(oldResource *prop.Resource, newResource *prop.Resource) ([]PatchOperation, error) {
+	if oldResource.ResourceType().ID() != newResource.ResourceType().ID() {
+		return nil, fmt.Errorf("%w: cannot diff resources of different resource types", spec.ErrInvalidValue)
+	}
+
+	var ops []PatchOperation
+
+	var visit func(oldProp prop.Property, newProp prop.Property) error
+	visit = func(oldProp prop.Property, newProp prop.Property) error {
+		attr := oldProp.Attribute()
+		if attr.Mutability() == spec.MutabilityReadOnly {
+			return nil
+		}
+		if oldProp.Matches(newProp) {
+			return nil
+		}
+
+		switch {
+		case newProp.IsUnassigned():
+			ops = append(ops, PatchOperation{Op: "remove", Path: attr.Path()})
+			return nil
+		case oldProp.IsUnassigned():
+			op, err := valueOperation("add", attr.Path(), newProp.Raw())
+			if err != nil {
+				return err
+			}
+			ops = append(ops, op)
+			return nil
+		case attr.MultiValued():
+			elementOps, err := diffElements(oldProp, newProp)
+			if err != nil {
+				return err
+			}
+			ops = append(ops, elementOps...)
+			return nil
+		case attr.Type() == spec.TypeComplex:
+			return oldProp.ForEachChild(func(_ int, oldChild prop.Property) error {
+				newChild, err := newProp.ChildAtIndex(oldChild.Attribute().Name())
+				if err != nil || newChild == nil {
+					return nil
+				}
+				return visit(oldChild, newChild)
+			})
+		default:
+			op, err := valueOperation("replace", attr.Path(), newProp.Raw())
+			if err != nil {
+				return err
+			}
+			ops = append(ops, op)
+			return nil
+		}
+	}
+
+	err := oldResource.Navigator().Current().ForEachChild(func(_ int, oldChild prop.Property) error {
+		newChild, err := newResource.Navigator().Current().ChildAtIndex(oldChild.Attribute().Name())
+		if err != nil || newChild == nil {
+			return nil
+		}
+		return visit(oldChild, newChild)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+// diffElements compares the elements of two assigned multiValued properties. Elements present only
+// in the new property produce a single add operation appending them; elements present only in the
+// old property produce filtered remove operations when their identifying "value" sub property is
+// assigned. When a removed element cannot be addressed by a filter, the whole property is replaced
+// instead.
+func diffElements(oldProp prop.Property, newProp prop.Property) ([]PatchOperation, error) {
+	attr := oldProp.Attribute()
+
+	var added []interface{}
+	_ = newProp.ForEachChild(func(_ int, newElem prop.Property) error {
+		if oldProp.FindChild(func(oldElem prop.Property) bool { return oldElem.Matches(newElem) }) == nil {
+			added = append(added, newElem.Raw())
+		}
+		return nil
+	})
+
+	var (
+		removePaths []string
+		replaceAll  bool
+	)
+	_ = oldProp.ForEachChild(func(_ int, oldElem prop.Property) error {
+		if replaceAll {
+			return nil
+		}
+		if newProp.FindChild(func(newElem prop.Property) bool { return newElem.Matches(oldElem) }) != nil {
+			return nil
+		}
+		literal, ok := elementValueLiteral(oldElem)
+		if !ok {
+			replaceAll = true
+			return nil
+		}
+		removePaths = append(removePaths, fmt.Sprintf("%s[value eq %s]", attr.Path(), literal))
+		return nil
+	})
+
+	if replaceAll {
+		op, err := valueOperation("replace", attr.Path(), newProp.Raw())
+		if err != nil {
+			return nil, err
+		}
+		return []PatchOperation{op}, nil
+	}
+
+	var ops []PatchOperation
+	for _, path := range removePaths {
+		ops = append(ops, PatchOperation{Op: "remove", Path: path})
+	}
+	if len(added) > 0 {
+		op, err := valueOperation("add", attr.Path(), added)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// elementValueLiteral renders the identifying "value" sub property of a complex multiValued element
+// as a filter literal. The second return value is false when the element carries no assigned "value"
+// sub property to filter on.
+func elementValueLiteral(element prop.Property) (string, bool) {
+	if element.Attribute().Type() != spec.TypeComplex {
+		return "", false
+	}
+
+	valueProp, err := element.ChildAtIndex("value")
+	if err != nil || valueProp == nil || valueProp.IsUnassigned() {
+		return "", false
+	}
+
+	raw, err := json.Marshal(valueProp.Raw())
+	if err != nil {
+		return "", false
+	}
+	return string(raw), true
+}
+
+// valueOperation renders the raw property value as the JSON value of a patch operation.
+func valueOperation(op string, path string, value interface{}) (PatchOperation, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return PatchOperation{}, fmt.Errorf("%w: value of '%s' cannot be rendered", spec.ErrInternal, path)
+	}
+	return PatchOperation{Op: op, Path: path, Value: raw}, nil
+}
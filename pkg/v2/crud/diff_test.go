@@ -0,0 +1,246 @@
+package crud
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestDiff(t *testing.T) {
+	s := new(DiffTestSuite)
+	suite.Run(t, s)
+}
+
+type DiffTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *DiffTestSuite) TestDiff() {
+	tests := []struct {
+		name          string
+		getOld        func(t *testing.T) *prop.Resource
+		getNew        func(t *testing.T) *prop.Resource
+		expect        func(t *testing.T, ops []PatchOperation, err error)
+		skipRoundTrip bool
+	}{
+		{
+			name: "identical resources produce no operations",
+			getOld: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("userName").Replace("imulab").HasError())
+				return r
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("userName").Replace("imulab").HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, ops, 0)
+			},
+		},
+		{
+			name: "changed simple property produces replace",
+			getOld: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("userName").Replace("imulab").HasError())
+				return r
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("userName").Replace("foobar").HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				require.Len(t, ops, 1)
+				assert.Equal(t, "replace", ops[0].Op)
+				assert.Equal(t, "userName", ops[0].Path)
+				assert.JSONEq(t, `"foobar"`, string(ops[0].Value))
+			},
+		},
+		{
+			name: "newly assigned property produces add",
+			getOld: func(t *testing.T) *prop.Resource {
+				return prop.NewResource(s.resourceType)
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("nickName").Replace("imulab").HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				require.Len(t, ops, 1)
+				assert.Equal(t, "add", ops[0].Op)
+				assert.Equal(t, "nickName", ops[0].Path)
+				assert.JSONEq(t, `"imulab"`, string(ops[0].Value))
+			},
+		},
+		{
+			name: "unassigned property produces remove",
+			getOld: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("title").Replace("engineer").HasError())
+				return r
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				return prop.NewResource(s.resourceType)
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				require.Len(t, ops, 1)
+				assert.Equal(t, "remove", ops[0].Op)
+				assert.Equal(t, "title", ops[0].Path)
+				assert.Nil(t, ops[0].Value)
+			},
+		},
+		{
+			name: "changed sub property produces nested replace",
+			getOld: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("name").Replace(map[string]interface{}{
+					"givenName":  "Weinan",
+					"familyName": "Qiu",
+				}).HasError())
+				return r
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("name").Replace(map[string]interface{}{
+					"givenName":  "David",
+					"familyName": "Qiu",
+				}).HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				require.Len(t, ops, 1)
+				assert.Equal(t, "replace", ops[0].Op)
+				assert.Equal(t, "name.givenName", ops[0].Path)
+				assert.JSONEq(t, `"David"`, string(ops[0].Value))
+			},
+		},
+		{
+			name: "changed multiValued elements produce filtered remove and add",
+			getOld: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("emails").Replace([]interface{}{
+					map[string]interface{}{"value": "one@test.org", "type": "work"},
+					map[string]interface{}{"value": "two@test.org", "type": "home"},
+				}).HasError())
+				return r
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("emails").Replace([]interface{}{
+					map[string]interface{}{"value": "one@test.org", "type": "work"},
+					map[string]interface{}{"value": "three@test.org", "type": "home"},
+				}).HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				require.Len(t, ops, 2)
+				assert.Equal(t, "remove", ops[0].Op)
+				assert.Equal(t, `emails[value eq "two@test.org"]`, ops[0].Path)
+				assert.Equal(t, "add", ops[1].Op)
+				assert.Equal(t, "emails", ops[1].Path)
+				assert.JSONEq(t, `[{"value":"three@test.org","type":"home"}]`, string(ops[1].Value))
+			},
+		},
+		{
+			// the readOnly differences are not patchable, hence the old resource cannot be
+			// reconciled with the new resource by the returned operations
+			name:          "readOnly property changes are skipped",
+			skipRoundTrip: true,
+			getOld: func(t *testing.T) *prop.Resource {
+				return prop.NewResource(s.resourceType)
+			},
+			getNew: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				assert.False(t, r.Navigator().Dot("id").Replace("foobar").HasError())
+				assert.False(t, r.Navigator().Dot("meta").Dot("version").Replace("W/\"1\"").HasError())
+				return r
+			},
+			expect: func(t *testing.T, ops []PatchOperation, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, ops, 0)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			old, latest := test.getOld(t), test.getNew(t)
+			ops, err := Diff(old, latest)
+			test.expect(t, ops, err)
+
+			// applying the operations back onto the old resource reconciles it with the new resource
+			if err == nil && !test.skipRoundTrip {
+				_, err = ApplyPatch(old, ops)
+				assert.Nil(t, err)
+				assert.Equal(t, latest.Hash(), old.Hash())
+			}
+		})
+	}
+}
+
+func (s *DiffTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+
+	Register(s.resourceType)
+}
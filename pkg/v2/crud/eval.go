@@ -156,6 +156,10 @@ func (v evaluator) evalNe(target prop.Property, ne *expr.Expression) (bool, erro
 }
 
 func (v evaluator) evalSw(target prop.Property, sw *expr.Expression) (bool, error) {
+	if err := v.checkStringLikeTarget(target, expr.Sw); err != nil {
+		return false, err
+	}
+
 	swTarget, ok := target.(prop.SwCapable)
 	if !ok {
 		return false, nil
@@ -174,6 +178,10 @@ func (v evaluator) evalSw(target prop.Property, sw *expr.Expression) (bool, erro
 }
 
 func (v evaluator) evalEw(target prop.Property, ew *expr.Expression) (bool, error) {
+	if err := v.checkStringLikeTarget(target, expr.Ew); err != nil {
+		return false, err
+	}
+
 	ewTarget, ok := target.(prop.EwCapable)
 	if !ok {
 		return false, nil
@@ -192,6 +200,10 @@ func (v evaluator) evalEw(target prop.Property, ew *expr.Expression) (bool, erro
 }
 
 func (v evaluator) evalCo(target prop.Property, co *expr.Expression) (bool, error) {
+	if err := v.checkStringLikeTarget(target, expr.Co); err != nil {
+		return false, err
+	}
+
 	coTarget, ok := target.(prop.CoCapable)
 	if !ok {
 		return false, nil
@@ -265,6 +277,18 @@ func (v evaluator) evalLe(target prop.Property, le *expr.Expression) (bool, erro
 	return leTarget.LessThanOrEqualTo(value), nil
 }
 
+// checkStringLikeTarget rejects the string matching operators (sw, ew, co) on targets that are not
+// string-like, instead of silently evaluating to false. The same restriction is enforced by the
+// MongoDB filter translation, keeping the semantics identical across backends.
+func (v evaluator) checkStringLikeTarget(target prop.Property, op string) error {
+	switch target.Attribute().Type() {
+	case spec.TypeString, spec.TypeReference:
+		return nil
+	default:
+		return fmt.Errorf("%w: operator '%s' is not applicable to '%s'", spec.ErrInvalidFilter, op, target.Attribute().Path())
+	}
+}
+
 func (v evaluator) evalPr(target prop.Property) (bool, error) {
 	prTarget, ok := target.(prop.PrCapable)
 	if !ok {
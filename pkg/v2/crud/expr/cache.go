@@ -0,0 +1,95 @@
+package expr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultCompileCacheCapacity bounds the number of compiled expressions kept in memory by default.
+const defaultCompileCacheCapacity = 256
+
+// compileCache caches compiled path and filter expressions keyed by their raw string. Services
+// compile the same handful of expressions over and over (i.e. sort keys, uniqueness filters), hence
+// a small bounded cache removes most of the compilation work.
+var compileCache = newLRUCache(defaultCompileCacheCapacity)
+
+// SetCompileCacheCapacity bounds the number of compiled path and filter expressions cached in
+// memory. A capacity less than or equal to zero disables caching and drops any existing entries.
+// Cached expressions are shared between callers and must be treated as immutable.
+func SetCompileCacheCapacity(capacity int) {
+	compileCache.resize(capacity)
+}
+
+type (
+	lruCache struct {
+		sync.Mutex
+		capacity int
+		order    *list.List
+		entries  map[string]*list.Element
+	}
+	lruEntry struct {
+		key   string
+		value *Expression
+	}
+)
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (*Expression, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) put(key string, value *Expression) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	c.evict()
+}
+
+func (c *lruCache) resize(capacity int) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.capacity = capacity
+	if capacity <= 0 {
+		c.order.Init()
+		c.entries = make(map[string]*list.Element)
+		return
+	}
+	c.evict()
+}
+
+// evict drops the least recently used entries until the cache is within capacity. Callers must hold
+// the lock.
+func (c *lruCache) evict() {
+	for c.order.Len() > c.capacity {
+		tail := c.order.Back()
+		c.order.Remove(tail)
+		delete(c.entries, tail.Value.(*lruEntry).key)
+	}
+}
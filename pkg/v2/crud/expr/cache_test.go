@@ -0,0 +1,82 @@
+package expr
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestCompileCache(t *testing.T) {
+	s := new(CompileCacheTestSuite)
+	suite.Run(t, s)
+}
+
+type CompileCacheTestSuite struct {
+	suite.Suite
+}
+
+func (s *CompileCacheTestSuite) TearDownTest() {
+	SetCompileCacheCapacity(defaultCompileCacheCapacity)
+}
+
+func (s *CompileCacheTestSuite) TestCachedExpressionIsShared() {
+	first, err := CompilePath("emails[type eq \"work\"].value")
+	s.Require().Nil(err)
+
+	second, err := CompilePath("emails[type eq \"work\"].value")
+	s.Require().Nil(err)
+	s.True(first == second)
+
+	f1, err := CompileFilter("userName eq \"imulab\"")
+	s.Require().Nil(err)
+
+	f2, err := CompileFilter("userName eq \"imulab\"")
+	s.Require().Nil(err)
+	s.True(f1 == f2)
+}
+
+func (s *CompileCacheTestSuite) TestEmbeddedFilterIsNotShared() {
+	filter, err := CompileFilter("value eq \"bar\"")
+	s.Require().Nil(err)
+
+	withSub, err := CompilePath("emails[value eq \"bar\"].display")
+	s.Require().Nil(err)
+	s.True(withSub.Next().IsRootOfFilter())
+	s.False(filter == withSub.Next())
+
+	// compiling a path whose embedded filter continues into a sub attribute
+	// must not link a next step onto the independently cached filter root
+	s.Nil(filter.Next())
+
+	withoutSub, err := CompilePath("emails[value eq \"bar\"]")
+	s.Require().Nil(err)
+	s.Nil(withoutSub.Next().Next())
+}
+
+func (s *CompileCacheTestSuite) TestDisableCache() {
+	SetCompileCacheCapacity(0)
+
+	first, err := CompilePath("userName")
+	s.Require().Nil(err)
+
+	second, err := CompilePath("userName")
+	s.Require().Nil(err)
+	s.False(first == second)
+}
+
+func (s *CompileCacheTestSuite) TestCapacityBoundsEntries() {
+	SetCompileCacheCapacity(2)
+
+	first, err := CompilePath("displayName")
+	s.Require().Nil(err)
+
+	for i := 0; i < 3; i++ {
+		_, err := CompilePath(fmt.Sprintf("title%d", i))
+		s.Require().Nil(err)
+	}
+
+	// displayName was the least recently used entry and must have been evicted
+	second, err := CompilePath("displayName")
+	s.Require().Nil(err)
+	s.False(first == second)
+}
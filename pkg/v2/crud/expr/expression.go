@@ -124,16 +124,19 @@ func (e *Expression) Walk(cb func(expression *Expression), marker *Expression, d
 	}
 }
 
+// newOperator returns an operator Expression for the given token. The token is normalized to its
+// lower case form, as RFC 7644 defines filter operators to be case insensitive, while downstream
+// consumers dispatch on the lower case operator constants.
 func newOperator(op string) *Expression {
 	switch strings.ToLower(op) {
 	case And, Or, Not:
 		return &Expression{
-			token: op,
+			token: strings.ToLower(op),
 			typ:   logicalOp,
 		}
 	case Eq, Ne, Sw, Ew, Co, Gt, Ge, Lt, Le, Pr:
 		return &Expression{
-			token: op,
+			token: strings.ToLower(op),
 			typ:   relationalOp,
 		}
 	default:
@@ -19,7 +19,25 @@ import (
 //	                     /  \
 //	                primary true
 //
+// Compilation results are cached and shared between callers; see SetCompileCacheCapacity.
 func CompileFilter(filter string) (*Expression, error) {
+	if cached, ok := compileCache.get("f\x00" + filter); ok {
+		return cached, nil
+	}
+
+	root, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	compileCache.put("f\x00"+filter, root)
+	return root, nil
+}
+
+// compileFilter performs the actual filter compilation, bypassing the cache. The path compiler uses
+// it directly because it links the compiled filter into the path expression, which must not touch a
+// shared cached instance.
+func compileFilter(filter string) (*Expression, error) {
 	compiler := &filterCompiler{
 		scan:    &filterScanner{},
 		data:    append(copyOf(filter), 0, 0),
@@ -136,7 +154,7 @@ var (
 	}
 	// function to return operator cardinality
 	opCardinality = func(op string) int {
-		switch op {
+		switch strings.ToLower(op) {
 		case Not, Pr:
 			return 1
 		case And, Or, Eq, Ne, Sw, Ew, Co, Gt, Ge, Lt, Le:
@@ -21,7 +21,12 @@ import (
 //	            /  \
 //	         value  "foo@bar.com"
 //
+// Compilation results are cached and shared between callers; see SetCompileCacheCapacity.
 func CompilePath(path string) (*Expression, error) {
+	if cached, ok := compileCache.get("p\x00" + path); ok {
+		return cached, nil
+	}
+
 	compiler := &pathCompiler{
 		scan: &pathScanner{},
 		data: append(copyOf(path), 0, 0),
@@ -41,9 +46,15 @@ func CompilePath(path string) (*Expression, error) {
 		cursor.next = next
 		cursor = cursor.next
 	}
+	// a scanning error raised right after a completed token ends the loop without passing through
+	// next, and must still fail the compilation instead of silently truncating the path
+	if compiler.op == scanPathError {
+		return nil, compiler.scan.err
+	}
 	cursor = head.next
 	head = cursor
 
+	compileCache.put("p\x00"+path, head)
 	return head, nil
 }
 
@@ -117,7 +128,7 @@ func (c *pathCompiler) scanFilter() (*Expression, error) {
 	end := c.skipWhile(scanPathContinue)
 	switch c.op {
 	case scanPathEndFilter, scanPathEnd:
-		root, err := CompileFilter(string(c.data[start:end]))
+		root, err := compileFilter(string(c.data[start:end]))
 		if err != nil {
 			return nil, err
 		}
@@ -248,6 +259,12 @@ func (ps *pathScanner) stateTryNamespaceStep(root *urns) func(scan *pathScanner,
 			return scanPathEndStep
 		}
 
+		if c == 0 && root.isWord() {
+			// the path consists of just the namespace, targeting the schema extension as a whole
+			scan.step = ps.stateEof
+			return scanPathEndStep
+		}
+
 		return ps.error(c, "invalid character after the initial SCIM attribute name character.")
 	}
 }
@@ -252,3 +252,74 @@ func (s *PathTestSuite) TestPathScanner() {
 		})
 	}
 }
+
+func (s *PathTestSuite) TestPathCompilerEdgeCases() {
+	RegisterURN("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User")
+
+	tokensOf := func(head *Expression) []string {
+		var tokens []string
+		for cur := head; cur != nil; cur = cur.Next() {
+			tokens = append(tokens, cur.Token())
+		}
+		return tokens
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		assert func(t *testing.T, head *Expression, err error)
+	}{
+		{
+			name: "namespace only path targets the extension as a whole",
+			path: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User",
+			assert: func(t *testing.T, head *Expression, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"}, tokensOf(head))
+				assert.True(t, head.IsPath())
+			},
+		},
+		{
+			name: "value path with sub attribute on namespaced path",
+			path: `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:addresses[type eq "work"].streetAddress`,
+			assert: func(t *testing.T, head *Expression, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []string{
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User",
+					"addresses",
+					"eq",
+					"streetAddress",
+				}, tokensOf(head))
+			},
+		},
+		{
+			name: "operators are case insensitive",
+			path: `Emails[Type EQ "work"].Value`,
+			assert: func(t *testing.T, head *Expression, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"Emails", "eq", "Value"}, tokensOf(head))
+			},
+		},
+		{
+			name: "sub attribute presence filter before sub attribute target",
+			path: `addresses[type pr].streetAddress`,
+			assert: func(t *testing.T, head *Expression, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []string{"addresses", "pr", "streetAddress"}, tokensOf(head))
+			},
+		},
+		{
+			name: "garbage after filter fails instead of truncating",
+			path: `emails[type eq "work"] .value`,
+			assert: func(t *testing.T, head *Expression, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			head, err := CompilePath(test.path)
+			test.assert(t, head, err)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package crud
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// PatchOperation is a single operation of a SCIM patch request, as defined in RFC 7644 Section 3.5.2.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyPatch applies the given patch operations to the resource in order, encapsulating the add, replace
+// and remove semantics including the multiValued edge cases. It is usable outside the service stack (i.e.
+// in tests, migrations or client side tooling). The returned changed flag reports whether the value of the
+// resource differs from before; the resource may have been partially modified when a non-nil error is
+// returned.
+func ApplyPatch(resource *prop.Resource, ops []PatchOperation) (changed bool, err error) {
+	before := resource.Hash()
+
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add":
+			value, err := op.ParseValue(resource)
+			if err != nil {
+				return resource.Hash() != before, err
+			}
+			if err := Add(resource, op.Path, value); err != nil {
+				return resource.Hash() != before, err
+			}
+		case "replace":
+			value, err := op.ParseValue(resource)
+			if err != nil {
+				return resource.Hash() != before, err
+			}
+			if err := Replace(resource, op.Path, value); err != nil {
+				return resource.Hash() != before, err
+			}
+		case "remove":
+			if len(op.Value) > 0 {
+				if err := deleteMatchingValues(resource, op.Path, op.Value); err != nil {
+					return resource.Hash() != before, err
+				}
+			} else if err := Delete(resource, op.Path); err != nil {
+				return resource.Hash() != before, err
+			}
+		default:
+			return resource.Hash() != before, fmt.Errorf("%w: invalid patch operation", spec.ErrInvalidSyntax)
+		}
+	}
+
+	return resource.Hash() != before, nil
+}
+
+// deleteMatchingValues implements the remove operation variant where the operation value, instead of a
+// filter in the path, identifies the elements to remove. Some identity providers (i.e. Azure AD) send
+// 	{"op": "remove", "path": "members", "value": [{"value": "<id>"}]}
+// instead of the filtered path 'members[value eq "<id>"]'. The path must resolve to a multiValued
+// property. The value is treated as one criterion, or an array of criteria, against the element
+// attribute: an element is removed when every assigned sub property of a criterion matches the
+// corresponding sub property of the element. Criteria matching no element are silently skipped, in
+// line with the behaviour of filtered paths matching no element.
+func deleteMatchingValues(resource *prop.Resource, path string, value json.RawMessage) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%w: path must be specified for delete operation", spec.ErrInvalidPath)
+	}
+
+	head, err := expr.CompilePath(path)
+	if err != nil {
+		return err
+	}
+
+	var criteria []json.RawMessage
+	if json.Unmarshal(value, &criteria) != nil {
+		criteria = []json.RawMessage{value}
+	}
+
+	return defaultTraverse(resource.RootProperty(), skipMainSchemaNamespace(resource, head), func(nav prop.Navigator) error {
+		if !nav.Current().Attribute().MultiValued() {
+			return fmt.Errorf("%w: remove by value requires path '%s' to target a multiValued property", spec.ErrInvalidPath, path)
+		}
+
+		elemAttr := nav.Current().Attribute().DeriveElementAttribute()
+		matchers := make([]prop.Property, 0, len(criteria))
+		for _, raw := range criteria {
+			matcher := prop.NewProperty(elemAttr)
+			if err := scimjson.DeserializeProperty(raw, matcher, false); err != nil {
+				return err
+			}
+			matchers = append(matchers, matcher)
+		}
+
+		// iterate in reverse so that deletions do not disturb the remaining indexes
+		for i := nav.Current().CountChildren() - 1; i >= 0; i-- {
+			element, err := nav.Current().ChildAtIndex(i)
+			if err != nil {
+				return err
+			}
+
+			matched := false
+			for _, matcher := range matchers {
+				if elementMatchesCriterion(element, matcher) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+
+			if err := nav.At(i).Delete().Error(); err != nil {
+				return err
+			}
+			nav.Retract()
+		}
+
+		return nil
+	})
+}
+
+// elementMatchesCriterion returns true if the element is identified by the criterion. Complex elements
+// are identified when every assigned sub property of the criterion matches the corresponding sub
+// property of the element; other elements are identified when the two properties match as a whole.
+func elementMatchesCriterion(element prop.Property, criterion prop.Property) bool {
+	if criterion.Attribute().Type() != spec.TypeComplex {
+		return criterion.Matches(element)
+	}
+
+	matched := true
+	_ = criterion.ForEachChild(func(_ int, sub prop.Property) error {
+		if sub.IsUnassigned() {
+			return nil
+		}
+		counterpart, err := element.ChildAtIndex(sub.Attribute().Name())
+		if err != nil || counterpart == nil || !sub.Matches(counterpart) {
+			matched = false
+		}
+		return nil
+	})
+	return matched
+}
+
+// ParseValue deserializes the raw JSON value of the operation against the attribute targeted by the
+// operation path, and returns the value in Golang's native type.
+func (o PatchOperation) ParseValue(resource *prop.Resource) (interface{}, error) {
+	var (
+		head *expr.Expression
+		err  error
+	)
+	{
+		if len(o.Path) > 0 {
+			head, err = expr.CompilePath(o.Path)
+			if err != nil {
+				return nil, err
+			}
+			head = skipMainSchemaNamespace(resource, head)
+		}
+	}
+
+	attr := o.targetAttribute(resource.RootAttribute(), head)
+	if attr == nil {
+		return nil, fmt.Errorf("%w: path '%s' is invalid", spec.ErrInvalidPath, o.Path)
+	}
+
+	p := prop.NewProperty(attr)
+	if err := scimjson.DeserializeProperty(o.Value, p, strings.ToLower(o.Op) == "add"); err != nil {
+		return nil, err
+	}
+
+	return p.Raw(), nil
+}
+
+func (o PatchOperation) targetAttribute(parentAttr *spec.Attribute, cursor *expr.Expression) *spec.Attribute {
+	if cursor == nil {
+		return parentAttr
+	}
+
+	if parentAttr == nil {
+		return nil
+	}
+
+	if cursor.IsRootOfFilter() {
+		return o.targetAttribute(parentAttr, cursor.Next())
+	}
+
+	return o.targetAttribute(parentAttr.SubAttributeForName(cursor.Token()), cursor.Next())
+}
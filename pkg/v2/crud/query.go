@@ -3,7 +3,11 @@ package crud
 import (
 	"github.com/imulab/go-scim/pkg/v2/crud/expr"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 	"sort"
+	"strings"
 )
 
 // Order for sorting
@@ -21,6 +25,22 @@ type (
 	Sort struct {
 		By    string
 		Order SortOrder
+		// Then specifies lower priority sort keys, applied in their given order to break ties
+		// among resources considered equal by the primary key.
+		Then []Sort
+		// Collation controls how string sort targets compare. A nil Collation falls back to the
+		// property comparison, which respects the caseExact setting of the attribute. A Then
+		// entry may carry its own Collation; one left nil inherits the top-level Collation.
+		Collation *Collation
+	}
+	// Collation options for comparing string sort targets.
+	Collation struct {
+		// Locale is a BCP 47 language tag enabling locale aware collation, e.g. "sv" or "tr". An
+		// empty locale compares strings by their byte values.
+		Locale string
+		// CaseSensitive forces case sensitive comparison. By default, strings compare case
+		// insensitively, as RFC 7644 prescribes for attributes that are not caseExact.
+		CaseSensitive bool
 	}
 	// Option to include or exclude attributes in the return. At most one can be specified.
 	Projection struct {
@@ -34,7 +54,8 @@ type (
 	}
 )
 
-// Sort the given list of resources according to the sort options.
+// Sort the given list of resources according to the sort options. The primary key is given by By and
+// Order; additional lower priority keys listed in Then are consulted in order to break ties.
 func (s Sort) Sort(resources []*prop.Resource) error {
 	if len(resources) <= 1 {
 		return nil
@@ -42,72 +63,244 @@ func (s Sort) Sort(resources []*prop.Resource) error {
 		return nil
 	}
 
-	head, err := expr.CompilePath(s.By)
-	if err != nil {
-		return err
+	keys := make([]sortKey, 0, len(s.Then)+1)
+	for _, each := range append([]Sort{{By: s.By, Order: s.Order, Collation: s.Collation}}, s.Then...) {
+		if len(each.By) == 0 {
+			continue
+		}
+		head, err := expr.CompilePath(each.By)
+		if err != nil {
+			return err
+		}
+		collation := each.Collation
+		if collation == nil {
+			collation = s.Collation
+		}
+		keys = append(keys, sortKey{by: head, dir: each.Order, compare: collation.comparer()})
 	}
 
 	sort.Sort(&sortWrapper{
 		resources: resources,
-		by:        head,
-		dir:       s.Order,
+		keys:      keys,
 	})
 	return nil
 }
 
-type sortWrapper struct {
-	by        *expr.Expression
-	dir       SortOrder
-	resources []*prop.Resource
+// comparer returns the string comparison function reflecting the collation options, or nil for a
+// nil Collation. Collators are stateful and not safe for concurrent use, so a fresh comparer is
+// built for every sort.
+func (c *Collation) comparer() func(a string, b string) int {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.Locale) > 0 {
+		var opts []collate.Option
+		if !c.CaseSensitive {
+			opts = append(opts, collate.IgnoreCase)
+		}
+		return collate.New(language.Make(c.Locale), opts...).CompareString
+	}
+
+	if c.CaseSensitive {
+		return strings.Compare
+	}
+	return func(a string, b string) int {
+		return strings.Compare(spec.FoldAttributeName(a), spec.FoldAttributeName(b))
+	}
 }
 
-func (s *sortWrapper) Len() int {
-	return len(s.resources)
+// Normalize applies the defaults and bounds of RFC 7644 Section 3.4.2.4 to the pagination options and
+// returns the normalized result. A nil pagination stays nil unless a positive defaultCount is given, in
+// which case the query becomes paginated from index 1 at the default count, so servers can bound
+// otherwise unbounded listings. A non-positive startIndex becomes 1 and a negative count becomes 0; a
+// positive maxCount caps the requested count. An explicit count of 0 is preserved, as it requests the
+// total result count without any resources.
+func (p *Pagination) Normalize(defaultCount int, maxCount int) *Pagination {
+	if p == nil {
+		if defaultCount <= 0 {
+			return nil
+		}
+		p = &Pagination{StartIndex: 1, Count: defaultCount}
+	}
+	if p.StartIndex < 1 {
+		p.StartIndex = 1
+	}
+	if p.Count < 0 {
+		p.Count = 0
+	}
+	if maxCount > 0 && p.Count > maxCount {
+		p.Count = maxCount
+	}
+	return p
 }
 
-func (s *sortWrapper) Less(i, j int) bool {
+// Normalize canonicalizes the attribute references listed in the projection against the given resource type, so
+// that every layer consuming the projection (i.e. database field pushdown, JSON serialization, patch path handling)
+// resolves the references to the same attributes. References qualified with the main schema namespace are stripped
+// of it (i.e. "urn:ietf:params:scim:schemas:core:2.0:User:userName" becomes "userName"), matching the treatment of
+// compiled paths; unqualified references to a top level attribute of a schema extension gain the extension namespace
+// (i.e. "employeeNumber" becomes "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber"),
+// matching the form under which extension attributes are addressed internally. References that are already in
+// canonical form, or that resolve to no attribute of the resource type, are returned untouched. The method is
+// idempotent and safe to call on a nil projection.
+func (p *Projection) Normalize(resourceType *spec.ResourceType) {
+	if p == nil || resourceType == nil {
+		return
+	}
+	for i, ref := range p.Attributes {
+		p.Attributes[i] = normalizeAttributeRef(resourceType, ref)
+	}
+	for i, ref := range p.ExcludedAttributes {
+		p.ExcludedAttributes[i] = normalizeAttributeRef(resourceType, ref)
+	}
+}
+
+// normalizeAttributeRef returns the canonical form of a single attribute reference with respect to the resource
+// type, according to the rules documented on Projection.Normalize.
+func normalizeAttributeRef(resourceType *spec.ResourceType, ref string) string {
+	if len(ref) == 0 {
+		return ref
+	}
+
+	mainPrefix := resourceType.Schema().ID() + ":"
+	if len(ref) > len(mainPrefix) && spec.SameAttributeName(ref[:len(mainPrefix)], mainPrefix) {
+		return ref[len(mainPrefix):]
+	}
+
+	qualified := false
+	_ = resourceType.ForEachExtension(func(extension *spec.Schema, _ bool) error {
+		extPrefix := extension.ID() + ":"
+		if spec.SameAttributeName(ref, extension.ID()) ||
+			(len(ref) > len(extPrefix) && spec.SameAttributeName(ref[:len(extPrefix)], extPrefix)) {
+			qualified = true
+		}
+		return nil
+	})
+	if qualified {
+		return ref
+	}
+
+	head := ref
+	if i := strings.IndexByte(ref, '.'); i >= 0 {
+		head = ref[:i]
+	}
+	if schemaHasTopLevelAttribute(resourceType.Schema(), head) {
+		return ref
+	}
+	if core, ok := spec.Schemas().Get(spec.CoreSchemaId); ok && schemaHasTopLevelAttribute(core, head) {
+		return ref
+	}
+
+	normalized := ref
+	_ = resourceType.ForEachExtension(func(extension *spec.Schema, _ bool) error {
+		if normalized == ref && schemaHasTopLevelAttribute(extension, head) {
+			normalized = extension.ID() + ":" + ref
+		}
+		return nil
+	})
+	return normalized
+}
+
+// schemaHasTopLevelAttribute returns true when the schema declares a top level attribute going by the given
+// name, compared case insensitively.
+func schemaHasTopLevelAttribute(schema *spec.Schema, name string) bool {
+	found := false
+	_ = schema.ForEachAttribute(func(attr *spec.Attribute) error {
+		if spec.SameAttributeName(attr.Name(), name) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+type sortKey struct {
+	by      *expr.Expression
+	dir     SortOrder
+	compare func(a string, b string) int
+}
+
+// less compares the sort targets of the two resources under this key. The second return value reports
+// whether the two resources are considered equal under this key, in which case the caller should consult
+// the next key.
+func (k sortKey) less(left *prop.Resource, right *prop.Resource) (less bool, tie bool) {
 	var (
 		a   prop.Property
 		b   prop.Property
 		err error
 	)
 
-	a, err = SeekSortTarget(s.resources[i], s.by)
-	if err != nil {
-		switch s.dir {
-		case SortDefault, SortAsc:
-			return false
-		case SortDesc:
-			return true
-		default:
-			panic("invalid sortOrder")
-		}
+	a, err = SeekSortTarget(left, skipMainSchemaNamespace(left, k.by))
+	aErr := err
+	b, err = SeekSortTarget(right, skipMainSchemaNamespace(right, k.by))
+	bErr := err
+
+	switch {
+	case aErr != nil && bErr != nil:
+		return false, true
+	case aErr != nil:
+		return k.dir == SortDesc, false
+	case bErr != nil:
+		return k.dir != SortDesc, false
 	}
 
-	b, err = SeekSortTarget(s.resources[j], s.by)
-	if err != nil {
-		switch s.dir {
-		case SortDefault, SortAsc:
-			return true
-		case SortDesc:
-			return false
-		default:
-			panic("invalid sortOrder")
+	if k.compare != nil {
+		if av, aok := a.Raw().(string); aok {
+			if bv, bok := b.Raw().(string); bok {
+				switch c := k.compare(av, bv); {
+				case c == 0:
+					return false, true
+				case k.dir == SortDesc:
+					return c > 0, false
+				default:
+					return c < 0, false
+				}
+			}
 		}
 	}
 
-	if ltCapable, ok := a.(prop.LtCapable); !ok {
-		return false
-	} else {
-		switch s.dir {
-		case SortDefault, SortAsc:
-			return ltCapable.LessThan(b.Raw())
-		case SortDesc:
-			return !ltCapable.LessThan(b.Raw())
-		default:
-			panic("invalid sortOrder")
+	ltCapable, ok := a.(prop.LtCapable)
+	if !ok {
+		return false, true
+	}
+
+	aLtB := ltCapable.LessThan(b.Raw())
+	bLtA := false
+	if rightLtCapable, ok := b.(prop.LtCapable); ok {
+		bLtA = rightLtCapable.LessThan(a.Raw())
+	}
+
+	if !aLtB && !bLtA {
+		return false, true
+	}
+
+	switch k.dir {
+	case SortDefault, SortAsc:
+		return aLtB, false
+	case SortDesc:
+		return bLtA, false
+	default:
+		panic("invalid sortOrder")
+	}
+}
+
+type sortWrapper struct {
+	keys      []sortKey
+	resources []*prop.Resource
+}
+
+func (s *sortWrapper) Len() int {
+	return len(s.resources)
+}
+
+func (s *sortWrapper) Less(i, j int) bool {
+	for _, key := range s.keys {
+		if less, tie := key.less(s.resources[i], s.resources[j]); !tie {
+			return less
 		}
 	}
+	return false
 }
 
 func (s *sortWrapper) Swap(i, j int) {
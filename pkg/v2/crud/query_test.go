@@ -0,0 +1,315 @@
+package crud
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSortCollation(t *testing.T) {
+	s := new(SortCollationTestSuite)
+	suite.Run(t, s)
+}
+
+type SortCollationTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *SortCollationTestSuite) TestSortWithCollation() {
+	tests := []struct {
+		name      string
+		userNames []string
+		sort      Sort
+		expect    []string
+	}{
+		{
+			name:      "default collation compares case insensitively",
+			userNames: []string{"Banana", "apple"},
+			sort: Sort{
+				By:        "userName",
+				Collation: &Collation{},
+			},
+			expect: []string{"apple", "Banana"},
+		},
+		{
+			name:      "case sensitive collation compares by byte values",
+			userNames: []string{"apple", "Banana"},
+			sort: Sort{
+				By:        "userName",
+				Collation: &Collation{CaseSensitive: true},
+			},
+			expect: []string{"Banana", "apple"},
+		},
+		{
+			name:      "locale collation treats accented letters by locale rules",
+			userNames: []string{"zebra", "öl"},
+			sort: Sort{
+				By:        "userName",
+				Collation: &Collation{Locale: "de"},
+			},
+			expect: []string{"öl", "zebra"},
+		},
+		{
+			name:      "no collation falls back to property comparison",
+			userNames: []string{"öl", "zebra"},
+			sort: Sort{
+				By: "userName",
+			},
+			expect: []string{"zebra", "öl"},
+		},
+		{
+			name:      "descending order is respected",
+			userNames: []string{"apple", "Banana"},
+			sort: Sort{
+				By:        "userName",
+				Order:     SortDesc,
+				Collation: &Collation{},
+			},
+			expect: []string{"Banana", "apple"},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resources := make([]*prop.Resource, 0, len(test.userNames))
+			for _, userName := range test.userNames {
+				r := prop.NewResource(s.resourceType)
+				require.False(t, r.Navigator().Dot("userName").Replace(userName).HasError())
+				resources = append(resources, r)
+			}
+
+			require.Nil(t, test.sort.Sort(resources))
+
+			sorted := make([]string, 0, len(resources))
+			for _, r := range resources {
+				sorted = append(sorted, r.Navigator().Dot("userName").Current().Raw().(string))
+			}
+			assert.Equal(t, test.expect, sorted)
+		})
+	}
+}
+
+func (s *SortCollationTestSuite) TestThenKeyUsesItsOwnCollation() {
+	resources := make([]*prop.Resource, 0, 2)
+	for _, userName := range []string{"apple", "Banana"} {
+		r := prop.NewResource(s.resourceType)
+		require.False(s.T(), r.Navigator().Dot("title").Replace("worker").HasError())
+		require.False(s.T(), r.Navigator().Dot("userName").Replace(userName).HasError())
+		resources = append(resources, r)
+	}
+
+	// the primary key ties on every resource; the tie is broken by the secondary key under its
+	// own case sensitive collation, not the case insensitive top-level one
+	sort := Sort{
+		By:        "title",
+		Collation: &Collation{},
+		Then: []Sort{
+			{By: "userName", Collation: &Collation{CaseSensitive: true}},
+		},
+	}
+	require.Nil(s.T(), sort.Sort(resources))
+
+	sorted := make([]string, 0, len(resources))
+	for _, r := range resources {
+		sorted = append(sorted, r.Navigator().Dot("userName").Current().Raw().(string))
+	}
+	assert.Equal(s.T(), []string{"Banana", "apple"}, sorted)
+}
+
+func (s *SortCollationTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
+
+func TestProjectionNormalize(t *testing.T) {
+	s := new(ProjectionNormalizeTestSuite)
+	suite.Run(t, s)
+}
+
+type ProjectionNormalizeTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ProjectionNormalizeTestSuite) TestNormalize() {
+	tests := []struct {
+		name   string
+		ref    string
+		expect string
+	}{
+		{
+			name:   "main schema attribute stays unqualified",
+			ref:    "userName",
+			expect: "userName",
+		},
+		{
+			name:   "main schema namespace is stripped",
+			ref:    "urn:ietf:params:scim:schemas:core:2.0:User:userName",
+			expect: "userName",
+		},
+		{
+			name:   "core attribute stays unqualified",
+			ref:    "id",
+			expect: "id",
+		},
+		{
+			name:   "unqualified extension attribute gains the extension namespace",
+			ref:    "employeeNumber",
+			expect: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber",
+		},
+		{
+			name:   "unqualified extension sub attribute path gains the extension namespace",
+			ref:    "manager.displayName",
+			expect: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.displayName",
+		},
+		{
+			name:   "qualified extension attribute is left untouched",
+			ref:    "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber",
+			expect: "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber",
+		},
+		{
+			name:   "unresolvable reference is left untouched",
+			ref:    "foobar",
+			expect: "foobar",
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			included := &Projection{Attributes: []string{test.ref}}
+			included.Normalize(s.resourceType)
+			assert.Equal(t, test.expect, included.Attributes[0])
+
+			excluded := &Projection{ExcludedAttributes: []string{test.ref}}
+			excluded.Normalize(s.resourceType)
+			assert.Equal(t, test.expect, excluded.ExcludedAttributes[0])
+		})
+	}
+}
+
+func (s *ProjectionNormalizeTestSuite) TestNormalizeNil() {
+	assert.NotPanics(s.T(), func() {
+		var p *Projection
+		p.Normalize(s.resourceType)
+	})
+}
+
+func (s *ProjectionNormalizeTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
+
+func TestPaginationNormalize(t *testing.T) {
+	// nil pagination stays nil without a default count
+	assert.Nil(t, (*Pagination)(nil).Normalize(0, 10))
+
+	// nil pagination adopts the default count
+	assert.Equal(t, &Pagination{StartIndex: 1, Count: 5}, (*Pagination)(nil).Normalize(5, 10))
+
+	// out of bounds values are corrected in place
+	assert.Equal(t, &Pagination{StartIndex: 1, Count: 0}, (&Pagination{StartIndex: -3, Count: -1}).Normalize(5, 10))
+
+	// requested count is capped at the maximum
+	assert.Equal(t, &Pagination{StartIndex: 2, Count: 10}, (&Pagination{StartIndex: 2, Count: 50}).Normalize(5, 10))
+
+	// explicit count of 0 is preserved
+	assert.Equal(t, &Pagination{StartIndex: 1, Count: 0}, (&Pagination{StartIndex: 1, Count: 0}).Normalize(5, 10))
+}
@@ -6,7 +6,10 @@ import (
 )
 
 // Register calls expr.RegisterURN for the main schema ids and all schema extension ids in the resource type.
+// It also relates the resource type with its main schema id in spec.ResourceTypes, so that decoders can
+// resolve a resource back to its resource type from the schemas attribute.
 func Register(resourceType *spec.ResourceType) {
+	spec.ResourceTypes().Register(resourceType)
 	expr.RegisterURN(resourceType.Schema().ID())
 	_ = resourceType.ForEachExtension(func(extension *spec.Schema, required bool) error {
 		expr.RegisterURN(extension.ID())
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"sync"
+	"time"
+)
+
+// Cached returns a DB implementation that decorates the delegate DB with an in-process, write-through
+// cache of resources by id, where every cached resource lives for the given ttl. Get is answered from
+// the cache when a live entry exists; otherwise the resource is fetched from the delegate and cached.
+// Concurrent cache misses for the same id are collapsed into a single delegate fetch. Insert, Replace
+// and Delete are passed through to the delegate and keep the cache coherent: successful writes refresh
+// or remove the cached entry, while failed writes drop it, since the delegate state is then unknown.
+// Count and Query always consult the delegate. The cache is local to the process; deployments with
+// multiple replicas writing to the same database should keep the ttl short enough to tolerate the
+// resulting staleness window.
+func Cached(delegate DB, ttl time.Duration) DB {
+	return CachedWithTTL(delegate, func(_ *prop.Resource) time.Duration {
+		return ttl
+	})
+}
+
+// CachedWithTTL returns a DB implementation like Cached, except that the time to live is decided per
+// resource by the given function. Resources for which the function returns a non-positive duration
+// are not cached at all. Use this to retain hot or expensive resources longer than the rest.
+func CachedWithTTL(delegate DB, ttl func(resource *prop.Resource) time.Duration) DB {
+	return &cachedDB{
+		delegate: delegate,
+		ttl:      ttl,
+		entries:  map[string]*cacheEntry{},
+		calls:    map[string]*cacheCall{},
+	}
+}
+
+type cachedDB struct {
+	delegate DB
+	ttl      func(resource *prop.Resource) time.Duration
+	lock     sync.Mutex
+	entries  map[string]*cacheEntry // live cached resources by id
+	calls    map[string]*cacheCall  // in-flight delegate fetches by id
+}
+
+// cacheEntry is a frozen copy of a resource together with its expiry deadline. The enclosed resource
+// is only ever read through Clone, hence may be shared between the entry and waiting callers.
+type cacheEntry struct {
+	resource *prop.Resource
+	expires  time.Time
+}
+
+// cacheCall tracks an in-flight delegate fetch so that concurrent misses for the same id wait for
+// its result instead of issuing duplicate fetches.
+type cacheCall struct {
+	wg       sync.WaitGroup
+	resource *prop.Resource
+	err      error
+}
+
+func (c *cachedDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	err := c.delegate.Insert(ctx, resource)
+	c.refresh(resource.IdOrEmpty(), resource, err)
+	return err
+}
+
+func (c *cachedDB) Count(ctx context.Context, filter string) (int, error) {
+	return c.delegate.Count(ctx, filter)
+}
+
+func (c *cachedDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	c.lock.Lock()
+
+	if entry, ok := c.entries[id]; ok && time.Now().Before(entry.expires) {
+		resource := entry.resource.Clone()
+		c.lock.Unlock()
+		return resource, nil
+	}
+
+	if call, ok := c.calls[id]; ok {
+		c.lock.Unlock()
+		call.wg.Wait()
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.resource.Clone(), nil
+	}
+
+	call := new(cacheCall)
+	call.wg.Add(1)
+	c.calls[id] = call
+	c.lock.Unlock()
+
+	// the cache always fetches the full resource so that the cached copy can answer any projection;
+	// per the DB contract, implementations may elect to ignore the projection parameter.
+	resource, err := c.delegate.Get(ctx, id, nil)
+
+	c.lock.Lock()
+	delete(c.calls, id)
+	if err == nil {
+		call.resource = resource.Clone()
+		c.store(id, call.resource)
+	}
+	call.err = err
+	c.lock.Unlock()
+	call.wg.Done()
+
+	return resource, err
+}
+
+func (c *cachedDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) error {
+	err := c.delegate.Replace(ctx, ref, replacement)
+	c.refresh(replacement.IdOrEmpty(), replacement, err)
+	return err
+}
+
+func (c *cachedDB) Delete(ctx context.Context, resource *prop.Resource) error {
+	err := c.delegate.Delete(ctx, resource)
+
+	c.lock.Lock()
+	delete(c.entries, resource.IdOrEmpty())
+	c.lock.Unlock()
+
+	return err
+}
+
+func (c *cachedDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error) {
+	return c.delegate.Query(ctx, filter, sort, pagination, projection)
+}
+
+// refresh writes the outcome of a mutating delegate call through to the cache: a successful write
+// caches a copy of the written resource, while a failed write drops the entry, since the delegate
+// state can no longer be trusted.
+func (c *cachedDB) refresh(id string, resource *prop.Resource, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err != nil {
+		delete(c.entries, id)
+		return
+	}
+	c.store(id, resource.Clone())
+}
+
+// store caches the resource under id, or removes the entry when the resource's ttl is non-positive.
+// The caller must hold the lock and must hand over a copy that is not mutated afterwards.
+func (c *cachedDB) store(id string, resource *prop.Resource) {
+	ttl := c.ttl(resource)
+	if ttl <= 0 {
+		delete(c.entries, id)
+		return
+	}
+	c.entries[id] = &cacheEntry{resource: resource, expires: time.Now().Add(ttl)}
+}
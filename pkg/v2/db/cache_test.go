@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCachedDB(t *testing.T) {
+	s := new(CachedDBTestSuite)
+	suite.Run(t, s)
+}
+
+type CachedDBTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+// countingDB decorates a DB and counts delegate Get calls, optionally delaying them to widen the
+// window for concurrent misses.
+type countingDB struct {
+	DB
+	gets  int64
+	delay time.Duration
+}
+
+func (c *countingDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	atomic.AddInt64(&c.gets, 1)
+	if c.delay > 0 {
+		time.Sleep(c.delay)
+	}
+	return c.DB.Get(ctx, id, projection)
+}
+
+func (s *CachedDBTestSuite) TestGetIsCached() {
+	delegate := &countingDB{DB: Memory()}
+	database := Cached(delegate, time.Minute)
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), "group001")))
+
+	for i := 0; i < 3; i++ {
+		r, err := database.Get(context.TODO(), "group001", nil)
+		assert.Nil(s.T(), err)
+		assert.Equal(s.T(), "group001", r.IdOrEmpty())
+	}
+	assert.Equal(s.T(), int64(0), atomic.LoadInt64(&delegate.gets), "insert should have primed the cache")
+}
+
+func (s *CachedDBTestSuite) TestExpiredEntryIsRefetched() {
+	delegate := &countingDB{DB: Memory()}
+	database := Cached(delegate, time.Millisecond)
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), "group001")))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := database.Get(context.TODO(), "group001", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), int64(1), atomic.LoadInt64(&delegate.gets))
+}
+
+func (s *CachedDBTestSuite) TestWritesKeepCacheCoherent() {
+	database := Cached(&countingDB{DB: Memory()}, time.Minute)
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), "group001")))
+
+	ref, err := database.Get(context.TODO(), "group001", nil)
+	require.Nil(s.T(), err)
+
+	replacement := s.resourceOf(s.T(), "group001")
+	require.False(s.T(), replacement.Navigator().Dot("displayName").Replace("Renamed").HasError())
+	require.Nil(s.T(), database.Replace(context.TODO(), ref, replacement))
+
+	r, err := database.Get(context.TODO(), "group001", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "Renamed", r.Navigator().Dot("displayName").Current().Raw())
+
+	require.Nil(s.T(), database.Delete(context.TODO(), r))
+	_, err = database.Get(context.TODO(), "group001", nil)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), spec.ErrNotFound, errors.Unwrap(err))
+}
+
+func (s *CachedDBTestSuite) TestConcurrentMissesCollapseToOneFetch() {
+	delegate := &countingDB{DB: Memory(), delay: 10 * time.Millisecond}
+	require.Nil(s.T(), delegate.DB.Insert(context.TODO(), s.resourceOf(s.T(), "group001")))
+	database := Cached(delegate, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := database.Get(context.TODO(), "group001", nil)
+			assert.Nil(s.T(), err)
+			assert.Equal(s.T(), "group001", r.IdOrEmpty())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), int64(1), atomic.LoadInt64(&delegate.gets))
+}
+
+func (s *CachedDBTestSuite) TestReturnedResourceIsIsolatedFromCache() {
+	database := Cached(&countingDB{DB: Memory()}, time.Minute)
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), "group001")))
+
+	first, err := database.Get(context.TODO(), "group001", nil)
+	require.Nil(s.T(), err)
+	require.False(s.T(), first.Navigator().Dot("displayName").Replace("Mutated").HasError())
+
+	second, err := database.Get(context.TODO(), "group001", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "Test Group", second.Navigator().Dot("displayName").Current().Raw())
+}
+
+func (s *CachedDBTestSuite) resourceOf(t *testing.T, id string) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(map[string]interface{}{
+		"id":          id,
+		"displayName": "Test Group",
+	}).Error())
+	return r
+}
+
+func (s *CachedDBTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
@@ -0,0 +1,155 @@
+// Package conformance hosts a conformance suite that every db.DB implementation is expected to pass.
+// The suite pins down the semantics of the SCIM filter operator set — including 'not' negation,
+// nested grouping, 'pr' on complex and multiValued properties, and the rejection of string matching
+// operators on non-string targets — as well as the sort, pagination and projection behaviour of
+// Query, so that switching between backends (i.e. the in-memory database and the MongoDB
+// implementation) does not change query behaviour. Run is the single entry point executing the
+// complete suite; the individual RunXxxConformance functions execute one scenario group each.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// seed resources for the query conformance suite. The suite relies on the standard User resource type.
+var querySeeds = []string{
+	`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "alpha001",
+  "userName": "alpha",
+  "active": true,
+  "name": {"givenName": "Alice"},
+  "emails": [{"value": "alpha@foo.com", "type": "work", "primary": true}]
+}
+`,
+	`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "beta002",
+  "userName": "beta",
+  "active": false,
+  "emails": [{"value": "beta@bar.com", "type": "home"}]
+}
+`,
+	`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "gamma003",
+  "userName": "gamma"
+}
+`,
+}
+
+// RunQueryConformance seeds the given empty database with a fixed set of user resources and asserts
+// that its Query implementation answers a canonical set of filters with the agreed upon semantics.
+// resourceType must be the standard User resource type, and the database must start out empty.
+func RunQueryConformance(t *testing.T, resourceType *spec.ResourceType, database db.DB) {
+	seedDatabase(t, resourceType, database)
+
+	tests := []struct {
+		name      string
+		filter    string
+		expectIds []string
+		expectErr error
+	}{
+		{
+			name:      "eq on string",
+			filter:    `userName eq "alpha"`,
+			expectIds: []string{"alpha001"},
+		},
+		{
+			name:      "ne on string",
+			filter:    `userName ne "alpha"`,
+			expectIds: []string{"beta002", "gamma003"},
+		},
+		{
+			name:      "eq on boolean",
+			filter:    `active eq true`,
+			expectIds: []string{"alpha001"},
+		},
+		{
+			name:      "not negation",
+			filter:    `not (userName eq "alpha")`,
+			expectIds: []string{"beta002", "gamma003"},
+		},
+		{
+			name:      "or grouping",
+			filter:    `(userName sw "a") or (userName sw "b")`,
+			expectIds: []string{"alpha001", "beta002"},
+		},
+		{
+			name:      "nested grouping with not",
+			filter:    `((userName sw "a") or (userName sw "b")) and not (active eq false)`,
+			expectIds: []string{"alpha001"},
+		},
+		{
+			name:      "pr on complex",
+			filter:    `name pr`,
+			expectIds: []string{"alpha001"},
+		},
+		{
+			name:      "pr on multiValued",
+			filter:    `emails pr`,
+			expectIds: []string{"alpha001", "beta002"},
+		},
+		{
+			name:      "co on nested string",
+			filter:    `emails.value co "foo"`,
+			expectIds: []string{"alpha001"},
+		},
+		{
+			name:      "ew on string",
+			filter:    `userName ew "ta"`,
+			expectIds: []string{"beta002"},
+		},
+		{
+			name:      "gt on string",
+			filter:    `userName gt "beta"`,
+			expectIds: []string{"gamma003"},
+		},
+		{
+			name:      "co on boolean is rejected",
+			filter:    `active co "ru"`,
+			expectErr: spec.ErrInvalidFilter,
+		},
+		{
+			name:      "sw on boolean is rejected",
+			filter:    `active sw "t"`,
+			expectErr: spec.ErrInvalidFilter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := database.Query(context.Background(), test.filter, nil, nil, nil)
+			if test.expectErr != nil {
+				if !errors.Is(err, test.expectErr) {
+					t.Fatalf("filter '%s': expected error '%v', got '%v'", test.filter, test.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filter '%s': unexpected error: %v", test.filter, err)
+			}
+
+			ids := make(map[string]bool)
+			for _, r := range results {
+				ids[r.IdOrEmpty()] = true
+			}
+			if len(ids) != len(test.expectIds) {
+				t.Fatalf("filter '%s': expected ids %v, got %v", test.filter, test.expectIds, ids)
+			}
+			for _, id := range test.expectIds {
+				if !ids[id] {
+					t.Fatalf("filter '%s': expected ids %v, got %v", test.filter, test.expectIds, ids)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Factory produces the standard User resource type along with a fresh, empty database under test.
+// It is invoked once per scenario group, so that groups do not observe each other's data. An
+// implementation typically registers the standard schemas once and returns a new database instance
+// on every call.
+type Factory func() (resourceType *spec.ResourceType, database db.DB)
+
+// Run executes the complete database conformance suite against databases produced by the factory.
+// It verifies the filter, sort, pagination and projection semantics that every db.DB implementation
+// is expected to share, so that third party implementations can assert compatibility with the
+// built-in databases without copying their test scenarios.
+func Run(t *testing.T, factory Factory) {
+	for _, group := range []struct {
+		name string
+		run  func(t *testing.T, resourceType *spec.ResourceType, database db.DB)
+	}{
+		{name: "query", run: RunQueryConformance},
+		{name: "sort", run: RunSortConformance},
+		{name: "pagination", run: RunPaginationConformance},
+		{name: "projection", run: RunProjectionConformance},
+	} {
+		t.Run(group.name, func(t *testing.T) {
+			resourceType, database := factory()
+			group.run(t, resourceType, database)
+		})
+	}
+}
+
+// RunSortConformance seeds the given empty database and asserts that its Query implementation
+// returns results in the agreed upon order for a canonical set of sort options.
+func RunSortConformance(t *testing.T, resourceType *spec.ResourceType, database db.DB) {
+	seedDatabase(t, resourceType, database)
+
+	tests := []struct {
+		name      string
+		sort      *crud.Sort
+		expectIds []string
+	}{
+		{
+			name:      "ascending",
+			sort:      &crud.Sort{By: "userName", Order: crud.SortAsc},
+			expectIds: []string{"alpha001", "beta002", "gamma003"},
+		},
+		{
+			name:      "descending",
+			sort:      &crud.Sort{By: "userName", Order: crud.SortDesc},
+			expectIds: []string{"gamma003", "beta002", "alpha001"},
+		},
+		{
+			name:      "default order is ascending",
+			sort:      &crud.Sort{By: "userName"},
+			expectIds: []string{"alpha001", "beta002", "gamma003"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := database.Query(context.Background(), "id pr", test.sort, nil, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertIdsInOrder(t, results, test.expectIds)
+		})
+	}
+}
+
+// RunPaginationConformance seeds the given empty database and asserts that its Query implementation
+// honours the 1-based startIndex and count pagination parameters.
+func RunPaginationConformance(t *testing.T, resourceType *spec.ResourceType, database db.DB) {
+	seedDatabase(t, resourceType, database)
+
+	sort := &crud.Sort{By: "userName", Order: crud.SortAsc}
+	tests := []struct {
+		name       string
+		pagination *crud.Pagination
+		expectIds  []string
+	}{
+		{
+			name:       "first page",
+			pagination: &crud.Pagination{StartIndex: 1, Count: 2},
+			expectIds:  []string{"alpha001", "beta002"},
+		},
+		{
+			name:       "last page is truncated",
+			pagination: &crud.Pagination{StartIndex: 3, Count: 5},
+			expectIds:  []string{"gamma003"},
+		},
+		{
+			name:       "middle page",
+			pagination: &crud.Pagination{StartIndex: 2, Count: 1},
+			expectIds:  []string{"beta002"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := database.Query(context.Background(), "id pr", sort, test.pagination, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertIdsInOrder(t, results, test.expectIds)
+		})
+	}
+}
+
+// RunProjectionConformance seeds the given empty database and asserts the minimal projection
+// contract shared by all implementations: a projection never errs the query, never drops the
+// explicitly included attributes, never drops attributes whose returned-ability is "always", and an
+// exclusion never affects attributes other than the excluded ones. Implementations are free to
+// return more than what was asked for (i.e. the in-memory database ignores projection altogether
+// and relies on the serializer post-guard), hence the suite does not assert absence.
+func RunProjectionConformance(t *testing.T, resourceType *spec.ResourceType, database db.DB) {
+	seedDatabase(t, resourceType, database)
+
+	tests := []struct {
+		name       string
+		projection *crud.Projection
+	}{
+		{
+			name:       "included attributes are returned",
+			projection: &crud.Projection{Attributes: []string{"userName"}},
+		},
+		{
+			name:       "exclusion leaves other attributes intact",
+			projection: &crud.Projection{ExcludedAttributes: []string{"emails"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := database.Query(context.Background(), "id pr", nil, nil, test.projection)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(querySeeds) {
+				t.Fatalf("expected %d resources, got %d", len(querySeeds), len(results))
+			}
+			for _, r := range results {
+				if len(r.IdOrEmpty()) == 0 {
+					t.Fatalf("projection dropped the 'id' attribute whose returned-ability is 'always'")
+				}
+				nav := r.Navigator().Dot("userName")
+				if nav.HasError() || nav.Current().IsUnassigned() {
+					t.Fatalf("projection dropped the 'userName' attribute of resource '%s'", r.IdOrEmpty())
+				}
+			}
+
+			got, err := database.Get(context.Background(), "alpha001", test.projection)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			nav := got.Navigator().Dot("userName")
+			if nav.HasError() || nav.Current().IsUnassigned() {
+				t.Fatalf("projection dropped the 'userName' attribute on Get")
+			}
+		})
+	}
+}
+
+// seedDatabase inserts the canonical seed resources into the database.
+func seedDatabase(t *testing.T, resourceType *spec.ResourceType, database db.DB) {
+	for _, seed := range querySeeds {
+		r := prop.NewResource(resourceType)
+		if err := scimjson.Deserialize([]byte(seed), r); err != nil {
+			t.Fatalf("failed to parse seed resource: %v", err)
+		}
+		if err := database.Insert(context.Background(), r); err != nil {
+			t.Fatalf("failed to seed database: %v", err)
+		}
+	}
+}
+
+// assertIdsInOrder fails the test unless the resources carry exactly the expected ids in order.
+func assertIdsInOrder(t *testing.T, results []*prop.Resource, expectIds []string) {
+	if len(results) != len(expectIds) {
+		t.Fatalf("expected ids %v, got %d resources", expectIds, len(results))
+	}
+	for i, r := range results {
+		if r.IdOrEmpty() != expectIds[i] {
+			t.Fatalf("expected id '%s' at position %d, got '%s'", expectIds[i], i, r.IdOrEmpty())
+		}
+	}
+}
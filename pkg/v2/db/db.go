@@ -26,3 +26,12 @@ type DB interface {
 	// additional processing.
 	Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error)
 }
+
+// QueryPlanner is an optional interface a DB implementation may implement to describe the strategy
+// it would use to execute a query with the given filter. The description is meant for human
+// operators debugging slow queries and carries no structural guarantee.
+type QueryPlanner interface {
+	// QueryPlan returns a human readable description of how a query with the given filter would be
+	// executed.
+	QueryPlan(ctx context.Context, filter string) (string, error)
+}
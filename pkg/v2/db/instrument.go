@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/metrics"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"time"
+)
+
+// Instrumented returns a DB implementation that records the latency and outcome of each call of the
+// delegate DB through the metrics package. Latencies are observed in seconds under the name
+// "scim_db_op_seconds", tagged with the operation name and whether it returned an error.
+func Instrumented(delegate DB) DB {
+	return &instrumentedDB{delegate: delegate}
+}
+
+type instrumentedDB struct {
+	delegate DB
+}
+
+func (i *instrumentedDB) observe(op string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	metrics.Observe("scim_db_op_seconds", time.Since(start).Seconds(), map[string]string{
+		"op":      op,
+		"outcome": outcome,
+	})
+}
+
+func (i *instrumentedDB) Insert(ctx context.Context, resource *prop.Resource) (err error) {
+	start := time.Now()
+	defer func() { i.observe("insert", start, err) }()
+
+	err = i.delegate.Insert(ctx, resource)
+	return
+}
+
+func (i *instrumentedDB) Count(ctx context.Context, filter string) (n int, err error) {
+	start := time.Now()
+	defer func() { i.observe("count", start, err) }()
+
+	n, err = i.delegate.Count(ctx, filter)
+	return
+}
+
+func (i *instrumentedDB) Get(ctx context.Context, id string, projection *crud.Projection) (resource *prop.Resource, err error) {
+	start := time.Now()
+	defer func() { i.observe("get", start, err) }()
+
+	resource, err = i.delegate.Get(ctx, id, projection)
+	return
+}
+
+func (i *instrumentedDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) (err error) {
+	start := time.Now()
+	defer func() { i.observe("replace", start, err) }()
+
+	err = i.delegate.Replace(ctx, ref, replacement)
+	return
+}
+
+func (i *instrumentedDB) Delete(ctx context.Context, resource *prop.Resource) (err error) {
+	start := time.Now()
+	defer func() { i.observe("delete", start, err) }()
+
+	err = i.delegate.Delete(ctx, resource)
+	return
+}
+
+func (i *instrumentedDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) (resources []*prop.Resource, err error) {
+	start := time.Now()
+	defer func() { i.observe("query", start, err) }()
+
+	resources, err = i.delegate.Query(ctx, filter, sort, pagination, projection)
+	return
+}
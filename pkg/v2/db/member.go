@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// MemberUpdater is an optional interface a DB implementation may implement to add or remove a
+// single member of a stored resource without reading and writing back the whole resource. It exists
+// to serve the dominant Group update pattern, where one member is added to or removed from a group
+// with a very large membership, and a full read-modify-write cycle would be wasteful.
+//
+// Both operations are idempotent: adding a member that already exists and removing a member that
+// does not exist are no-ops. Implementations must advance meta.lastModified and meta.version along
+// with the modification, mirroring what the meta filter does on the regular service path.
+type MemberUpdater interface {
+	// AddMember adds the member, expressed as a map of member sub attribute names to values, to the
+	// resource with the given id.
+	AddMember(ctx context.Context, id string, member map[string]interface{}) error
+	// RemoveMember removes the member whose value sub attribute equals memberValue from the
+	// resource with the given id.
+	RemoveMember(ctx context.Context, id string, memberValue string) error
+}
+
+// AddMember implements MemberUpdater. The member is added to the stored resource directly under the
+// shard lock, without copying the resource.
+func (m *memoryDB) AddMember(_ context.Context, id string, member map[string]interface{}) error {
+	shard := m.shardFor(id)
+	shard.Lock()
+	defer shard.Unlock()
+
+	r, ok := shard.db[id]
+	if !ok {
+		return fmt.Errorf("%w: resource not found by id", spec.ErrNotFound)
+	}
+
+	nav := r.Navigator()
+	if nav.Dot("members").HasError() {
+		return fmt.Errorf("%w: resource has no members attribute", spec.ErrInvalidPath)
+	}
+	if err := nav.Add([]interface{}{member}).Error(); err != nil {
+		return err
+	}
+
+	return refreshMeta(r)
+}
+
+// RemoveMember implements MemberUpdater. The member is removed from the stored resource directly
+// under the shard lock, without copying the resource.
+func (m *memoryDB) RemoveMember(_ context.Context, id string, memberValue string) error {
+	shard := m.shardFor(id)
+	shard.Lock()
+	defer shard.Unlock()
+
+	r, ok := shard.db[id]
+	if !ok {
+		return fmt.Errorf("%w: resource not found by id", spec.ErrNotFound)
+	}
+
+	nav := r.Navigator()
+	if nav.Dot("members").HasError() {
+		return fmt.Errorf("%w: resource has no members attribute", spec.ErrInvalidPath)
+	}
+	nav.Where(func(child prop.Property) bool {
+		value, err := child.ChildAtIndex("value")
+		return err == nil && value.Raw() == memberValue
+	})
+	if nav.HasError() {
+		// removing an absent member is a no-op
+		return nil
+	}
+	if err := nav.Delete().Error(); err != nil {
+		return err
+	}
+
+	return refreshMeta(r)
+}
+
+// refreshMeta advances meta.lastModified and meta.version of the stored resource after a targeted
+// modification, producing values in the same format as the meta filter on the service path.
+func refreshMeta(r *prop.Resource) error {
+	nav := r.Navigator()
+	if nav.Dot("meta").HasError() {
+		return nav.Error()
+	}
+
+	if nav.Dot("lastModified").HasError() {
+		return nav.Error()
+	}
+	if err := nav.Replace(time.Now().Format(spec.ISO8601)).Error(); err != nil {
+		return err
+	}
+	nav.Retract()
+
+	if nav.Dot("version").HasError() {
+		return nav.Error()
+	}
+
+	tsBuf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(tsBuf, rand.Uint64())
+	sha := sha1.New()
+	sha.Write([]byte(r.IdOrEmpty()))
+	sha.Write(tsBuf)
+
+	return nav.Replace(fmt.Sprintf("W/\"%x\"", sha.Sum(nil))).Error()
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMemoryMemberUpdater(t *testing.T) {
+	s := new(MemoryMemberUpdaterTestSuite)
+	suite.Run(t, s)
+}
+
+type MemoryMemberUpdaterTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *MemoryMemberUpdaterTestSuite) TestAddAndRemoveMember() {
+	database := Memory()
+	updater, ok := database.(MemberUpdater)
+	require.True(s.T(), ok)
+
+	group := prop.NewResource(s.resourceType)
+	require.Nil(s.T(), group.Navigator().Replace(map[string]interface{}{
+		"id":          "group001",
+		"displayName": "Test Group",
+		"members": []interface{}{
+			map[string]interface{}{"value": "user001"},
+		},
+	}).Error())
+	require.Nil(s.T(), database.Insert(context.TODO(), group))
+	oldVersion := s.version(database, "group001")
+
+	err := updater.AddMember(context.TODO(), "group001", map[string]interface{}{"value": "user002"})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, s.memberCount(database, "group001"))
+	newVersion := s.version(database, "group001")
+	assert.NotEqual(s.T(), oldVersion, newVersion)
+
+	err = updater.RemoveMember(context.TODO(), "group001", "user001")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, s.memberCount(database, "group001"))
+	assert.NotEqual(s.T(), newVersion, s.version(database, "group001"))
+}
+
+func (s *MemoryMemberUpdaterTestSuite) TestRemoveAbsentMemberIsNoOp() {
+	database := Memory()
+	updater := database.(MemberUpdater)
+
+	group := prop.NewResource(s.resourceType)
+	require.Nil(s.T(), group.Navigator().Replace(map[string]interface{}{
+		"id":          "group002",
+		"displayName": "Test Group",
+	}).Error())
+	require.Nil(s.T(), database.Insert(context.TODO(), group))
+
+	err := updater.RemoveMember(context.TODO(), "group002", "user404")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, s.memberCount(database, "group002"))
+}
+
+func (s *MemoryMemberUpdaterTestSuite) TestResourceNotFound() {
+	database := Memory()
+	updater := database.(MemberUpdater)
+
+	err := updater.AddMember(context.TODO(), "not_found", map[string]interface{}{"value": "user001"})
+	assert.True(s.T(), errors.Is(err, spec.ErrNotFound))
+
+	err = updater.RemoveMember(context.TODO(), "not_found", "user001")
+	assert.True(s.T(), errors.Is(err, spec.ErrNotFound))
+}
+
+func (s *MemoryMemberUpdaterTestSuite) memberCount(database DB, id string) int {
+	resource, err := database.Get(context.TODO(), id, nil)
+	require.Nil(s.T(), err)
+	nav := resource.Navigator().Dot("members")
+	if nav.HasError() {
+		return 0
+	}
+	return nav.Current().CountChildren()
+}
+
+func (s *MemoryMemberUpdaterTestSuite) version(database DB, id string) string {
+	resource, err := database.Get(context.TODO(), id, nil)
+	require.Nil(s.T(), err)
+	return resource.MetaVersionOrEmpty()
+}
+
+func (s *MemoryMemberUpdaterTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -6,93 +6,174 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"hash/fnv"
 	"sync"
 )
 
-// Memory return a new memory implementation of DB. This implementation saves resources in memory. Although
-// it does allow for concurrent access through the use of RWMutex, it does not support high throughput usage.
-// Hence, it is only intended for testing and showcasing purposes. This implementation also ignores all the field projection
-// parameters that it always returned the full resource regardless of the request to include or exclude attributes.
+// memoryShardCount is the number of shards the memory database spreads resources over. Sharding
+// keeps lock contention low when operations target different resources.
+const memoryShardCount = 32
+
+// Memory return a new memory implementation of DB. This implementation saves resources in memory,
+// spread over a fixed number of shards that are locked independently, so that concurrent operations
+// on different resources do not serialize behind a single lock. Reads return copies of the stored
+// resources, hence callers are free to modify what they read without affecting the store. It is
+// intended for testing, showcasing and small deployments. This implementation also ignores all the
+// field projection parameters that it always returned the full resource regardless of the request
+// to include or exclude attributes.
 func Memory() DB {
-	db := memoryDB{
-		RWMutex: sync.RWMutex{},
-		db:      make(map[string]*prop.Resource),
+	db := memoryDB{shards: make([]*memoryShard, memoryShardCount)}
+	for i := range db.shards {
+		db.shards[i] = &memoryShard{db: make(map[string]*prop.Resource)}
 	}
 	return &db
 }
 
 type memoryDB struct {
+	shards []*memoryShard
+}
+
+type memoryShard struct {
 	sync.RWMutex
 	db map[string]*prop.Resource
 }
 
+// shardFor returns the shard that holds the resource with the given id.
+func (m *memoryDB) shardFor(id string) *memoryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return m.shards[h.Sum32()%uint32(len(m.shards))]
+}
+
+// forEach invokes fn for every stored resource, holding one shard read lock at a time. The
+// iteration aborts as soon as the context is cancelled, so that large scans do not outlive the
+// request that started them.
+func (m *memoryDB) forEach(ctx context.Context, fn func(resource *prop.Resource) error) error {
+	for _, shard := range m.shards {
+		shard.RLock()
+		for _, r := range shard.db {
+			if err := ctx.Err(); err != nil {
+				shard.RUnlock()
+				return err
+			}
+			if err := fn(r); err != nil {
+				shard.RUnlock()
+				return err
+			}
+		}
+		shard.RUnlock()
+	}
+	return nil
+}
+
 func (m *memoryDB) Insert(_ context.Context, resource *prop.Resource) error {
 	id := resource.IdOrEmpty()
 	if len(id) == 0 {
 		return fmt.Errorf("%w: empty id", spec.ErrInternal)
 	}
 
-	if _, ok := m.db[id]; ok {
+	shard := m.shardFor(id)
+	shard.Lock()
+	defer shard.Unlock()
+
+	if _, ok := shard.db[id]; ok {
 		return fmt.Errorf("%w: id exists", spec.ErrInvalidValue)
 	}
-
-	m.Lock()
-	defer m.Unlock()
-	m.db[id] = resource
+	shard.db[id] = resource
 
 	return nil
 }
 
 func (m *memoryDB) Get(_ context.Context, id string, _ *crud.Projection) (*prop.Resource, error) {
-	r, ok := m.db[id]
+	shard := m.shardFor(id)
+	shard.RLock()
+	defer shard.RUnlock()
+
+	r, ok := shard.db[id]
 	if !ok {
 		return nil, fmt.Errorf("%w: resource not found by id", spec.ErrNotFound)
 	}
-	return r, nil
+	return r.Clone(), nil
 }
 
-func (m *memoryDB) Count(_ context.Context, filter string) (int, error) {
-	if len(filter) == 0 {
-		return len(m.db), nil
-	}
-
+func (m *memoryDB) Count(ctx context.Context, filter string) (int, error) {
 	n := 0
-	for _, r := range m.db {
-		ok, _ := crud.Evaluate(r, filter)
+	err := m.forEach(ctx, func(r *prop.Resource) error {
+		if len(filter) == 0 {
+			n++
+			return nil
+		}
+		ok, err := crud.Evaluate(r, filter)
+		if err != nil {
+			return err
+		}
 		if ok {
 			n++
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 	return n, nil
 }
 
 func (m *memoryDB) Replace(_ context.Context, ref *prop.Resource, replacement *prop.Resource) error {
 	id := ref.IdOrEmpty()
-	_, ok := m.db[id]
+	shard := m.shardFor(id)
+	shard.Lock()
+	defer shard.Unlock()
+
+	stored, ok := shard.db[id]
 	if !ok {
 		return fmt.Errorf("%w: resource not found by id", spec.ErrNotFound)
 	}
 
 	version := ref.MetaVersionOrEmpty()
-	if len(version) > 0 && m.db[id].MetaVersionOrEmpty() != version {
+	if len(version) > 0 && stored.MetaVersionOrEmpty() != version {
 		return spec.ErrConflict
 	}
 
-	m.db[id] = replacement
+	shard.db[id] = replacement
 	return nil
 }
 
 func (m *memoryDB) Delete(_ context.Context, resource *prop.Resource) error {
-	delete(m.db, resource.IdOrEmpty())
+	id := resource.IdOrEmpty()
+	shard := m.shardFor(id)
+	shard.Lock()
+	defer shard.Unlock()
+
+	delete(shard.db, id)
 	return nil
 }
 
-func (m *memoryDB) Query(_ context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, _ *crud.Projection) ([]*prop.Resource, error) {
+// QueryPlan implements QueryPlanner. The memory database always evaluates the filter against every
+// stored resource.
+func (m *memoryDB) QueryPlan(_ context.Context, _ string) (string, error) {
+	n := 0
+	for _, shard := range m.shards {
+		shard.RLock()
+		n += len(shard.db)
+		shard.RUnlock()
+	}
+	return fmt.Sprintf("full scan of %d resources across %d shards", n, len(m.shards)), nil
+}
+
+func (m *memoryDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, _ *crud.Projection) ([]*prop.Resource, error) {
 	var candidates = make([]*prop.Resource, 0)
-	for _, r := range m.db {
-		if ok, _ := crud.Evaluate(r, filter); ok {
-			candidates = append(candidates, r)
+	err := m.forEach(ctx, func(r *prop.Resource) error {
+		ok, err := crud.Evaluate(r, filter)
+		if err != nil {
+			return err
+		}
+		if ok {
+			candidates = append(candidates, r.Clone())
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	if len(candidates) == 0 {
 		return []*prop.Resource{}, nil
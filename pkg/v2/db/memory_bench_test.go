@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+var (
+	benchmarkSetupOnce     sync.Once
+	benchmarkResourceType  *spec.ResourceType
+	benchmarkResourceCount = 1000
+)
+
+func benchmarkSetup(b *testing.B) *spec.ResourceType {
+	benchmarkSetupOnce.Do(func() {
+		for _, filepath := range []string{
+			"../../../public/schemas/core_schema.json",
+			"../../../public/schemas/user_schema.json",
+			"../../../public/schemas/user_enterprise_extension_schema.json",
+		} {
+			f, err := os.Open(filepath)
+			if err != nil {
+				b.Fatal(err)
+			}
+			raw, err := ioutil.ReadAll(f)
+			if err != nil {
+				b.Fatal(err)
+			}
+			schema := new(spec.Schema)
+			if err := json.Unmarshal(raw, schema); err != nil {
+				b.Fatal(err)
+			}
+			spec.Schemas().Register(schema)
+		}
+
+		f, err := os.Open("../../../public/resource_types/user_resource_type.json")
+		if err != nil {
+			b.Fatal(err)
+		}
+		raw, err := ioutil.ReadAll(f)
+		if err != nil {
+			b.Fatal(err)
+		}
+		resourceType := new(spec.ResourceType)
+		if err := json.Unmarshal(raw, resourceType); err != nil {
+			b.Fatal(err)
+		}
+		benchmarkResourceType = resourceType
+	})
+	return benchmarkResourceType
+}
+
+func benchmarkDatabase(b *testing.B) DB {
+	resourceType := benchmarkSetup(b)
+	database := Memory()
+	for i := 0; i < benchmarkResourceCount; i++ {
+		resource := prop.NewResource(resourceType)
+		if err := resource.Navigator().Replace(map[string]interface{}{
+			"id":       fmt.Sprintf("user%04d", i),
+			"userName": fmt.Sprintf("user%04d", i),
+		}).Error(); err != nil {
+			b.Fatal(err)
+		}
+		if err := database.Insert(context.Background(), resource); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return database
+}
+
+// BenchmarkMemoryDBGet measures concurrent point reads spread over different resources.
+func BenchmarkMemoryDBGet(b *testing.B) {
+	database := benchmarkDatabase(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			id := fmt.Sprintf("user%04d", i%benchmarkResourceCount)
+			i++
+			if _, err := database.Get(context.Background(), id, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMemoryDBQueryReplace measures concurrent full scans mixed with point writes, the pattern
+// that serializes completely behind a single database wide lock.
+func BenchmarkMemoryDBQueryReplace(b *testing.B) {
+	database := benchmarkDatabase(b)
+	var counter int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			i := atomic.AddInt64(&counter, 1)
+			if i%10 == 0 {
+				id := fmt.Sprintf("user%04d", i%int64(benchmarkResourceCount))
+				resource, err := database.Get(context.Background(), id, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := database.Replace(context.Background(), resource, resource); err != nil {
+					b.Fatal(err)
+				}
+				continue
+			}
+			if _, err := database.Query(context.Background(), "userName pr", nil, nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+	"sync"
+)
+
+// Partition returns a DB implementation that routes each call to a tenant scoped delegate DB. The tenant id
+// is resolved from the context using the tenant package; calls without a tenant id are routed to the delegate
+// of the empty tenant. Delegates are created lazily by the provider function on the first call of each tenant
+// and cached for subsequent calls. The provider must be safe to call with any tenant id.
+func Partition(provider func(tenantId string) DB) DB {
+	return &partitionDB{
+		provider:  provider,
+		delegates: make(map[string]DB),
+	}
+}
+
+type partitionDB struct {
+	sync.RWMutex
+	provider  func(tenantId string) DB
+	delegates map[string]DB
+}
+
+func (p *partitionDB) delegate(ctx context.Context) DB {
+	tenantId := tenant.FromContext(ctx)
+
+	p.RLock()
+	delegate, ok := p.delegates[tenantId]
+	p.RUnlock()
+	if ok {
+		return delegate
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	if delegate, ok := p.delegates[tenantId]; ok {
+		return delegate
+	}
+	delegate = p.provider(tenantId)
+	p.delegates[tenantId] = delegate
+	return delegate
+}
+
+func (p *partitionDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	return p.delegate(ctx).Insert(ctx, resource)
+}
+
+func (p *partitionDB) Count(ctx context.Context, filter string) (int, error) {
+	return p.delegate(ctx).Count(ctx, filter)
+}
+
+func (p *partitionDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	return p.delegate(ctx).Get(ctx, id, projection)
+}
+
+func (p *partitionDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) error {
+	return p.delegate(ctx).Replace(ctx, ref, replacement)
+}
+
+func (p *partitionDB) Delete(ctx context.Context, resource *prop.Resource) error {
+	return p.delegate(ctx).Delete(ctx, resource)
+}
+
+func (p *partitionDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error) {
+	return p.delegate(ctx).Query(ctx, filter, sort, pagination, projection)
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/scimfilter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+const (
+	// PredicateEq matches resources whose value at the path equals the predicate value.
+	PredicateEq = "eq"
+	// PredicateNe matches resources whose value at the path does not equal the predicate value.
+	PredicateNe = "ne"
+)
+
+type (
+	// Predicate is a structured match condition on a single attribute path. Because the value is
+	// carried as data instead of being rendered into a filter string, values containing quotes or
+	// filter metacharacters cannot break or subvert the query.
+	Predicate struct {
+		Path  string
+		Op    string // one of PredicateEq and PredicateNe
+		Value interface{}
+	}
+	// PredicateCounter is an optional interface that DB implementations may implement to count
+	// resources matching a conjunction of structured predicates natively (i.e. as a parameterized
+	// query), bypassing filter string compilation altogether.
+	PredicateCounter interface {
+		CountPredicates(ctx context.Context, predicates []Predicate) (int, error)
+	}
+)
+
+// CountMatch counts the resources matching the conjunction of the given predicates. When the
+// database implements PredicateCounter, the predicates are passed through as they are. Otherwise,
+// they are rendered into a filter string with proper value escaping and counted via Count.
+func CountMatch(ctx context.Context, database DB, predicates []Predicate) (int, error) {
+	if len(predicates) == 0 {
+		return 0, fmt.Errorf("%w: no predicates to count", spec.ErrInternal)
+	}
+
+	if counter, ok := database.(PredicateCounter); ok {
+		return counter.CountPredicates(ctx, predicates)
+	}
+
+	expressions := make([]scimfilter.Expression, 0, len(predicates))
+	for _, predicate := range predicates {
+		switch predicate.Op {
+		case PredicateEq:
+			expressions = append(expressions, scimfilter.Eq(predicate.Path, predicate.Value))
+		case PredicateNe:
+			expressions = append(expressions, scimfilter.Ne(predicate.Path, predicate.Value))
+		default:
+			return 0, fmt.Errorf("%w: unsupported predicate op '%s'", spec.ErrInternal, predicate.Op)
+		}
+	}
+
+	expression := expressions[0]
+	if len(expressions) > 1 {
+		expression = scimfilter.And(expressions[0], expressions[1], expressions[2:]...)
+	}
+
+	filter, err := expression.Render()
+	if err != nil {
+		return 0, err
+	}
+	return database.Count(ctx, filter)
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// RefResolver returns a prop.Resolver backed by the database holding resources of the given resource
+// type. Reference values are accepted both as plain resource ids and as location URIs (i.e.
+// "https://example.com/Users/6784"): for URIs, the id is the path segment following the endpoint of
+// the resource type, or the last path segment when the endpoint does not appear. Resolution errors
+// from the database, including spec.ErrNotFound, are returned as is.
+func RefResolver(resourceType *spec.ResourceType, database DB) prop.Resolver {
+	return &refResolver{resourceType: resourceType, database: database}
+}
+
+type refResolver struct {
+	resourceType *spec.ResourceType
+	database     DB
+}
+
+func (r *refResolver) Resolve(ctx context.Context, ref string) (*prop.Resource, error) {
+	return r.database.Get(ctx, r.idOf(ref), nil)
+}
+
+func (r *refResolver) idOf(ref string) string {
+	if i := strings.Index(ref, r.resourceType.Endpoint()+"/"); i >= 0 {
+		ref = ref[i+len(r.resourceType.Endpoint())+1:]
+	} else if i := strings.LastIndex(ref, "/"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	if i := strings.IndexAny(ref, "/?"); i >= 0 {
+		ref = ref[:i]
+	}
+	return ref
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// SnapshotOptions returns the default snapshot options for the given resource type and snapshot file
+// path. By default, snapshots are only written on explicit request and file contents are not synced
+// to stable storage beyond what the operating system provides.
+func SnapshotOptions(resourceType *spec.ResourceType, path string) *MemorySnapshotOptions {
+	return &MemorySnapshotOptions{
+		resourceType: resourceType,
+		path:         path,
+	}
+}
+
+// MemorySnapshotOptions is the configuration for snapshot persistence of the memory database.
+type MemorySnapshotOptions struct {
+	resourceType *spec.ResourceType
+	path         string
+	interval     time.Duration
+	fsync        bool
+}
+
+// Every enables periodic background snapshotting at the given interval. Intervals less than or equal
+// to zero disable background snapshotting.
+func (opt *MemorySnapshotOptions) Every(interval time.Duration) *MemorySnapshotOptions {
+	opt.interval = interval
+	return opt
+}
+
+// FSync syncs the snapshot file to stable storage before it is put in place, at the cost of slower
+// snapshots.
+func (opt *MemorySnapshotOptions) FSync() *MemorySnapshotOptions {
+	opt.fsync = true
+	return opt
+}
+
+// MemorySnapshotDB extends DB with explicit snapshot control.
+type MemorySnapshotDB interface {
+	DB
+	// Snapshot writes a snapshot of the current contents to disk.
+	Snapshot() error
+	// Close stops background snapshotting and writes a final snapshot.
+	Close() error
+}
+
+// MemoryWithSnapshot returns a memory database whose contents are reloaded from the snapshot file on
+// start and written back to it on snapshots. The snapshot file contains one resource per line in its
+// JSON representation, and is replaced atomically so that a crash mid-snapshot never corrupts the
+// previous snapshot. Like Memory, the returned database is only intended for testing, showcasing and
+// small deployments.
+func MemoryWithSnapshot(options *MemorySnapshotOptions) (MemorySnapshotDB, error) {
+	s := &snapshotDB{
+		memoryDB: Memory().(*memoryDB),
+		options:  options,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if options.interval > 0 {
+		s.stop = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.run()
+	}
+	return s, nil
+}
+
+type snapshotDB struct {
+	*memoryDB
+	options   *MemorySnapshotOptions
+	closeOnce sync.Once
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+func (s *snapshotDB) Snapshot() error {
+	raw, err := s.render()
+	if err != nil {
+		return err
+	}
+	return s.write(raw)
+}
+
+func (s *snapshotDB) Close() (err error) {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+			<-s.stopped
+		}
+		err = s.Snapshot()
+	})
+	return
+}
+
+// run periodically snapshots the database contents until stopped. Errors are dropped here: the same
+// condition will resurface on the final snapshot written by Close.
+func (s *snapshotDB) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.options.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.Snapshot()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// load replaces the database contents with the resources read from the snapshot file. A missing
+// snapshot file yields an empty database.
+func (s *snapshotDB) load() error {
+	f, err := os.Open(s.options.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("%w: failed to open snapshot file: %v", spec.ErrInternal, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resource := prop.NewResource(s.options.resourceType)
+		if err := json.Deserialize(line, resource); err != nil {
+			return fmt.Errorf("%w: failed to parse snapshot resource: %v", spec.ErrInternal, err)
+		}
+		id := resource.IdOrEmpty()
+		if len(id) == 0 {
+			return fmt.Errorf("%w: snapshot resource has no id", spec.ErrInternal)
+		}
+		shard := s.shardFor(id)
+		shard.Lock()
+		shard.db[id] = resource
+		shard.Unlock()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: failed to read snapshot file: %v", spec.ErrInternal, err)
+	}
+	return nil
+}
+
+// render serializes the database contents to the snapshot format.
+func (s *snapshotDB) render() ([]byte, error) {
+	var buf bytes.Buffer
+	err := s.forEach(context.Background(), func(resource *prop.Resource) error {
+		raw, err := json.Serialize(resource)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+		buf.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write puts the rendered snapshot in place by writing to a temporary file in the same directory and
+// renaming it over the snapshot file.
+func (s *snapshotDB) write(raw []byte) error {
+	dir := filepath.Dir(s.options.path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(s.options.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("%w: failed to create snapshot file: %v", spec.ErrInternal, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("%w: failed to write snapshot file: %v", spec.ErrInternal, err)
+	}
+	if s.options.fsync {
+		if err := tmp.Sync(); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("%w: failed to sync snapshot file: %v", spec.ErrInternal, err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: failed to close snapshot file: %v", spec.ErrInternal, err)
+	}
+	if err := os.Rename(tmp.Name(), s.options.path); err != nil {
+		return fmt.Errorf("%w: failed to replace snapshot file: %v", spec.ErrInternal, err)
+	}
+	return nil
+}
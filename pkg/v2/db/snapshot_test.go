@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMemoryWithSnapshot(t *testing.T) {
+	s := new(MemorySnapshotTestSuite)
+	suite.Run(t, s)
+}
+
+type MemorySnapshotTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *MemorySnapshotTestSuite) TestSnapshotAndReload() {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.Nil(s.T(), err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "User.jsonl")
+
+	database, err := MemoryWithSnapshot(SnapshotOptions(s.resourceType, path).FSync())
+	require.Nil(s.T(), err)
+
+	for _, userData := range []map[string]interface{}{
+		{"id": "user001", "userName": "foo"},
+		{"id": "user002", "userName": "bar"},
+	} {
+		resource := prop.NewResource(s.resourceType)
+		require.Nil(s.T(), resource.Navigator().Replace(userData).Error())
+		require.Nil(s.T(), database.Insert(context.TODO(), resource))
+	}
+	require.Nil(s.T(), database.Close())
+
+	reloaded, err := MemoryWithSnapshot(SnapshotOptions(s.resourceType, path))
+	require.Nil(s.T(), err)
+	defer func() { _ = reloaded.Close() }()
+
+	n, err := reloaded.Count(context.TODO(), "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+
+	resource, err := reloaded.Get(context.TODO(), "user002", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "bar", resource.Navigator().Dot("userName").Current().Raw())
+}
+
+func (s *MemorySnapshotTestSuite) TestMissingSnapshotFileYieldsEmptyDatabase() {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.Nil(s.T(), err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	database, err := MemoryWithSnapshot(SnapshotOptions(s.resourceType, filepath.Join(dir, "User.jsonl")))
+	require.Nil(s.T(), err)
+	defer func() { _ = database.Close() }()
+
+	n, err := database.Count(context.TODO(), "")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 0, n)
+}
+
+func (s *MemorySnapshotTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// Traced returns a DB implementation that wraps each call of the delegate DB in a span started through
+// the trace package. Spans are annotated with the filter text and result counts where applicable. When
+// no tracing provider was registered, the wrapper adds negligible overhead.
+func Traced(delegate DB) DB {
+	return &tracedDB{delegate: delegate}
+}
+
+type tracedDB struct {
+	delegate DB
+}
+
+func (t *tracedDB) Insert(ctx context.Context, resource *prop.Resource) (err error) {
+	ctx, span := trace.StartSpan(ctx, "db.insert")
+	defer func() { span.SetError(err); span.End() }()
+
+	err = t.delegate.Insert(ctx, resource)
+	return
+}
+
+func (t *tracedDB) Count(ctx context.Context, filter string) (n int, err error) {
+	ctx, span := trace.StartSpan(ctx, "db.count")
+	span.SetTag("scim.filter", filter)
+	defer func() { span.SetError(err); span.End() }()
+
+	n, err = t.delegate.Count(ctx, filter)
+	span.SetTag("scim.count", n)
+	return
+}
+
+func (t *tracedDB) Get(ctx context.Context, id string, projection *crud.Projection) (resource *prop.Resource, err error) {
+	ctx, span := trace.StartSpan(ctx, "db.get")
+	defer func() { span.SetError(err); span.End() }()
+
+	resource, err = t.delegate.Get(ctx, id, projection)
+	return
+}
+
+func (t *tracedDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) (err error) {
+	ctx, span := trace.StartSpan(ctx, "db.replace")
+	defer func() { span.SetError(err); span.End() }()
+
+	err = t.delegate.Replace(ctx, ref, replacement)
+	return
+}
+
+func (t *tracedDB) Delete(ctx context.Context, resource *prop.Resource) (err error) {
+	ctx, span := trace.StartSpan(ctx, "db.delete")
+	defer func() { span.SetError(err); span.End() }()
+
+	err = t.delegate.Delete(ctx, resource)
+	return
+}
+
+func (t *tracedDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) (resources []*prop.Resource, err error) {
+	ctx, span := trace.StartSpan(ctx, "db.query")
+	span.SetTag("scim.filter", filter)
+	defer func() { span.SetError(err); span.End() }()
+
+	resources, err = t.delegate.Query(ctx, filter, sort, pagination, projection)
+	span.SetTag("scim.results", len(resources))
+	return
+}
@@ -0,0 +1,8 @@
+// This package publishes resource change events to downstream consumers.
+//
+// Mutating services can be decorated with PublishingCreate, PublishingReplace, PublishingPatch and
+// PublishingDelete so that an Event carrying the serialized resource is handed to a Publisher after the
+// operation committed. The package ships an in-process Publisher useful for wiring consumers living in
+// the same process (i.e. search indexers, group sync workers); adapters for message brokers (i.e. AMQP,
+// Kafka, NATS) can be provided by implementing Publisher against the broker client of choice.
+package events
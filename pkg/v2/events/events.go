@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Event types describing the kind of resource change.
+const (
+	TypeCreated = "created"
+	TypeUpdated = "updated"
+	TypeDeleted = "deleted"
+)
+
+// Event describes a single committed resource change.
+type Event struct {
+	// Type is one of TypeCreated, TypeUpdated and TypeDeleted.
+	Type string `json:"type"`
+	// Time is the instant the event was assembled, in ISO8601 format.
+	Time string `json:"time"`
+	// ResourceID is the id of the changed resource.
+	ResourceID string `json:"resourceId"`
+	// ResourceType is the name of the resource type of the changed resource.
+	ResourceType string `json:"resourceType"`
+	// Data is the JSON serialization of the resource after the change. It is empty for deleted events.
+	Data []byte `json:"data,omitempty"`
+}
+
+// Publisher delivers events to interested consumers. Implementations must be safe for concurrent use.
+type Publisher interface {
+	// Publish delivers the event, or returns any error. Errors are expected to be handled (i.e. logged
+	// and retried) by the caller and must not affect the outcome of the operation that emitted the event.
+	Publish(ctx context.Context, event *Event) error
+}
+
+// NewEvent assembles an event of the given type from the resource. For deleted events, the resource
+// reflects the state before deletion and its serialization is omitted from the event.
+func NewEvent(eventType string, resource *prop.Resource) (*Event, error) {
+	event := &Event{
+		Type:         eventType,
+		Time:         time.Now().Format(spec.ISO8601),
+		ResourceID:   resource.IdOrEmpty(),
+		ResourceType: resource.ResourceType().Name(),
+	}
+
+	if eventType != TypeDeleted {
+		raw, err := json.Serialize(resource)
+		if err != nil {
+			return nil, err
+		}
+		event.Data = raw
+	}
+
+	return event, nil
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// InProcess returns a Publisher that fans events out to subscribers living in the same process. Each
+// subscriber receives events on its own buffered channel; when a subscriber's buffer is full, the event
+// is dropped for that subscriber so that a slow consumer cannot block the publishing service.
+func InProcess(buffer int) *InProcessPublisher {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &InProcessPublisher{buffer: buffer}
+}
+
+// InProcessPublisher is an in-process fan-out implementation of Publisher.
+type InProcessPublisher struct {
+	sync.RWMutex
+	buffer      int
+	subscribers []chan *Event
+}
+
+// Subscribe registers a new subscriber and returns the channel on which it will receive events.
+func (p *InProcessPublisher) Subscribe() <-chan *Event {
+	p.Lock()
+	defer p.Unlock()
+
+	ch := make(chan *Event, p.buffer)
+	p.subscribers = append(p.subscribers, ch)
+	return ch
+}
+
+func (p *InProcessPublisher) Publish(_ context.Context, event *Event) error {
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
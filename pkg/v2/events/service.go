@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/service"
+)
+
+// PublishingCreate returns a service.Create that publishes a created event after the delegate service
+// succeeded. Publishing errors are returned by the publisher and ignored here, as the resource change
+// has already committed.
+func PublishingCreate(delegate service.Create, publisher Publisher) service.Create {
+	return &publishingCreate{delegate: delegate, publisher: publisher}
+}
+
+type publishingCreate struct {
+	delegate  service.Create
+	publisher Publisher
+}
+
+func (s *publishingCreate) Do(ctx context.Context, req *service.CreateRequest) (*service.CreateResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Resource != nil {
+		if event, eventErr := NewEvent(TypeCreated, resp.Resource); eventErr == nil {
+			_ = s.publisher.Publish(ctx, event)
+		}
+	}
+	return resp, err
+}
+
+// PublishingReplace returns a service.Replace that publishes an updated event after the delegate service
+// actually replaced the resource.
+func PublishingReplace(delegate service.Replace, publisher Publisher) service.Replace {
+	return &publishingReplace{delegate: delegate, publisher: publisher}
+}
+
+type publishingReplace struct {
+	delegate  service.Replace
+	publisher Publisher
+}
+
+func (s *publishingReplace) Do(ctx context.Context, req *service.ReplaceRequest) (*service.ReplaceResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Replaced && resp.Resource != nil {
+		if event, eventErr := NewEvent(TypeUpdated, resp.Resource); eventErr == nil {
+			_ = s.publisher.Publish(ctx, event)
+		}
+	}
+	return resp, err
+}
+
+// PublishingPatch returns a service.Patch that publishes an updated event after the delegate service
+// actually modified the resource.
+func PublishingPatch(delegate service.Patch, publisher Publisher) service.Patch {
+	return &publishingPatch{delegate: delegate, publisher: publisher}
+}
+
+type publishingPatch struct {
+	delegate  service.Patch
+	publisher Publisher
+}
+
+func (s *publishingPatch) Do(ctx context.Context, req *service.PatchRequest) (*service.PatchResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Patched && resp.Resource != nil {
+		if event, eventErr := NewEvent(TypeUpdated, resp.Resource); eventErr == nil {
+			_ = s.publisher.Publish(ctx, event)
+		}
+	}
+	return resp, err
+}
+
+// PublishingDelete returns a service.Delete that publishes a deleted event after the delegate service
+// succeeded.
+func PublishingDelete(delegate service.Delete, publisher Publisher) service.Delete {
+	return &publishingDelete{delegate: delegate, publisher: publisher}
+}
+
+type publishingDelete struct {
+	delegate  service.Delete
+	publisher Publisher
+}
+
+func (s *publishingDelete) Do(ctx context.Context, req *service.DeleteRequest) (*service.DeleteResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Deleted != nil {
+		if event, eventErr := NewEvent(TypeDeleted, resp.Deleted); eventErr == nil {
+			_ = s.publisher.Publish(ctx, event)
+		}
+	}
+	return resp, err
+}
@@ -58,6 +58,20 @@ func Compare(before *prop.Resource, after *prop.Resource) *Diff {
 	return diff
 }
 
+// Changes reports a membership difference directly from the values of the members that
+// joined and left the group. It serves callers that already know the delta, such as those
+// reacting to targeted member updates, without requiring two resource snapshots to compare.
+func Changes(joined []string, left []string) *Diff {
+	diff := new(Diff)
+	for _, id := range joined {
+		diff.addJoined(id)
+	}
+	for _, id := range left {
+		diff.addLeft(id)
+	}
+	return diff
+}
+
 // Diff reports the difference between the members of two group resources.
 type Diff struct {
 	joined map[string]struct{}
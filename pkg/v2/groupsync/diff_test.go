@@ -218,3 +218,19 @@ func (s *CompareTestSuite) SetupSuite() {
 		}
 	}
 }
+
+func TestChanges(t *testing.T) {
+	diff := Changes([]string{"m1", "m2"}, []string{"m3"})
+
+	assert.Equal(t, 2, diff.CountJoined())
+	assert.Equal(t, 1, diff.CountLeft())
+
+	joined := map[string]struct{}{}
+	diff.ForEachJoined(func(id string) { joined[id] = struct{}{} })
+	assert.Contains(t, joined, "m1")
+	assert.Contains(t, joined, "m2")
+
+	left := map[string]struct{}{}
+	diff.ForEachLeft(func(id string) { left[id] = struct{}{} })
+	assert.Contains(t, left, "m3")
+}
@@ -0,0 +1,162 @@
+package groupsync
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// NewDisplayService returns a new DisplayService.
+func NewDisplayService(userDB db.DB, groupDB db.DB) *DisplayService {
+	return &DisplayService{
+		userDB:     userDB,
+		groupDB:    groupDB,
+		metaFilter: filter.MetaFilter(),
+	}
+}
+
+// DisplayService propagates a Group resource's displayName to the denormalized display fields that
+// mention the group: the "groups.display" property of users that belong to the group, and the
+// "members.display" property of groups that contain the group as a nested member. These copies
+// otherwise go stale when the group is renamed.
+type DisplayService struct {
+	userDB     db.DB
+	groupDB    db.DB
+	metaFilter filter.ByResource
+}
+
+// SyncDisplayName updates the display fields referencing the group to the group's current
+// displayName. Resources whose display fields are already up to date are left untouched; modified
+// resources have their meta refreshed and are replaced in their database.
+func (s *DisplayService) SyncDisplayName(ctx context.Context, group *prop.Resource) error {
+	groupID := group.IdOrEmpty()
+	if len(groupID) == 0 {
+		return fmt.Errorf("%w: group has no id", spec.ErrInvalidValue)
+	}
+
+	displayNav := group.Navigator().Dot("displayName")
+	if displayNav.HasError() {
+		return displayNav.Error()
+	}
+	display := displayNav.Current().Raw()
+
+	for _, t := range []struct {
+		database  db.DB
+		attribute string
+	}{
+		{database: s.userDB, attribute: "groups"},
+		{database: s.groupDB, attribute: "members"},
+	} {
+		resources, err := t.database.Query(
+			ctx,
+			fmt.Sprintf("%s.value eq %s", t.attribute, strconv.Quote(groupID)),
+			nil, nil, nil,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resources {
+			ref := resource.Clone()
+
+			changed, err := s.updateDisplay(resource, t.attribute, groupID, display)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+
+			if err := s.metaFilter.FilterRef(ctx, resource, ref); err != nil {
+				return err
+			}
+			if err := t.database.Replace(ctx, ref, resource); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Consume drives the display synchronization from the event bus. It blocks until the context is
+// cancelled or the source channel is closed, reacting to updated events of the given group resource
+// type and ignoring all other events. Errors encountered while processing an event are reported to
+// onError, if non-nil, and do not stop consumption.
+func (s *DisplayService) Consume(ctx context.Context, groupResourceType *spec.ResourceType, source <-chan *events.Event, onError func(err error)) {
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-source:
+			if !ok {
+				return
+			}
+			if event.Type != events.TypeUpdated || event.ResourceType != groupResourceType.Name() {
+				continue
+			}
+
+			group := prop.NewResource(groupResourceType)
+			if err := json.Deserialize(event.Data, group); err != nil {
+				report(err)
+				continue
+			}
+			if err := s.SyncDisplayName(ctx, group); err != nil {
+				report(err)
+			}
+		}
+	}
+}
+
+// updateDisplay sets the display sub property of the elements referencing the group to the given
+// value, and reports whether the resource was modified.
+func (s *DisplayService) updateDisplay(resource *prop.Resource, attribute string, groupID string, display interface{}) (bool, error) {
+	nav := resource.Navigator().Dot(attribute)
+	if nav.HasError() {
+		return false, nav.Error()
+	}
+
+	var indexes []int
+	_ = nav.Current().ForEachChild(func(index int, child prop.Property) error {
+		value, err := child.ChildAtIndex("value")
+		if err == nil && value.Raw() == groupID {
+			indexes = append(indexes, index)
+		}
+		return nil
+	})
+
+	changed := false
+	for _, index := range indexes {
+		if nav.At(index).Dot("display").HasError() {
+			return changed, nav.Error()
+		}
+		if nav.Current().Raw() != display {
+			if display == nil {
+				if err := nav.Delete().Error(); err != nil {
+					return changed, err
+				}
+			} else {
+				if err := nav.Replace(display).Error(); err != nil {
+					return changed, err
+				}
+			}
+			changed = true
+		}
+		nav.Retract().Retract()
+	}
+
+	return changed, nil
+}
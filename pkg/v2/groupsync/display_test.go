@@ -0,0 +1,234 @@
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestDisplayService(t *testing.T) {
+	s := new(DisplayServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type DisplayServiceTestSuite struct {
+	suite.Suite
+	userResourceType  *spec.ResourceType
+	groupResourceType *spec.ResourceType
+}
+
+func (s *DisplayServiceTestSuite) TestSyncDisplayName() {
+	userDB, groupDB := s.seedDatabases()
+
+	group, err := groupDB.Get(context.Background(), "g1", nil)
+	require.Nil(s.T(), err)
+	updated := group.Clone()
+	require.False(s.T(), updated.Navigator().Dot("displayName").Replace("Renamed Group").HasError())
+	require.Nil(s.T(), groupDB.Replace(context.Background(), group, updated))
+
+	err = NewDisplayService(userDB, groupDB).SyncDisplayName(context.Background(), updated)
+	assert.Nil(s.T(), err)
+
+	user, err := userDB.Get(context.Background(), "u1", nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "Renamed Group", s.displayOf(user, "groups", "g1"))
+
+	parent, err := groupDB.Get(context.Background(), "g2", nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "Renamed Group", s.displayOf(parent, "members", "g1"))
+
+	// a resource not referencing the group is untouched
+	bystander, err := userDB.Get(context.Background(), "u2", nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "Other Group", s.displayOf(bystander, "groups", "g9"))
+}
+
+func (s *DisplayServiceTestSuite) TestConsume() {
+	userDB, groupDB := s.seedDatabases()
+
+	group, err := groupDB.Get(context.Background(), "g1", nil)
+	require.Nil(s.T(), err)
+	updated := group.Clone()
+	require.False(s.T(), updated.Navigator().Dot("displayName").Replace("Renamed Group").HasError())
+	require.Nil(s.T(), groupDB.Replace(context.Background(), group, updated))
+
+	publisher := events.InProcess(10)
+	source := publisher.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		NewDisplayService(userDB, groupDB).Consume(ctx, s.groupResourceType, source, nil)
+		close(done)
+	}()
+
+	event, err := events.NewEvent(events.TypeUpdated, updated)
+	require.Nil(s.T(), err)
+	require.Nil(s.T(), publisher.Publish(context.Background(), event))
+
+	assert.Eventually(s.T(), func() bool {
+		user, err := userDB.Get(context.Background(), "u1", nil)
+		require.Nil(s.T(), err)
+		return s.displayOf(user, "groups", "g1") == "Renamed Group"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func (s *DisplayServiceTestSuite) seedDatabases() (userDB db.DB, groupDB db.DB) {
+	userDB, groupDB = db.Memory(), db.Memory()
+
+	for _, t := range []struct {
+		resourceType *spec.ResourceType
+		database     db.DB
+		data         map[string]interface{}
+	}{
+		{
+			resourceType: s.userResourceType,
+			database:     userDB,
+			data: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "u1",
+				"userName": "u1",
+				"groups": []interface{}{
+					map[string]interface{}{"value": "g1", "$ref": "/Groups/g1", "display": "Old Group"},
+				},
+			},
+		},
+		{
+			resourceType: s.userResourceType,
+			database:     userDB,
+			data: map[string]interface{}{
+				"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+				"id":       "u2",
+				"userName": "u2",
+				"groups": []interface{}{
+					map[string]interface{}{"value": "g9", "$ref": "/Groups/g9", "display": "Other Group"},
+				},
+			},
+		},
+		{
+			resourceType: s.groupResourceType,
+			database:     groupDB,
+			data: map[string]interface{}{
+				"schemas":     []interface{}{"urn:ietf:params:scim:schemas:core:2.0:Group"},
+				"id":          "g1",
+				"displayName": "Old Group",
+				"members": []interface{}{
+					map[string]interface{}{"value": "u1", "$ref": "/Users/u1", "display": "u1"},
+				},
+			},
+		},
+		{
+			resourceType: s.groupResourceType,
+			database:     groupDB,
+			data: map[string]interface{}{
+				"schemas":     []interface{}{"urn:ietf:params:scim:schemas:core:2.0:Group"},
+				"id":          "g2",
+				"displayName": "Parent Group",
+				"members": []interface{}{
+					map[string]interface{}{"value": "g1", "$ref": "/Groups/g1", "display": "Old Group"},
+				},
+			},
+		},
+	} {
+		r := prop.NewResource(t.resourceType)
+		require.False(s.T(), r.Navigator().Replace(t.data).HasError())
+		require.Nil(s.T(), t.database.Insert(context.Background(), r))
+	}
+
+	return
+}
+
+func (s *DisplayServiceTestSuite) displayOf(resource *prop.Resource, attribute string, groupID string) string {
+	var display string
+	_ = resource.Navigator().Dot(attribute).ForEachChild(func(_ int, child prop.Property) error {
+		value, _ := child.ChildAtIndex("value")
+		if value != nil && value.Raw() == groupID {
+			d, _ := child.ChildAtIndex("display")
+			if d != nil && !d.IsUnassigned() {
+				display = d.Raw().(string)
+			}
+		}
+		return nil
+	})
+	return display
+}
+
+func (s *DisplayServiceTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.userResourceType = parsed.(*spec.ResourceType)
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.groupResourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handlerutil
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+)
+
+// bulkIdPlaceholder matches a "bulkId:<id>" placeholder as it appears inside rendered JSON values,
+// capturing the client assigned id.
+var bulkIdPlaceholder = regexp.MustCompile(`bulkId:([A-Za-z0-9._-]+)`)
+
+// BulkIdRewriter returns an io.Writer that resolves "bulkId:<id>" placeholders in a streamed bulk
+// response. When an operation result reports both a bulkId and the location of the resource it
+// created, the mapping is remembered, and later writes containing the placeholder — typically in
+// echoed location or $ref values of operations that referenced the newly created resource — are
+// rewritten with the resolved location before reaching the delegate.
+//
+// Each Write is treated as one unit: the bulk service streams one operation result per Write, in
+// request order, and RFC 7644 forbids forward bulkId references, so a placeholder is always learned
+// before it needs to be resolved. Placeholders whose bulkId was never learned are left untouched.
+func BulkIdRewriter(delegate io.Writer) io.Writer {
+	return &bulkIdRewriter{
+		delegate:  delegate,
+		locations: map[string]string{},
+	}
+}
+
+type bulkIdRewriter struct {
+	delegate  io.Writer
+	locations map[string]string
+}
+
+func (r *bulkIdRewriter) Write(p []byte) (int, error) {
+	out := bulkIdPlaceholder.ReplaceAllFunc(p, func(match []byte) []byte {
+		if location, ok := r.locations[string(match[len("bulkId:"):])]; ok {
+			return []byte(location)
+		}
+		return match
+	})
+
+	var result struct {
+		BulkId   string `json:"bulkId"`
+		Location string `json:"location"`
+	}
+	if err := json.Unmarshal(p, &result); err == nil && len(result.BulkId) > 0 && len(result.Location) > 0 {
+		r.locations[result.BulkId] = result.Location
+	}
+
+	if _, err := r.delegate.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,74 @@
+package handlerutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkIdRewriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		expect string
+	}{
+		{
+			name: "placeholder resolves to the location learned earlier",
+			writes: []string{
+				`{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}`,
+				`,`,
+				`{"method":"POST","bulkId":"ytrewq","location":"https://test.org/Groups/g1","status":"201","response":{"members":[{"value":"u1","$ref":"bulkId:qwerty"}]}}`,
+			},
+			expect: `{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}` +
+				`,` +
+				`{"method":"POST","bulkId":"ytrewq","location":"https://test.org/Groups/g1","status":"201","response":{"members":[{"value":"u1","$ref":"https://test.org/Users/u1"}]}}`,
+		},
+		{
+			name: "unknown placeholder is left untouched",
+			writes: []string{
+				`{"method":"POST","bulkId":"qwerty","status":"400"}`,
+				`,`,
+				`{"method":"POST","bulkId":"ytrewq","location":"https://test.org/Groups/g1","status":"201","response":{"members":[{"$ref":"bulkId:qwerty"}]}}`,
+			},
+			expect: `{"method":"POST","bulkId":"qwerty","status":"400"}` +
+				`,` +
+				`{"method":"POST","bulkId":"ytrewq","location":"https://test.org/Groups/g1","status":"201","response":{"members":[{"$ref":"bulkId:qwerty"}]}}`,
+		},
+		{
+			name: "placeholder in a later location value is rewritten",
+			writes: []string{
+				`{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}`,
+				`,`,
+				`{"method":"PATCH","location":"bulkId:qwerty","status":"200"}`,
+			},
+			expect: `{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}` +
+				`,` +
+				`{"method":"PATCH","location":"https://test.org/Users/u1","status":"200"}`,
+		},
+		{
+			name: "forward reference is not resolved",
+			writes: []string{
+				`{"method":"PATCH","location":"bulkId:qwerty","status":"200"}`,
+				`,`,
+				`{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}`,
+			},
+			expect: `{"method":"PATCH","location":"bulkId:qwerty","status":"200"}` +
+				`,` +
+				`{"method":"POST","bulkId":"qwerty","location":"https://test.org/Users/u1","status":"201"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sink bytes.Buffer
+			rewriter := BulkIdRewriter(&sink)
+			for _, chunk := range test.writes {
+				n, err := rewriter.Write([]byte(chunk))
+				assert.Nil(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+			assert.Equal(t, test.expect, sink.String())
+		})
+	}
+}
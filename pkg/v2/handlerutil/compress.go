@@ -0,0 +1,107 @@
+package handlerutil
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipWriterPool and flateWriterPool recycle compressor state between responses, since compressor
+// allocation dominates the cost of compressing small payloads.
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+	flateWriterPool = sync.Pool{
+		New: func() interface{} {
+			w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return w
+		},
+	}
+)
+
+// NegotiateCompression inspects the Accept-Encoding header of the request and, when the client
+// accepts gzip or deflate encoding, returns a wrapped http.ResponseWriter that compresses the
+// response body accordingly, along with a finish function flushing the compressor. The finish
+// function must be called after the response is completely written, typically in a defer. When the
+// client accepts neither encoding, the response writer is returned as is and the finish function is
+// a no-op. Gzip is preferred when both encodings are acceptable.
+//
+// Compression is most useful on endpoints returning large payloads, such as list responses with
+// many resources; wrapping small responses is harmless but yields no benefit.
+func NegotiateCompression(rw http.ResponseWriter, r *http.Request) (http.ResponseWriter, func() error) {
+	switch selectEncoding(r.Header.Get("Accept-Encoding")) {
+	case "gzip":
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(rw)
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.Header().Add("Vary", "Accept-Encoding")
+		rw.Header().Del("Content-Length")
+		return &compressedResponseWriter{ResponseWriter: rw, compressor: gw}, func() error {
+			err := gw.Close()
+			gzipWriterPool.Put(gw)
+			return err
+		}
+	case "deflate":
+		fw := flateWriterPool.Get().(*flate.Writer)
+		fw.Reset(rw)
+		rw.Header().Set("Content-Encoding", "deflate")
+		rw.Header().Add("Vary", "Accept-Encoding")
+		rw.Header().Del("Content-Length")
+		return &compressedResponseWriter{ResponseWriter: rw, compressor: fw}, func() error {
+			err := fw.Close()
+			flateWriterPool.Put(fw)
+			return err
+		}
+	default:
+		return rw, func() error { return nil }
+	}
+}
+
+// selectEncoding returns the preferred supported content coding from the Accept-Encoding header
+// value, or empty string when neither gzip nor deflate is acceptable. Codings explicitly rejected
+// with q=0 are skipped.
+func selectEncoding(acceptEncoding string) string {
+	var deflateOk bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != "gzip" && coding != "deflate" {
+			continue
+		}
+		rejected := false
+		for _, param := range fields[1:] {
+			if p := strings.ReplaceAll(strings.TrimSpace(param), " ", ""); p == "q=0" || p == "q=0.0" || p == "q=0.00" || p == "q=0.000" {
+				rejected = true
+				break
+			}
+		}
+		if rejected {
+			continue
+		}
+		if coding == "gzip" {
+			return "gzip"
+		}
+		deflateOk = true
+	}
+	if deflateOk {
+		return "deflate"
+	}
+	return ""
+}
+
+// compressedResponseWriter routes body writes through the compressor while leaving header access on
+// the underlying http.ResponseWriter.
+type compressedResponseWriter struct {
+	http.ResponseWriter
+	compressor io.Writer
+}
+
+func (w *compressedResponseWriter) Write(p []byte) (int, error) {
+	return w.compressor.Write(p)
+}
@@ -0,0 +1,94 @@
+package handlerutil
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	const payload = `{"schemas":["urn:ietf:params:scim:api:messages:2.0:ListResponse"],"totalResults":0}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		expect         func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "gzip accepted",
+			acceptEncoding: "gzip, deflate",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+				gr, err := gzip.NewReader(recorder.Body)
+				require.Nil(t, err)
+				decoded, err := ioutil.ReadAll(gr)
+				require.Nil(t, err)
+				assert.Equal(t, payload, string(decoded))
+			},
+		},
+		{
+			name:           "deflate accepted",
+			acceptEncoding: "deflate",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, "deflate", recorder.Header().Get("Content-Encoding"))
+				decoded, err := ioutil.ReadAll(flate.NewReader(recorder.Body))
+				require.Nil(t, err)
+				assert.Equal(t, payload, string(decoded))
+			},
+		},
+		{
+			name:           "gzip preferred over deflate",
+			acceptEncoding: "deflate, gzip;q=0.5",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+			},
+		},
+		{
+			name:           "rejected with q=0",
+			acceptEncoding: "gzip;q=0, deflate;q=0",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+				assert.Equal(t, payload, recorder.Body.String())
+			},
+		},
+		{
+			name:           "no accept encoding",
+			acceptEncoding: "",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+				assert.Equal(t, payload, recorder.Body.String())
+			},
+		},
+		{
+			name:           "unsupported encoding",
+			acceptEncoding: "br",
+			expect: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+				assert.Equal(t, payload, recorder.Body.String())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/Users", nil)
+			if len(test.acceptEncoding) > 0 {
+				r.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+
+			recorder := httptest.NewRecorder()
+			rw, finish := NegotiateCompression(recorder, r)
+			_, err := rw.Write([]byte(payload))
+			require.Nil(t, err)
+			require.Nil(t, finish())
+
+			test.expect(t, recorder)
+		})
+	}
+}
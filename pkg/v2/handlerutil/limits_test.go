@@ -0,0 +1,118 @@
+package handlerutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimitMiddlewareRejectsOversizedBody(t *testing.T) {
+	handler := BodyLimitMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "handler should not be reached")
+	}), 8)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/Users", strings.NewReader("this body is too large")))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	var body struct {
+		Status   int    `json:"status"`
+		ScimType string `json:"scimType"`
+	}
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, body.Status)
+	assert.Equal(t, "payloadTooLarge", body.ScimType)
+}
+
+func TestBodyLimitMiddlewareCutsOffUndeclaredBody(t *testing.T) {
+	handler := BodyLimitMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, err := ioutil.ReadAll(r.Body)
+		assert.NotNil(t, err, "reading past the limit should fail")
+	}), 8)
+
+	r := httptest.NewRequest(http.MethodPost, "/Users", strings.NewReader("this body is too large"))
+	r.ContentLength = -1 // simulate a chunked body of undeclared length
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestBodyLimitMiddlewareAppliesSeparateBulkLimit(t *testing.T) {
+	reached := false
+	handler := BodyLimitMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		reached = true
+	}), 8, WithBulkBodyLimit(1024))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/Bulk", strings.NewReader("this body is too large for the general limit")))
+
+	assert.True(t, reached)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMutationLimitMiddlewareRejectsConcurrentMutations(t *testing.T) {
+	var (
+		entered = make(chan struct{}, 8)
+		release = make(chan struct{})
+	)
+	handler := MutationLimitMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}), 1)
+
+	var (
+		wg     sync.WaitGroup
+		rrFoo  = httptest.NewRecorder()
+		rrBar  = httptest.NewRecorder()
+		attack = func(rr *httptest.ResponseRecorder, path string) {
+			defer wg.Done()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, path, nil))
+		}
+	)
+	wg.Add(1)
+	go attack(rrFoo, "/Users/foo")
+	<-entered
+
+	// a second concurrent mutation on the same resource is rejected
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, "/Users/foo", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	var body struct {
+		ScimType string `json:"scimType"`
+	}
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "tooManyRequests", body.ScimType)
+
+	// a concurrent mutation on a different resource is not limited
+	wg.Add(1)
+	go attack(rrBar, "/Users/bar")
+	<-entered
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, rrFoo.Code)
+	assert.Equal(t, http.StatusOK, rrBar.Code)
+
+	// once the first mutation finished, the resource accepts mutations again
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, "/Users/foo", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMutationLimitMiddlewareIgnoresReads(t *testing.T) {
+	blocked := MutationLimitMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+	}), 1)
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		blocked.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/Users/foo", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+}
@@ -0,0 +1,32 @@
+package handlerutil
+
+import (
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/log"
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+)
+
+// LoggerMiddleware wraps the next handler so that every request context carries a request scoped
+// logger derived from the given base logger, stamped with the request method, path and, when the
+// path addresses a single resource, its id. Downstream code retrieves the logger with
+// log.FromContext and can log without threading a logger through every call. A nil logger leaves
+// the request context untouched, in which case log.FromContext falls back to the no-op logger.
+func LoggerMiddleware(next http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if logger != nil {
+			fields := map[string]interface{}{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+			if id := resourceIdFromPath(r.URL.Path); len(id) > 0 {
+				fields["resourceId"] = id
+			}
+			if id := requestid.FromContext(r.Context()); len(id) > 0 {
+				fields["requestId"] = id
+			}
+			r = r.WithContext(log.WithContext(r.Context(), log.WithFields(logger, fields)))
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
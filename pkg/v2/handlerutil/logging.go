@@ -0,0 +1,196 @@
+package handlerutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// RequestLogEntry is the structured record of a single handled request, passed to the RequestLogFunc
+// of the logging middleware. Filter and Payload have had sensitive values redacted and are safe to log.
+type RequestLogEntry struct {
+	Method     string        // HTTP method of the request
+	Path       string        // URL path of the request
+	Filter     string        // the filter query parameter, redacted; empty when absent
+	ResourceID string        // id of the addressed resource; empty when the request addresses no single resource
+	RequestId  string        // correlation id assigned by the request id middleware; empty when it is not installed
+	Status     int           // HTTP status of the response
+	Duration   time.Duration // time taken to serve the request
+	Payload    string        // redacted excerpt of the request payload; empty unless enabled through WithPayloadExcerpt
+}
+
+// RequestLogFunc receives one RequestLogEntry per handled request. It adapts the logging middleware
+// to whatever logging library the integrator uses.
+type RequestLogFunc func(entry RequestLogEntry)
+
+// LoggingOption customizes the behaviour of LoggingMiddleware.
+type LoggingOption interface {
+	apply(middleware *loggingMiddleware)
+}
+
+// WithSensitiveAttributes returns a LoggingOption that adds attribute names whose values must be
+// redacted from logged filters and payload excerpts. Attribute names are matched case-insensitively.
+// The password attribute is always treated as sensitive and does not need to be listed.
+func WithSensitiveAttributes(names ...string) LoggingOption {
+	return loggingOptionFunc(func(m *loggingMiddleware) {
+		for _, name := range names {
+			m.sensitive[spec.FoldAttributeName(name)] = struct{}{}
+		}
+	})
+}
+
+// WithPayloadExcerpt returns a LoggingOption that enables logging an excerpt of the request payload
+// of at most limit bytes. The payload is only logged when it parses as JSON, after the values of all
+// sensitive attributes have been redacted; payloads that do not parse are never logged.
+func WithPayloadExcerpt(limit int) LoggingOption {
+	return loggingOptionFunc(func(m *loggingMiddleware) {
+		m.payloadLimit = limit
+	})
+}
+
+type loggingOptionFunc func(middleware *loggingMiddleware)
+
+func (f loggingOptionFunc) apply(middleware *loggingMiddleware) {
+	f(middleware)
+}
+
+// LoggingMiddleware wraps the next handler to invoke log with a structured RequestLogEntry after each
+// request, recording method, path, filter, resource id, response status and duration. Values of the
+// password attribute and any attributes configured through WithSensitiveAttributes are redacted from
+// the logged filter and payload excerpt, so that log output never carries credentials or other
+// sensitive values.
+func LoggingMiddleware(next http.Handler, log RequestLogFunc, options ...LoggingOption) http.Handler {
+	m := &loggingMiddleware{
+		next:      next,
+		log:       log,
+		sensitive: map[string]struct{}{"password": {}},
+	}
+	for _, opt := range options {
+		opt.apply(m)
+	}
+	return m
+}
+
+type loggingMiddleware struct {
+	next         http.Handler
+	log          RequestLogFunc
+	sensitive    map[string]struct{}
+	payloadLimit int
+}
+
+func (m *loggingMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	entry := RequestLogEntry{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Filter:     m.redactFilter(r.URL.Query().Get(paramFilter)),
+		ResourceID: resourceIdFromPath(r.URL.Path),
+		RequestId:  requestid.FromContext(r.Context()),
+	}
+
+	if m.payloadLimit > 0 && r.Body != nil {
+		raw, err := ioutil.ReadAll(r.Body)
+		if err == nil {
+			// hand the handler an untouched copy of the body it would have seen
+			r.Body = ioutil.NopCloser(bytes.NewReader(raw))
+			entry.Payload = m.redactPayload(raw)
+		}
+	}
+
+	start := time.Now()
+	recorder := &loggingResponseWriter{ResponseWriter: rw, status: http.StatusOK}
+	m.next.ServeHTTP(recorder, r)
+
+	entry.Status = recorder.status
+	entry.Duration = time.Since(start)
+	m.log(entry)
+}
+
+// redactFilter replaces the compared value of any sensitive attribute in the filter with a
+// placeholder, leaving the rest of the filter intact.
+func (m *loggingMiddleware) redactFilter(filter string) string {
+	if len(filter) == 0 {
+		return filter
+	}
+	for name := range m.sensitive {
+		filter = sensitiveFilterPattern(name).ReplaceAllString(filter, "${1} ${2} [REDACTED]")
+	}
+	return filter
+}
+
+// redactPayload parses the raw payload as JSON, recursively replaces the values of all sensitive
+// attributes with a placeholder, and returns the redacted payload truncated to the configured limit.
+// Payloads that do not parse as JSON yield an empty excerpt: a payload that cannot be understood
+// cannot be safely redacted either.
+func (m *loggingMiddleware) redactPayload(raw []byte) string {
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ""
+	}
+
+	redacted, err := json.Marshal(m.redactValue(data))
+	if err != nil {
+		return ""
+	}
+
+	if len(redacted) > m.payloadLimit {
+		redacted = redacted[:m.payloadLimit]
+	}
+	return string(redacted)
+}
+
+func (m *loggingMiddleware) redactValue(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if _, ok := m.sensitive[spec.FoldAttributeName(k)]; ok {
+				v[k] = "[REDACTED]"
+			} else {
+				v[k] = m.redactValue(e)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = m.redactValue(e)
+		}
+		return v
+	default:
+		return data
+	}
+}
+
+// sensitiveFilterPattern matches comparisons of the named attribute inside a SCIM filter, such as
+// 'password eq "secret"', capturing the attribute and operator so that only the value is replaced.
+func sensitiveFilterPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(` + regexp.QuoteMeta(name) + `)\s+(eq|ne|co|sw|ew|gt|ge|lt|le)\s+("(?:[^"\\]|\\.)*"|\S+)`)
+}
+
+// resourceIdFromPath extracts the resource id segment from well known resource endpoints.
+func resourceIdFromPath(path string) string {
+	for _, prefix := range []string{"/Users/", "/Groups/"} {
+		if strings.HasPrefix(path, prefix) {
+			id := strings.TrimPrefix(path, prefix)
+			if len(id) > 0 && !strings.Contains(id, "/") {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
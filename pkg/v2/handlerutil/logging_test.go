@@ -0,0 +1,104 @@
+package handlerutil
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []LoggingOption
+		request func() *http.Request
+		handler http.Handler
+		expect  func(t *testing.T, entry RequestLogEntry)
+	}{
+		{
+			name: "records method, path, resource id, status and duration",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/Users/foo", nil)
+			},
+			handler: http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.WriteHeader(http.StatusNotFound)
+			}),
+			expect: func(t *testing.T, entry RequestLogEntry) {
+				assert.Equal(t, http.MethodGet, entry.Method)
+				assert.Equal(t, "/Users/foo", entry.Path)
+				assert.Equal(t, "foo", entry.ResourceID)
+				assert.Equal(t, http.StatusNotFound, entry.Status)
+				assert.True(t, entry.Duration >= 0)
+			},
+		},
+		{
+			name: "redacts password comparison in filter",
+			request: func() *http.Request {
+				filter := url.QueryEscape(`(userName eq "foo") and (password eq "s3cret")`)
+				return httptest.NewRequest(http.MethodGet, "/Users?filter="+filter, nil)
+			},
+			handler: http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}),
+			expect: func(t *testing.T, entry RequestLogEntry) {
+				assert.Equal(t, `(userName eq "foo") and (password eq [REDACTED])`, entry.Filter)
+			},
+		},
+		{
+			name:    "redacts sensitive attributes in payload excerpt",
+			options: []LoggingOption{WithPayloadExcerpt(1024), WithSensitiveAttributes("ssn")},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/Users", strings.NewReader(`
+{
+  "userName": "foo",
+  "password": "s3cret",
+  "ssn": "123-45-6789",
+  "emails": [{"value": "foo@bar.com"}]
+}
+`))
+			},
+			handler: http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				// the handler still sees the original payload
+				raw, err := ioutil.ReadAll(r.Body)
+				require.Nil(t, err)
+				assert.Contains(t, string(raw), "s3cret")
+				rw.WriteHeader(http.StatusCreated)
+			}),
+			expect: func(t *testing.T, entry RequestLogEntry) {
+				assert.Equal(t, http.StatusCreated, entry.Status)
+				assert.Contains(t, entry.Payload, `"password":"[REDACTED]"`)
+				assert.Contains(t, entry.Payload, `"ssn":"[REDACTED]"`)
+				assert.Contains(t, entry.Payload, "foo@bar.com")
+				assert.NotContains(t, entry.Payload, "s3cret")
+			},
+		},
+		{
+			name:    "non json payload is not logged",
+			options: []LoggingOption{WithPayloadExcerpt(1024)},
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/Users", strings.NewReader("not json"))
+			},
+			handler: http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}),
+			expect: func(t *testing.T, entry RequestLogEntry) {
+				assert.Empty(t, entry.Payload)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logged *RequestLogEntry
+			middleware := LoggingMiddleware(test.handler, func(entry RequestLogEntry) {
+				logged = &entry
+			}, test.options...)
+
+			middleware.ServeHTTP(httptest.NewRecorder(), test.request())
+
+			require.NotNil(t, logged)
+			test.expect(t, *logged)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handlerutil
+
+import (
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/satori/go.uuid"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicLogFunc receives the details of a recovered panic: the correlation id included in the error
+// response, the recovered panic value and the stack trace of the panicking goroutine. It adapts the
+// recovery middleware to whatever logging library the integrator uses.
+type PanicLogFunc func(correlationId string, recovered interface{}, stack []byte)
+
+// RecoveryMiddleware wraps the next handler so that a panic while serving a request is recovered and
+// converted into a SCIM formatted 500 error response, instead of tearing down the connection through
+// the server's default panic handling. Each recovered panic is assigned a correlation id which is
+// included in the error detail and handed to log together with the panic value and stack trace, so
+// that a failure reported by a client can be matched to the logged stack. When the request id
+// middleware is installed, the request id doubles as the correlation id. When the response had
+// already been partially written before the panic, no error body is written; the panic is still
+// logged. http.ErrAbortHandler is not recovered, preserving its contract of silently aborting the
+// response.
+func RecoveryMiddleware(next http.Handler, log PanicLogFunc) http.Handler {
+	return &recoveryMiddleware{next: next, log: log}
+}
+
+type recoveryMiddleware struct {
+	next http.Handler
+	log  PanicLogFunc
+}
+
+func (m *recoveryMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	recorder := &recoveryResponseWriter{ResponseWriter: rw}
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+
+		correlationId := requestid.FromContext(r.Context())
+		if len(correlationId) == 0 {
+			correlationId = uuid.NewV4().String()
+		}
+		if m.log != nil {
+			m.log(correlationId, recovered, debug.Stack())
+		}
+
+		if !recorder.wrote {
+			_ = WriteError(rw, fmt.Errorf("%w: unexpected internal error [correlationId=%s]",
+				spec.ErrInternal, correlationId))
+		}
+	}()
+
+	m.next.ServeHTTP(recorder, r)
+}
+
+// recoveryResponseWriter records whether the response was started, so that the middleware knows if
+// an error body can still be written after a panic.
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *recoveryResponseWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoveryResponseWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,87 @@
+package handlerutil
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryMiddlewareWritesScimError(t *testing.T) {
+	var (
+		loggedId    string
+		loggedStack []byte
+	)
+	handler := RecoveryMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), func(correlationId string, recovered interface{}, stack []byte) {
+		loggedId = correlationId
+		assert.Equal(t, "boom", recovered)
+		loggedStack = stack
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/Users/foo", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.NotEmpty(t, loggedId)
+	assert.NotEmpty(t, loggedStack)
+
+	var body struct {
+		Schemas []string `json:"schemas"`
+		Status  int      `json:"status"`
+		Detail  string   `json:"detail"`
+	}
+	require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, []string{"urn:ietf:params:scim:api:messages:2.0:Error"}, body.Schemas)
+	assert.Equal(t, http.StatusInternalServerError, body.Status)
+	assert.True(t, strings.Contains(body.Detail, loggedId), "detail should carry the logged correlation id")
+}
+
+func TestRecoveryMiddlewarePassesThroughWithoutPanic(t *testing.T) {
+	logged := false
+	handler := RecoveryMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}), func(string, interface{}, []byte) {
+		logged = true
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/Users/foo", nil))
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.False(t, logged)
+}
+
+func TestRecoveryMiddlewareDoesNotOverwriteStartedResponse(t *testing.T) {
+	logged := false
+	handler := RecoveryMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("partial"))
+		panic("boom")
+	}), func(string, interface{}, []byte) {
+		logged = true
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/Users/foo", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "partial", rr.Body.String())
+	assert.True(t, logged)
+}
+
+func TestRecoveryMiddlewareDoesNotRecoverAbortHandler(t *testing.T) {
+	handler := RecoveryMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), func(string, interface{}, []byte) {
+		assert.Fail(t, "http.ErrAbortHandler should not be logged")
+	})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/Users/foo", nil))
+	})
+}
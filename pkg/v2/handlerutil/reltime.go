@@ -0,0 +1,79 @@
+package handlerutil
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// relativeTimePattern matches the non-standard relative time tokens: the word "now", optionally
+// followed by a signed offset such as "-1h" or "+30m". Accepted units are s, m, h, d and w.
+var relativeTimePattern = regexp.MustCompile(`\bnow(?:[+-]\d+[smhdw])?\b`)
+
+// TranslateRelativeTimes rewrites relative time tokens (i.e. "now", "now-1h", "now+30m") appearing
+// outside of quoted strings in the filter to quoted dateTime literals anchored at the current time,
+// so that clients and internal jobs can query recently modified resources (i.e.
+// `meta.lastModified gt now-1h`) without computing timestamps themselves. The token syntax is not
+// part of the SCIM standard; deployments should only route filters through this translation behind
+// an explicit opt-in. Filters without tokens are returned unchanged.
+func TranslateRelativeTimes(filter string) string {
+	return translateRelativeTimes(filter, time.Now())
+}
+
+func translateRelativeTimes(filter string, now time.Time) string {
+	var (
+		sb      strings.Builder
+		inQuote bool
+		start   = 0
+	)
+	for i := 0; i <= len(filter); i++ {
+		if i == len(filter) || filter[i] == '"' {
+			segment := filter[start:i]
+			if inQuote {
+				sb.WriteString(segment)
+			} else {
+				sb.WriteString(relativeTimePattern.ReplaceAllStringFunc(segment, func(token string) string {
+					return `"` + now.Add(offsetOf(token)).Format(spec.ISO8601) + `"`
+				}))
+			}
+			if i < len(filter) {
+				sb.WriteByte('"')
+				inQuote = !inQuote
+			}
+			start = i + 1
+		} else if inQuote && filter[i] == '\\' {
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// offsetOf returns the duration denoted by the offset part of the token, or zero for a bare "now".
+func offsetOf(token string) time.Duration {
+	if len(token) == 3 {
+		return 0
+	}
+
+	n, _ := strconv.Atoi(token[4 : len(token)-1])
+	var d time.Duration
+	switch token[len(token)-1] {
+	case 's':
+		d = time.Duration(n) * time.Second
+	case 'm':
+		d = time.Duration(n) * time.Minute
+	case 'h':
+		d = time.Duration(n) * time.Hour
+	case 'd':
+		d = time.Duration(n) * 24 * time.Hour
+	case 'w':
+		d = time.Duration(n) * 7 * 24 * time.Hour
+	}
+
+	if token[3] == '-' {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,55 @@
+package handlerutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateRelativeTimes(t *testing.T) {
+	now := time.Date(2020, 1, 19, 15, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter string
+		expect string
+	}{
+		{
+			name:   "no tokens is returned unchanged",
+			filter: `userName eq "foobar"`,
+			expect: `userName eq "foobar"`,
+		},
+		{
+			name:   "bare now",
+			filter: `meta.lastModified le now`,
+			expect: `meta.lastModified le "2020-01-19T15:15:00"`,
+		},
+		{
+			name:   "negative offset",
+			filter: `meta.lastModified gt now-1h`,
+			expect: `meta.lastModified gt "2020-01-19T14:15:00"`,
+		},
+		{
+			name:   "positive offset in compound filter",
+			filter: `(meta.lastModified gt now-2d) and (meta.lastModified lt now+30m)`,
+			expect: `(meta.lastModified gt "2020-01-17T15:15:00") and (meta.lastModified lt "2020-01-19T15:45:00")`,
+		},
+		{
+			name:   "tokens inside quoted strings are left alone",
+			filter: `displayName eq "now-1h" and meta.lastModified gt now-1w`,
+			expect: `displayName eq "now-1h" and meta.lastModified gt "2020-01-12T15:15:00"`,
+		},
+		{
+			name:   "word containing now is not a token",
+			filter: `userName sw "foo" and nickName eq "nowhere"`,
+			expect: `userName sw "foo" and nickName eq "nowhere"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, translateRelativeTimes(test.filter, now))
+		})
+	}
+}
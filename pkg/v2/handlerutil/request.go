@@ -20,6 +20,8 @@ const (
 	paramCount              = "count"
 	paramAttributes         = "attributes"
 	paramExcludedAttributes = "excludedAttributes"
+
+	headerIdempotencyKey = "Idempotency-Key"
 )
 
 // GetRequestProjection returns a nullable *crud.Projection structure that may encapsulate the attributes or excludedAttributes
@@ -47,7 +49,10 @@ func GetRequestProjection(request *http.Request) (projection *crud.Projection, e
 // CreateRequest returns a parsed *service.CreateRequest directly from *http.Request, and a closer function which should
 // be called after resource processing is done (preferably using defer).
 func CreateRequest(request *http.Request) (cr *service.CreateRequest, closer func()) {
-	cr = &service.CreateRequest{PayloadSource: request.Body}
+	cr = &service.CreateRequest{
+		PayloadSource:  request.Body,
+		IdempotencyKey: request.Header.Get(headerIdempotencyKey),
+	}
 	closer = func() {
 		_ = request.Body.Close()
 	}
@@ -241,3 +246,26 @@ func MatchCriteria(request *http.Request) func(resource *prop.Resource) bool {
 		return true
 	}
 }
+
+// ModifiedSinceCriteria returns a function reporting whether the resource counts as modified with respect to the
+// If-Modified-Since header of the request. When the header is absent, cannot be parsed as an HTTP date, or the
+// resource carries no parsable meta.lastModified, the function returns true, so that the response is served normally.
+// Callers serve the response when the function returns true, and may respond 304 Not Modified otherwise.
+func ModifiedSinceCriteria(request *http.Request) func(resource *prop.Resource) bool {
+	if ifModifiedSince := request.Header.Get("If-Modified-Since"); len(ifModifiedSince) > 0 {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil {
+			return func(resource *prop.Resource) bool {
+				lastModified, err := spec.ParseDateTime(resource.MetaLastModifiedOrEmpty())
+				if err != nil {
+					return true
+				}
+				return lastModified.Time().After(since)
+			}
+		}
+	}
+
+	return func(_ *prop.Resource) bool {
+		return true
+	}
+}
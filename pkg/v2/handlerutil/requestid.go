@@ -0,0 +1,105 @@
+package handlerutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+)
+
+// RequestIdOption customizes the behaviour of RequestIdMiddleware.
+type RequestIdOption interface {
+	apply(middleware *requestIdMiddleware)
+}
+
+// WithRequestIdInErrorDetail returns a RequestIdOption that makes WriteErrorForRequest append the
+// request id to the detail of error responses, so that a failure reported by a client carries the id
+// needed to locate the corresponding server logs. It is off by default: some deployments prefer not
+// to reflect server side identifiers in response bodies.
+func WithRequestIdInErrorDetail() RequestIdOption {
+	return requestIdOptionFunc(func(m *requestIdMiddleware) {
+		m.exposeInErrorDetail = true
+	})
+}
+
+type requestIdOptionFunc func(middleware *requestIdMiddleware)
+
+func (f requestIdOptionFunc) apply(middleware *requestIdMiddleware) {
+	f(middleware)
+}
+
+// RequestIdMiddleware wraps the next handler so that every request carries a correlation id. The id
+// is taken from the request's X-Request-Id header when present and acceptable, and freshly generated
+// otherwise; it is echoed on the response under the same header and placed on the request context,
+// where the logging middlewares, the tracing spans and the audit records pick it up. Incoming ids
+// are rejected and replaced when empty, longer than 128 characters or containing characters outside
+// the visible ASCII range, so that a client cannot inject header or log content through the id.
+func RequestIdMiddleware(next http.Handler, options ...RequestIdOption) http.Handler {
+	m := &requestIdMiddleware{next: next}
+	for _, opt := range options {
+		opt.apply(m)
+	}
+	return m
+}
+
+type requestIdMiddleware struct {
+	next                http.Handler
+	exposeInErrorDetail bool
+}
+
+func (m *requestIdMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(requestid.Header)
+	if !acceptableRequestId(id) {
+		id = requestid.New()
+	}
+
+	rw.Header().Set(requestid.Header, id)
+
+	ctx := requestid.WithContext(r.Context(), id)
+	if m.exposeInErrorDetail {
+		ctx = withExposedRequestId(ctx)
+	}
+
+	m.next.ServeHTTP(rw, r.WithContext(ctx))
+}
+
+// acceptableRequestId reports whether a client supplied request id can be used as is.
+func acceptableRequestId(id string) bool {
+	if len(id) == 0 || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= 0x20 || id[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+type requestIdContextKey int
+
+// exposedRequestIdKey marks a context whose request id may be included in error response details.
+const exposedRequestIdKey requestIdContextKey = iota
+
+func withExposedRequestId(parent context.Context) context.Context {
+	return context.WithValue(parent, exposedRequestIdKey, true)
+}
+
+func requestIdExposed(ctx context.Context) bool {
+	exposed, _ := ctx.Value(exposedRequestIdKey).(bool)
+	return exposed
+}
+
+// WriteErrorForRequest writes the error to the http.ResponseWriter like WriteError, additionally
+// appending the request id carried on the request context to the error detail when the request id
+// middleware was configured with WithRequestIdInErrorDetail. Handlers should prefer it over
+// WriteError whenever the request is at hand.
+func WriteErrorForRequest(r *http.Request, rw http.ResponseWriter, err error) error {
+	if requestIdExposed(r.Context()) {
+		if id := requestid.FromContext(r.Context()); len(id) > 0 {
+			err = fmt.Errorf("%w [requestId=%s]", err, id)
+		}
+	}
+	return WriteError(rw, err)
+}
@@ -0,0 +1,86 @@
+package handlerutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIdMiddlewareAcceptsClientSuppliedId(t *testing.T) {
+	var seen string
+	handler := RequestIdMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seen = requestid.FromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/Users/foo", nil)
+	r.Header.Set(requestid.Header, "client-supplied-id")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	assert.Equal(t, "client-supplied-id", seen)
+	assert.Equal(t, "client-supplied-id", rr.Header().Get(requestid.Header))
+}
+
+func TestRequestIdMiddlewareGeneratesIdWhenAbsent(t *testing.T) {
+	var seen string
+	handler := RequestIdMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seen = requestid.FromContext(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/Users/foo", nil))
+
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, rr.Header().Get(requestid.Header))
+}
+
+func TestRequestIdMiddlewareReplacesUnacceptableId(t *testing.T) {
+	for _, id := range []string{
+		"injected\r\nheader",
+		strings.Repeat("x", 129),
+	} {
+		var seen string
+		handler := RequestIdMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			seen = requestid.FromContext(r.Context())
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/Users/foo", nil)
+		r.Header["X-Request-Id"] = []string{id}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.NotEmpty(t, seen)
+		assert.NotEqual(t, id, seen)
+	}
+}
+
+func TestWriteErrorForRequestIncludesIdWhenConfigured(t *testing.T) {
+	detailOf := func(handler http.Handler) string {
+		r := httptest.NewRequest(http.MethodGet, "/Users/foo", nil)
+		r.Header.Set(requestid.Header, "the-request-id")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+
+		var body struct {
+			Detail string `json:"detail"`
+		}
+		require.Nil(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body.Detail
+	}
+	failing := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = WriteErrorForRequest(r, rw, fmt.Errorf("%w: resource is gone", spec.ErrNotFound))
+	})
+
+	detail := detailOf(RequestIdMiddleware(failing, WithRequestIdInErrorDetail()))
+	assert.True(t, strings.Contains(detail, "[requestId=the-request-id]"))
+
+	detail = detailOf(RequestIdMiddleware(failing))
+	assert.False(t, strings.Contains(detail, "the-request-id"))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"net/http"
@@ -13,9 +14,9 @@ import (
 // WriteResourceToResponse writes the given resource to http.ResponseWriter, respecting the attributes or excludedAttributes
 // specified through options. Any error during the process will be returned.
 // Apart from writing the JSON representation of the resource to body, this method also sets Content-Type header to
-// application/scim+json; sets Location header to resource's meta.location field, if any; and sets ETag header to
-// resource's meta.version field, if any. This method does not set response status, which should be set before calling
-// this method.
+// application/scim+json; sets Location header to resource's meta.location field, if any; sets ETag header to
+// resource's meta.version field, if any; and sets Last-Modified header to resource's meta.lastModified field, if it
+// parses as a dateTime. This method does not set response status, which should be set before calling this method.
 func WriteResourceToResponse(rw http.ResponseWriter, resource *prop.Resource, options ...scimjson.Options) error {
 	raw, jsonErr := scimjson.Serialize(resource, options...)
 	if jsonErr != nil {
@@ -29,11 +30,26 @@ func WriteResourceToResponse(rw http.ResponseWriter, resource *prop.Resource, op
 	if version := resource.MetaVersionOrEmpty(); len(version) > 0 {
 		rw.Header().Set("ETag", version)
 	}
+	if lastModified, err := spec.ParseDateTime(resource.MetaLastModifiedOrEmpty()); err == nil {
+		rw.Header().Set("Last-Modified", lastModified.Time().Format(http.TimeFormat))
+	}
 
 	_, writeErr := rw.Write(raw)
 	return writeErr
 }
 
+// WritePatchedResourceToResponse writes the outcome of a patch operation according to the given
+// configuration. When the resource is nil, or the configuration enables patch.noContent, 204 No
+// Content is written; otherwise, the resource is written just like WriteResourceToResponse. Any
+// error during the process will be returned.
+func WritePatchedResourceToResponse(rw http.ResponseWriter, resource *prop.Resource, config *scim.Config, options ...scimjson.Options) error {
+	if resource == nil || config.PatchNoContent() {
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return WriteResourceToResponse(rw, resource, options...)
+}
+
 // WriteSearchResultToResponse writes the search result to http.ResponseWrite, respecting the attribute or excludedAttributes
 // specified through options. Any error during the process will be returned.
 // This method also sets Content-Type header to application/scim+json. This method does not set response status, which should
@@ -60,8 +76,8 @@ func WriteSearchResultToResponse(rw http.ResponseWriter, searchResult *service.Q
 }
 
 // WriteError writes the error to the http.ResponseWriter. Any error during the process will be returned.
-// If the cause of the error (determined using errors.Unwrap) is a *spec.Error, the cause status and scimType will be
-// used together with the error's message as detail. If the cause is not a *spec.Error, spec.ErrInternal is used instead.
+// If the error, or any error on its chain (determined using errors.As), is a *spec.Error, its status and scimType
+// will be used together with the error's message as detail. Otherwise, spec.ErrInternal is used instead.
 // This method also writes the http status with the error's defined status, and set Content-Type header to application/scim+json.
 func WriteError(rw http.ResponseWriter, err error) error {
 	var errMsg = struct {
@@ -74,8 +90,8 @@ func WriteError(rw http.ResponseWriter, err error) error {
 		Detail:  err.Error(),
 	}
 
-	cause := errors.Unwrap(err)
-	if scimError, ok := cause.(*spec.Error); ok {
+	var scimError *spec.Error
+	if errors.As(err, &scimError) {
 		errMsg.Status = scimError.Status
 		errMsg.ScimType = scimError.Type
 	} else {
@@ -95,6 +111,30 @@ func WriteError(rw http.ResponseWriter, err error) error {
 	return writeErr
 }
 
+// WriteValuesResultToResponse writes a page of multiValued attribute elements to http.ResponseWriter.
+// Any error during the process will be returned. The page is rendered in the shape of a ListResponse,
+// where Resources holds the raw element values instead of full resources. This method also sets
+// Content-Type header to application/scim+json. This method does not set response status, which
+// should be set before calling this method.
+func WriteValuesResultToResponse(rw http.ResponseWriter, valuesResult *service.ValuesResponse) error {
+	render := struct {
+		Schemas      []string      `json:"schemas"`
+		TotalResults int           `json:"totalResults"`
+		StartIndex   int           `json:"startIndex"`
+		ItemsPerPage int           `json:"itemsPerPage"`
+		Resources    []interface{} `json:"Resources,omitempty"`
+	}{
+		Schemas:      []string{"urn:ietf:params:scim:api:messages:2.0:ListResponse"},
+		TotalResults: valuesResult.TotalResults,
+		StartIndex:   valuesResult.StartIndex,
+		ItemsPerPage: valuesResult.ItemsPerPage,
+		Resources:    valuesResult.Values,
+	}
+
+	rw.Header().Set("Content-Type", spec.ApplicationScimJson)
+	return json.NewEncoder(rw).Encode(render)
+}
+
 // SearchResultRendering is the JSON rendering structure for search results. This is very similar to
 // service.QueryResponse except that resources are pre-rendered to adapt for objects serialized using
 // scim json mechanism or go's json mechanism.
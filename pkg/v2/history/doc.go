@@ -0,0 +1,12 @@
+// This package records the version history of resources and restores resources to a prior version.
+//
+// Versions are captured asynchronously by consuming resource change events (see the events package):
+// Mirror applies created and updated events onto a Store, keeping a snapshot of each recorded
+// meta.version. RollbackService then restores a resource to one of its recorded versions by replacing
+// the current state with the snapshot; the replacement runs through the usual resource filters, so a
+// fresh meta.version is generated, and can be decorated with PublishingRollback to fire an updated
+// event just like other mutations.
+//
+// The package ships an in-memory Store suitable for a single process; durable implementations can be
+// provided by implementing Store against the storage of choice.
+package history
@@ -0,0 +1,143 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Version is a recorded snapshot of a resource at one of its past versions.
+type Version struct {
+	// Version is the meta.version of the snapshot.
+	Version string `json:"version"`
+	// Time is the instant the snapshot was recorded, in ISO8601 format.
+	Time string `json:"time"`
+	// Data is the JSON serialization of the resource at this version.
+	Data []byte `json:"-"`
+}
+
+// Store records and serves resource version snapshots. Implementations must be safe for concurrent use.
+type Store interface {
+	// Record stores a snapshot of the resource at its current version. Recording a version that is
+	// already stored is a no-op.
+	Record(ctx context.Context, resource *prop.Resource) error
+	// List returns the recorded versions of the resource by id, oldest first. Data is omitted from
+	// the listed versions.
+	List(ctx context.Context, id string) ([]Version, error)
+	// Get returns the recorded snapshot of the resource at the given version, or an error wrapping
+	// spec.ErrNotFound when the version was not recorded.
+	Get(ctx context.Context, id string, version string) (*Version, error)
+}
+
+// Memory returns an in-memory Store that keeps up to limit versions per resource, discarding the
+// oldest when the limit is exceeded. A non-positive limit keeps all versions.
+func Memory(limit int) Store {
+	return &memoryStore{limit: limit, db: map[string][]Version{}}
+}
+
+type memoryStore struct {
+	sync.RWMutex
+	limit int
+	db    map[string][]Version
+}
+
+func (s *memoryStore) Record(_ context.Context, resource *prop.Resource) error {
+	var (
+		id      = resource.IdOrEmpty()
+		version = resource.MetaVersionOrEmpty()
+	)
+	if len(id) == 0 || len(version) == 0 {
+		return fmt.Errorf("%w: resource lacks id or version for history recording", spec.ErrInternal)
+	}
+
+	raw, err := json.Serialize(resource)
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	for _, v := range s.db[id] {
+		if v.Version == version {
+			return nil
+		}
+	}
+
+	s.db[id] = append(s.db[id], Version{
+		Version: version,
+		Time:    time.Now().Format(spec.ISO8601),
+		Data:    raw,
+	})
+	if s.limit > 0 && len(s.db[id]) > s.limit {
+		s.db[id] = s.db[id][len(s.db[id])-s.limit:]
+	}
+
+	return nil
+}
+
+func (s *memoryStore) List(_ context.Context, id string) ([]Version, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	versions := make([]Version, 0, len(s.db[id]))
+	for _, v := range s.db[id] {
+		versions = append(versions, Version{Version: v.Version, Time: v.Time})
+	}
+	return versions, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id string, version string) (*Version, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, v := range s.db[id] {
+		if v.Version == version {
+			return &Version{Version: v.Version, Time: v.Time, Data: v.Data}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: version '%s' of resource '%s' was not recorded", spec.ErrNotFound, version, id)
+}
+
+// Mirror consumes resource change events from the source channel and records snapshots onto the
+// store. Events of other resource types and deleted events are skipped; recording errors are
+// reported to onError (which may be nil) and do not stop the mirror. Mirror returns when the source
+// channel closes or the context is cancelled. It is typically run on its own goroutine against a
+// subscription of events.InProcessPublisher.
+func Mirror(ctx context.Context, resourceType *spec.ResourceType, source <-chan *events.Event, store Store, onError func(err error)) {
+	report := func(err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-source:
+			if !ok {
+				return
+			}
+			if event.ResourceType != resourceType.Name() {
+				continue
+			}
+
+			switch event.Type {
+			case events.TypeCreated, events.TypeUpdated:
+				resource := prop.NewResource(resourceType)
+				if err := json.Deserialize(event.Data, resource); err != nil {
+					report(err)
+					continue
+				}
+				report(store.Record(ctx, resource))
+			}
+		}
+	}
+}
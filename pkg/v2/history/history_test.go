@@ -0,0 +1,155 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestHistory(t *testing.T) {
+	s := new(HistoryTestSuite)
+	suite.Run(t, s)
+}
+
+type HistoryTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *HistoryTestSuite) TestMemoryStore() {
+	store := Memory(2)
+
+	for _, version := range []string{"v1", "v2", "v3"} {
+		err := store.Record(context.Background(), s.resourceOf("user001", version))
+		assert.Nil(s.T(), err)
+	}
+	// recording an already recorded version is a no-op
+	assert.Nil(s.T(), store.Record(context.Background(), s.resourceOf("user001", "v3")))
+
+	versions, err := store.List(context.Background(), "user001")
+	assert.Nil(s.T(), err)
+	require.Len(s.T(), versions, 2)
+	assert.Equal(s.T(), "v2", versions[0].Version)
+	assert.Equal(s.T(), "v3", versions[1].Version)
+
+	snapshot, err := store.Get(context.Background(), "user001", "v2")
+	assert.Nil(s.T(), err)
+	assert.NotEmpty(s.T(), snapshot.Data)
+
+	_, err = store.Get(context.Background(), "user001", "v1")
+	assert.True(s.T(), errors.Is(err, spec.ErrNotFound))
+}
+
+func (s *HistoryTestSuite) TestRollback() {
+	var (
+		database = db.Memory()
+		store    = Memory(0)
+		svc      = RollbackService(scim.NewConfig(), s.resourceType, database, store, []filter.ByResource{
+			filter.MetaFilter(),
+		})
+	)
+
+	v1 := s.resourceOf("user001", "v1")
+	require.Nil(s.T(), store.Record(context.Background(), v1))
+
+	current := s.resourceOf("user001", "v2")
+	require.Nil(s.T(), database.Insert(context.Background(), current))
+
+	// rolling back to the current version is a no-op
+	resp, err := svc.Do(context.Background(), &RollbackRequest{ResourceID: "user001", Version: "v2"})
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), resp.Rolled)
+
+	// rolling back to an unrecorded version is not found
+	_, err = svc.Do(context.Background(), &RollbackRequest{ResourceID: "user001", Version: "v0"})
+	assert.True(s.T(), errors.Is(err, spec.ErrNotFound))
+
+	// rolling back to v1 restores the snapshot under a fresh version
+	resp, err = svc.Do(context.Background(), &RollbackRequest{ResourceID: "user001", Version: "v1"})
+	assert.Nil(s.T(), err)
+	require.True(s.T(), resp.Rolled)
+	assert.NotEqual(s.T(), "v1", resp.Resource.MetaVersionOrEmpty())
+	assert.NotEqual(s.T(), "v2", resp.Resource.MetaVersionOrEmpty())
+
+	restored, err := database.Get(context.Background(), "user001", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), resp.Resource.MetaVersionOrEmpty(), restored.MetaVersionOrEmpty())
+}
+
+func (s *HistoryTestSuite) resourceOf(id string, version string) *prop.Resource {
+	resource := prop.NewResource(s.resourceType)
+	err := scimjson.Deserialize([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "`+id+`",
+  "userName": "user-`+version+`",
+  "meta": {
+    "resourceType": "User",
+    "version": "`+version+`"
+  }
+}
+`), resource)
+	require.Nil(s.T(), err)
+	return resource
+}
+
+func (s *HistoryTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// RollbackService returns a rollback service that restores a resource to a prior version recorded in
+// the store. The snapshot replaces the current state of the resource and runs through the given
+// resource filters, hence a filter.MetaFilter in the chain generates a fresh meta.version instead of
+// reviving the old one.
+func RollbackService(
+	config *scim.Config,
+	resourceType *spec.ResourceType,
+	database db.DB,
+	store Store,
+	filters []filter.ByResource,
+) Rollback {
+	return &rollbackService{
+		config:       config,
+		resourceType: resourceType,
+		database:     database,
+		store:        store,
+		filters:      filters,
+	}
+}
+
+type (
+	// Rollback resource service
+	Rollback interface {
+		Do(ctx context.Context, req *RollbackRequest) (resp *RollbackResponse, err error)
+	}
+	// Rollback resource request
+	RollbackRequest struct {
+		ResourceID string // id of the resource to be rolled back
+		Version    string // recorded version to restore the resource to
+	}
+	// Rollback resource response
+	RollbackResponse struct {
+		Rolled   bool           // true if the resource was rolled back; false when it is already at the requested version
+		Ref      *prop.Resource // reference resource (before state)
+		Resource *prop.Resource // rolled back resource (after state)
+	}
+)
+
+type rollbackService struct {
+	config       *scim.Config
+	resourceType *spec.ResourceType
+	database     db.DB
+	store        Store
+	filters      []filter.ByResource
+}
+
+func (s *rollbackService) Do(ctx context.Context, req *RollbackRequest) (resp *RollbackResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.rollback")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
+	if len(req.Version) == 0 {
+		err = fmt.Errorf("%w: no version to roll back to", spec.ErrInvalidValue)
+		return
+	}
+
+	ref, err := s.database.Get(ctx, req.ResourceID, nil)
+	if err != nil {
+		return
+	}
+
+	if ref.MetaVersionOrEmpty() == req.Version {
+		resp = &RollbackResponse{
+			Rolled: false,
+			Ref:    ref,
+		}
+		return
+	}
+
+	snapshot, err := s.store.Get(ctx, req.ResourceID, req.Version)
+	if err != nil {
+		return
+	}
+
+	replacement := prop.NewResource(s.resourceType)
+	if err = json.Deserialize(snapshot.Data, replacement); err != nil {
+		return
+	}
+
+	for _, f := range s.filters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = f.FilterRef(ctx, replacement, ref); err != nil {
+			return
+		}
+	}
+
+	if err = s.database.Replace(ctx, ref, replacement); err != nil {
+		return
+	}
+
+	resp = &RollbackResponse{
+		Rolled:   true,
+		Ref:      ref,
+		Resource: replacement,
+	}
+	return
+}
+
+// PublishingRollback returns a Rollback that publishes an updated event after the delegate service
+// actually rolled back the resource, so that downstream consumers (i.e. search indexers, the history
+// store itself) observe the rollback like any other mutation.
+func PublishingRollback(delegate Rollback, publisher events.Publisher) Rollback {
+	return &publishingRollback{delegate: delegate, publisher: publisher}
+}
+
+type publishingRollback struct {
+	delegate  Rollback
+	publisher events.Publisher
+}
+
+func (s *publishingRollback) Do(ctx context.Context, req *RollbackRequest) (*RollbackResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Rolled && resp.Resource != nil {
+		if event, eventErr := events.NewEvent(events.TypeUpdated, resp.Resource); eventErr == nil {
+			_ = s.publisher.Publish(ctx, event)
+		}
+	}
+	return resp, err
+}
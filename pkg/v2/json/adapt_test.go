@@ -1,8 +1,9 @@
-package json
+package json_test
 
 import (
 	"encoding/json"
 	"github.com/imulab/go-scim/pkg/v2/crud"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"os"
@@ -35,7 +36,7 @@ func TestResourceTypeToSerializable(t *testing.T) {
 
 	crud.Register(rt)
 
-	raw, err := Serialize(ResourceTypeToSerializable(rt))
+	raw, err := scimjson.Serialize(scimjson.ResourceTypeToSerializable(rt))
 	assert.Nil(t, err)
 
 	expect := `
@@ -72,7 +73,7 @@ func TestSchemaToSerializable(t *testing.T) {
 	err = json.NewDecoder(f).Decode(sch)
 	assert.Nil(t, err)
 
-	raw, err := Serialize(SchemaToSerializable(sch))
+	raw, err := scimjson.Serialize(scimjson.SchemaToSerializable(sch))
 	assert.Nil(t, err)
 
 	expect := `
@@ -832,3 +833,62 @@ func TestSchemaToSerializable(t *testing.T) {
 
 	assert.JSONEq(t, expect, string(raw))
 }
+
+func TestSchemaToSerializableWithVendorFields(t *testing.T) {
+	raw := `
+{
+  "id": "urn:vendor:schemas:Custom",
+  "name": "Custom",
+  "description": "Custom vendor schema",
+  "attributes": [
+    {
+      "id": "urn:vendor:schemas:Custom:tag",
+      "name": "tag",
+      "type": "string",
+      "_path": "tag",
+      "_index": 0,
+      "x-origin": "hr-system"
+    }
+  ],
+  "x-documentation": "https://vendor.example.com/docs/custom"
+}
+`
+
+	sch := new(spec.Schema)
+	err := json.Unmarshal([]byte(raw), sch)
+	assert.Nil(t, err)
+
+	serialized, err := scimjson.Serialize(scimjson.SchemaToSerializable(sch))
+	assert.Nil(t, err)
+
+	expect := `
+{
+  "schemas": [
+    "urn:ietf:params:scim:schemas:core:2.0:Schema"
+  ],
+  "id": "urn:vendor:schemas:Custom",
+  "meta": {
+    "resourceType": "Schema",
+    "location": "/Schemas/urn:vendor:schemas:Custom"
+  },
+  "name": "Custom",
+  "description": "Custom vendor schema",
+  "attributes": [
+    {
+      "name": "tag",
+      "type": "string",
+      "multiValued": false,
+      "required": false,
+      "caseExact": false,
+      "mutability": "readWrite",
+      "returned": "default",
+      "uniqueness": "none",
+      "x-origin": "hr-system"
+    }
+  ],
+  "x-documentation": "https://vendor.example.com/docs/custom"
+}
+`
+
+	assert.JSONEq(t, expect, string(serialized))
+}
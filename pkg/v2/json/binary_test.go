@@ -0,0 +1,179 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestBinaryAttributeHandling(t *testing.T) {
+	s := new(BinaryTestSuite)
+	suite.Run(t, s)
+}
+
+type BinaryTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *BinaryTestSuite) TestDeserializeValidatesBase64() {
+	tests := []struct {
+		name     string
+		leniency Leniency
+		value    string
+		expect   func(t *testing.T, resource *prop.Resource, err error)
+	}{
+		{
+			name:  "well-formed base64 is accepted",
+			value: "dGVzdA==",
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "dGVzdA==", s.certificateOf(resource))
+			},
+		},
+		{
+			name:  "unpadded base64 is re-padded",
+			value: "dGVzdA",
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "dGVzdA==", s.certificateOf(resource))
+			},
+		},
+		{
+			name:  "malformed base64 is rejected with the offending attribute",
+			value: "this is not base64!",
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+				assert.Contains(t, err.Error(), "x509Certificates")
+			},
+		},
+		{
+			name:  "url-safe base64 is rejected without leniency",
+			value: "-_8=",
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+			},
+		},
+		{
+			name:     "url-safe base64 is re-encoded with leniency",
+			leniency: Leniency{URLSafeBase64: true},
+			value:    "-_8=",
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "+/8=", s.certificateOf(resource))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			SetLeniency(test.leniency)
+			defer SetLeniency(Leniency{})
+
+			resource := prop.NewResource(s.resourceType)
+			err := Deserialize([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "x509Certificates": [{"value": "`+test.value+`"}]
+}
+`), resource)
+			test.expect(t, resource, err)
+		})
+	}
+}
+
+func (s *BinaryTestSuite) TestSerializeBinaryOptions() {
+	resource := prop.NewResource(s.resourceType)
+	require.False(s.T(), resource.Navigator().Replace(map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "u1",
+		"userName": "imulab",
+		"x509Certificates": []interface{}{
+			map[string]interface{}{"value": "dGVzdDEyMw=="},
+		},
+	}).HasError())
+
+	s.T().Run("chunked output", func(t *testing.T) {
+		raw, err := Serialize(resource, BinaryChunk(4))
+		assert.Nil(t, err)
+		assert.Contains(t, string(raw), `dGVz\ndDEy\nMw==`)
+	})
+
+	s.T().Run("oversized value is rejected with the offending attribute", func(t *testing.T) {
+		_, err := Serialize(resource, BinaryLimit(4))
+		assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+		assert.Contains(t, err.Error(), "x509Certificates")
+	})
+
+	s.T().Run("within limit renders unchanged", func(t *testing.T) {
+		raw, err := Serialize(resource, BinaryLimit(1024))
+		assert.Nil(t, err)
+		assert.Contains(t, string(raw), "dGVzdDEyMw==")
+	})
+}
+
+func (s *BinaryTestSuite) certificateOf(resource *prop.Resource) string {
+	nav := resource.Navigator().Dot("x509Certificates").At(0).Dot("value")
+	require.False(s.T(), nav.HasError())
+	return nav.Current().Raw().(string)
+}
+
+func (s *BinaryTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
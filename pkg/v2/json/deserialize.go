@@ -1,6 +1,7 @@
 package json
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
@@ -103,10 +104,30 @@ type deserializeState struct {
 	opCode    int // last read result
 	scan      scanner
 	navigator prop.Navigator
+	pointer   []string // JSON pointer tokens leading to the value currently being parsed
 }
 
 func (d *deserializeState) errInvalidSyntax(msg string, args ...interface{}) error {
-	return fmt.Errorf("%w: %s (pos:%d)", spec.ErrInvalidSyntax, fmt.Sprintf(msg, args...), d.off)
+	return &DeserializationError{
+		Path:    d.navigator.Current().Attribute().Path(),
+		Pointer: d.currentPointer(),
+		Offset:  d.off,
+		Detail:  fmt.Sprintf(msg, args...),
+	}
+}
+
+// currentPointer renders the RFC 6901 JSON pointer to the payload location currently being parsed,
+// or an empty string at the document level.
+func (d *deserializeState) currentPointer() string {
+	if len(d.pointer) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, token := range d.pointer {
+		sb.WriteByte('/')
+		sb.WriteString(escapePointerToken(token))
+	}
+	return sb.String()
 }
 
 // Parses the attribute/field name in a JSON object. This method expects a quoted string and skips through
@@ -164,6 +185,7 @@ kvs:
 			if d.navigator.Error() != nil {
 				return d.navigator.Error()
 			}
+			d.pointer = append(d.pointer, attrName)
 		}
 
 		// Parse field value
@@ -178,6 +200,7 @@ kvs:
 
 		// Exit focus on the field value property
 		d.navigator.Retract()
+		d.pointer = d.pointer[:len(d.pointer)-1]
 
 		// Fast forward to the next field name/value pair, or exit the loop.
 	fastForward:
@@ -231,6 +254,9 @@ func (d *deserializeState) parseMultiValuedProperty() error {
 		if d.opCode == scanBeginLiteral {
 			return d.parseNull()
 		}
+		if d.opCode == scanBeginObject && leniency.ObjectForArray {
+			return d.parseObjectAsArrayElement()
+		}
 		return d.errInvalidSyntax("expects JSON array")
 	}
 
@@ -240,6 +266,7 @@ func (d *deserializeState) parseMultiValuedProperty() error {
 		d.scanWhile(scanSkipSpace)
 	}
 
+	elementIndex := 0
 elements:
 	for d.opCode != scanEndArray {
 		// Create the place-holding element prototype and focus on it
@@ -257,6 +284,7 @@ elements:
 				return d.navigator.Error()
 			}
 		}
+		d.pointer = append(d.pointer, strconv.Itoa(elementIndex))
 
 		// Parse the focused element property
 		err := d.parseSingleValuedProperty()
@@ -266,6 +294,8 @@ elements:
 
 		// Exit the focus
 		d.navigator.Retract()
+		d.pointer = d.pointer[:len(d.pointer)-1]
+		elementIndex++
 
 		// Fast forward to the next element, or exit the loop.
 	fastForward:
@@ -327,6 +357,19 @@ func (d *deserializeState) parseStringProperty() error {
 		return d.errInvalidSyntax("failed to unquote json string for '%s'", p.Attribute().Path())
 	}
 
+	if p.Attribute().Type() == spec.TypeBinary {
+		decoded, err := d.decodeBase64(p, v)
+		if err != nil {
+			return err
+		}
+		// hand the decoded bytes to the property directly so the value is not
+		// re-encoded and re-decoded on the way in
+		if _, err := d.navigator.Current().Replace(decoded); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	if _, err := d.navigator.Current().Replace(v); err != nil {
 		return err
 	}
@@ -334,6 +377,30 @@ func (d *deserializeState) parseStringProperty() error {
 	return nil
 }
 
+// decodeBase64 decodes the binary attribute value, accepting the standard padded alphabet as well as
+// the unpadded standard alphabet. URL-safe values are accepted when the URLSafeBase64 leniency is
+// enabled. Malformed values are rejected with an error naming the offending attribute.
+func (d *deserializeState) decodeBase64(p prop.Property, v string) ([]byte, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+		return decoded, nil
+	}
+
+	if decoded, err := base64.RawStdEncoding.DecodeString(v); err == nil {
+		return decoded, nil
+	}
+
+	if leniency.URLSafeBase64 {
+		for _, encoding := range []*base64.Encoding{base64.URLEncoding, base64.RawURLEncoding} {
+			if decoded, err := encoding.DecodeString(v); err == nil {
+				warnLenient("accepted url-safe base64 value for '%s'", p.Attribute().Path())
+				return decoded, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: value of '%s' is not well-formed base64", spec.ErrInvalidValue, p.Attribute().Path())
+}
+
 // Parses a JSON integer. This method expects an integer literal and the null literal.
 func (d *deserializeState) parseIntegerProperty() error {
 	p := d.navigator.Current()
@@ -361,7 +428,14 @@ func (d *deserializeState) parseIntegerProperty() error {
 
 	val, err := strconv.ParseInt(string(d.data[start:end]), 10, 64)
 	if err != nil {
-		return d.errInvalidSyntax("expects integer value")
+		quoted, ok := d.lenientString(start, end)
+		if ok && leniency.StringNumbers {
+			val, err = strconv.ParseInt(strings.TrimSpace(quoted), 10, 64)
+		}
+		if err != nil {
+			return d.errInvalidSyntax("expects integer value")
+		}
+		warnLenient("coerced string value to integer for '%s'", p.Attribute().Path())
 	}
 
 	if _, err := d.navigator.Current().Replace(val); err != nil {
@@ -405,6 +479,9 @@ func (d *deserializeState) parseBooleanProperty() error {
 			return err
 		}
 	} else {
+		if coerced, err := d.tryLenientBoolean(p, start, end); coerced {
+			return err
+		}
 		if isHackingForMicrosoft, err := d.tryHackForMicrosoftADBooleanIssue(p, start, end); isHackingForMicrosoft {
 			return err
 		}
@@ -441,7 +518,14 @@ func (d *deserializeState) parseDecimalProperty() error {
 
 	val, err := strconv.ParseFloat(string(d.data[start:end]), 64)
 	if err != nil {
-		return d.errInvalidSyntax("expects decimal value")
+		quoted, ok := d.lenientString(start, end)
+		if ok && leniency.StringNumbers {
+			val, err = strconv.ParseFloat(strings.TrimSpace(quoted), 64)
+		}
+		if err != nil {
+			return d.errInvalidSyntax("expects decimal value")
+		}
+		warnLenient("coerced string value to decimal for '%s'", p.Attribute().Path())
 	}
 
 	if _, err := d.navigator.Current().Replace(val); err != nil {
@@ -519,11 +603,70 @@ func (d *deserializeState) isFalseInMicrosoftFormat(start, end int) bool {
 		d.data[start+6] == '"'
 }
 
+// lenientString returns the unquoted content of the literal between start and end when it is a JSON
+// string, for use by the leniency coercions.
+func (d *deserializeState) lenientString(start, end int) (string, bool) {
+	if d.data[start] != '"' || d.data[end-1] != '"' {
+		return "", false
+	}
+	return unquote(d.data[start:end])
+}
+
+// tryLenientBoolean coerces quoted boolean literals such as "True" when the StringBooleans leniency
+// is enabled. The first return value reports whether the coercion was applied.
+func (d *deserializeState) tryLenientBoolean(p prop.Property, start, end int) (bool, error) {
+	if !leniency.StringBooleans {
+		return false, nil
+	}
+
+	quoted, ok := d.lenientString(start, end)
+	if !ok {
+		return false, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(quoted)) {
+	case "true":
+		warnLenient("coerced string value to boolean for '%s'", p.Attribute().Path())
+		_, err := d.navigator.Current().Replace(true)
+		return true, err
+	case "false":
+		warnLenient("coerced string value to boolean for '%s'", p.Attribute().Path())
+		_, err := d.navigator.Current().Replace(false)
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+// parseObjectAsArrayElement deserializes a single JSON object as the only element of the focused
+// multiValued property, applied when the ObjectForArray leniency is enabled.
+func (d *deserializeState) parseObjectAsArrayElement() error {
+	mv, ok := d.navigator.Current().(interface {
+		AppendElement() int
+	})
+	if !ok {
+		return d.errInvalidSyntax("non-multiValued property at json array")
+	}
+
+	warnLenient("coerced single object to array for '%s'", d.navigator.Current().Attribute().Path())
+
+	i := mv.AppendElement()
+	if i < 0 {
+		return fmt.Errorf("%w: failed to create property to host json array element", spec.ErrInternal)
+	}
+	d.navigator.At(i)
+	defer d.navigator.Retract()
+	if d.navigator.Error() != nil {
+		return d.navigator.Error()
+	}
+	return d.parseSingleValuedProperty()
+}
+
 // Microsoft Azure Directory passes boolean values in as "True" and "False". In order to support this popular
 // use case, we include a hack here temporarily for this issue only. Thanks to @plamenGo.
 // See https://github.com/imulab/go-scim/pull/67
 func (d *deserializeState) tryHackForMicrosoftADBooleanIssue(p prop.Property, start, end int) (bool, error) {
-	if strings.ToLower(p.Attribute().Path()) != "active" {
+	if !spec.SameAttributeName(p.Attribute().Path(), "active") {
 		// We are only hacking for the "active" property for now.
 		return false, nil
 	}
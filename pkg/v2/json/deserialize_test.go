@@ -2,6 +2,7 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
@@ -512,6 +513,56 @@ func (s *JsonDeserializeTestSuite) TestDeserializeProperty() {
 	}
 }
 
+func (s *JsonDeserializeTestSuite) TestDeserializationErrorLocation() {
+	tests := []struct {
+		name          string
+		json          string
+		expectPath    string
+		expectPointer string
+	}{
+		{
+			name:          "scalar for complex attribute",
+			json:          `{"name": "foobar"}`,
+			expectPath:    "name",
+			expectPointer: "/name",
+		},
+		{
+			name:          "scalar for multiValued attribute",
+			json:          `{"emails": "foobar"}`,
+			expectPath:    "emails",
+			expectPointer: "/emails",
+		},
+		{
+			name:          "bad value inside array element",
+			json:          `{"emails": [{"value": "one@test.org"}, {"value": 42}]}`,
+			expectPath:    "emails.value",
+			expectPointer: "/emails/1/value",
+		},
+		{
+			name:          "bad value inside nested complex",
+			json:          `{"name": {"givenName": 42}}`,
+			expectPath:    "name.givenName",
+			expectPointer: "/name/givenName",
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resource := prop.NewResource(s.resourceType)
+			err := Deserialize([]byte(test.json), resource)
+			require.NotNil(t, err)
+			assert.True(t, errors.Is(err, spec.ErrInvalidSyntax))
+
+			var de *DeserializationError
+			require.True(t, errors.As(err, &de))
+			assert.Equal(t, test.expectPath, de.Path)
+			assert.Equal(t, test.expectPointer, de.Pointer)
+			assert.True(t, de.Offset > 0)
+			assert.NotEmpty(t, de.Detail)
+		})
+	}
+}
+
 func (s *JsonDeserializeTestSuite) SetupSuite() {
 	for _, each := range []struct {
 		filepath  string
@@ -532,6 +583,13 @@ func (s *JsonDeserializeTestSuite) SetupSuite() {
 				spec.Schemas().Register(parsed.(*spec.Schema))
 			},
 		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
 		{
 			filepath:  "../../../public/resource_types/user_resource_type.json",
 			structure: new(spec.ResourceType),
@@ -0,0 +1,51 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// DeserializationError describes a failure to deserialize a JSON payload onto a resource or property.
+// In addition to a human readable detail, it locates the failure both in terms of the data model, by
+// the full path of the attribute being deserialized, and in terms of the payload, by an RFC 6901 JSON
+// pointer and a byte offset, so that malformed payloads can be troubleshot without reproducing the
+// request. The structured fields can be extracted through errors.As; the error unwraps to
+// spec.ErrInvalidSyntax, so existing errors.Is checks continue to work.
+type DeserializationError struct {
+	// Path is the full path of the attribute being deserialized, i.e. "emails.value", or empty when
+	// the failure occurred at the document level.
+	Path string
+	// Pointer is the RFC 6901 JSON pointer to the offending location in the payload, i.e.
+	// "/emails/0/value", or empty when the failure occurred at the document level.
+	Pointer string
+	// Offset is the byte offset into the payload at which the failure was detected.
+	Offset int
+	// Detail is the human readable description of the failure.
+	Detail string
+}
+
+func (e *DeserializationError) Error() string {
+	var location []string
+	if len(e.Path) > 0 {
+		location = append(location, fmt.Sprintf("path:'%s'", e.Path))
+	}
+	if len(e.Pointer) > 0 {
+		location = append(location, fmt.Sprintf("pointer:'%s'", e.Pointer))
+	}
+	location = append(location, fmt.Sprintf("pos:%d", e.Offset))
+	return fmt.Sprintf("%s: %s (%s)", spec.ErrInvalidSyntax.Type, e.Detail, strings.Join(location, ", "))
+}
+
+func (e *DeserializationError) Unwrap() error {
+	return spec.ErrInvalidSyntax
+}
+
+// escapePointerToken escapes a JSON pointer reference token per RFC 6901: "~" becomes "~0" and "/"
+// becomes "~1".
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+var _ error = (*DeserializationError)(nil)
@@ -10,9 +10,12 @@ type SerializableSchema struct {
 	Sch *spec.Schema
 }
 
+// schemaUrn is the main schema id for Schema as a resource.
+const schemaUrn = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+
 // MainSchemaId returns the main schema id for Schema as a resource.
 func (s *SerializableSchema) MainSchemaId() string {
-	return "urn:ietf:params:scim:schemas:core:2.0:Schema"
+	return schemaUrn
 }
 
 // Visit takes the visitor on a DFS tour of the structure of the Schema resource. This method takes control of what
@@ -100,6 +103,47 @@ func (s *SerializableSchema) visitSchema(visitor prop.Visitor) error {
 	}
 	visitor.EndChildren(dummyMulti)
 
+	if s.Sch.CountVendorFields() > 0 {
+		if err := s.visitVendorFields(schemaUrn, s.Sch.ForEachVendorField, visitor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visitVendorFields takes the visitor through the vendor extension fields enumerated by forEach,
+// rendering each through an ad hoc attribute derived from the dynamic type of its value.
+func (s *SerializableSchema) visitVendorFields(parentId string, forEach func(callback func(name string, value interface{})), visitor prop.Visitor) error {
+	var err error
+	forEach(func(name string, value interface{}) {
+		if err != nil {
+			return
+		}
+		p := prop.NewProperty(spec.MetaAttributes().VendorFieldAttribute(parentId, name, value))
+		if _, replaceErr := p.Replace(value); replaceErr != nil {
+			err = replaceErr
+			return
+		}
+		err = s.visitPropertyTree(p, visitor)
+	})
+	return err
+}
+
+// visitPropertyTree visits the property and, for container properties, recursively visits its children.
+func (s *SerializableSchema) visitPropertyTree(p prop.Property, visitor prop.Visitor) error {
+	if err := visitor.Visit(p); err != nil {
+		return err
+	}
+	if p.Attribute().MultiValued() || p.Attribute().Type() == spec.TypeComplex {
+		visitor.BeginChildren(p)
+		if err := p.ForEachChild(func(_ int, child prop.Property) error {
+			return s.visitPropertyTree(child, visitor)
+		}); err != nil {
+			return err
+		}
+		visitor.EndChildren(p)
+	}
 	return nil
 }
 
@@ -203,5 +247,11 @@ func (s *SerializableSchema) visitAttribute(attr *spec.Attribute, visitor prop.V
 		visitor.EndChildren(dummyMulti)
 	}
 
+	if attr.CountVendorFields() > 0 {
+		if err := s.visitVendorFields(schemaUrn+":attributes", attr.ForEachVendorField, visitor); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,39 @@
+package json
+
+import "fmt"
+
+// Leniency controls the optional coercions the deserializer applies to non-conformant payloads.
+// Azure AD and several HR systems are known to send numbers and booleans as JSON strings, and a
+// single object where the schema expects an array. With the corresponding coercion enabled, such
+// values are accepted and converted instead of being rejected with an invalid syntax error.
+type Leniency struct {
+	// StringNumbers accepts JSON string values for integer and decimal attributes, e.g. "42" for 42.
+	StringNumbers bool
+	// StringBooleans accepts JSON string values for boolean attributes, e.g. "True" for true. The
+	// comparison is case insensitive.
+	StringBooleans bool
+	// ObjectForArray accepts a single JSON object where a multiValued attribute expects a JSON
+	// array, deserializing it as the array's only element.
+	ObjectForArray bool
+	// URLSafeBase64 accepts URL-safe base64 values for binary attributes, re-encoding them to the
+	// standard alphabet.
+	URLSafeBase64 bool
+	// OnWarning is invoked with a description every time a coercion is applied, so that deployments
+	// can log the offending payloads. A nil OnWarning discards the warnings.
+	OnWarning func(warning string)
+}
+
+var leniency Leniency
+
+// SetLeniency installs the package wide deserialization leniency. It is meant to be called once
+// during program initialization and is not safe for concurrent use with ongoing deserialization.
+// Passing a zero valued Leniency restores the default strict behavior.
+func SetLeniency(l Leniency) {
+	leniency = l
+}
+
+func warnLenient(format string, args ...interface{}) {
+	if leniency.OnWarning != nil {
+		leniency.OnWarning(fmt.Sprintf(format, args...))
+	}
+}
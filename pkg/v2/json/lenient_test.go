@@ -0,0 +1,208 @@
+package json
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestLenientDeserialize(t *testing.T) {
+	s := new(LenientDeserializeTestSuite)
+	suite.Run(t, s)
+}
+
+type LenientDeserializeTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *LenientDeserializeTestSuite) TearDownTest() {
+	SetLeniency(Leniency{})
+}
+
+func (s *LenientDeserializeTestSuite) TestDeserializeWithLeniency() {
+	tests := []struct {
+		name     string
+		leniency Leniency
+		json     string
+		expect   func(t *testing.T, resource *prop.Resource, err error)
+	}{
+		{
+			name:     "boolean sent as string",
+			leniency: Leniency{StringBooleans: true},
+			json: `
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "emails": [{"value": "imulab@foo.com", "primary": "True"}]
+}
+`,
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().Dot("emails").At(0).Dot("primary")
+				require.False(t, nav.HasError())
+				assert.Equal(t, true, nav.Current().Raw())
+			},
+		},
+		{
+			name:     "boolean sent as string rejected without leniency",
+			leniency: Leniency{},
+			json: `
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "emails": [{"value": "imulab@foo.com", "primary": "True"}]
+}
+`,
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name:     "single object sent for array",
+			leniency: Leniency{ObjectForArray: true},
+			json: `
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "emails": {"value": "imulab@foo.com", "primary": true}
+}
+`,
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().Dot("emails")
+				require.False(t, nav.HasError())
+				assert.Equal(t, 1, nav.Current().CountChildren())
+			},
+		},
+		{
+			name:     "single object sent for array rejected without leniency",
+			leniency: Leniency{},
+			json: `
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "emails": {"value": "imulab@foo.com", "primary": true}
+}
+`,
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			SetLeniency(test.leniency)
+			defer SetLeniency(Leniency{})
+
+			resource := prop.NewResource(s.resourceType)
+			err := Deserialize([]byte(test.json), resource)
+			test.expect(t, resource, err)
+		})
+	}
+}
+
+func (s *LenientDeserializeTestSuite) TestStringNumberCoercion() {
+	attr := new(spec.Attribute)
+	require.Nil(s.T(), json.Unmarshal([]byte(`
+{
+  "id": "quota",
+  "name": "quota",
+  "type": "integer",
+  "_index": 0,
+  "_path": "quota"
+}
+`), attr))
+
+	SetLeniency(Leniency{StringNumbers: true})
+	defer SetLeniency(Leniency{})
+
+	p := prop.NewProperty(attr)
+	require.Nil(s.T(), DeserializeProperty([]byte(`"42"`), p, false))
+	assert.Equal(s.T(), int64(42), p.Raw())
+}
+
+func (s *LenientDeserializeTestSuite) TestWarningCallback() {
+	var warnings []string
+	SetLeniency(Leniency{
+		StringBooleans: true,
+		OnWarning: func(warning string) {
+			warnings = append(warnings, warning)
+		},
+	})
+	defer SetLeniency(Leniency{})
+
+	resource := prop.NewResource(s.resourceType)
+	require.Nil(s.T(), Deserialize([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "u1",
+  "userName": "imulab",
+  "emails": [{"value": "imulab@foo.com", "primary": "False"}]
+}
+`), resource))
+	require.Len(s.T(), warnings, 1)
+	assert.Contains(s.T(), warnings[0], "primary")
+}
+
+func (s *LenientDeserializeTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package json
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// ListResponseUrn is the URN of the SCIM ListResponse message.
+const ListResponseUrn = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+
+// ListResponse is the parsed form of a SCIM ListResponse message, holding the pagination metadata and
+// the materialized resources of one page.
+type ListResponse struct {
+	TotalResults int
+	StartIndex   int
+	ItemsPerPage int
+	Resources    []*prop.Resource
+}
+
+// DeserializeListResponse parses the payload of a ListResponse message into pagination metadata and
+// materialized resources. Unlike Deserialize, the resource type of each resource is not known up front:
+// it is resolved by matching the ids in the resource's schemas attribute against the resource types
+// registered with spec.ResourceTypes(). This makes the decoder suitable for clients and proxies that
+// consume query results from a remote service provider, potentially spanning several resource types.
+func DeserializeListResponse(raw []byte) (*ListResponse, error) {
+	var adapter struct {
+		Schemas      []string             `json:"schemas"`
+		TotalResults int                  `json:"totalResults"`
+		StartIndex   int                  `json:"startIndex"`
+		ItemsPerPage int                  `json:"itemsPerPage"`
+		Resources    []stdjson.RawMessage `json:"Resources"`
+	}
+	if err := stdjson.Unmarshal(raw, &adapter); err != nil {
+		return nil, fmt.Errorf("%w: payload is not well-formed JSON", spec.ErrInvalidSyntax)
+	}
+
+	if !containsUrn(adapter.Schemas, ListResponseUrn) {
+		return nil, fmt.Errorf("%w: payload is not a ListResponse message", spec.ErrInvalidSyntax)
+	}
+
+	list := &ListResponse{
+		TotalResults: adapter.TotalResults,
+		StartIndex:   adapter.StartIndex,
+		ItemsPerPage: adapter.ItemsPerPage,
+		Resources:    make([]*prop.Resource, 0, len(adapter.Resources)),
+	}
+	for i, each := range adapter.Resources {
+		resourceType, err := resolveResourceType(each)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse resource at index %d: %w", i, err)
+		}
+		resource := prop.NewResource(resourceType)
+		if err := Deserialize(each, resource); err != nil {
+			return nil, fmt.Errorf("failed to parse resource at index %d: %w", i, err)
+		}
+		list.Resources = append(list.Resources, resource)
+	}
+	return list, nil
+}
+
+// resolveResourceType peeks at the schemas attribute of the raw resource and returns the registered
+// resource type whose main schema is among the declared schema ids.
+func resolveResourceType(raw []byte) (*spec.ResourceType, error) {
+	var peek struct {
+		Schemas []string `json:"schemas"`
+	}
+	if err := stdjson.Unmarshal(raw, &peek); err != nil {
+		return nil, fmt.Errorf("%w: resource is not well-formed JSON", spec.ErrInvalidSyntax)
+	}
+
+	for _, id := range peek.Schemas {
+		if resourceType, ok := spec.ResourceTypes().Get(id); ok {
+			return resourceType, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no registered resource type matches schemas %v", spec.ErrInvalidValue, peek.Schemas)
+}
+
+func containsUrn(schemas []string, urn string) bool {
+	for _, each := range schemas {
+		if each == urn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDeserializeListResponse(t *testing.T) {
+	s := new(ListResponseTestSuite)
+	suite.Run(t, s)
+}
+
+type ListResponseTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ListResponseTestSuite) TestDeserialize() {
+	tests := []struct {
+		name    string
+		payload string
+		expect  func(t *testing.T, list *ListResponse, err error)
+	}{
+		{
+			name: "list response with resolvable resources",
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 2,
+  "startIndex": 1,
+  "itemsPerPage": 2,
+  "Resources": [
+    {
+      "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+      "id": "user001",
+      "userName": "foo"
+    },
+    {
+      "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+      "id": "user002",
+      "userName": "bar"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, list *ListResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 2, list.TotalResults)
+				assert.Equal(t, 1, list.StartIndex)
+				assert.Equal(t, 2, list.ItemsPerPage)
+				require.Len(t, list.Resources, 2)
+				assert.Equal(t, "User", list.Resources[0].ResourceType().Name())
+				assert.Equal(t, "foo", list.Resources[0].Navigator().Dot("userName").Current().Raw())
+				assert.Equal(t, "bar", list.Resources[1].Navigator().Dot("userName").Current().Raw())
+			},
+		},
+		{
+			name: "empty page",
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 0,
+  "Resources": []
+}
+`,
+			expect: func(t *testing.T, list *ListResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, list.TotalResults)
+				assert.Len(t, list.Resources, 0)
+			},
+		},
+		{
+			name: "payload is not a list response",
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "user001"
+}
+`,
+			expect: func(t *testing.T, list *ListResponse, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidSyntax))
+			},
+		},
+		{
+			name: "resource with unresolvable schemas",
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 1,
+  "Resources": [
+    {
+      "schemas": ["urn:unknown:Type"],
+      "id": "something"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, list *ListResponse, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			list, err := DeserializeListResponse([]byte(test.payload))
+			test.expect(t, list, err)
+		})
+	}
+}
+
+func (s *ListResponseTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				spec.ResourceTypes().Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
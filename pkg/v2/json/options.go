@@ -1,7 +1,10 @@
 package json
 
 import (
+	"net/url"
 	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
 )
 
 // Include returns Options to include given attributes in JSON serialization. Supplied attributes are still
@@ -16,6 +19,39 @@ func Exclude(attributes ...string) Options {
 	return exclude{attributes: attributes}
 }
 
+// BaseURL returns Options to resolve reference values (i.e. meta.location and $ref) against the given
+// base URL at render time. The scheme, host and path prefix of the base replace those of the persisted
+// value, so that resources render correct absolute URLs for the actual external host (i.e. behind
+// proxies or after domain migrations) regardless of what was persisted. Reference attributes declaring
+// the "external" or "uri" reference types, as well as opaque URIs (i.e. URNs), are left untouched.
+// A base URL that cannot be parsed is ignored.
+func BaseURL(base string) Options {
+	return baseURL{base: base}
+}
+
+// BinaryLimit returns Options to reject rendering binary attribute values larger than the given
+// number of bytes, with an error naming the offending attribute. It protects response payloads from
+// oversized certificates or photos. A non-positive limit disables the check.
+func BinaryLimit(limit int) Options {
+	return binaryLimit{limit: limit}
+}
+
+// BinaryChunk returns Options to break rendered binary attribute values into newline separated
+// chunks of the given size, for consumers expecting MIME style wrapped base64. A non-positive size
+// renders the values unchunked.
+func BinaryChunk(size int) Options {
+	return binaryChunk{size: size}
+}
+
+// Deterministic returns Options to render byte-stable output for equal resource states. Attributes
+// of an object already render in schema declaration order; this option additionally renders the
+// elements of multiValued properties in a stable order (sorted by their rendered form), independent
+// of the insertion and patch history of the property. Use it when response bytes feed into caches,
+// body-derived ETags or golden-file comparisons.
+func Deterministic() Options {
+	return deterministic{}
+}
+
 // JSON serialization options.
 type Options interface {
 	apply(s *serializer, serializable Serializable)
@@ -32,13 +68,23 @@ func (i include) apply(s *serializer, serializable Serializable) {
 	for _, path := range i.attributes {
 		if len(path) > 0 {
 			s.includes = append(s.includes, strings.TrimPrefix(
-				strings.ToLower(path),
-				strings.ToLower(serializable.MainSchemaId()+":")),
+				spec.FoldAttributeName(path),
+				spec.FoldAttributeName(serializable.MainSchemaId()+":")),
 			)
 		}
 	}
 }
 
+type baseURL struct {
+	base string
+}
+
+func (b baseURL) apply(s *serializer, _ Serializable) {
+	if parsed, err := url.Parse(b.base); err == nil && len(parsed.Host) > 0 {
+		s.baseURL = parsed
+	}
+}
+
 type exclude struct {
 	attributes []string
 }
@@ -50,9 +96,47 @@ func (e exclude) apply(s *serializer, serializable Serializable) {
 	for _, path := range e.attributes {
 		if len(path) > 0 {
 			s.excludes = append(s.excludes, strings.TrimPrefix(
-				strings.ToLower(path),
-				strings.ToLower(serializable.MainSchemaId()+":")),
+				spec.FoldAttributeName(path),
+				spec.FoldAttributeName(serializable.MainSchemaId()+":")),
 			)
 		}
 	}
 }
+
+type binaryLimit struct {
+	limit int
+}
+
+func (b binaryLimit) apply(s *serializer, _ Serializable) {
+	if b.limit > 0 {
+		s.binaryLimit = b.limit
+	}
+}
+
+type deterministic struct{}
+
+func (d deterministic) apply(s *serializer, _ Serializable) {
+	s.deterministic = true
+}
+
+type binaryChunk struct {
+	size int
+}
+
+func (b binaryChunk) apply(s *serializer, _ Serializable) {
+	if b.size > 0 {
+		s.binaryChunk = b.size
+	}
+}
+
+// chunk breaks the value into newline separated chunks of the given size.
+func chunk(value string, size int) string {
+	var sb strings.Builder
+	for len(value) > size {
+		sb.WriteString(value[:size])
+		sb.WriteByte('\n')
+		value = value[size:]
+	}
+	sb.WriteString(value)
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package json
+
+import (
+	"sync"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// attributePlans caches rendering metadata per attribute id. Resources of the same resource type
+// share attributes, hence repeated serialization of the same type reuses the cached plans instead
+// of re-encoding property names and re-lowering attribute paths on every call. The cache is never
+// evicted: the set of attributes is fixed once schemas are registered.
+var attributePlans sync.Map
+
+// attributePlan is the pre-computed rendering metadata of a single attribute.
+type attributePlan struct {
+	// nameJSON is the pre-encoded property name, including quotes and the colon (i.e. `"userName":`).
+	// Attribute names are restricted to characters that need no JSON escaping.
+	nameJSON []byte
+	// lowerPath is the pre-lowered attribute path used for attribute and excludedAttributes matching.
+	lowerPath string
+}
+
+// planOf returns the rendering plan of the attribute, computing and caching it on first use.
+func planOf(attr *spec.Attribute) *attributePlan {
+	if cached, ok := attributePlans.Load(attr.ID()); ok {
+		return cached.(*attributePlan)
+	}
+	plan := &attributePlan{
+		nameJSON:  []byte(`"` + attr.Name() + `":`),
+		lowerPath: spec.FoldAttributeName(attr.Path()),
+	}
+	attributePlans.Store(attr.ID(), plan)
+	return plan
+}
@@ -2,10 +2,14 @@ package json
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"io"
 	"math"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -58,14 +62,27 @@ type (
 		container container
 		// index of the element within the container
 		index int
+		// whether the rendered elements of this array frame are to be sorted on EndChildren
+		sorted bool
+		// buffer offset right after the opening bracket of this array frame
+		start int
+		// buffer offsets right after each rendered element of this array frame
+		marks []int
 	}
 	// json serializer state
 	serializer struct {
 		bytes.Buffer
 		includes []string
 		excludes []string
+		baseURL  *url.URL
 		stack    []*frame
 		scratch  [64]byte
+		// maximum rendered size of a binary value in bytes, zero means no limit
+		binaryLimit int
+		// chunk size of rendered binary values, zero renders them unchunked
+		binaryChunk int
+		// whether multiValued elements render in a stable sorted order
+		deterministic bool
 	}
 )
 
@@ -87,7 +104,7 @@ func (s *serializer) ShouldVisit(property prop.Property) bool {
 		if len(s.includes) == 0 && len(s.excludes) == 0 {
 			return !property.IsUnassigned()
 		} else {
-			test := strings.ToLower(property.Attribute().Path())
+			test := planOf(property.Attribute()).lowerPath
 			if len(s.includes) > 0 {
 				for _, include := range s.includes {
 					if include == test || strings.HasPrefix(include, test+".") || strings.HasPrefix(test, include+".") {
@@ -108,7 +125,7 @@ func (s *serializer) ShouldVisit(property prop.Property) bool {
 		}
 	case spec.ReturnedRequest:
 		if len(s.includes) > 0 {
-			test := strings.ToLower(property.Attribute().Path())
+			test := planOf(property.Attribute()).lowerPath
 			for _, include := range s.includes {
 				if include == test || strings.HasPrefix(include, test+".") || strings.HasPrefix(test, include+".") {
 					return true
@@ -141,8 +158,14 @@ func (s *serializer) Visit(property prop.Property) error {
 	}
 
 	switch property.Attribute().Type() {
-	case spec.TypeString, spec.TypeReference, spec.TypeDateTime, spec.TypeBinary:
+	case spec.TypeReference:
+		s.appendString(s.resolveReference(property.Attribute(), property.Raw().(string)))
+	case spec.TypeString, spec.TypeDateTime:
 		s.appendString(property.Raw().(string))
+	case spec.TypeBinary:
+		if err := s.appendBinary(property); err != nil {
+			return err
+		}
 	case spec.TypeInteger:
 		s.appendInteger(property.Raw().(int64))
 	case spec.TypeDecimal:
@@ -154,6 +177,40 @@ func (s *serializer) Visit(property prop.Property) error {
 	}
 
 	s.current().index++
+	s.markElement()
+	return nil
+}
+
+// appendBinary renders a binary property value. When the property exposes its raw bytes through
+// prop.BinarySource and no chunking is requested, the bytes are base64 encoded directly into the
+// output buffer, skipping the intermediate string copies of the Raw form. The base64 alphabet never
+// requires JSON escaping, so the encoded bytes can be written verbatim.
+func (s *serializer) appendBinary(property prop.Property) error {
+	if source, ok := property.(prop.BinarySource); ok && s.binaryChunk <= 0 {
+		encodedLen := base64.StdEncoding.EncodedLen(source.BinarySize())
+		if s.binaryLimit > 0 && encodedLen > s.binaryLimit {
+			return fmt.Errorf("%w: binary value of '%s' exceeds the %d byte render limit",
+				spec.ErrInvalidValue, property.Attribute().Path(), s.binaryLimit)
+		}
+		_ = s.WriteByte('"')
+		encoder := base64.NewEncoder(base64.StdEncoding, s)
+		if _, err := io.Copy(encoder, source.BinaryReader()); err != nil {
+			return fmt.Errorf("%w: failed to render binary value of '%s'", spec.ErrInternal, property.Attribute().Path())
+		}
+		_ = encoder.Close()
+		_ = s.WriteByte('"')
+		return nil
+	}
+
+	v := property.Raw().(string)
+	if s.binaryLimit > 0 && len(v) > s.binaryLimit {
+		return fmt.Errorf("%w: binary value of '%s' exceeds the %d byte render limit",
+			spec.ErrInvalidValue, property.Attribute().Path(), s.binaryLimit)
+	}
+	if s.binaryChunk > 0 {
+		v = chunk(v, s.binaryChunk)
+	}
+	s.appendString(v)
 	return nil
 }
 
@@ -162,6 +219,10 @@ func (s *serializer) BeginChildren(container prop.Property) {
 	case container.Attribute().MultiValued():
 		_ = s.WriteByte('[')
 		s.push(containerArray)
+		if s.deterministic {
+			s.current().sorted = true
+			s.current().start = s.Len()
+		}
 	case container.Attribute().Type() == spec.TypeComplex:
 		_ = s.WriteByte('{')
 		s.push(containerObject)
@@ -173,6 +234,7 @@ func (s *serializer) BeginChildren(container prop.Property) {
 func (s *serializer) EndChildren(container prop.Property) {
 	switch {
 	case container.Attribute().MultiValued():
+		s.sortElements()
 		_ = s.WriteByte(']')
 	case container.Attribute().Type() == spec.TypeComplex:
 		_ = s.WriteByte('}')
@@ -182,20 +244,89 @@ func (s *serializer) EndChildren(container prop.Property) {
 	s.pop()
 	if len(s.stack) > 0 {
 		s.current().index++
+		s.markElement()
+	}
+}
+
+// markElement records the buffer offset at which the element that just finished rendering ends,
+// when the current frame is an array frame subject to sorting.
+func (s *serializer) markElement() {
+	if f := s.current(); f.sorted {
+		f.marks = append(f.marks, s.Len())
+	}
+}
+
+// sortElements rewrites the rendered elements of the current array frame in sorted order of their
+// rendered form, making the output independent of element insertion order. It is called right
+// before the closing bracket is written.
+func (s *serializer) sortElements() {
+	f := s.current()
+	if !f.sorted || len(f.marks) < 2 {
+		return
+	}
+
+	var (
+		buf      = s.Bytes()
+		elements = make([]string, 0, len(f.marks))
+		prev     = f.start
+	)
+	for i, mark := range f.marks {
+		if i > 0 {
+			prev++ // skip the separating comma
+		}
+		elements = append(elements, string(buf[prev:mark]))
+		prev = mark
+	}
+	sort.Strings(elements)
+
+	s.Truncate(f.start)
+	for i, element := range elements {
+		if i > 0 {
+			_ = s.WriteByte(',')
+		}
+		_, _ = s.WriteString(element)
 	}
 }
 
 func (s *serializer) appendPropertyName(attribute *spec.Attribute) {
-	_ = s.WriteByte('"')
-	_, _ = s.WriteString(attribute.Name())
-	_ = s.WriteByte('"')
-	_ = s.WriteByte(':')
+	_, _ = s.Write(planOf(attribute).nameJSON)
 }
 
 func (s *serializer) appendNull() {
 	_, _ = s.WriteString("null")
 }
 
+// resolveReference rewrites the scheme, host and path prefix of a reference value to those of the
+// configured base URL. Reference attributes declaring the "external" or "uri" reference types, as
+// well as opaque URIs (i.e. URNs), are returned as they are.
+func (s *serializer) resolveReference(attr *spec.Attribute, value string) string {
+	if s.baseURL == nil || len(value) == 0 {
+		return value
+	}
+
+	if attr.ExistsReferenceType(func(referenceType string) bool {
+		return referenceType == "external" || referenceType == "uri"
+	}) {
+		return value
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || len(parsed.Opaque) > 0 {
+		return value
+	}
+
+	path := parsed.Path
+	if prefix := strings.TrimSuffix(s.baseURL.Path, "/"); len(prefix) > 0 && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		path = prefix + path
+	}
+
+	rewritten := *parsed
+	rewritten.Scheme = s.baseURL.Scheme
+	rewritten.Host = s.baseURL.Host
+	rewritten.Path = path
+	return rewritten.String()
+}
+
 func (s *serializer) appendString(value string) {
 	_ = s.WriteByte('"')
 	start := 0
@@ -0,0 +1,100 @@
+package json
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+var (
+	benchmarkSetupOnce    sync.Once
+	benchmarkResourceType *spec.ResourceType
+)
+
+func benchmarkResource(b *testing.B) *prop.Resource {
+	benchmarkSetupOnce.Do(func() {
+		for _, filepath := range []string{
+			"../../../public/schemas/core_schema.json",
+			"../../../public/schemas/user_schema.json",
+			"../../../public/schemas/user_enterprise_extension_schema.json",
+		} {
+			f, err := os.Open(filepath)
+			if err != nil {
+				b.Fatal(err)
+			}
+			raw, err := ioutil.ReadAll(f)
+			if err != nil {
+				b.Fatal(err)
+			}
+			schema := new(spec.Schema)
+			if err := json.Unmarshal(raw, schema); err != nil {
+				b.Fatal(err)
+			}
+			spec.Schemas().Register(schema)
+		}
+
+		f, err := os.Open("../../../public/resource_types/user_resource_type.json")
+		if err != nil {
+			b.Fatal(err)
+		}
+		raw, err := ioutil.ReadAll(f)
+		if err != nil {
+			b.Fatal(err)
+		}
+		resourceType := new(spec.ResourceType)
+		if err := json.Unmarshal(raw, resourceType); err != nil {
+			b.Fatal(err)
+		}
+		benchmarkResourceType = resourceType
+	})
+
+	resource := prop.NewResource(benchmarkResourceType)
+	if err := resource.Navigator().Replace(map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "3cc032f5-2361-417f-9e2f-bc80adddf4a3",
+		"userName": "imulab",
+		"name": map[string]interface{}{
+			"formatted":  "Mr. Weinan Qiu",
+			"familyName": "Qiu",
+			"givenName":  "Weinan",
+		},
+		"emails": []interface{}{
+			map[string]interface{}{"value": "imulab@foo.com", "type": "work", "primary": true},
+			map[string]interface{}{"value": "imulab@bar.com", "type": "home"},
+		},
+	}).Error(); err != nil {
+		b.Fatal(err)
+	}
+	return resource
+}
+
+// BenchmarkSerialize measures repeated serialization of the same resource type, the pattern of
+// rendering list responses.
+func BenchmarkSerialize(b *testing.B) {
+	resource := benchmarkResource(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Serialize(resource); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSerializeWithProjection measures repeated serialization with an attributes projection,
+// which exercises the attribute path matching rules.
+func BenchmarkSerializeWithProjection(b *testing.B) {
+	resource := benchmarkResource(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Serialize(resource, Include("userName", "emails")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
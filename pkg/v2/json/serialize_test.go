@@ -30,6 +30,30 @@ func (s *JsonSerializeTestSuite) TestSerialize() {
 		options     []Options
 		expect      func(t *testing.T, raw []byte, err error)
 	}{
+		{
+			name: "resolve references against base URL",
+			getResource: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(s.resourceType)
+				_, err := r.RootProperty().Replace(s.resourceData)
+				assert.Nil(t, err)
+				return r
+			},
+			options: []Options{BaseURL("https://scim.example.com/v2")},
+			expect: func(t *testing.T, raw []byte, err error) {
+				assert.Nil(t, err)
+
+				var parsed struct {
+					Meta struct {
+						Location string `json:"location"`
+					} `json:"meta"`
+					ProfileUrl string `json:"profileUrl"`
+				}
+				assert.Nil(t, json.Unmarshal(raw, &parsed))
+				assert.Equal(t, "https://scim.example.com/v2/Users/3cc032f5-2361-417f-9e2f-bc80adddf4a3", parsed.Meta.Location)
+				// external references are not rewritten
+				assert.Equal(t, "https://identity.imulab.io/profiles/3cc032f5-2361-417f-9e2f-bc80adddf4a3", parsed.ProfileUrl)
+			},
+		},
 		{
 			name: "default",
 			getResource: func(t *testing.T) *prop.Resource {
@@ -199,6 +223,49 @@ func (s *JsonSerializeTestSuite) TestSerialize() {
 	}
 }
 
+func (s *JsonSerializeTestSuite) TestSerializeDeterministic() {
+	original := s.resourceData.(map[string]interface{})
+
+	// same state as s.resourceData, but with the emails elements in reverse order
+	reordered := map[string]interface{}{}
+	for k, v := range original {
+		reordered[k] = v
+	}
+	var (
+		emails   = original["emails"].([]interface{})
+		reversed = make([]interface{}, 0, len(emails))
+	)
+	for i := len(emails) - 1; i >= 0; i-- {
+		reversed = append(reversed, emails[i])
+	}
+	reordered["emails"] = reversed
+
+	resourceOf := func(data interface{}) *prop.Resource {
+		r := prop.NewResource(s.resourceType)
+		_, err := r.RootProperty().Replace(data)
+		require.Nil(s.T(), err)
+		return r
+	}
+
+	first, err := Serialize(resourceOf(original), Deterministic())
+	assert.Nil(s.T(), err)
+	second, err := Serialize(resourceOf(reordered), Deterministic())
+	assert.Nil(s.T(), err)
+
+	// byte-stable regardless of element order, and still the same content
+	assert.Equal(s.T(), string(first), string(second))
+
+	var parsed struct {
+		Emails []struct {
+			Value string `json:"value"`
+		} `json:"emails"`
+	}
+	require.Nil(s.T(), json.Unmarshal(first, &parsed))
+	require.Len(s.T(), parsed.Emails, 2)
+	assert.Equal(s.T(), "imulab@bar.com", parsed.Emails[0].Value)
+	assert.Equal(s.T(), "imulab@foo.com", parsed.Emails[1].Value)
+}
+
 func (s *JsonSerializeTestSuite) SetupSuite() {
 	for _, each := range []struct {
 		filepath  string
@@ -219,6 +286,13 @@ func (s *JsonSerializeTestSuite) SetupSuite() {
 				spec.Schemas().Register(parsed.(*spec.Schema))
 			},
 		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
 		{
 			filepath:  "../../../public/resource_types/user_resource_type.json",
 			structure: new(spec.ResourceType),
@@ -0,0 +1,8 @@
+// This package implements a soft account lifecycle around the "active" attribute.
+//
+// SoftDelete translates resource deletion into a deactivation: instead of removing the resource from
+// the database, the "active" attribute is set to false and the resource is kept. Deactivation side
+// effects, such as revoking the sessions of a deactivated user, are expressed as Hook implementations:
+// HookedReplace and HookedPatch decorate the replace and patch services to invoke the hooks whenever a
+// mutation transitions "active" from true to false, and SoftDelete invokes them on every soft deletion.
+package lifecycle
@@ -0,0 +1,174 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// Hook reacts to the deactivation of a resource. Implementations must be safe for concurrent use.
+type Hook interface {
+	// OnDeactivated is invoked after the resource transitioned to inactive state. Errors are expected
+	// to be handled (i.e. logged and retried) by the implementation and must not affect the outcome
+	// of the mutation that triggered the hook.
+	OnDeactivated(ctx context.Context, resource *prop.Resource) error
+}
+
+// HookFunc adapts an ordinary function to a Hook.
+type HookFunc func(ctx context.Context, resource *prop.Resource) error
+
+func (f HookFunc) OnDeactivated(ctx context.Context, resource *prop.Resource) error {
+	return f(ctx, resource)
+}
+
+// SoftDelete returns a service.Delete that deactivates the resource instead of removing it: the
+// "active" attribute is set to false and the modified resource runs through the given resource
+// filters, so a filter.MetaFilter in the chain advances meta.version. Deleting an already inactive
+// resource is a no-op that still responds successfully. All hooks are invoked after a successful
+// deactivation; hook errors are swallowed as the deactivation has already committed.
+func SoftDelete(
+	config *scim.Config,
+	database db.DB,
+	filters []filter.ByResource,
+	hooks ...Hook,
+) service.Delete {
+	return &softDeleteService{
+		config:   config,
+		database: database,
+		filters:  filters,
+		hooks:    hooks,
+	}
+}
+
+type softDeleteService struct {
+	config   *scim.Config
+	database db.DB
+	filters  []filter.ByResource
+	hooks    []Hook
+}
+
+func (s *softDeleteService) Do(ctx context.Context, req *service.DeleteRequest) (resp *service.DeleteResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.softDelete")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
+	ref, err := s.database.Get(ctx, req.ResourceID, nil)
+	if err != nil {
+		return
+	}
+
+	if s.config.ETagSupported() && req.MatchCriteria != nil {
+		if !req.MatchCriteria(ref) {
+			err = fmt.Errorf("%w: resource does not meet pre condition", spec.ErrConflict)
+			return
+		}
+	}
+
+	if !IsActive(ref) {
+		resp = &service.DeleteResponse{Deleted: ref}
+		return
+	}
+
+	deactivated := ref.Clone()
+	if err = deactivate(deactivated); err != nil {
+		return
+	}
+
+	for _, f := range s.filters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = f.FilterRef(ctx, deactivated, ref); err != nil {
+			return
+		}
+	}
+
+	if err = s.database.Replace(ctx, ref, deactivated); err != nil {
+		return
+	}
+
+	fireHooks(ctx, s.hooks, deactivated)
+
+	resp = &service.DeleteResponse{Deleted: deactivated}
+	return
+}
+
+// HookedReplace returns a service.Replace that invokes the hooks after the delegate service
+// transitioned the resource from active to inactive state.
+func HookedReplace(delegate service.Replace, hooks ...Hook) service.Replace {
+	return &hookedReplace{delegate: delegate, hooks: hooks}
+}
+
+type hookedReplace struct {
+	delegate service.Replace
+	hooks    []Hook
+}
+
+func (s *hookedReplace) Do(ctx context.Context, req *service.ReplaceRequest) (*service.ReplaceResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Replaced && deactivated(resp.Ref, resp.Resource) {
+		fireHooks(ctx, s.hooks, resp.Resource)
+	}
+	return resp, err
+}
+
+// HookedPatch returns a service.Patch that invokes the hooks after the delegate service transitioned
+// the resource from active to inactive state.
+func HookedPatch(delegate service.Patch, hooks ...Hook) service.Patch {
+	return &hookedPatch{delegate: delegate, hooks: hooks}
+}
+
+type hookedPatch struct {
+	delegate service.Patch
+	hooks    []Hook
+}
+
+func (s *hookedPatch) Do(ctx context.Context, req *service.PatchRequest) (*service.PatchResponse, error) {
+	resp, err := s.delegate.Do(ctx, req)
+	if err == nil && resp != nil && resp.Patched && deactivated(resp.Ref, resp.Resource) {
+		fireHooks(ctx, s.hooks, resp.Resource)
+	}
+	return resp, err
+}
+
+// IsActive reports the value of the resource's "active" attribute. A resource whose "active"
+// attribute is unassigned, or whose resource type does not define one, is considered active.
+func IsActive(resource *prop.Resource) bool {
+	nav := resource.Navigator().Dot("active")
+	if nav.HasError() || nav.Current().IsUnassigned() {
+		return true
+	}
+	active, ok := nav.Current().Raw().(bool)
+	return !ok || active
+}
+
+// deactivated reports whether the mutation from the before state to the after state transitioned the
+// resource from active to inactive.
+func deactivated(before *prop.Resource, after *prop.Resource) bool {
+	return before != nil && after != nil && IsActive(before) && !IsActive(after)
+}
+
+// deactivate sets the resource's "active" attribute to false.
+func deactivate(resource *prop.Resource) error {
+	nav := resource.Navigator().Dot("active")
+	if nav.HasError() {
+		return fmt.Errorf("%w: resource type does not define an 'active' attribute", spec.ErrInternal)
+	}
+	return nav.Replace(false).Error()
+}
+
+func fireHooks(ctx context.Context, hooks []Hook, resource *prop.Resource) {
+	for _, hook := range hooks {
+		_ = hook.OnDeactivated(ctx, resource)
+	}
+}
@@ -0,0 +1,185 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestLifecycle(t *testing.T) {
+	s := new(LifecycleTestSuite)
+	suite.Run(t, s)
+}
+
+type LifecycleTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *LifecycleTestSuite) TestSoftDelete() {
+	var (
+		database = db.Memory()
+		hooked   []string
+		svc      = SoftDelete(scim.NewConfig(), database, []filter.ByResource{
+			filter.MetaFilter(),
+		}, HookFunc(func(_ context.Context, resource *prop.Resource) error {
+			hooked = append(hooked, resource.IdOrEmpty())
+			return nil
+		}))
+	)
+
+	require.Nil(s.T(), database.Insert(context.Background(), s.resourceOf("user001", true)))
+
+	resp, err := svc.Do(context.Background(), &service.DeleteRequest{ResourceID: "user001"})
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), IsActive(resp.Deleted))
+	assert.Equal(s.T(), []string{"user001"}, hooked)
+
+	// the resource is kept, deactivated, under a fresh version
+	kept, err := database.Get(context.Background(), "user001", nil)
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), IsActive(kept))
+	assert.NotEqual(s.T(), "v1", kept.MetaVersionOrEmpty())
+
+	// deleting an already inactive resource is a no-op and fires no hook
+	hooked = nil
+	_, err = svc.Do(context.Background(), &service.DeleteRequest{ResourceID: "user001"})
+	assert.Nil(s.T(), err)
+	assert.Empty(s.T(), hooked)
+}
+
+func (s *LifecycleTestSuite) TestHookedReplace() {
+	tests := []struct {
+		name       string
+		before     *prop.Resource
+		after      *prop.Resource
+		replaced   bool
+		expectHook bool
+	}{
+		{
+			name:       "transition to inactive fires the hook",
+			before:     s.resourceOf("user001", true),
+			after:      s.resourceOf("user001", false),
+			replaced:   true,
+			expectHook: true,
+		},
+		{
+			name:       "staying active fires no hook",
+			before:     s.resourceOf("user001", true),
+			after:      s.resourceOf("user001", true),
+			replaced:   true,
+			expectHook: false,
+		},
+		{
+			name:       "unreplaced response fires no hook",
+			before:     s.resourceOf("user001", true),
+			after:      s.resourceOf("user001", false),
+			replaced:   false,
+			expectHook: false,
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			var (
+				fired    bool
+				delegate = replaceFunc(func(_ context.Context, _ *service.ReplaceRequest) (*service.ReplaceResponse, error) {
+					return &service.ReplaceResponse{Replaced: test.replaced, Ref: test.before, Resource: test.after}, nil
+				})
+			)
+
+			_, err := HookedReplace(delegate, HookFunc(func(_ context.Context, _ *prop.Resource) error {
+				fired = true
+				return nil
+			})).Do(context.Background(), &service.ReplaceRequest{ResourceID: "user001"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectHook, fired)
+		})
+	}
+}
+
+type replaceFunc func(ctx context.Context, req *service.ReplaceRequest) (*service.ReplaceResponse, error)
+
+func (f replaceFunc) Do(ctx context.Context, req *service.ReplaceRequest) (*service.ReplaceResponse, error) {
+	return f(ctx, req)
+}
+
+func (s *LifecycleTestSuite) resourceOf(id string, active bool) *prop.Resource {
+	resource := prop.NewResource(s.resourceType)
+	err := scimjson.Deserialize([]byte(`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "id": "`+id+`",
+  "userName": "imulab",
+  "active": `+map[bool]string{true: "true", false: "false"}[active]+`,
+  "meta": {
+    "resourceType": "User",
+    "version": "v1"
+  }
+}
+`), resource)
+	require.Nil(s.T(), err)
+	return resource
+}
+
+func (s *LifecycleTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
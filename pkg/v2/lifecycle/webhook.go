@@ -0,0 +1,57 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+)
+
+// WebhookHook returns a Hook that posts the JSON serialization of the deactivated resource to the
+// given URL, so external systems (i.e. a session store revoking the sessions of a deactivated user)
+// can react to the deactivation. A nil client defaults to http.DefaultClient. Delivery failures are
+// reported to onError (which may be nil) and are otherwise swallowed, as the deactivation has
+// already committed.
+func WebhookHook(url string, client *http.Client, onError func(err error)) Hook {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return HookFunc(func(ctx context.Context, resource *prop.Resource) error {
+		err := deliver(ctx, client, url, resource)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		return err
+	})
+}
+
+func deliver(ctx context.Context, client *http.Client, url string, resource *prop.Resource) error {
+	raw, err := json.Serialize(resource)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("deactivation webhook received status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// This package defines a minimal structured logging abstraction for the library and its integrators.
+// The library packages do not depend on any concrete logging implementation; they emit records
+// through the Logger interface, which defaults to a no-op. Integrators adapt their logging library
+// of choice (i.e. log/slog, zap, zerolog) through the Func adapter, and may derive request scoped
+// loggers carrying shared fields with WithFields. A Logger travels with the request through the
+// context using WithContext and FromContext.
+package log
+
+import "context"
+
+// Level indicates the severity of a log record.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Logger is the minimal structured logging abstraction. Implementations must be safe for concurrent
+// use. The fields map carries the structured context of the record and may be nil.
+type Logger interface {
+	Log(level Level, msg string, fields map[string]interface{})
+}
+
+// None returns a Logger that discards all records. It is the default wherever no Logger was
+// configured.
+func None() Logger {
+	return noneLogger{}
+}
+
+type noneLogger struct{}
+
+func (noneLogger) Log(Level, string, map[string]interface{}) {}
+
+// Func adapts a plain function to the Logger interface, so that any logging library can be plugged
+// in with a short closure.
+type Func func(level Level, msg string, fields map[string]interface{})
+
+func (f Func) Log(level Level, msg string, fields map[string]interface{}) {
+	f(level, msg, fields)
+}
+
+// WithFields returns a Logger that adds the given fields to every record it forwards to the wrapped
+// logger. Record fields win over shared fields on collision. Use it to derive request scoped loggers
+// that stamp every record with, for example, a request id or tenant id.
+func WithFields(logger Logger, fields map[string]interface{}) Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+	return &fieldsLogger{logger: logger, fields: fields}
+}
+
+type fieldsLogger struct {
+	logger Logger
+	fields map[string]interface{}
+}
+
+func (l *fieldsLogger) Log(level Level, msg string, fields map[string]interface{}) {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	l.logger.Log(level, msg, merged)
+}
+
+type contextKey int
+
+// loggerContextKey is the context key under which the Logger is stored.
+const loggerContextKey contextKey = iota
+
+// WithContext returns a copy of the parent context carrying the given Logger. A nil logger returns
+// the parent context unmodified.
+func WithContext(parent context.Context, logger Logger) context.Context {
+	if logger == nil {
+		return parent
+	}
+	return context.WithValue(parent, loggerContextKey, logger)
+}
+
+// FromContext extracts the Logger carried on the context. It returns the no-op Logger when the
+// context carries none, so that callers can log unconditionally.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(Logger); ok {
+		return logger
+	}
+	return None()
+}
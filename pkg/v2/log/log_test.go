@@ -0,0 +1,82 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger captures the last record it received.
+type recordingLogger struct {
+	level  Level
+	msg    string
+	fields map[string]interface{}
+}
+
+func (l *recordingLogger) Log(level Level, msg string, fields map[string]interface{}) {
+	l.level = level
+	l.msg = msg
+	l.fields = fields
+}
+
+func TestFuncAdapter(t *testing.T) {
+	recorded := &recordingLogger{}
+	var logger Logger = Func(func(level Level, msg string, fields map[string]interface{}) {
+		recorded.Log(level, msg, fields)
+	})
+
+	logger.Log(LevelWarn, "hello", map[string]interface{}{"k": "v"})
+
+	assert.Equal(t, LevelWarn, recorded.level)
+	assert.Equal(t, "hello", recorded.msg)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, recorded.fields)
+}
+
+func TestWithFieldsMergesSharedFields(t *testing.T) {
+	recorded := &recordingLogger{}
+	logger := WithFields(recorded, map[string]interface{}{
+		"requestId": "r1",
+		"shared":    "base",
+	})
+
+	logger.Log(LevelInfo, "created", map[string]interface{}{
+		"resourceId": "u1",
+		"shared":     "record",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"requestId":  "r1",
+		"resourceId": "u1",
+		"shared":     "record",
+	}, recorded.fields, "record fields should win over shared fields on collision")
+}
+
+func TestWithFieldsWithoutFieldsReturnsLoggerUnchanged(t *testing.T) {
+	recorded := &recordingLogger{}
+	assert.Equal(t, Logger(recorded), WithFields(recorded, nil))
+}
+
+func TestFromContextFallsBackToNone(t *testing.T) {
+	logger := FromContext(context.Background())
+	assert.NotNil(t, logger)
+	assert.NotPanics(t, func() {
+		logger.Log(LevelError, "discarded", nil)
+	})
+}
+
+func TestLoggerTravelsWithContext(t *testing.T) {
+	recorded := &recordingLogger{}
+	ctx := WithContext(context.Background(), recorded)
+
+	FromContext(ctx).Log(LevelDebug, "found", nil)
+
+	assert.Equal(t, "found", recorded.msg)
+}
+
+func TestLevelString(t *testing.T) {
+	assert.Equal(t, "debug", LevelDebug.String())
+	assert.Equal(t, "info", LevelInfo.String())
+	assert.Equal(t, "warn", LevelWarn.String())
+	assert.Equal(t, "error", LevelError.String())
+}
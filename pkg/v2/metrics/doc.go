@@ -0,0 +1,6 @@
+// This package provides a vendor neutral metrics hook for the SCIM processing pipeline.
+//
+// The package itself does not depend on any particular metrics backend. Instead, callers register a Collector
+// implementation (i.e. a bridge to Prometheus, StatsD or a home grown aggregator) via SetCollector during
+// initialization. When no collector is registered, all recordings are no-ops and incur negligible overhead.
+package metrics
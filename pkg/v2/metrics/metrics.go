@@ -0,0 +1,38 @@
+package metrics
+
+// Collector is the abstraction of a metrics backend capable of recording counters and observations.
+type Collector interface {
+	// Count increments the counter of the given name by delta. Tags further partition the counter
+	// by dimension and may be nil.
+	Count(name string, delta int, tags map[string]string)
+	// Observe records a single observation (i.e. a latency in seconds, or a size in bytes) under the
+	// given name. Tags further partition the observations by dimension and may be nil.
+	Observe(name string, value float64, tags map[string]string)
+}
+
+var collector Collector = noOpCollector{}
+
+// SetCollector registers the global metrics collector. This method is not safe for concurrent use and is
+// expected to be called once during initialization, before any requests are served.
+func SetCollector(c Collector) {
+	if c == nil {
+		c = noOpCollector{}
+	}
+	collector = c
+}
+
+// Count increments a counter using the registered collector.
+func Count(name string, delta int, tags map[string]string) {
+	collector.Count(name, delta, tags)
+}
+
+// Observe records an observation using the registered collector.
+func Observe(name string, value float64, tags map[string]string) {
+	collector.Observe(name, value, tags)
+}
+
+type noOpCollector struct{}
+
+func (_ noOpCollector) Count(_ string, _ int, _ map[string]string) {}
+
+func (_ noOpCollector) Observe(_ string, _ float64, _ map[string]string) {}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id returns a Hasher backed by the argon2id algorithm using sensible default parameters
+// (64MB memory, 1 iteration, 4 lanes). Hashes are encoded in the PHC string format
+// (i.e. "$argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>").
+func Argon2id() Hasher {
+	return argon2idHasher{
+		memory:  64 * 1024,
+		time:    1,
+		threads: 4,
+		saltLen: 16,
+		keyLen:  32,
+	}
+}
+
+type argon2idHasher struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	saltLen int
+	keyLen  uint32
+}
+
+func (h argon2idHasher) Hash(plaintext []byte) (string, error) {
+	salt := make([]byte, h.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("%w: failed to generate password salt", spec.ErrInternal)
+	}
+
+	key := argon2.IDKey(plaintext, salt, h.time, h.memory, h.threads, h.keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h argon2idHasher) Verify(hashed string, plaintext []byte) error {
+	var (
+		version            int
+		memory, time       uint32
+		threads            uint8
+		saltPart, hashPart string
+	)
+
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return fmt.Errorf("%w: malformed argon2id hash", spec.ErrInternal)
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return fmt.Errorf("%w: malformed argon2id hash", spec.ErrInternal)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return fmt.Errorf("%w: malformed argon2id hash", spec.ErrInternal)
+	}
+	saltPart, hashPart = parts[4], parts[5]
+
+	salt, err := base64.RawStdEncoding.DecodeString(saltPart)
+	if err != nil {
+		return fmt.Errorf("%w: malformed argon2id hash", spec.ErrInternal)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(hashPart)
+	if err != nil {
+		return fmt.Errorf("%w: malformed argon2id hash", spec.ErrInternal)
+	}
+
+	computed := argon2.IDKey(plaintext, salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, computed) != 1 {
+		return fmt.Errorf("%w: password does not match", spec.ErrInvalidValue)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package password
+
+import (
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BCrypt returns a Hasher backed by the bcrypt algorithm with the given cost. A cost less than
+// the bcrypt minimum falls back to bcrypt.DefaultCost.
+func BCrypt(cost int) Hasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	return bcryptHasher{cost: cost}
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h bcryptHasher) Hash(plaintext []byte) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(plaintext, h.cost)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to bcrypt password", spec.ErrInternal)
+	}
+	return string(hashed), nil
+}
+
+func (h bcryptHasher) Verify(hashed string, plaintext []byte) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), plaintext); err != nil {
+		return fmt.Errorf("%w: password does not match", spec.ErrInvalidValue)
+	}
+	return nil
+}
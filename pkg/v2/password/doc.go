@@ -0,0 +1,4 @@
+// This package defines the password hashing abstraction used by the server to protect the
+// password attribute, along with bcrypt and argon2id implementations and a verification
+// entry point for authentication integrations.
+package password
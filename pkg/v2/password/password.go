@@ -0,0 +1,33 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Hasher abstracts a one-way password hashing algorithm. Implementations produce self describing
+// hashes (i.e. modular crypt or PHC formatted strings) so that the algorithm and its parameters
+// can be recovered from the hash alone at verification time.
+type Hasher interface {
+	// Hash returns the hashed form of the given plaintext. The plaintext is never retained.
+	Hash(plaintext []byte) (string, error)
+	// Verify compares the given plaintext against a hash previously produced by this Hasher.
+	// It returns nil when they match, or an error wrapping spec.ErrInvalidValue when they do not.
+	Verify(hashed string, plaintext []byte) error
+}
+
+// Verify compares plaintext against a hash produced by any of the built-in hashers. The algorithm
+// is detected from the hash prefix, which allows verification to keep working after the server
+// switches its configured algorithm. It returns nil on a match.
+func Verify(hashed string, plaintext []byte) error {
+	switch {
+	case strings.HasPrefix(hashed, "$2"):
+		return BCrypt(0).Verify(hashed, plaintext)
+	case strings.HasPrefix(hashed, "$argon2id$"):
+		return Argon2id().Verify(hashed, plaintext)
+	default:
+		return fmt.Errorf("%w: unrecognized password hash format", spec.ErrInternal)
+	}
+}
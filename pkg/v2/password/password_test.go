@@ -0,0 +1,46 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashers(t *testing.T) {
+	tests := []struct {
+		name   string
+		hasher Hasher
+	}{
+		{
+			name:   "bcrypt",
+			hasher: BCrypt(5),
+		},
+		{
+			name:   "argon2id",
+			hasher: Argon2id(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hashed, err := test.hasher.Hash([]byte("s3cret"))
+			require.Nil(t, err)
+			assert.NotEqual(t, "s3cret", hashed)
+
+			// verification through the hasher itself
+			assert.Nil(t, test.hasher.Verify(hashed, []byte("s3cret")))
+			assert.True(t, errors.Is(test.hasher.Verify(hashed, []byte("wrong")), spec.ErrInvalidValue))
+
+			// verification through algorithm detection
+			assert.Nil(t, Verify(hashed, []byte("s3cret")))
+			assert.True(t, errors.Is(Verify(hashed, []byte("wrong")), spec.ErrInvalidValue))
+		})
+	}
+}
+
+func TestVerifyUnknownFormat(t *testing.T) {
+	assert.NotNil(t, Verify("not_a_hash", []byte("s3cret")))
+}
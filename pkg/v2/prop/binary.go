@@ -1,16 +1,29 @@
 package prop
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"hash/fnv"
+	"io"
 )
 
+// BinarySource is implemented by properties holding raw binary values that can be accessed without going
+// through the base64 encoded string form returned by Raw. It allows consumers such as the json serializer
+// to stream the bytes instead of copying them into intermediate strings.
+type BinarySource interface {
+	// BinaryReader returns a reader over the raw (decoded) bytes of the value.
+	BinaryReader() io.Reader
+	// BinarySize returns the number of raw bytes in the value.
+	BinarySize() int
+}
+
 // NewBinary creates a new binary property associated with attribute.
 func NewBinary(attr *spec.Attribute) Property {
 	ensureSingularBinaryType(attr)
-	p := binaryProperty{attr: attr, subscribers: []Subscriber{}}
+	p := binaryProperty{attr: attr, subscribers: []Subscriber{}, maxBytes: binarySizeLimitOf(attr)}
 	attr.ForEachAnnotation(func(annotation string, params map[string]interface{}) {
 		if subscriber, ok := SubscriberFactory().Create(annotation, &p, params); ok {
 			p.subscribers = append(p.subscribers, subscriber)
@@ -19,6 +32,23 @@ func NewBinary(attr *spec.Attribute) Property {
 	return &p
 }
 
+// binarySizeLimitOf returns the value of the "maxBytes" parameter of the @BinarySizeLimit annotation on
+// the attribute, or zero when the annotation or the parameter is absent.
+func binarySizeLimitOf(attr *spec.Attribute) int {
+	params, ok := attr.Annotation(annotation.BinarySizeLimit)
+	if !ok {
+		return 0
+	}
+	switch v := params["maxBytes"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
 // NewBinaryOf creates a new binary property of given value associated with attribute.
 func NewBinaryOf(attr *spec.Attribute, value string) Property {
 	p := NewBinary(attr)
@@ -41,6 +71,7 @@ type binaryProperty struct {
 	hash        uint64
 	dirty       bool
 	subscribers []Subscriber
+	maxBytes    int // maximum decoded size in bytes, zero means no limit
 }
 
 func (p *binaryProperty) Attribute() *spec.Attribute {
@@ -92,6 +123,7 @@ func (p *binaryProperty) Clone() Property {
 		hash:        p.hash,
 		dirty:       p.dirty,
 		subscribers: p.subscribers,
+		maxBytes:    p.maxBytes,
 	}
 	copy(c.value, p.value)
 	return &c
@@ -109,14 +141,24 @@ func (p *binaryProperty) Replace(value interface{}) (*Event, error) {
 		return p.Delete()
 	}
 
-	s, ok := value.(string)
-	if !ok {
+	var b64 []byte
+	switch v := value.(type) {
+	case string:
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value for '%s' is not base64 encoded", spec.ErrInvalidValue, p.attr.Path())
+		}
+		b64 = decoded
+	case []byte:
+		// raw bytes, already decoded by the caller (i.e. the json deserializer)
+		b64 = v
+	default:
 		return nil, fmt.Errorf("%w: value is incompatible with '%s'", spec.ErrInvalidValue, p.attr.Path())
 	}
 
-	b64, err := base64.StdEncoding.DecodeString(s)
-	if err != nil {
-		return nil, fmt.Errorf("%w: value for '%s' is not base64 encoded", spec.ErrInvalidValue, p.attr.Path())
+	if p.maxBytes > 0 && len(b64) > p.maxBytes {
+		return nil, fmt.Errorf("%w: value for '%s' exceeds the maximum size of %d bytes",
+			spec.ErrInvalidValue, p.attr.Path(), p.maxBytes)
 	}
 
 	p.dirty = true
@@ -202,7 +244,16 @@ func (p *binaryProperty) Present() bool {
 	return len(p.value) > 0
 }
 
+func (p *binaryProperty) BinaryReader() io.Reader {
+	return bytes.NewReader(p.value)
+}
+
+func (p *binaryProperty) BinarySize() int {
+	return len(p.value)
+}
+
 var (
-	_ EqCapable = (*binaryProperty)(nil)
-	_ PrCapable = (*binaryProperty)(nil)
+	_ EqCapable    = (*binaryProperty)(nil)
+	_ PrCapable    = (*binaryProperty)(nil)
+	_ BinarySource = (*binaryProperty)(nil)
 )
@@ -6,6 +6,7 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"io/ioutil"
 	"strings"
 	"testing"
 )
@@ -351,6 +352,15 @@ func (s *BinaryPropertyTestSuite) TestReplace() {
 				assert.Equal(t, s.base64("world"), raw)
 			},
 		},
+		{
+			name:  "replace with raw bytes",
+			prop:  NewBinary(s.standardAttr),
+			value: []byte("hello"),
+			expect: func(t *testing.T, raw interface{}, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, s.base64("hello"), raw)
+			},
+		},
 		{
 			name:  "replace incompatible value",
 			prop:  NewBinary(s.standardAttr),
@@ -463,6 +473,71 @@ func (s *BinaryPropertyTestSuite) TestPresent() {
 	}
 }
 
+func (s *BinaryPropertyTestSuite) TestSizeLimit() {
+	attr := s.mustAttribute(s.T(), strings.NewReader(`
+{
+  "id": "urn:ietf:params:scim:schemas:2.0:User:x509Certificates.value",
+  "name": "value",
+  "type": "binary",
+  "_path": "x509Certificates.value",
+  "_index": 100,
+  "_annotations": {
+    "@BinarySizeLimit": {
+      "maxBytes": 8
+    }
+  }
+}`))
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:  "value within limit is accepted",
+			value: s.base64("12345678"),
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:  "value exceeding limit is rejected",
+			value: s.base64("123456789"),
+			expect: func(t *testing.T, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrInvalidValue, errors.Unwrap(err))
+			},
+		},
+		{
+			name:  "raw bytes exceeding limit are rejected",
+			value: []byte("123456789"),
+			expect: func(t *testing.T, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrInvalidValue, errors.Unwrap(err))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			_, err := NewBinary(attr).Replace(test.value)
+			test.expect(t, err)
+		})
+	}
+}
+
+func (s *BinaryPropertyTestSuite) TestBinarySource() {
+	p := NewBinaryOf(s.standardAttr, s.base64("hello world"))
+
+	source, ok := p.(BinarySource)
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), len("hello world"), source.BinarySize())
+
+	raw, err := ioutil.ReadAll(source.BinaryReader())
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "hello world", string(raw))
+}
+
 func (s *BinaryPropertyTestSuite) base64(str string) string {
 	return base64.StdEncoding.EncodeToString([]byte(str))
 }
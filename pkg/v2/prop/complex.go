@@ -6,10 +6,11 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"hash/fnv"
-	"strings"
 )
 
-// NewComplex creates a new complex property associated with attribute. All sub attributes are created.
+// NewComplex creates a new complex property associated with attribute. All sub attributes are created,
+// except for schema extension root attributes, whose subtree is attached lazily and only materialized
+// when the resource actually carries data for the extension, or when it is first navigated.
 func NewComplex(attr *spec.Attribute) Property {
 	ensureSingularComplexType(attr)
 	p := complexProperty{
@@ -24,8 +25,12 @@ func NewComplex(attr *spec.Attribute) Property {
 		}
 	})
 	_ = attr.ForEachSubAttribute(func(subAttribute *spec.Attribute) error {
-		p.subProps = append(p.subProps, NewProperty(subAttribute))
-		p.nameIndex[strings.ToLower(subAttribute.Name())] = len(p.subProps) - 1
+		if _, ok := subAttribute.Annotation(annotation.SchemaExtensionRoot); ok {
+			p.subProps = append(p.subProps, newLazyExtension(subAttribute))
+		} else {
+			p.subProps = append(p.subProps, NewProperty(subAttribute))
+		}
+		p.nameIndex[spec.FoldAttributeName(subAttribute.Name())] = len(p.subProps) - 1
 		return nil
 	})
 	return &p
@@ -155,7 +160,7 @@ func (p *complexProperty) Clone() Property {
 	}
 	for i, sp := range p.subProps {
 		c.subProps = append(c.subProps, sp.Clone())
-		c.nameIndex[strings.ToLower(sp.Attribute().Name())] = i
+		c.nameIndex[spec.FoldAttributeName(sp.Attribute().Name())] = i
 	}
 	return &c
 }
@@ -173,7 +178,7 @@ func (p *complexProperty) Add(value interface{}) (*Event, error) {
 	wasUnassigned := p.IsUnassigned()
 
 	for k, v := range m {
-		i, ok := p.nameIndex[strings.ToLower(k)]
+		i, ok := p.nameIndex[spec.FoldAttributeName(k)]
 		if !ok {
 			continue
 		}
@@ -259,7 +264,7 @@ func (p *complexProperty) FindChild(criteria func(child Property) bool) Property
 func (p *complexProperty) ChildAtIndex(index interface{}) (Property, error) {
 	switch i := index.(type) {
 	case string:
-		ni, ok := p.nameIndex[strings.ToLower(i)]
+		ni, ok := p.nameIndex[spec.FoldAttributeName(i)]
 		if !ok {
 			return nil, fmt.Errorf("%w: '%s' does not have child '%s'", spec.ErrInvalidPath, p.attr.Path(), i)
 		}
@@ -86,7 +86,7 @@ func (p *dateTimeProperty) Clone() Property {
 		subscribers: p.subscribers,
 	}
 	if p.value != nil {
-		v, _ := time.Parse(spec.ISO8601, p.Raw().(string))
+		v := *(p.value)
 		c.value = &v
 	}
 	return c
@@ -168,11 +168,11 @@ func (p *dateTimeProperty) mustToISO8601() string {
 }
 
 func (p *dateTimeProperty) fromISO8601(value string) (time.Time, error) {
-	t, err := time.Parse(spec.ISO8601, value)
+	dt, err := spec.ParseDateTime(value)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("%w, value for '%s' does not conform to ISO8601", spec.ErrInvalidValue, p.attr.Path())
 	}
-	return t, nil
+	return dt.Time(), nil
 }
 
 func (p *dateTimeProperty) EqualsTo(value interface{}) bool {
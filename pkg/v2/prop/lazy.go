@@ -0,0 +1,147 @@
+package prop
+
+import (
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// newLazyExtension creates a property for a schema extension root attribute whose subtree is only
+// materialized when first needed.
+func newLazyExtension(attr *spec.Attribute) Property {
+	ensureSingularComplexType(attr)
+	return &lazyProperty{attr: attr}
+}
+
+// lazyProperty is a stand-in for the complex property of a schema extension root attribute. A resource
+// type may register several extensions of which a given resource typically populates only a few, so
+// building every extension subtree up front wastes both memory and traversal effort. The stand-in
+// answers the vacant state queries (unassigned, not dirty, zero hash) without constructing anything,
+// and only builds the real complex property, together with its subscribers, when data is added or the
+// subtree is first navigated. Because serialization skips unassigned properties before requesting
+// their children, resources that never touch an extension never pay for it.
+type lazyProperty struct {
+	attr  *spec.Attribute
+	inner Property
+}
+
+// materialize constructs the enclosed complex property on first invocation and returns it.
+func (p *lazyProperty) materialize() Property {
+	if p.inner == nil {
+		p.inner = NewComplex(p.attr)
+	}
+	return p.inner
+}
+
+func (p *lazyProperty) Attribute() *spec.Attribute {
+	return p.attr
+}
+
+func (p *lazyProperty) Raw() interface{} {
+	if p.inner == nil {
+		return map[string]interface{}{}
+	}
+	return p.inner.Raw()
+}
+
+func (p *lazyProperty) IsUnassigned() bool {
+	if p.inner == nil {
+		return true
+	}
+	return p.inner.IsUnassigned()
+}
+
+func (p *lazyProperty) Dirty() bool {
+	if p.inner == nil {
+		return false
+	}
+	return p.inner.Dirty()
+}
+
+func (p *lazyProperty) Hash() uint64 {
+	if p.inner == nil {
+		return 0
+	}
+	return p.inner.Hash()
+}
+
+func (p *lazyProperty) Matches(another Property) bool {
+	if p.inner == nil {
+		// mirrors the unassigned case of the complex property: attributes and children count line
+		// up, and the counterpart hashes to the unassigned value.
+		return p.attr.Equals(another.Attribute()) &&
+			p.CountChildren() == another.CountChildren() &&
+			another.Hash() == 0
+	}
+	return p.inner.Matches(another)
+}
+
+func (p *lazyProperty) Clone() Property {
+	c := lazyProperty{attr: p.attr}
+	if p.inner != nil {
+		c.inner = p.inner.Clone()
+	}
+	return &c
+}
+
+func (p *lazyProperty) Add(value interface{}) (*Event, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return p.materialize().Add(value)
+}
+
+func (p *lazyProperty) Replace(value interface{}) (*Event, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return p.materialize().Replace(value)
+}
+
+func (p *lazyProperty) Delete() (*Event, error) {
+	if p.inner == nil {
+		return nil, nil
+	}
+	return p.inner.Delete()
+}
+
+func (p *lazyProperty) Notify(events *Events) error {
+	if p.inner == nil {
+		return nil
+	}
+	return p.inner.Notify(events)
+}
+
+func (p *lazyProperty) CountChildren() int {
+	if p.inner == nil {
+		n := 0
+		_ = p.attr.ForEachSubAttribute(func(_ *spec.Attribute) error {
+			n++
+			return nil
+		})
+		return n
+	}
+	return p.inner.CountChildren()
+}
+
+func (p *lazyProperty) ForEachChild(callback func(index int, child Property) error) error {
+	return p.materialize().ForEachChild(callback)
+}
+
+func (p *lazyProperty) FindChild(criteria func(child Property) bool) Property {
+	return p.materialize().FindChild(criteria)
+}
+
+func (p *lazyProperty) ChildAtIndex(index interface{}) (Property, error) {
+	return p.materialize().ChildAtIndex(index)
+}
+
+func (p *lazyProperty) Present() bool {
+	if p.inner == nil {
+		return false
+	}
+	return p.inner.(PrCapable).Present()
+}
+
+var (
+	_ Property  = (*lazyProperty)(nil)
+	_ PrCapable = (*lazyProperty)(nil)
+)
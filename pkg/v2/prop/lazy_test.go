@@ -0,0 +1,127 @@
+package prop
+
+import (
+	"encoding/json"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLazyExtensionProperty(t *testing.T) {
+	attrFunc := func(t *testing.T) *spec.Attribute {
+		attr := new(spec.Attribute)
+		require.Nil(t, json.Unmarshal([]byte(`
+{
+  "id": "urn:test:Root",
+  "name": "root",
+  "type": "complex",
+  "_path": "",
+  "_index": 0,
+  "_annotations": {
+    "@Root": {},
+    "@SyncSchema": {}
+  },
+  "subAttributes": [
+    {
+      "id": "schemas",
+      "name": "schemas",
+      "type": "string",
+      "multiValued": true,
+      "_path": "schemas",
+      "_index": 0,
+      "_annotations": {
+        "@AutoCompact": {}
+      }
+    },
+    {
+      "id": "userName",
+      "name": "userName",
+      "type": "string",
+      "_path": "userName",
+      "_index": 1
+    },
+    {
+      "id": "urn:test:Extension",
+      "name": "urn:test:Extension",
+      "type": "complex",
+      "_path": "urn:test:Extension",
+      "_index": 2,
+      "_annotations": {
+        "@StateSummary": {},
+        "@SchemaExtensionRoot": {}
+      },
+      "subAttributes": [
+        {
+          "id": "urn:test:Extension:employeeNumber",
+          "name": "employeeNumber",
+          "type": "string",
+          "_path": "urn:test:Extension:employeeNumber",
+          "_index": 0
+        }
+      ]
+    }
+  ]
+}
+`), attr))
+		return attr
+	}
+
+	extensionOf := func(t *testing.T, root Property) *lazyProperty {
+		var ext *lazyProperty
+		_ = root.ForEachChild(func(_ int, child Property) error {
+			if child.Attribute().ID() == "urn:test:Extension" {
+				ext = child.(*lazyProperty)
+			}
+			return nil
+		})
+		require.NotNil(t, ext)
+		return ext
+	}
+
+	t.Run("extension subtree is not materialized up front", func(t *testing.T) {
+		root := NewComplex(attrFunc(t))
+		ext := extensionOf(t, root)
+		assert.Nil(t, ext.inner)
+		assert.True(t, ext.IsUnassigned())
+		assert.False(t, ext.Dirty())
+		assert.Zero(t, ext.Hash())
+		assert.Equal(t, 1, ext.CountChildren())
+		assert.Nil(t, ext.inner, "state queries should not materialize the subtree")
+	})
+
+	t.Run("data outside the extension does not materialize it", func(t *testing.T) {
+		root := NewComplex(attrFunc(t))
+		assert.False(t, Navigate(root).Dot("userName").Replace("imulab").HasError())
+		assert.Nil(t, extensionOf(t, root).inner)
+	})
+
+	t.Run("first navigation materializes and assignment adds the schemas entry", func(t *testing.T) {
+		root := NewComplex(attrFunc(t))
+		assert.False(t, Navigate(root).Dot("urn:test:Extension").Dot("employeeNumber").Replace("6500").HasError())
+
+		ext := extensionOf(t, root)
+		assert.NotNil(t, ext.inner)
+		assert.Equal(t, map[string]interface{}{"employeeNumber": "6500"}, ext.Raw())
+
+		schemas, err := root.ChildAtIndex("schemas")
+		require.Nil(t, err)
+		assert.Equal(t, []interface{}{"urn:test:Extension"}, schemas.Raw())
+	})
+
+	t.Run("deleting all extension data drops the schemas entry", func(t *testing.T) {
+		root := NewComplex(attrFunc(t))
+		nav := Navigate(root)
+		assert.False(t, nav.Dot("schemas").Replace([]interface{}{"urn:test:Root"}).HasError())
+		nav.Retract()
+		assert.False(t, nav.Dot("urn:test:Extension").Dot("employeeNumber").Replace("6500").HasError())
+
+		schemas, err := root.ChildAtIndex("schemas")
+		require.Nil(t, err)
+		assert.Equal(t, []interface{}{"urn:test:Root", "urn:test:Extension"}, schemas.Raw())
+
+		assert.False(t, nav.Delete().HasError())
+		assert.True(t, extensionOf(t, root).IsUnassigned())
+		assert.Equal(t, []interface{}{"urn:test:Root"}, schemas.Raw())
+	})
+}
@@ -12,6 +12,58 @@ func Navigate(property Property) Navigator {
 	return &defaultNavigator{stack: []Property{property}}
 }
 
+// NavigateWithOptions returns a navigator like Navigate, configured with the given options. See
+// PanicOnError and CollectAllErrors for the available options.
+func NavigateWithOptions(property Property, options ...NavigatorOption) Navigator {
+	n := &defaultNavigator{stack: []Property{property}}
+	for _, opt := range options {
+		opt(n)
+	}
+	return n
+}
+
+// NavigatorOption configures the error handling behaviour of a navigator created through
+// NavigateWithOptions.
+type NavigatorOption func(n *defaultNavigator)
+
+// PanicOnError returns a NavigatorOption that panics with the *NavigationError as soon as a step
+// fails, instead of latching the error silently. Intended for development and debugging of complex
+// traversal code, where the failing step is otherwise hard to locate.
+func PanicOnError() NavigatorOption {
+	return func(n *defaultNavigator) {
+		n.panicOnError = true
+	}
+}
+
+// CollectAllErrors returns a NavigatorOption that retains every error recorded over the lifetime of
+// the navigator, surviving ClearError and Reset, so that traversal code retrying multiple paths
+// (i.e. deserialization, patch application) can report all failures through Errors instead of just
+// the first.
+func CollectAllErrors() NavigatorOption {
+	return func(n *defaultNavigator) {
+		n.collectAll = true
+	}
+}
+
+// NavigationError describes a single failed navigation or modification step. It carries the step
+// name, the path of the property that was focused when the step failed, and the stack depth at that
+// point. It unwraps to the underlying error, so errors.Is against the spec error prototypes keeps
+// working.
+type NavigationError struct {
+	Step  string // the step that failed: "dot", "at", "where", "add", "replace" or "delete"
+	Path  string // path of the property focused when the step failed
+	Depth int    // stack depth when the step failed
+	cause error
+}
+
+func (e *NavigationError) Error() string {
+	return fmt.Sprintf("%s [step=%s, path=%s, depth=%d]", e.cause.Error(), e.Step, e.Path, e.Depth)
+}
+
+func (e *NavigationError) Unwrap() error {
+	return e.cause
+}
+
 // Navigator is a controlled mechanism to traverse the Resource/Property data structure. It should be used in cases
 // where the caller has knowledge of what to access. For example, when de-serializing JSON into a Resource, caller
 // has knowledge of the JSON structure, therefore knows what to access in the Resource structure.
@@ -32,12 +84,24 @@ func Navigate(property Property) Navigator {
 type Navigator interface {
 	// Error returns any error occurred during fluent navigation. If any step
 	// during the navigation had generated an error, further steps will become
-	// no op and the original error is reflected here.
+	// no op and the original error is reflected here. Errors produced by failed
+	// steps are of type *NavigationError.
 	Error() error
 	// HasError returns true when Error is not nil.
 	HasError() bool
 	// ClearError resets the error in the navigator.
 	ClearError()
+	// Errors returns the *NavigationError records of the failed steps. Unless the navigator was
+	// created with the CollectAllErrors option, the records are dropped along with the error state
+	// on ClearError and Reset.
+	Errors() []error
+	// Checkpoint marks the current stack position, so that a later Reset returns to it. Checkpoints
+	// nest: each Reset consumes the most recent one.
+	Checkpoint() Navigator
+	// Reset retracts the stack back to the most recent checkpoint, or to the source property when no
+	// checkpoint was marked, and clears the error state, so that traversal can resume from a known
+	// good position after a failed excursion.
+	Reset() Navigator
 	// Depth return the number of properties that was focused, including the
 	// currently focused. These properties, excluding the current one, can be
 	// refocused by calling Retract, one at a time in the reversed order that
@@ -68,8 +132,12 @@ type Navigator interface {
 }
 
 type defaultNavigator struct {
-	stack []Property
-	err   error
+	stack        []Property
+	err          error
+	errs         []error // every error recorded, kept only in collectAll mode
+	checkpoints  []int   // stack depths marked by Checkpoint
+	panicOnError bool
+	collectAll   bool
 }
 
 func (n *defaultNavigator) Error() error {
@@ -82,6 +150,48 @@ func (n *defaultNavigator) HasError() bool {
 
 func (n *defaultNavigator) ClearError() {
 	n.err = nil
+	if !n.collectAll {
+		n.errs = nil
+	}
+}
+
+func (n *defaultNavigator) Errors() []error {
+	return n.errs
+}
+
+func (n *defaultNavigator) Checkpoint() Navigator {
+	n.checkpoints = append(n.checkpoints, n.Depth())
+	return n
+}
+
+func (n *defaultNavigator) Reset() Navigator {
+	depth := 1
+	if k := len(n.checkpoints); k > 0 {
+		depth = n.checkpoints[k-1]
+		n.checkpoints = n.checkpoints[:k-1]
+	}
+	for n.Depth() > depth {
+		n.Retract()
+	}
+	n.ClearError()
+	return n
+}
+
+// fail records the failed step: a *NavigationError carrying the step context is collected for
+// Errors and panicked in panicOnError mode, while the plain cause is latched as the navigator
+// error, so that existing callers unwrapping Error directly keep seeing the same chain.
+func (n *defaultNavigator) fail(step string, cause error) {
+	e := &NavigationError{
+		Step:  step,
+		Path:  n.Current().Attribute().Path(),
+		Depth: n.Depth(),
+		cause: cause,
+	}
+	n.errs = append(n.errs, e)
+	if n.panicOnError {
+		panic(e)
+	}
+	n.err = cause
 }
 
 func (n *defaultNavigator) Depth() int {
@@ -110,7 +220,7 @@ func (n *defaultNavigator) Dot(name string) Navigator {
 
 	child, err := n.Current().ChildAtIndex(name)
 	if err != nil {
-		n.err = fmt.Errorf("%w: no attribute named '%s' from '%s'", spec.ErrInvalidPath, name, n.Current().Attribute().Path())
+		n.fail("dot", fmt.Errorf("%w: no attribute named '%s' from '%s'", spec.ErrInvalidPath, name, n.Current().Attribute().Path()))
 		return n
 	}
 
@@ -125,7 +235,7 @@ func (n *defaultNavigator) At(index int) Navigator {
 
 	child, err := n.Current().ChildAtIndex(index)
 	if err != nil {
-		n.err = fmt.Errorf("%w: no target at index '%d' from '%s'", spec.ErrNoTarget, index, n.Current().Attribute().Path())
+		n.fail("at", fmt.Errorf("%w: no target at index '%d' from '%s'", spec.ErrNoTarget, index, n.Current().Attribute().Path()))
 		return n
 	}
 
@@ -140,7 +250,7 @@ func (n *defaultNavigator) Where(criteria func(child Property) bool) Navigator {
 
 	child := n.Current().FindChild(criteria)
 	if child == nil {
-		n.err = fmt.Errorf("%w: no target meeting criteria from '%s'", spec.ErrNoTarget, n.Current().Attribute().Path())
+		n.fail("where", fmt.Errorf("%w: no target meeting criteria from '%s'", spec.ErrNoTarget, n.Current().Attribute().Path()))
 		return n
 	}
 
@@ -157,25 +267,31 @@ func (n *defaultNavigator) ForEachChild(callback func(index int, child Property)
 
 // Add delegates for Add of the Current property and propagates events to upstream properties.
 func (n *defaultNavigator) Add(value interface{}) Navigator {
-	n.err = n.delegateMod(func() (event *Event, err error) {
+	if err := n.delegateMod(func() (event *Event, err error) {
 		return n.Current().Add(value)
-	})
+	}); err != nil && err != n.err {
+		n.fail("add", err)
+	}
 	return n
 }
 
 // Replace delegates for Replace of the Current property and propagates events to upstream properties.
 func (n *defaultNavigator) Replace(value interface{}) Navigator {
-	n.err = n.delegateMod(func() (event *Event, err error) {
+	if err := n.delegateMod(func() (event *Event, err error) {
 		return n.Current().Replace(value)
-	})
+	}); err != nil && err != n.err {
+		n.fail("replace", err)
+	}
 	return n
 }
 
 // Delete delegates for Delete of the Current property and propagates events to upstream properties.
 func (n *defaultNavigator) Delete() Navigator {
-	n.err = n.delegateMod(func() (event *Event, err error) {
+	if err := n.delegateMod(func() (event *Event, err error) {
 		return n.Current().Delete()
-	})
+	}); err != nil && err != n.err {
+		n.fail("delete", err)
+	}
 	return n
 }
 
@@ -1,5 +1,16 @@
 package prop
 
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
 func ExampleNavigator() {
 	getResource := func() *Resource {
 		return &Resource{}
@@ -15,3 +26,97 @@ func ExampleNavigator() {
 	// access the property at the top of the trace stack
 	println(nav.Current().Raw())
 }
+
+func TestNavigatorErrorHandling(t *testing.T) {
+	s := new(NavigatorErrorTestSuite)
+	suite.Run(t, s)
+}
+
+type NavigatorErrorTestSuite struct {
+	suite.Suite
+	PropertyTestSuite
+	nameAttr *spec.Attribute
+}
+
+func (s *NavigatorErrorTestSuite) SetupSuite() {
+	s.nameAttr = s.mustAttribute(s.T(), strings.NewReader(`
+{
+  "id": "name",
+  "name": "name",
+  "type": "complex",
+  "_path": "name",
+  "_index": 0,
+  "subAttributes": [
+    {
+      "id": "name.givenName",
+      "name": "givenName",
+      "type": "string",
+      "_path": "name.givenName",
+      "_index": 0
+    }
+  ]
+}`))
+}
+
+func (s *NavigatorErrorTestSuite) TestTypedErrors() {
+	nav := Navigate(NewComplex(s.nameAttr))
+	nav.Dot("foobar")
+
+	assert.True(s.T(), nav.HasError())
+	assert.True(s.T(), errors.Is(nav.Error(), spec.ErrInvalidPath))
+
+	errs := nav.Errors()
+	require.Len(s.T(), errs, 1)
+
+	var ne *NavigationError
+	require.True(s.T(), errors.As(errs[0], &ne))
+	assert.Equal(s.T(), "dot", ne.Step)
+	assert.Equal(s.T(), "name", ne.Path)
+	assert.Equal(s.T(), 1, ne.Depth)
+	assert.True(s.T(), errors.Is(ne, spec.ErrInvalidPath))
+
+	// error records are dropped along with the error state by default
+	nav.ClearError()
+	assert.Empty(s.T(), nav.Errors())
+}
+
+func (s *NavigatorErrorTestSuite) TestCheckpointReset() {
+	nav := Navigate(NewComplex(s.nameAttr))
+	nav.Dot("givenName")
+	require.False(s.T(), nav.HasError())
+
+	nav.Checkpoint()
+	nav.Where(func(child Property) bool { return false })
+	assert.True(s.T(), nav.HasError())
+
+	nav.Reset()
+	assert.False(s.T(), nav.HasError())
+	assert.Equal(s.T(), "name.givenName", nav.Current().Attribute().Path())
+
+	// without a checkpoint, Reset retracts to the source
+	nav.Reset()
+	assert.Equal(s.T(), "name", nav.Current().Attribute().Path())
+}
+
+func (s *NavigatorErrorTestSuite) TestCollectAllErrors() {
+	nav := NavigateWithOptions(NewComplex(s.nameAttr), CollectAllErrors())
+
+	nav.Checkpoint().Dot("foo").Reset()
+	nav.Checkpoint().Dot("bar").Reset()
+	assert.False(s.T(), nav.HasError())
+	assert.Len(s.T(), nav.Errors(), 2)
+}
+
+func (s *NavigatorErrorTestSuite) TestPanicOnError() {
+	nav := NavigateWithOptions(NewComplex(s.nameAttr), PanicOnError())
+
+	defer func() {
+		recovered := recover()
+		require.NotNil(s.T(), recovered)
+
+		ne, ok := recovered.(*NavigationError)
+		require.True(s.T(), ok)
+		assert.Equal(s.T(), "dot", ne.Step)
+	}()
+	nav.Dot("foobar")
+}
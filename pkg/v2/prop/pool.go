@@ -0,0 +1,120 @@
+package prop
+
+import (
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"sync"
+)
+
+// resourcePools caches a sync.Pool of resource trees per resource type id. Resource types are
+// registered once and live for the duration of the process, hence the map only ever grows to the
+// number of resource types in use.
+var resourcePools sync.Map
+
+// BorrowResource returns a pristine resource of the given resource type, reusing a property tree
+// recycled by ReturnResource when one is available. Building the property tree is the dominant
+// allocation cost of creating a resource, hence high-throughput paths that create and drop many
+// resources of the same type may borrow and return them instead of calling NewResource every time.
+// A borrowed resource behaves exactly like a new resource. Callers that do not return the resource
+// simply forfeit the reuse; correctness is unaffected.
+func BorrowResource(resourceType *spec.ResourceType) *Resource {
+	pool, ok := resourcePools.Load(resourceType.ID())
+	if !ok {
+		pool, _ = resourcePools.LoadOrStore(resourceType.ID(), &sync.Pool{
+			New: func() interface{} {
+				return NewResource(resourceType)
+			},
+		})
+	}
+	return pool.(*sync.Pool).Get().(*Resource)
+}
+
+// ReturnResource resets the resource to its pristine state and makes its property tree available
+// for reuse by BorrowResource. The caller must own the resource exclusively and must not retain any
+// reference into it, including properties obtained from navigators, after returning it. Resources
+// that escaped to shared structures (i.e. handed to a database implementation that keeps the
+// pointer) must not be returned.
+func ReturnResource(resource *Resource) {
+	pool, ok := resourcePools.Load(resource.resourceType.ID())
+	if !ok {
+		return
+	}
+	resource.data.reset()
+	pool.(*sync.Pool).Put(resource)
+}
+
+// resettable is implemented by properties and subscribers that can return to the state they were in
+// right after construction, so that their enclosing resource can be recycled.
+type resettable interface {
+	reset()
+}
+
+func (p *complexProperty) reset() {
+	for _, sp := range p.subProps {
+		sp.(resettable).reset()
+	}
+	for _, sub := range p.subscribers {
+		if r, ok := sub.(resettable); ok {
+			r.reset()
+		}
+	}
+}
+
+// reset discards the materialized subtree altogether, returning the property to its lazy state, so
+// that a recycled resource does not keep extension subtrees the next borrower may never touch.
+func (p *lazyProperty) reset() {
+	p.inner = nil
+}
+
+func (p *multiValuedProperty) reset() {
+	p.elements = []Property{}
+	p.dirty = false
+	for _, sub := range p.subscribers {
+		if r, ok := sub.(resettable); ok {
+			r.reset()
+		}
+	}
+}
+
+func (p *stringProperty) reset() {
+	p.value = nil
+	p.hash = 0
+	p.dirty = false
+}
+
+func (p *integerProperty) reset() {
+	p.value = nil
+	p.dirty = false
+}
+
+func (p *decimalProperty) reset() {
+	p.value = nil
+	p.dirty = false
+}
+
+func (p *booleanProperty) reset() {
+	p.value = nil
+	p.dirty = false
+}
+
+func (p *dateTimeProperty) reset() {
+	p.value = nil
+	p.dirty = false
+}
+
+func (p *referenceProperty) reset() {
+	p.value = nil
+	p.hash = 0
+	p.dirty = false
+}
+
+func (p *binaryProperty) reset() {
+	p.value = nil
+	p.hash = 0
+	p.dirty = false
+}
+
+// reset clears the cached assigned state so that a recycled property reports state changes as if it
+// was newly constructed.
+func (s *ComplexStateSummarySubscriber) reset() {
+	s.assigned = false
+}
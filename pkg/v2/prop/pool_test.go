@@ -0,0 +1,104 @@
+package prop
+
+import (
+	"encoding/json"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestResourcePool(t *testing.T) {
+	s := new(ResourcePoolTestSuite)
+	suite.Run(t, s)
+}
+
+type ResourcePoolTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ResourcePoolTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		s.Require().Nil(err)
+
+		raw, err := ioutil.ReadAll(f)
+		s.Require().Nil(err)
+
+		err = json.Unmarshal(raw, each.structure)
+		s.Require().Nil(err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
+
+func (s *ResourcePoolTestSuite) TestBorrowedResourceIsPristine() {
+	first := BorrowResource(s.resourceType)
+	s.Require().Nil(first.Navigator().Replace(map[string]interface{}{
+		"id":       "6B694E5E-D8E7-4B39-AD46-B5B9F92E0223",
+		"userName": "imulab",
+		"emails": []interface{}{
+			map[string]interface{}{"value": "imulab@foo.com", "primary": true},
+		},
+	}).Error())
+	s.False(first.RootProperty().IsUnassigned())
+	ReturnResource(first)
+
+	second := BorrowResource(s.resourceType)
+	s.True(second.RootProperty().IsUnassigned())
+	s.Empty(second.IdOrEmpty())
+
+	nav := second.Navigator().Dot("emails")
+	s.Require().Nil(nav.Error())
+	s.Zero(nav.Current().CountChildren())
+	s.False(nav.Current().Dirty())
+	ReturnResource(second)
+}
+
+func (s *ResourcePoolTestSuite) TestBorrowedResourceBehavesLikeNew() {
+	resource := BorrowResource(s.resourceType)
+	defer ReturnResource(resource)
+
+	s.Require().Nil(resource.Navigator().Replace(map[string]interface{}{
+		"userName": "imulab",
+	}).Error())
+	s.Equal("imulab", resource.Navigator().Dot("userName").Current().Raw())
+	s.Equal(s.resourceType, resource.ResourceType())
+}
@@ -0,0 +1,74 @@
+package prop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Resolver loads the Resource that a reference value points to. The ref argument is the raw value of
+// a reference property (i.e. "$ref") or, as a fallback, of the "value" sub property holding the id of
+// the target. Implementations are typically backed by db.DB. When the target does not exist,
+// implementations return an error wrapping spec.ErrNotFound.
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (*Resource, error)
+}
+
+// ResolveRef resolves the resource that the property weakly refers to, using the resolver. The
+// property is either a singular reference property, whose value is used directly, or a complex
+// property (i.e. a "manager" or a group "member"), in which case the value of its "$ref" sub property
+// is preferred and the value of its "value" sub property is the fallback. An error wrapping
+// spec.ErrInvalidValue is returned when no reference value can be extracted.
+//
+// Resolution chains are protected against cycles: the context handed to the resolver remembers the
+// references already being resolved, so that a nested ResolveRef call on a reference pointing back
+// into the chain fails with an error wrapping spec.ErrInvalidValue instead of recursing forever.
+// Implementations that resolve recursively must propagate the context they were handed.
+func ResolveRef(ctx context.Context, property Property, resolver Resolver) (*Resource, error) {
+	ref, err := extractRef(property)
+	if err != nil {
+		return nil, err
+	}
+
+	visited, _ := ctx.Value(resolvingRefsKey).(map[string]struct{})
+	if _, cyclic := visited[ref]; cyclic {
+		return nil, fmt.Errorf("%w: cyclic reference to '%s'", spec.ErrInvalidValue, ref)
+	}
+
+	next := map[string]struct{}{ref: {}}
+	for k := range visited {
+		next[k] = struct{}{}
+	}
+
+	return resolver.Resolve(context.WithValue(ctx, resolvingRefsKey, next), ref)
+}
+
+func extractRef(property Property) (string, error) {
+	candidates := []Property{property}
+	if property.Attribute().Type() == spec.TypeComplex && !property.Attribute().MultiValued() {
+		candidates = nil
+		for _, name := range []string{"$ref", "value"} {
+			if sub, err := property.ChildAtIndex(name); err == nil {
+				candidates = append(candidates, sub)
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		switch candidate.Attribute().Type() {
+		case spec.TypeReference, spec.TypeString:
+			if ref, ok := candidate.Raw().(string); ok && len(ref) > 0 {
+				return ref, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: property '%s' holds no reference to resolve", spec.ErrInvalidValue, property.Attribute().Path())
+}
+
+type resolvingRefsContextKey int
+
+// resolvingRefsKey is the context key under which ResolveRef remembers the references being resolved
+// in the current chain.
+const resolvingRefsKey resolvingRefsContextKey = iota
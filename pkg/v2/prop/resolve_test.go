@@ -0,0 +1,160 @@
+package prop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestResolveRef(t *testing.T) {
+	s := new(ResolveRefTestSuite)
+	suite.Run(t, s)
+}
+
+type ResolveRefTestSuite struct {
+	suite.Suite
+	PropertyTestSuite
+	refAttr     *spec.Attribute
+	managerAttr *spec.Attribute
+}
+
+func (s *ResolveRefTestSuite) SetupSuite() {
+	s.refAttr = s.mustAttribute(s.T(), strings.NewReader(`
+{
+  "id": "manager.$ref",
+  "name": "$ref",
+  "type": "reference",
+  "_path": "manager.$ref",
+  "_index": 1
+}`))
+	s.managerAttr = s.mustAttribute(s.T(), strings.NewReader(`
+{
+  "id": "manager",
+  "name": "manager",
+  "type": "complex",
+  "_path": "manager",
+  "_index": 0,
+  "subAttributes": [
+    {
+      "id": "manager.value",
+      "name": "value",
+      "type": "string",
+      "_path": "manager.value",
+      "_index": 0
+    },
+    {
+      "id": "manager.$ref",
+      "name": "$ref",
+      "type": "reference",
+      "_path": "manager.$ref",
+      "_index": 1
+    }
+  ]
+}`))
+}
+
+func (s *ResolveRefTestSuite) TestResolve() {
+	target := new(Resource)
+
+	tests := []struct {
+		name     string
+		getProp  func(t *testing.T) Property
+		expect   func(t *testing.T, resolved *Resource, err error)
+		resolver Resolver
+	}{
+		{
+			name: "resolves a singular reference property",
+			getProp: func(t *testing.T) Property {
+				return NewReferenceOf(s.refAttr, "https://example.com/Users/6784")
+			},
+			resolver: resolverFunc(func(_ context.Context, ref string) (*Resource, error) {
+				assert.Equal(s.T(), "https://example.com/Users/6784", ref)
+				return target, nil
+			}),
+			expect: func(t *testing.T, resolved *Resource, err error) {
+				assert.Nil(t, err)
+				assert.Same(t, target, resolved)
+			},
+		},
+		{
+			name: "complex property prefers $ref over value",
+			getProp: func(t *testing.T) Property {
+				p := NewComplex(s.managerAttr)
+				_, err := p.Add(map[string]interface{}{
+					"value": "6784",
+					"$ref":  "https://example.com/Users/6784",
+				})
+				assert.Nil(t, err)
+				return p
+			},
+			resolver: resolverFunc(func(_ context.Context, ref string) (*Resource, error) {
+				assert.Equal(s.T(), "https://example.com/Users/6784", ref)
+				return target, nil
+			}),
+			expect: func(t *testing.T, resolved *Resource, err error) {
+				assert.Nil(t, err)
+				assert.Same(t, target, resolved)
+			},
+		},
+		{
+			name: "complex property falls back to value",
+			getProp: func(t *testing.T) Property {
+				p := NewComplex(s.managerAttr)
+				_, err := p.Add(map[string]interface{}{"value": "6784"})
+				assert.Nil(t, err)
+				return p
+			},
+			resolver: resolverFunc(func(_ context.Context, ref string) (*Resource, error) {
+				assert.Equal(s.T(), "6784", ref)
+				return target, nil
+			}),
+			expect: func(t *testing.T, resolved *Resource, err error) {
+				assert.Nil(t, err)
+				assert.Same(t, target, resolved)
+			},
+		},
+		{
+			name: "unassigned property yields invalidValue",
+			getProp: func(t *testing.T) Property {
+				return NewComplex(s.managerAttr)
+			},
+			resolver: resolverFunc(func(_ context.Context, _ string) (*Resource, error) {
+				return target, nil
+			}),
+			expect: func(t *testing.T, resolved *Resource, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resolved, err := ResolveRef(context.Background(), test.getProp(t), test.resolver)
+			test.expect(t, resolved, err)
+		})
+	}
+}
+
+func (s *ResolveRefTestSuite) TestCycleProtection() {
+	var resolver Resolver
+	resolver = resolverFunc(func(ctx context.Context, _ string) (*Resource, error) {
+		// a resolver that recursively chases a reference pointing back to itself; the second hop
+		// must be cut off by the cycle protection
+		return ResolveRef(ctx, NewReferenceOf(s.refAttr, "https://example.com/Users/6784"), resolver)
+	})
+
+	_, err := ResolveRef(context.Background(), NewReferenceOf(s.refAttr, "https://example.com/Users/6784"), resolver)
+	assert.True(s.T(), errors.Is(err, spec.ErrInvalidValue))
+	assert.Contains(s.T(), err.Error(), "cyclic")
+}
+
+type resolverFunc func(ctx context.Context, ref string) (*Resource, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, ref string) (*Resource, error) {
+	return f(ctx, ref)
+}
@@ -123,3 +123,25 @@ func (r *Resource) MetaVersionOrEmpty() string {
 		return s
 	}
 }
+
+// MetaLastModifiedOrEmpty returns meta.lastModified value of the resource, defined in the core schema. If in any case,
+// the meta.lastModified value is not available (i.e. unassigned, wrong type), empty string is returned.
+func (r *Resource) MetaLastModifiedOrEmpty() string {
+	meta, err := r.data.ChildAtIndex("meta")
+	if err != nil {
+		return ""
+	}
+
+	lastModified, err := meta.ChildAtIndex("lastModified")
+	if err != nil {
+		return ""
+	}
+
+	if lastModified.IsUnassigned() {
+		return ""
+	} else if s, ok := lastModified.Raw().(string); !ok {
+		return ""
+	} else {
+		return s
+	}
+}
@@ -1,6 +1,7 @@
 package prop
 
 import (
+	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"sync"
@@ -112,9 +113,19 @@ func (s *AutoCompactSubscriber) hasUnassignedEventFromElementAttribute(publisher
 // The subscriber reacts to assigned events from the primary property. If the event reports a primary property has a new
 // value of true, this subscriber goes through all primary properties and turn off the old true value. The result is that
 // at most one primary property will have the value of true.
-type ExclusivePrimarySubscriber struct{}
+//
+// The behavior can be configured through the "mode" annotation parameter. The default mode "unset" silently turns off
+// the other true-valued primary property; mode "reject" returns an error instead, for deployments that prefer explicit
+// failure over silent toggling; mode "none" disables the regulation altogether, allowing multiple primaries.
+type ExclusivePrimarySubscriber struct {
+	mode string
+}
 
 func (s *ExclusivePrimarySubscriber) Notify(publisher Property, events *Events) error {
+	if s.mode == ExclusivePrimaryModeNone {
+		return nil
+	}
+
 	if !s.validPublisher(publisher) {
 		return nil
 	}
@@ -124,6 +135,10 @@ func (s *ExclusivePrimarySubscriber) Notify(publisher Property, events *Events)
 		return nil
 	}
 
+	if s.mode == ExclusivePrimaryModeReject {
+		return s.rejectOtherPrimary(publisher, ev)
+	}
+
 	nav := Navigate(publisher)
 	return nav.ForEachChild(func(index int, child Property) error {
 		defer func() {
@@ -154,10 +169,47 @@ func (s *ExclusivePrimarySubscriber) Notify(publisher Property, events *Events)
 	})
 }
 
+// rejectOtherPrimary returns an error when any primary property other than the event source holds the value true.
+func (s *ExclusivePrimarySubscriber) rejectOtherPrimary(publisher Property, ev *Event) error {
+	nav := Navigate(publisher)
+	return nav.ForEachChild(func(index int, child Property) error {
+		defer func() {
+			for nav.Current() != publisher {
+				nav.Retract()
+			}
+		}()
+
+		nav.At(index).Dot(ev.Source().Attribute().Name())
+		if nav.HasError() {
+			return nil
+		}
+
+		if nav.Current() == ev.Source() {
+			return nil
+		}
+
+		if nav.Current().Raw() == true {
+			return fmt.Errorf("%w: only one '%s' may be true within '%s'",
+				spec.ErrInvalidValue, ev.Source().Attribute().Name(), publisher.Attribute().Path())
+		}
+
+		return nil
+	})
+}
+
 func (s *ExclusivePrimarySubscriber) validPublisher(publisher Property) bool {
 	return publisher.Attribute().MultiValued() && publisher.Attribute().Type() == spec.TypeComplex
 }
 
+const (
+	// ExclusivePrimaryModeUnset silently turns off the other true-valued primary property. This is the default.
+	ExclusivePrimaryModeUnset = "unset"
+	// ExclusivePrimaryModeReject returns an error when a second primary property is set to true.
+	ExclusivePrimaryModeReject = "reject"
+	// ExclusivePrimaryModeNone disables primary exclusivity regulation, allowing multiple primaries.
+	ExclusivePrimaryModeNone = "none"
+)
+
 func (s *ExclusivePrimarySubscriber) findPrimaryAssignedToTrueEvent(events *Events) *Event {
 	return events.FindEvent(func(ev *Event) bool {
 		if ev.Type() != EventAssigned {
@@ -300,9 +352,9 @@ func init() {
 		return &acs
 	})
 
-	eps := ExclusivePrimarySubscriber{}
-	SubscriberFactory().Register(annotation.ExclusivePrimary, func(_ Property, _ map[string]interface{}) Subscriber {
-		return &eps
+	SubscriberFactory().Register(annotation.ExclusivePrimary, func(_ Property, params map[string]interface{}) Subscriber {
+		mode, _ := params["mode"].(string)
+		return &ExclusivePrimarySubscriber{mode: mode}
 	})
 
 	s3 := SchemaSyncSubscriber{}
@@ -229,6 +229,82 @@ func TestExclusivePrimarySubscriber(t *testing.T) {
 	}
 }
 
+func TestExclusivePrimarySubscriberModes(t *testing.T) {
+	attrFunc := func(t *testing.T, mode string) *spec.Attribute {
+		attr := new(spec.Attribute)
+		require.Nil(t, json.Unmarshal([]byte(fmt.Sprintf(`
+{
+  "id": "emails",
+  "name": "emails",
+  "type": "complex",
+  "multiValued": true,
+  "subAttributes": [
+    {
+      "id": "emails.value",
+      "name": "value",
+      "type": "string",
+      "_path": "emails.value",
+      "_index": 0,
+      "_annotations": {
+        "@Identity": {}
+      }
+    },
+    {
+      "id": "emails.primary",
+      "name": "primary",
+      "type": "boolean",
+      "_path": "emails.primary",
+      "_index": 1,
+      "_annotations": {
+        "@Primary": {}
+      }
+    }
+  ],
+  "_path": "emails",
+  "_index": 0,
+  "_annotations": {
+    "@ExclusivePrimary": {"mode": %q}
+  }
+}
+`, mode)), attr))
+		return attr
+	}
+
+	propertyOf := func(t *testing.T, mode string) Property {
+		return NewMultiOf(attrFunc(t, mode), []interface{}{
+			map[string]interface{}{
+				"value":   "foo",
+				"primary": true,
+			},
+			map[string]interface{}{
+				"value": "bar",
+			},
+		})
+	}
+
+	t.Run("reject mode errors on second primary", func(t *testing.T) {
+		p := propertyOf(t, ExclusivePrimaryModeReject)
+		nav := Navigate(p).At(1).Dot("primary").Replace(true)
+		assert.True(t, nav.HasError())
+	})
+
+	t.Run("none mode allows multiple primaries", func(t *testing.T) {
+		p := propertyOf(t, ExclusivePrimaryModeNone)
+		nav := Navigate(p).At(1).Dot("primary").Replace(true)
+		assert.False(t, nav.HasError())
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{
+				"value":   "foo",
+				"primary": true,
+			},
+			map[string]interface{}{
+				"value":   "bar",
+				"primary": true,
+			},
+		}, p.Raw())
+	})
+}
+
 func TestSchemaSyncSubscriber(t *testing.T) {
 	var (
 		coreSchema      = new(spec.Schema)
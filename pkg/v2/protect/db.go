@@ -0,0 +1,114 @@
+package protect
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/annotation"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// DB returns a db.DB that applies the transformer to the values of "@Sensitive" annotated string
+// properties between the caller and the delegate database: values are encoded on Insert and Replace
+// and decoded on Get and Query, so the delegate only ever sees the protected form regardless of its
+// backend. Resources passed in by the caller are not modified; encoding operates on a clone.
+//
+// Count and the filters of Query are delegated untouched: a filter targeting a protected attribute
+// compares against ciphertext and will not match meaningfully.
+func DB(delegate db.DB, transformer Transformer) db.DB {
+	return &protectedDB{delegate: delegate, transformer: transformer}
+}
+
+type protectedDB struct {
+	delegate    db.DB
+	transformer Transformer
+}
+
+func (d *protectedDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	sealed := resource.Clone()
+	if err := transformResource(sealed, d.transformer.Encode); err != nil {
+		return err
+	}
+	return d.delegate.Insert(ctx, sealed)
+}
+
+func (d *protectedDB) Count(ctx context.Context, filter string) (int, error) {
+	return d.delegate.Count(ctx, filter)
+}
+
+func (d *protectedDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	resource, err := d.delegate.Get(ctx, id, projection)
+	if err != nil {
+		return nil, err
+	}
+	if err := transformResource(resource, d.transformer.Decode); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (d *protectedDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) error {
+	sealed := replacement.Clone()
+	if err := transformResource(sealed, d.transformer.Encode); err != nil {
+		return err
+	}
+	return d.delegate.Replace(ctx, ref, sealed)
+}
+
+func (d *protectedDB) Delete(ctx context.Context, resource *prop.Resource) error {
+	return d.delegate.Delete(ctx, resource)
+}
+
+func (d *protectedDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error) {
+	resources, err := d.delegate.Query(ctx, filter, sort, pagination, projection)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		if err := transformResource(resource, d.transformer.Decode); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// transformResource applies f to the values of all "@Sensitive" annotated string properties of the
+// resource, in place.
+func transformResource(resource *prop.Resource, f func(value string, attr *spec.Attribute) (string, error)) error {
+	return transformProperty(resource.RootProperty(), false, f)
+}
+
+// transformProperty applies f to the property value when the property is a sensitive string, and
+// recurses into children otherwise. forced marks elements of an annotated multiValued string
+// attribute as sensitive, since the derived element attribute does not inherit the annotation.
+func transformProperty(property prop.Property, forced bool, f func(value string, attr *spec.Attribute) (string, error)) error {
+	attr := property.Attribute()
+	_, sensitive := attr.Annotation(annotation.Sensitive)
+	sensitive = sensitive || forced
+
+	if sensitive && attr.Type() == spec.TypeString && !attr.MultiValued() {
+		if property.IsUnassigned() {
+			return nil
+		}
+		value, ok := property.Raw().(string)
+		if !ok {
+			return nil
+		}
+		transformed, err := f(value, attr)
+		if err != nil {
+			return err
+		}
+		_, err = property.Replace(transformed)
+		return err
+	}
+
+	return property.ForEachChild(func(_ int, child prop.Property) error {
+		return transformProperty(child, sensitive && attr.MultiValued(), f)
+	})
+}
+
+var (
+	_ db.DB = (*protectedDB)(nil)
+)
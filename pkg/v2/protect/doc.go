@@ -0,0 +1,11 @@
+// This package protects sensitive attribute values at rest, independent of the database backend.
+//
+// String properties annotated with "@Sensitive" are transformed (i.e. encrypted, tokenized) on their
+// way into the database and restored on their way out, by wrapping any db.DB with DB. The package
+// ships an AES-GCM Transformer with key rotation support: ciphertext embeds the id of the key that
+// produced it, so values encrypted under retired keys remain readable after the active key rotates.
+//
+// Since the database only ever sees the transformed values, filtering and sorting on protected
+// attributes compares ciphertext and will not behave meaningfully; protect attributes that are not
+// used as query targets, or accept the trade-off.
+package protect
@@ -0,0 +1,206 @@
+package protect
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestProtect(t *testing.T) {
+	s := new(ProtectTestSuite)
+	suite.Run(t, s)
+}
+
+type ProtectTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ProtectTestSuite) TestAESGCMRoundTrip() {
+	transformer, err := AESGCM(map[string][]byte{
+		"k1": []byte("0123456789abcdef"),
+	}, "k1")
+	require.Nil(s.T(), err)
+
+	protected, err := transformer.Encode("ssn-123-45-6789", nil)
+	assert.Nil(s.T(), err)
+	assert.NotEqual(s.T(), "ssn-123-45-6789", protected)
+
+	plain, err := transformer.Decode(protected, nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "ssn-123-45-6789", plain)
+
+	// values not in the protected form pass through unchanged
+	plain, err = transformer.Decode("legacy plaintext", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "legacy plaintext", plain)
+}
+
+func (s *ProtectTestSuite) TestAESGCMKeyRotation() {
+	old, err := AESGCM(map[string][]byte{
+		"k1": []byte("0123456789abcdef"),
+	}, "k1")
+	require.Nil(s.T(), err)
+
+	protected, err := old.Encode("secret", nil)
+	require.Nil(s.T(), err)
+
+	// rotated transformer keeps the retired key and can still read old ciphertext
+	rotated, err := AESGCM(map[string][]byte{
+		"k1": []byte("0123456789abcdef"),
+		"k2": []byte("fedcba9876543210"),
+	}, "k2")
+	require.Nil(s.T(), err)
+
+	plain, err := rotated.Decode(protected, nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "secret", plain)
+
+	// a transformer without the producing key cannot
+	withoutKey, err := AESGCM(map[string][]byte{
+		"k2": []byte("fedcba9876543210"),
+	}, "k2")
+	require.Nil(s.T(), err)
+
+	_, err = withoutKey.Decode(protected, nil)
+	assert.NotNil(s.T(), err)
+}
+
+func (s *ProtectTestSuite) TestDB() {
+	transformer, err := AESGCM(map[string][]byte{
+		"k1": []byte("0123456789abcdef"),
+	}, "k1")
+	require.Nil(s.T(), err)
+
+	var (
+		backend   = db.Memory()
+		protected = DB(backend, transformer)
+	)
+
+	resource := prop.NewResource(s.resourceType)
+	require.Nil(s.T(), scimjson.Deserialize([]byte(`
+{
+  "schemas": ["urn:imulab:test:schemas:Vault"],
+  "id": "vault001",
+  "name": "primary",
+  "secret": "hunter2",
+  "codes": ["alpha", "bravo"],
+  "meta": {
+    "resourceType": "Vault",
+    "version": "v1"
+  }
+}
+`), resource))
+
+	require.Nil(s.T(), protected.Insert(context.Background(), resource))
+
+	// the caller's resource still holds plaintext
+	assert.Equal(s.T(), "hunter2", resource.Navigator().Dot("secret").Current().Raw())
+
+	// the backend only sees the protected form
+	stored, err := backend.Get(context.Background(), "vault001", nil)
+	assert.Nil(s.T(), err)
+	assert.NotEqual(s.T(), "hunter2", stored.Navigator().Dot("secret").Current().Raw())
+	for _, code := range stored.Navigator().Dot("codes").Current().Raw().([]interface{}) {
+		assert.NotContains(s.T(), []interface{}{"alpha", "bravo"}, code)
+	}
+	assert.Equal(s.T(), "primary", stored.Navigator().Dot("name").Current().Raw())
+
+	// reading through the wrapper restores plaintext
+	got, err := protected.Get(context.Background(), "vault001", nil)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), "hunter2", got.Navigator().Dot("secret").Current().Raw())
+	assert.Equal(s.T(), []interface{}{"alpha", "bravo"}, got.Navigator().Dot("codes").Current().Raw())
+
+	results, err := protected.Query(context.Background(), `id eq "vault001"`, nil, nil, nil)
+	assert.Nil(s.T(), err)
+	require.Len(s.T(), results, 1)
+	assert.Equal(s.T(), "hunter2", results[0].Navigator().Dot("secret").Current().Raw())
+}
+
+func (s *ProtectTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+
+	schema := new(spec.Schema)
+	require.Nil(s.T(), json.Unmarshal([]byte(`
+{
+  "id": "urn:imulab:test:schemas:Vault",
+  "name": "Vault",
+  "attributes": [
+    {
+      "id": "urn:imulab:test:schemas:Vault:name",
+      "name": "name",
+      "type": "string",
+      "_index": 100,
+      "_path": "name"
+    },
+    {
+      "id": "urn:imulab:test:schemas:Vault:secret",
+      "name": "secret",
+      "type": "string",
+      "_index": 101,
+      "_path": "secret",
+      "_annotations": {
+        "@Sensitive": {}
+      }
+    },
+    {
+      "id": "urn:imulab:test:schemas:Vault:codes",
+      "name": "codes",
+      "type": "string",
+      "multiValued": true,
+      "_index": 102,
+      "_path": "codes",
+      "_annotations": {
+        "@Sensitive": {}
+      }
+    }
+  ]
+}
+`), schema))
+	spec.Schemas().Register(schema)
+
+	resourceType := new(spec.ResourceType)
+	require.Nil(s.T(), json.Unmarshal([]byte(`
+{
+  "id": "Vault",
+  "name": "Vault",
+  "endpoint": "/Vaults",
+  "schema": "urn:imulab:test:schemas:Vault"
+}
+`), resourceType))
+	s.resourceType = resourceType
+}
@@ -0,0 +1,104 @@
+package protect
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Transformer converts a sensitive value between its plain form and its protected at-rest form.
+// Implementations must be safe for concurrent use.
+type Transformer interface {
+	// Encode transforms the plain value of the attribute to its protected form.
+	Encode(value string, attr *spec.Attribute) (string, error)
+	// Decode restores the plain value of the attribute from its protected form. Values that are not
+	// in the protected form are returned unchanged, so that data persisted before protection was
+	// enabled remains readable.
+	Decode(value string, attr *spec.Attribute) (string, error)
+}
+
+// protectedPrefix marks values in the AES-GCM protected form: "$aes:<keyId>:<base64(nonce|ciphertext)>".
+const protectedPrefix = "$aes:"
+
+// AESGCM returns a Transformer that encrypts values with AES-GCM under the active key. The keys map
+// holds all keys by id; each key must be 16, 24 or 32 bytes, selecting AES-128, AES-192 or AES-256.
+// The id of the key that produced a ciphertext is embedded in the protected form, hence rotation
+// amounts to adding a new key and making it active: newly written values use the new key, while
+// values encrypted under the retired keys remain readable as long as they stay in the map.
+func AESGCM(keys map[string][]byte, active string) (Transformer, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys for AES-GCM transformer", spec.ErrInternal)
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("%w: active key '%s' is not among the keys", spec.ErrInternal, active)
+	}
+
+	t := &aesGCMTransformer{active: active, aead: map[string]cipher.AEAD{}}
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid key '%s': %v", spec.ErrInternal, id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", spec.ErrInternal, err)
+		}
+		t.aead[id] = aead
+	}
+
+	return t, nil
+}
+
+type aesGCMTransformer struct {
+	active string
+	aead   map[string]cipher.AEAD
+}
+
+func (t *aesGCMTransformer) Encode(value string, _ *spec.Attribute) (string, error) {
+	aead := t.aead[t.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+	return fmt.Sprintf("%s%s:%s", protectedPrefix, t.active, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func (t *aesGCMTransformer) Decode(value string, _ *spec.Attribute) (string, error) {
+	if !strings.HasPrefix(value, protectedPrefix) {
+		return value, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(value, protectedPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("%w: malformed protected value", spec.ErrInternal)
+	}
+
+	aead, ok := t.aead[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: protected value refers to unknown key '%s'", spec.ErrInternal, parts[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed protected value", spec.ErrInternal)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: malformed protected value", spec.ErrInternal)
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to decrypt protected value", spec.ErrInternal)
+	}
+
+	return string(plain), nil
+}
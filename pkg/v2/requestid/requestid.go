@@ -0,0 +1,43 @@
+// This package defines the request correlation id that ties together everything recorded about a
+// single request: logs, traces, audit entries and, optionally, the detail of error responses. The id
+// is accepted from or issued to the client through the Header, placed on the context at the
+// transport layer, and read back by any interested party with FromContext.
+package requestid
+
+import (
+	"context"
+
+	"github.com/satori/go.uuid"
+)
+
+// Header is the HTTP header through which the request id is accepted from the client and echoed
+// back on the response.
+const Header = "X-Request-Id"
+
+// New returns a freshly generated request id.
+func New() string {
+	return uuid.NewV4().String()
+}
+
+type contextKey int
+
+// requestIdContextKey is the context key under which the request id is stored.
+const requestIdContextKey contextKey = iota
+
+// WithContext returns a copy of the parent context carrying the given request id. An empty id
+// returns the parent context unmodified.
+func WithContext(parent context.Context, id string) context.Context {
+	if len(id) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, requestIdContextKey, id)
+}
+
+// FromContext extracts the request id carried on the context. It returns an empty string when the
+// context carries none.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIdContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package scim
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// NewConfig returns a Config with the given options applied. By default, the configuration reflects
+// an empty spec.ServiceProviderConfig, resource locations are formatted as "<endpoint>/<id>", and
+// bulk operations are processed one at a time.
+func NewConfig(options ...Option) *Config {
+	config := &Config{
+		serviceProviderConfig: new(spec.ServiceProviderConfig),
+		locationFormatter: func(_ context.Context, resourceType *spec.ResourceType, id string) string {
+			return strings.TrimSuffix(resourceType.Endpoint(), "/") + "/" + id
+		},
+		bulkConcurrency: 1,
+	}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// Config aggregates the configuration knobs shared by services and handler utilities. Capability
+// knobs, such as patch and etag support, are read off the wrapped spec.ServiceProviderConfig, so
+// that behavior always matches the document published at /ServiceProviderConfig; operational knobs
+// that have no place in the published document are carried by the Config directly.
+type Config struct {
+	serviceProviderConfig *spec.ServiceProviderConfig
+	locationFormatter     func(ctx context.Context, resourceType *spec.ResourceType, id string) string
+	bulkConcurrency       int
+	operationTimeout      time.Duration
+	maskRules             []MaskRule
+	defaultPageSize       int
+	maxPageSize           int
+}
+
+// Option customizes a Config.
+type Option func(config *Config)
+
+// WithServiceProviderConfig sets the service provider config document that capability knobs are
+// read off. A nil document is ignored.
+func WithServiceProviderConfig(serviceProviderConfig *spec.ServiceProviderConfig) Option {
+	return func(config *Config) {
+		if serviceProviderConfig != nil {
+			config.serviceProviderConfig = serviceProviderConfig
+		}
+	}
+}
+
+// WithLocationFormatter sets the function used to format the location URL of a resource. A nil
+// formatter is ignored.
+func WithLocationFormatter(formatter func(ctx context.Context, resourceType *spec.ResourceType, id string) string) Option {
+	return func(config *Config) {
+		if formatter != nil {
+			config.locationFormatter = formatter
+		}
+	}
+}
+
+// WithBulkConcurrency sets the number of independent bulk operations processed at the same time.
+// Values less than one are treated as one.
+func WithBulkConcurrency(concurrency int) Option {
+	return func(config *Config) {
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		config.bulkConcurrency = concurrency
+	}
+}
+
+// WithOperationTimeout sets the maximum duration a single service operation may run before its
+// context is cancelled. Values less than or equal to zero disable the timeout.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(config *Config) {
+		config.operationTimeout = timeout
+	}
+}
+
+// WithPageSize sets the page size defaults and bounds applied to queries. defaultCount is the page
+// size used when the client omits pagination entirely, and maxCount caps the page size a client may
+// request. A value of zero or less disables the respective knob.
+func WithPageSize(defaultCount int, maxCount int) Option {
+	return func(config *Config) {
+		config.defaultPageSize = defaultCount
+		config.maxPageSize = maxCount
+	}
+}
+
+// ServiceProviderConfig returns the wrapped service provider config document.
+func (c *Config) ServiceProviderConfig() *spec.ServiceProviderConfig {
+	return c.serviceProviderConfig
+}
+
+// LocationOf formats the location URL of the resource with the given id.
+func (c *Config) LocationOf(ctx context.Context, resourceType *spec.ResourceType, id string) string {
+	return c.locationFormatter(ctx, resourceType, id)
+}
+
+// OperationTimeout returns the maximum duration a single service operation may run, where zero
+// means no timeout.
+func (c *Config) OperationTimeout() time.Duration {
+	return c.operationTimeout
+}
+
+// OperationContext derives the context a service operation runs under, applying the configured
+// per-operation timeout when one is set. The returned cancel function must always be called when
+// the operation finishes to release resources.
+func (c *Config) OperationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.operationTimeout > 0 {
+		return context.WithTimeout(ctx, c.operationTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// BulkConcurrency returns the number of independent bulk operations processed at the same time.
+func (c *Config) BulkConcurrency() int {
+	return c.bulkConcurrency
+}
+
+// BulkMaxOperations returns the maximum number of operations accepted in a bulk request, where zero
+// means no limit.
+func (c *Config) BulkMaxOperations() int {
+	return c.serviceProviderConfig.Bulk.MaxOp
+}
+
+// PatchSupported returns true when patch requests are supported.
+func (c *Config) PatchSupported() bool {
+	return c.serviceProviderConfig.Patch.Supported
+}
+
+// PatchNoContent returns true when a successful patch responds with 204 No Content instead of the
+// updated resource.
+func (c *Config) PatchNoContent() bool {
+	return c.serviceProviderConfig.Patch.NoContent
+}
+
+// ETagSupported returns true when resource versioning through ETags is supported.
+func (c *Config) ETagSupported() bool {
+	return c.serviceProviderConfig.ETag.Supported
+}
+
+// FilterSupported returns true when query filters are supported.
+func (c *Config) FilterSupported() bool {
+	return c.serviceProviderConfig.Filter.Supported
+}
+
+// FilterMaxResults returns the maximum number of results a query may return, where zero means no
+// limit.
+func (c *Config) FilterMaxResults() int {
+	return c.serviceProviderConfig.Filter.MaxResults
+}
+
+// DefaultPageSize returns the page size applied to queries that omit pagination entirely, where
+// zero means such queries stay unpaginated.
+func (c *Config) DefaultPageSize() int {
+	return c.defaultPageSize
+}
+
+// MaxPageSize returns the maximum page size a client may request, where zero means no cap.
+func (c *Config) MaxPageSize() int {
+	return c.maxPageSize
+}
+
+// SortSupported returns true when query sorting is supported.
+func (c *Config) SortSupported() bool {
+	return c.serviceProviderConfig.Sort.Supported
+}
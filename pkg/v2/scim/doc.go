@@ -0,0 +1,5 @@
+// Package scim provides the consolidated configuration consumed by services and handler utilities.
+// The Config type wraps the published spec.ServiceProviderConfig document and adds operational knobs
+// that have no place in the published document, such as location formatting and bulk concurrency.
+// New knobs are introduced through new options, so that the signatures of consumers remain stable.
+package scim
@@ -0,0 +1,46 @@
+package scim
+
+import "strings"
+
+type (
+	// MaskFunc produces the masked form of a raw attribute value, as defined by prop.Property#Raw.
+	// Returning nil removes the value from the response entirely.
+	MaskFunc func(value interface{}) interface{}
+	// MaskRule masks one attribute in responses for callers that do not hold a privileged scope.
+	// Rules are matched by attribute path and evaluated against the scopes carried on the request
+	// context (see the scope package).
+	MaskRule struct {
+		// Path is the full path of the attribute to mask, i.e. "phoneNumbers.value".
+		Path string
+		// Scopes that may read the clear value. A caller holding any one of these scopes sees the
+		// value unmasked. An empty list masks the value for every caller.
+		Scopes []string
+		// Mask transforms the raw value into its masked form. A nil Mask removes the value.
+		Mask MaskFunc
+	}
+)
+
+// WithMaskRules sets the attribute masking rules applied to service responses.
+func WithMaskRules(rules ...MaskRule) Option {
+	return func(config *Config) {
+		config.maskRules = rules
+	}
+}
+
+// MaskRules returns the attribute masking rules applied to service responses.
+func (c *Config) MaskRules() []MaskRule {
+	return c.maskRules
+}
+
+// PartialMask returns a MaskFunc that replaces all but the last keep characters of a string value
+// with asterisks, i.e. "******7890". Values that are not strings, or strings no longer than keep,
+// are removed entirely.
+func PartialMask(keep int) MaskFunc {
+	return func(value interface{}) interface{} {
+		s, ok := value.(string)
+		if !ok || len(s) <= keep {
+			return nil
+		}
+		return strings.Repeat("*", len(s)-keep) + s[len(s)-keep:]
+	}
+}
@@ -0,0 +1,141 @@
+package scimfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Expression is a SCIM filter expression under construction. Expressions are immutable and may be
+// freely combined with And, Or and Not.
+type Expression struct {
+	rendered string
+	err      error
+}
+
+// Eq returns a "path eq value" expression.
+func Eq(path string, value interface{}) Expression { return compare(path, "eq", value) }
+
+// Ne returns a "path ne value" expression.
+func Ne(path string, value interface{}) Expression { return compare(path, "ne", value) }
+
+// Co returns a "path co value" expression.
+func Co(path string, value interface{}) Expression { return compare(path, "co", value) }
+
+// Sw returns a "path sw value" expression.
+func Sw(path string, value interface{}) Expression { return compare(path, "sw", value) }
+
+// Ew returns a "path ew value" expression.
+func Ew(path string, value interface{}) Expression { return compare(path, "ew", value) }
+
+// Gt returns a "path gt value" expression.
+func Gt(path string, value interface{}) Expression { return compare(path, "gt", value) }
+
+// Ge returns a "path ge value" expression.
+func Ge(path string, value interface{}) Expression { return compare(path, "ge", value) }
+
+// Lt returns a "path lt value" expression.
+func Lt(path string, value interface{}) Expression { return compare(path, "lt", value) }
+
+// Le returns a "path le value" expression.
+func Le(path string, value interface{}) Expression { return compare(path, "le", value) }
+
+// Pr returns a "path pr" expression.
+func Pr(path string) Expression {
+	if err := validatePath(path); err != nil {
+		return Expression{err: err}
+	}
+	return Expression{rendered: path + " pr"}
+}
+
+// And returns the conjunction of the given expressions.
+func And(first Expression, second Expression, rest ...Expression) Expression {
+	return join("and", append([]Expression{first, second}, rest...))
+}
+
+// Or returns the disjunction of the given expressions.
+func Or(first Expression, second Expression, rest ...Expression) Expression {
+	return join("or", append([]Expression{first, second}, rest...))
+}
+
+// Not returns the negation of the given expression.
+func Not(expression Expression) Expression {
+	if expression.err != nil {
+		return expression
+	}
+	return Expression{rendered: "not (" + expression.rendered + ")"}
+}
+
+// Render returns the expression as a SCIM filter string, or the first error encountered while
+// building it.
+func (e Expression) Render() (string, error) {
+	return e.rendered, e.err
+}
+
+// MustRender is like Render, but panics on error. It is intended for use in tests and static filters.
+func (e Expression) MustRender() string {
+	rendered, err := e.Render()
+	if err != nil {
+		panic(err)
+	}
+	return rendered
+}
+
+// Compile renders the expression and compiles it into the filter AST.
+func (e Expression) Compile() (*expr.Expression, error) {
+	rendered, err := e.Render()
+	if err != nil {
+		return nil, err
+	}
+	return expr.CompileFilter(rendered)
+}
+
+func compare(path string, op string, value interface{}) Expression {
+	if err := validatePath(path); err != nil {
+		return Expression{err: err}
+	}
+
+	literal, err := renderLiteral(value)
+	if err != nil {
+		return Expression{err: err}
+	}
+
+	return Expression{rendered: fmt.Sprintf("%s %s %s", path, op, literal)}
+}
+
+func join(op string, expressions []Expression) Expression {
+	rendered := make([]string, 0, len(expressions))
+	for _, each := range expressions {
+		if each.err != nil {
+			return each
+		}
+		rendered = append(rendered, "("+each.rendered+")")
+	}
+	return Expression{rendered: strings.Join(rendered, " "+op+" ")}
+}
+
+func validatePath(path string) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%w: no path for filter expression", spec.ErrInvalidFilter)
+	}
+	_, err := expr.CompilePath(path)
+	return err
+}
+
+// renderLiteral renders the given value as a SCIM filter literal. Strings are quoted and escaped
+// according to JSON string rules, which is what shields the filter from injection.
+func renderLiteral(value interface{}) (string, error) {
+	switch value.(type) {
+	case string, bool, int, int32, int64, float32, float64:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: filter value cannot be rendered", spec.ErrInvalidFilter)
+		}
+		return string(raw), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported filter value type %T", spec.ErrInvalidFilter, value)
+	}
+}
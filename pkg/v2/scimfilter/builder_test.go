@@ -0,0 +1,88 @@
+package scimfilter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func() Expression
+		expect func(t *testing.T, rendered string, err error)
+	}{
+		{
+			name: "comparison",
+			build: func() Expression {
+				return Eq("userName", "imulab")
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, `userName eq "imulab"`, rendered)
+			},
+		},
+		{
+			name: "conjunction and presence",
+			build: func() Expression {
+				return And(Sw("userName", "i"), Pr("emails"))
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, `(userName sw "i") and (emails pr)`, rendered)
+			},
+		},
+		{
+			name: "negated disjunction with non-string literals",
+			build: func() Expression {
+				return Not(Or(Eq("active", true), Gt("meta.version", 3)))
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, `not ((active eq true) or (meta.version gt 3))`, rendered)
+			},
+		},
+		{
+			name: "quotes in value are escaped",
+			build: func() Expression {
+				return Eq("userName", `imu"lab" or id pr`)
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, `userName eq "imu\"lab\" or id pr"`, rendered)
+
+				// the rendered filter must still parse as a single comparison
+				compiled, err := Eq("userName", `imu"lab" or id pr`).Compile()
+				assert.Nil(t, err)
+				assert.Equal(t, "eq", compiled.Token())
+			},
+		},
+		{
+			name: "invalid path fails the build",
+			build: func() Expression {
+				return And(Eq("userName", "imulab"), Pr(`emails[type eq "work"`))
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "unsupported value type fails the build",
+			build: func() Expression {
+				return Eq("userName", []string{"imulab"})
+			},
+			expect: func(t *testing.T, rendered string, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidFilter))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := test.build().Render()
+			test.expect(t, rendered, err)
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// This package provides a programmatic builder for SCIM filter expressions. Literal values are
+// rendered with proper quoting and escaping, protecting callers that would otherwise concatenate
+// filters with fmt.Sprintf from injection through attribute values.
+package scimfilter
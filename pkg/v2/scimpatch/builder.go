@@ -0,0 +1,107 @@
+package scimpatch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// New returns an empty patch Builder.
+func New() *Builder {
+	return &Builder{}
+}
+
+// Builder accumulates patch operations and renders them as crud.PatchOperation values or as a
+// complete PatchOp message. The zero value is ready to use. Builder is not safe for concurrent use.
+type Builder struct {
+	operations []crud.PatchOperation
+	err        error
+}
+
+// Add appends an add operation. An empty path targets the resource root, in which case value must
+// be a map of attribute names to values.
+func (b *Builder) Add(path string, value interface{}) *Builder {
+	return b.operation("add", path, value, true)
+}
+
+// Replace appends a replace operation.
+func (b *Builder) Replace(path string, value interface{}) *Builder {
+	return b.operation("replace", path, value, true)
+}
+
+// Remove appends a remove operation. The path is required.
+func (b *Builder) Remove(path string) *Builder {
+	if len(path) == 0 {
+		b.fail(fmt.Errorf("%w: no path for remove operation", spec.ErrInvalidSyntax))
+		return b
+	}
+	return b.operation("remove", path, nil, false)
+}
+
+// Build returns the accumulated operations, or the first error encountered while building.
+func (b *Builder) Build() ([]crud.PatchOperation, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.operations) == 0 {
+		return nil, fmt.Errorf("%w: patch contains no operations", spec.ErrInvalidSyntax)
+	}
+	return b.operations, nil
+}
+
+// MustBuild is like Build, but panics on error. It is intended for use in tests and static patches.
+func (b *Builder) MustBuild() []crud.PatchOperation {
+	operations, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return operations
+}
+
+// Payload renders the accumulated operations as a complete PatchOp message, ready to be sent as
+// the body of a patch request.
+func (b *Builder) Payload() ([]byte, error) {
+	operations, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+		"Operations": operations,
+	})
+}
+
+func (b *Builder) operation(op string, path string, value interface{}, hasValue bool) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	if len(path) > 0 {
+		if _, err := expr.CompilePath(path); err != nil {
+			b.fail(err)
+			return b
+		}
+	}
+
+	operation := crud.PatchOperation{Op: op, Path: path}
+	if hasValue {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			b.fail(fmt.Errorf("%w: value of %s operation cannot be marshaled", spec.ErrInvalidValue, op))
+			return b
+		}
+		operation.Value = raw
+	}
+
+	b.operations = append(b.operations, operation)
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,83 @@
+package scimpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func() *Builder
+		expect func(t *testing.T, payload []byte, err error)
+	}{
+		{
+			name: "add, replace and remove",
+			build: func() *Builder {
+				return New().
+					Replace("name.givenName", "Ann").
+					Add("emails", map[string]interface{}{"value": "ann@example.com", "type": "work"}).
+					Remove(`emails[type eq "home"]`)
+			},
+			expect: func(t *testing.T, payload []byte, err error) {
+				assert.Nil(t, err)
+
+				var parsed struct {
+					Schemas    []string `json:"schemas"`
+					Operations []struct {
+						Op    string          `json:"op"`
+						Path  string          `json:"path"`
+						Value json.RawMessage `json:"value"`
+					} `json:"Operations"`
+				}
+				require.Nil(t, json.Unmarshal(payload, &parsed))
+				assert.Equal(t, []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"}, parsed.Schemas)
+				require.Len(t, parsed.Operations, 3)
+				assert.Equal(t, "replace", parsed.Operations[0].Op)
+				assert.Equal(t, `"Ann"`, string(parsed.Operations[0].Value))
+				assert.Equal(t, "add", parsed.Operations[1].Op)
+				assert.Equal(t, "remove", parsed.Operations[2].Op)
+				assert.Empty(t, parsed.Operations[2].Value)
+			},
+		},
+		{
+			name: "invalid path fails the build",
+			build: func() *Builder {
+				return New().Replace(`emails[type eq "work"`, "ann@example.com")
+			},
+			expect: func(t *testing.T, payload []byte, err error) {
+				assert.NotNil(t, err)
+			},
+		},
+		{
+			name: "remove without path fails the build",
+			build: func() *Builder {
+				return New().Remove("")
+			},
+			expect: func(t *testing.T, payload []byte, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidSyntax))
+			},
+		},
+		{
+			name: "empty builder fails the build",
+			build: func() *Builder {
+				return New()
+			},
+			expect: func(t *testing.T, payload []byte, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidSyntax))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := test.build().Payload()
+			test.expect(t, payload, err)
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// This package provides a fluent builder for SCIM patch payloads. Paths are validated with the
+// expr compiler at build time, so malformed patches are caught before they reach a service
+// provider. The built operations plug directly into crud.ApplyPatch and the client package.
+package scimpatch
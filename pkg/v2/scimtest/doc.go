@@ -0,0 +1,4 @@
+// Package scimtest provides schema aware test data generation for SCIM resource types. It is
+// intended for use in consumer test suites, property based tests and fuzzing of parser and
+// serializer round trips, and is not meant to be imported by production code.
+package scimtest
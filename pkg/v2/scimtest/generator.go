@@ -0,0 +1,236 @@
+package scimtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// NewGenerator returns a Generator producing random, schema conformant test data for the given
+// resource type. The generator is deterministic for a given seed, so that failing cases can be
+// reproduced by re-running with the seed that produced them.
+func NewGenerator(resourceType *spec.ResourceType, seed int64) *Generator {
+	return &Generator{
+		resourceType: resourceType,
+		rand:         rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Generator produces random resources, filters and patch operations that conform to the schemas of
+// a resource type. A Generator is not safe for concurrent use.
+type Generator struct {
+	resourceType *spec.ResourceType
+	rand         *rand.Rand
+}
+
+// Resource generates a random resource of the generator's resource type. Required attributes are
+// always assigned; optional attributes are assigned at random; readOnly attributes other than id
+// are left unassigned, mirroring what a client is allowed to submit. The schemas attribute always
+// reflects the main schema, and id is always assigned so that the resource can be stored.
+func (g *Generator) Resource() *prop.Resource {
+	resource := prop.NewResource(g.resourceType)
+
+	data := g.complexValue(resource.RootAttribute())
+	data["schemas"] = []interface{}{g.resourceType.Schema().ID()}
+	data["id"] = g.uuid()
+	delete(data, "meta")
+
+	if err := resource.Navigator().Replace(data).Error(); err != nil {
+		panic(fmt.Errorf("scimtest: generated resource was rejected: %v", err))
+	}
+	return resource
+}
+
+// Filter generates a random valid filter expression against the generator's resource type. The
+// filter references random filterable attribute paths and combines predicates with random logical
+// operators up to a small depth.
+func (g *Generator) Filter() string {
+	return g.filterExpression(2)
+}
+
+// Patch generates a random valid patch operation against the generator's resource type. Add and
+// replace operations carry a generated value compatible with the targeted attribute; remove
+// operations target optional attributes only.
+func (g *Generator) Patch() crud.PatchOperation {
+	switch g.rand.Intn(3) {
+	case 0:
+		attr := g.randomAttribute(func(attr *spec.Attribute) bool {
+			return attr.Mutability() == spec.MutabilityReadWrite
+		})
+		return crud.PatchOperation{Op: "add", Path: attr.Path(), Value: g.rawValue(attr)}
+	case 1:
+		attr := g.randomAttribute(func(attr *spec.Attribute) bool {
+			return attr.Mutability() == spec.MutabilityReadWrite
+		})
+		return crud.PatchOperation{Op: "replace", Path: attr.Path(), Value: g.rawValue(attr)}
+	default:
+		attr := g.randomAttribute(func(attr *spec.Attribute) bool {
+			return attr.Mutability() == spec.MutabilityReadWrite && !attr.Required()
+		})
+		return crud.PatchOperation{Op: "remove", Path: attr.Path()}
+	}
+}
+
+// value generates a random value compatible with the attribute. MultiValued attributes yield a
+// slice of one to three element values.
+func (g *Generator) value(attr *spec.Attribute) interface{} {
+	if attr.MultiValued() {
+		elemAttr := attr.DeriveElementAttribute()
+		values := make([]interface{}, 0)
+		for i := g.rand.Intn(3) + 1; i > 0; i-- {
+			values = append(values, g.value(elemAttr))
+		}
+		return values
+	}
+
+	switch attr.Type() {
+	case spec.TypeString:
+		if attr.CountCanonicalValues() > 0 {
+			return g.canonicalValue(attr)
+		}
+		return g.word(8)
+	case spec.TypeInteger:
+		return int64(g.rand.Intn(10000))
+	case spec.TypeDecimal:
+		return g.rand.Float64() * 100
+	case spec.TypeBoolean:
+		return g.rand.Intn(2) == 0
+	case spec.TypeDateTime:
+		return time.Unix(g.rand.Int63n(4102416000), 0).UTC().Format(spec.ISO8601)
+	case spec.TypeReference:
+		return "https://test.scim.example.com/" + g.word(8)
+	case spec.TypeBinary:
+		return "dGVzdA=="
+	case spec.TypeComplex:
+		return g.complexValue(attr)
+	default:
+		panic("scimtest: unexpected attribute type")
+	}
+}
+
+// complexValue generates a random map value for a complex attribute, always assigning required sub
+// attributes, assigning optional ones at random and leaving readOnly ones out.
+func (g *Generator) complexValue(attr *spec.Attribute) map[string]interface{} {
+	values := map[string]interface{}{}
+	_ = attr.ForEachSubAttribute(func(subAttribute *spec.Attribute) error {
+		if subAttribute.Mutability() == spec.MutabilityReadOnly {
+			return nil
+		}
+		if subAttribute.Required() || g.rand.Intn(2) == 0 {
+			values[subAttribute.Name()] = g.value(subAttribute)
+		}
+		return nil
+	})
+	return values
+}
+
+// rawValue generates a random value for the attribute in its JSON representation.
+func (g *Generator) rawValue(attr *spec.Attribute) json.RawMessage {
+	raw, err := json.Marshal(g.value(attr))
+	if err != nil {
+		panic(fmt.Errorf("scimtest: failed to marshal generated value: %v", err))
+	}
+	return raw
+}
+
+// filterExpression generates a random filter, combining predicates with logical operators up to the
+// given depth.
+func (g *Generator) filterExpression(depth int) string {
+	if depth > 0 && g.rand.Intn(2) == 0 {
+		switch g.rand.Intn(3) {
+		case 0:
+			return fmt.Sprintf("(%s) and (%s)", g.filterExpression(depth-1), g.filterExpression(depth-1))
+		case 1:
+			return fmt.Sprintf("(%s) or (%s)", g.filterExpression(depth-1), g.filterExpression(depth-1))
+		default:
+			return fmt.Sprintf("not (%s)", g.filterExpression(depth-1))
+		}
+	}
+	return g.predicate()
+}
+
+// predicate generates a single relational predicate on a random filterable attribute.
+func (g *Generator) predicate() string {
+	attr := g.randomAttribute(func(attr *spec.Attribute) bool {
+		return true
+	})
+
+	if g.rand.Intn(4) == 0 {
+		return attr.Path() + " pr"
+	}
+
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference, spec.TypeBinary:
+		op := []string{"eq", "ne", "co", "sw", "ew"}[g.rand.Intn(5)]
+		return fmt.Sprintf("%s %s %q", attr.Path(), op, g.word(5))
+	case spec.TypeInteger:
+		op := []string{"eq", "ne", "gt", "ge", "lt", "le"}[g.rand.Intn(6)]
+		return fmt.Sprintf("%s %s %d", attr.Path(), op, g.rand.Intn(10000))
+	case spec.TypeDecimal:
+		op := []string{"eq", "ne", "gt", "ge", "lt", "le"}[g.rand.Intn(6)]
+		return fmt.Sprintf("%s %s %f", attr.Path(), op, g.rand.Float64()*100)
+	case spec.TypeBoolean:
+		return fmt.Sprintf("%s eq %t", attr.Path(), g.rand.Intn(2) == 0)
+	case spec.TypeDateTime:
+		op := []string{"gt", "ge", "lt", "le"}[g.rand.Intn(4)]
+		return fmt.Sprintf("%s %s %q", attr.Path(), op, time.Unix(g.rand.Int63n(4102416000), 0).UTC().Format(spec.ISO8601))
+	default:
+		return attr.Path() + " pr"
+	}
+}
+
+// randomAttribute returns a random non-complex attribute of the resource type satisfying the
+// criteria. Sub attributes of complex and multiValued attributes are candidates as well, addressed
+// by their full path.
+func (g *Generator) randomAttribute(criteria func(attr *spec.Attribute) bool) *spec.Attribute {
+	var candidates []*spec.Attribute
+	g.resourceType.SuperAttribute(true).DFS(func(attr *spec.Attribute) {
+		switch {
+		case attr.Type() == spec.TypeComplex,
+			len(attr.Path()) == 0,
+			strings.Contains(attr.Path(), ":"), // skip extension attributes whose path needs URN prefixing
+			strings.Contains(attr.Path(), "$"), // the expression compiler does not accept '$ref'
+			attr.Name() == "schemas" || attr.Name() == "id" || strings.HasPrefix(attr.Path(), "meta"):
+		default:
+			if criteria(attr) {
+				candidates = append(candidates, attr)
+			}
+		}
+	})
+	if len(candidates) == 0 {
+		panic("scimtest: resource type has no attribute satisfying the criteria")
+	}
+	return candidates[g.rand.Intn(len(candidates))]
+}
+
+// canonicalValue returns a random canonical value of the attribute.
+func (g *Generator) canonicalValue(attr *spec.Attribute) string {
+	var values []string
+	attr.ForEachCanonicalValues(func(canonicalValue string) {
+		values = append(values, canonicalValue)
+	})
+	return values[g.rand.Intn(len(values))]
+}
+
+// word returns a random lower case word of the given length.
+func (g *Generator) word(length int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	var sb strings.Builder
+	for i := 0; i < length; i++ {
+		sb.WriteByte(letters[g.rand.Intn(len(letters))])
+	}
+	return sb.String()
+}
+
+// uuid returns a random identifier in the canonical UUID text format.
+func (g *Generator) uuid() string {
+	b := make([]byte, 16)
+	_, _ = g.rand.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,123 @@
+package scimtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestGenerator(t *testing.T) {
+	s := new(GeneratorTestSuite)
+	suite.Run(t, s)
+}
+
+type GeneratorTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *GeneratorTestSuite) TestResourceRoundTrip() {
+	for seed := int64(0); seed < 10; seed++ {
+		generator := NewGenerator(s.resourceType, seed)
+		resource := generator.Resource()
+		s.NotEmpty(resource.IdOrEmpty(), "seed %d", seed)
+
+		raw, err := scimjson.Serialize(resource)
+		s.Require().Nil(err, "seed %d", seed)
+
+		parsed := prop.NewResource(s.resourceType)
+		s.Require().Nil(scimjson.Deserialize(raw, parsed), "seed %d", seed)
+		s.Equal(resource.IdOrEmpty(), parsed.IdOrEmpty(), "seed %d", seed)
+	}
+}
+
+func (s *GeneratorTestSuite) TestFilterCompiles() {
+	generator := NewGenerator(s.resourceType, 42)
+	for i := 0; i < 100; i++ {
+		filter := generator.Filter()
+		_, err := expr.CompileFilter(filter)
+		s.Nil(err, "filter %q does not compile", filter)
+	}
+}
+
+func (s *GeneratorTestSuite) TestPatchIsWellFormed() {
+	generator := NewGenerator(s.resourceType, 42)
+	for i := 0; i < 100; i++ {
+		patch := generator.Patch()
+		s.Contains([]string{"add", "replace", "remove"}, patch.Op)
+
+		_, err := expr.CompilePath(patch.Path)
+		s.Nil(err, "path %q does not compile", patch.Path)
+
+		if patch.Op == "remove" {
+			s.Empty(patch.Value)
+		} else {
+			var value interface{}
+			s.Nil(json.Unmarshal(patch.Value, &value))
+		}
+	}
+}
+
+func (s *GeneratorTestSuite) TestSameSeedGeneratesSameData() {
+	first := NewGenerator(s.resourceType, 7)
+	second := NewGenerator(s.resourceType, 7)
+	s.Equal(first.Filter(), second.Filter())
+	s.Equal(first.Resource().IdOrEmpty(), second.Resource().IdOrEmpty())
+}
+
+func (s *GeneratorTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		s.Require().Nil(err)
+
+		raw, err := ioutil.ReadAll(f)
+		s.Require().Nil(err)
+
+		err = json.Unmarshal(raw, each.structure)
+		s.Require().Nil(err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
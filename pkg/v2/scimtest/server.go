@@ -0,0 +1,243 @@
+package scimtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// NewServer returns a Server hosting fully wired SCIM endpoints for the given resource types. Each
+// resource type is served on its endpoint by the full service stack backed by an in-memory
+// database, so that downstream projects can integration-test their SCIM clients without standing up
+// real infrastructure. The caller is responsible for registering the schemas referenced by the
+// resource types beforehand, and for calling Close when done.
+func NewServer(t *testing.T, resourceTypes ...*spec.ResourceType) *Server {
+	if len(resourceTypes) == 0 {
+		t.Fatal("scimtest: NewServer requires at least one resource type")
+	}
+
+	spc := new(spec.ServiceProviderConfig)
+	spc.Patch.Supported = true
+	spc.Filter.Supported = true
+	spc.Sort.Supported = true
+	spc.ETag.Supported = true
+
+	s := &Server{
+		t:        t,
+		config:   scim.NewConfig(scim.WithServiceProviderConfig(spc)),
+		backends: map[string]*serverBackend{},
+	}
+
+	for _, resourceType := range resourceTypes {
+		database := db.Memory()
+		mutationFilters := []filter.ByResource{
+			filter.ByPropertyToByResource(
+				filter.ReadOnlyFilter(),
+				filter.UUIDFilter(),
+			),
+			filter.MetaFilter(),
+			filter.ByPropertyToByResource(filter.ValidationFilter(database)),
+		}
+		s.backends[strings.TrimSuffix(resourceType.Endpoint(), "/")] = &serverBackend{
+			config:       s.config,
+			resourceType: resourceType,
+			database:     database,
+			create:       service.CreateService(s.config, resourceType, database, mutationFilters),
+			replace:      service.ReplaceService(s.config, resourceType, database, mutationFilters),
+			patch:        service.PatchService(s.config, database, nil, mutationFilters),
+			delete:       service.DeleteService(s.config, database),
+			get:          service.GetService(s.config, database),
+			query:        service.QueryService(s.config, database),
+		}
+	}
+
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// Server is an in-memory SCIM server fixture for use in tests. The embedded httptest.Server exposes
+// the base URL and Close method.
+type Server struct {
+	*httptest.Server
+	t        *testing.T
+	config   *scim.Config
+	backends map[string]*serverBackend
+}
+
+type serverBackend struct {
+	config       *scim.Config
+	resourceType *spec.ResourceType
+	database     db.DB
+	create       service.Create
+	replace      service.Replace
+	patch        service.Patch
+	delete       service.Delete
+	get          service.Get
+	query        service.Query
+}
+
+// Seed inserts the resource directly into the database backing its resource type, bypassing the
+// service stack. It fails the test when the resource type is not served or the insert is rejected.
+func (s *Server) Seed(resource *prop.Resource) {
+	backend := s.backendFor(resource.ResourceType())
+	if err := backend.database.Insert(context.Background(), resource); err != nil {
+		s.t.Fatalf("scimtest: failed to seed resource: %v", err)
+	}
+}
+
+// Database returns the in-memory database backing the endpoint of the given resource type, so that
+// tests can inspect or manipulate stored resources directly. It fails the test when the resource
+// type is not served.
+func (s *Server) Database(resourceType *spec.ResourceType) db.DB {
+	return s.backendFor(resourceType).database
+}
+
+func (s *Server) backendFor(resourceType *spec.ResourceType) *serverBackend {
+	backend, ok := s.backends[strings.TrimSuffix(resourceType.Endpoint(), "/")]
+	if !ok {
+		s.t.Fatalf("scimtest: resource type '%s' is not served", resourceType.Name())
+	}
+	return backend
+}
+
+func (s *Server) handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ServiceProviderConfig" && r.Method == http.MethodGet {
+			rw.Header().Set("Content-Type", spec.ApplicationScimJson)
+			_ = json.NewEncoder(rw).Encode(s.config.ServiceProviderConfig())
+			return
+		}
+
+		for endpoint, backend := range s.backends {
+			switch {
+			case r.URL.Path == endpoint:
+				backend.serveCollection(rw, r)
+				return
+			case r.URL.Path == endpoint+"/.search":
+				backend.serveSearch(rw, r)
+				return
+			case strings.HasPrefix(r.URL.Path, endpoint+"/"):
+				backend.serveResource(rw, r, strings.TrimPrefix(r.URL.Path, endpoint+"/"))
+				return
+			}
+		}
+
+		_ = handlerutil.WriteError(rw, fmt.Errorf("%w: no route for '%s'", spec.ErrNotFound, r.URL.Path))
+	})
+}
+
+func (b *serverBackend) serveCollection(rw http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		qr, err := handlerutil.QueryRequestFromGet(r)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		resp, err := b.query.Do(r.Context(), qr)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		_ = handlerutil.WriteSearchResultToResponse(rw, resp)
+	case http.MethodPost:
+		cr, closer := handlerutil.CreateRequest(r)
+		defer closer()
+		resp, err := b.create.Do(r.Context(), cr)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		rw.WriteHeader(http.StatusCreated)
+		_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
+	default:
+		_ = handlerutil.WriteError(rw, fmt.Errorf("%w: method not supported", spec.ErrInvalidSyntax))
+	}
+}
+
+func (b *serverBackend) serveSearch(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		_ = handlerutil.WriteError(rw, fmt.Errorf("%w: method not supported", spec.ErrInvalidSyntax))
+		return
+	}
+	qr, closer, err := handlerutil.QueryRequestFromPost(r)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	defer closer()
+	resp, err := b.query.Do(r.Context(), qr)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	_ = handlerutil.WriteSearchResultToResponse(rw, resp)
+}
+
+func (b *serverBackend) serveResource(rw http.ResponseWriter, r *http.Request, id string) {
+	if len(id) == 0 {
+		_ = handlerutil.WriteError(rw, fmt.Errorf("%w: id is empty", spec.ErrInvalidSyntax))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		projection, err := handlerutil.GetRequestProjection(r)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		resp, err := b.get.Do(r.Context(), &service.GetRequest{ResourceID: id, Projection: projection})
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
+	case http.MethodPut:
+		rr, closer := handlerutil.ReplaceRequest(r)
+		defer closer()
+		resp, err := b.replace.Do(r.Context(), rr(id))
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		if resp.Replaced {
+			_ = handlerutil.WriteResourceToResponse(rw, resp.Resource)
+			return
+		}
+		_ = handlerutil.WriteResourceToResponse(rw, resp.Ref)
+	case http.MethodPatch:
+		pr, closer := handlerutil.PatchRequest(r)
+		defer closer()
+		resp, err := b.patch.Do(r.Context(), pr(id))
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		var patched *prop.Resource
+		if resp.Patched {
+			patched = resp.Resource
+		}
+		_ = handlerutil.WritePatchedResourceToResponse(rw, patched, b.config)
+	case http.MethodDelete:
+		if _, err := b.delete.Do(r.Context(), handlerutil.DeleteRequest(r)(id)); err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		_ = handlerutil.WriteError(rw, fmt.Errorf("%w: method not supported", spec.ErrInvalidSyntax))
+	}
+}
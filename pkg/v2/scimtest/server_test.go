@@ -0,0 +1,170 @@
+package scimtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestServer(t *testing.T) {
+	s := new(ServerTestSuite)
+	suite.Run(t, s)
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ServerTestSuite) TestResourceLifecycle() {
+	server := NewServer(s.T(), s.resourceType)
+	defer server.Close()
+
+	// create
+	resp, err := http.Post(server.URL+"/Users", spec.ApplicationScimJson, bytes.NewBufferString(`
+{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "userName": "imulab",
+  "emails": [{"value": "imulab@foo.com", "primary": true}]
+}
+`))
+	s.Require().Nil(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var created struct {
+		Id       string `json:"id"`
+		UserName string `json:"userName"`
+	}
+	raw, err := ioutil.ReadAll(resp.Body)
+	s.Require().Nil(err)
+	s.Require().Nil(json.Unmarshal(raw, &created))
+	s.NotEmpty(created.Id)
+	s.Equal("imulab", created.UserName)
+
+	// get
+	resp, err = http.Get(server.URL + "/Users/" + created.Id)
+	s.Require().Nil(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	// query
+	resp, err = http.Get(server.URL + "/Users?filter=" + "userName%20eq%20%22imulab%22")
+	s.Require().Nil(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var listed struct {
+		TotalResults int `json:"totalResults"`
+	}
+	raw, err = ioutil.ReadAll(resp.Body)
+	s.Require().Nil(err)
+	s.Require().Nil(json.Unmarshal(raw, &listed))
+	s.Equal(1, listed.TotalResults)
+
+	// patch
+	req, err := http.NewRequest(http.MethodPatch, server.URL+"/Users/"+created.Id, bytes.NewBufferString(`
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [
+    {"op": "replace", "path": "nickName", "value": "foo"}
+  ]
+}
+`))
+	s.Require().Nil(err)
+	resp, err = http.DefaultClient.Do(req)
+	s.Require().Nil(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	// delete
+	req, err = http.NewRequest(http.MethodDelete, server.URL+"/Users/"+created.Id, nil)
+	s.Require().Nil(err)
+	resp, err = http.DefaultClient.Do(req)
+	s.Require().Nil(err)
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	// gone
+	resp, err = http.Get(server.URL + "/Users/" + created.Id)
+	s.Require().Nil(err)
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) TestSeedAndGenerate() {
+	server := NewServer(s.T(), s.resourceType)
+	defer server.Close()
+
+	generator := NewGenerator(s.resourceType, 1)
+	seeded := generator.Resource()
+	server.Seed(seeded)
+
+	resp, err := http.Get(server.URL + "/Users/" + seeded.IdOrEmpty())
+	s.Require().Nil(err)
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	count, err := server.Database(s.resourceType).Count(context.TODO(), "id pr")
+	s.Require().Nil(err)
+	s.Equal(1, count)
+}
+
+func (s *ServerTestSuite) TestUnknownRouteReturnsNotFound() {
+	server := NewServer(s.T(), s.resourceType)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/Nonsense")
+	s.Require().Nil(err)
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *ServerTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		s.Require().Nil(err)
+
+		raw, err := ioutil.ReadAll(f)
+		s.Require().Nil(err)
+
+		err = json.Unmarshal(raw, each.structure)
+		s.Require().Nil(err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
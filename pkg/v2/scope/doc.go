@@ -0,0 +1,5 @@
+// This package defines context utilities to carry the identity of the provisioning client on whose
+// behalf a request is made, along with the scopes the caller holds. Provisioning clients (i.e. Okta,
+// Azure AD) key resources by externalId, whose uniqueness is scoped to the client that assigned it.
+// Scopes drive privilege sensitive behavior such as attribute masking in responses.
+package scope
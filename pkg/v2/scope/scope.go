@@ -0,0 +1,48 @@
+package scope
+
+import "context"
+
+type contextKey int
+
+const (
+	// clientContextKey is the context key under which the provisioning client id is stored.
+	clientContextKey contextKey = iota
+	// scopesContextKey is the context key under which the caller's scopes are stored.
+	scopesContextKey
+)
+
+// WithClient returns a copy of the parent context carrying the given provisioning client id. An empty
+// id returns the parent context unmodified.
+func WithClient(parent context.Context, clientId string) context.Context {
+	if len(clientId) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, clientContextKey, clientId)
+}
+
+// ClientFromContext extracts the provisioning client id carried on the context. It returns an empty
+// string when the request is not associated with any provisioning client.
+func ClientFromContext(ctx context.Context) string {
+	if clientId, ok := ctx.Value(clientContextKey).(string); ok {
+		return clientId
+	}
+	return ""
+}
+
+// WithScopes returns a copy of the parent context carrying the given caller scopes. An empty scope
+// list returns the parent context unmodified.
+func WithScopes(parent context.Context, scopes ...string) context.Context {
+	if len(scopes) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, scopesContextKey, scopes)
+}
+
+// ScopesFromContext extracts the caller scopes carried on the context. It returns nil when the
+// request is not associated with any scopes.
+func ScopesFromContext(ctx context.Context) []string {
+	if scopes, ok := ctx.Value(scopesContextKey).([]string); ok {
+		return scopes
+	}
+	return nil
+}
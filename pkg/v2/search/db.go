@@ -0,0 +1,115 @@
+package search
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// DB returns a db.DB that routes queries whose filter contains the string matching operators (co,
+// sw, ew) to the search index, while all other queries, exact lookups and mutations are delegated to
+// the primary database. Index backed queries resolve matching ids on the index and fetch the
+// resources from the primary store, so responses never serve stale resource content; a resource
+// indexed but already deleted from the primary store is silently skipped. When the index is
+// unavailable, queries fall back to the primary database so that a search outage degrades latency
+// instead of failing requests.
+//
+// Keeping the index in sync is the caller's concern: run Mirror against a subscription of the event
+// publisher wired into the mutating services.
+func DB(primary db.DB, index Indexer) db.DB {
+	return &searchDB{primary: primary, index: index}
+}
+
+type searchDB struct {
+	primary db.DB
+	index   Indexer
+}
+
+func (d *searchDB) Insert(ctx context.Context, resource *prop.Resource) error {
+	return d.primary.Insert(ctx, resource)
+}
+
+func (d *searchDB) Get(ctx context.Context, id string, projection *crud.Projection) (*prop.Resource, error) {
+	return d.primary.Get(ctx, id, projection)
+}
+
+func (d *searchDB) Replace(ctx context.Context, ref *prop.Resource, replacement *prop.Resource) error {
+	return d.primary.Replace(ctx, ref, replacement)
+}
+
+func (d *searchDB) Delete(ctx context.Context, resource *prop.Resource) error {
+	return d.primary.Delete(ctx, resource)
+}
+
+func (d *searchDB) Count(ctx context.Context, filter string) (int, error) {
+	if routeToIndex(filter) {
+		result, err := d.index.Search(ctx, filter, nil, nil)
+		if err == nil {
+			return result.Total, nil
+		}
+		if errors.Is(err, spec.ErrInvalidFilter) {
+			return 0, err
+		}
+	}
+	return d.primary.Count(ctx, filter)
+}
+
+func (d *searchDB) Query(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination, projection *crud.Projection) ([]*prop.Resource, error) {
+	if routeToIndex(filter) {
+		result, err := d.index.Search(ctx, filter, sort, pagination)
+		if err == nil {
+			return d.fetch(ctx, result.IDs, projection)
+		}
+		if errors.Is(err, spec.ErrInvalidFilter) {
+			return nil, err
+		}
+	}
+	return d.primary.Query(ctx, filter, sort, pagination, projection)
+}
+
+// fetch resolves the resources by id from the primary store, preserving the index result order.
+// Resources deleted from the primary store since they were indexed are skipped.
+func (d *searchDB) fetch(ctx context.Context, ids []string, projection *crud.Projection) ([]*prop.Resource, error) {
+	resources := make([]*prop.Resource, 0, len(ids))
+	for _, id := range ids {
+		resource, err := d.primary.Get(ctx, id, projection)
+		if err != nil {
+			if errors.Is(err, spec.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// routeToIndex reports whether the filter should be answered by the search index, which is the case
+// when it contains any of the string matching operators. A filter that does not compile is left to
+// the primary database to reject.
+func routeToIndex(filter string) bool {
+	root, err := expr.CompileFilter(filter)
+	if err != nil {
+		return false
+	}
+
+	route := false
+	root.Walk(func(expression *expr.Expression) {
+		if expression.IsRelationalOperator() {
+			switch expression.Token() {
+			case expr.Co, expr.Sw, expr.Ew:
+				route = true
+			}
+		}
+	}, root, func() {})
+	return route
+}
+
+var (
+	_ db.DB = (*searchDB)(nil)
+)
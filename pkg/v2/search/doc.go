@@ -0,0 +1,12 @@
+// This package provides an optional secondary search index for SCIM resources.
+//
+// Resources are mirrored into the index asynchronously by consuming resource change events (see the
+// events package): Mirror applies created, updated and deleted events onto an Indexer. Queries whose
+// filter contains the string matching operators (co, sw, ew) can then be routed to the index by
+// wrapping the primary database with DB, while exact lookups and all mutations continue to hit the
+// primary store. Because mirroring is asynchronous, index backed query results are eventually
+// consistent with the primary store.
+//
+// The package ships an Elasticsearch implementation of Indexer that talks to the cluster over its
+// REST API; it is equally compatible with OpenSearch.
+package search
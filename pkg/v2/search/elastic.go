@@ -0,0 +1,154 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Elasticsearch returns an Indexer backed by an Elasticsearch (or OpenSearch) index, addressed over
+// the cluster's REST API. Documents are stored in the same shape as the resource JSON serialization
+// under the resource id, so attribute paths double as document field paths. A nil client defaults to
+// http.DefaultClient; authentication, TLS and timeouts are configured on the supplied client.
+func Elasticsearch(baseURL string, index string, client *http.Client, resourceType *spec.ResourceType) Indexer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &elasticIndexer{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		index:   index,
+		client:  client,
+		t:       newTranslator(resourceType),
+	}
+}
+
+type elasticIndexer struct {
+	baseURL string
+	index   string
+	client  *http.Client
+	t       *translator
+}
+
+func (e *elasticIndexer) Index(ctx context.Context, resource *prop.Resource) error {
+	raw, err := json.Serialize(resource)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s/_doc/%s", e.baseURL, e.index, resource.IdOrEmpty())
+	return e.do(ctx, http.MethodPut, url, raw, nil)
+}
+
+func (e *elasticIndexer) Delete(ctx context.Context, id string) error {
+	url := fmt.Sprintf("%s/%s/_doc/%s", e.baseURL, e.index, id)
+	err := e.do(ctx, http.MethodDelete, url, nil, nil)
+	if err != nil && strings.Contains(err.Error(), "status 404") {
+		// deleting an absent resource is not an error
+		return nil
+	}
+	return err
+}
+
+func (e *elasticIndexer) Search(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination) (*Result, error) {
+	root, err := expr.CompileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	query, err := e.t.translate(root)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"query":   query,
+		"_source": false,
+	}
+	if sort != nil && len(sort.By) > 0 {
+		order := "asc"
+		if sort.Order == crud.SortDesc {
+			order = "desc"
+		}
+		body["sort"] = []interface{}{
+			map[string]interface{}{sort.By: map[string]interface{}{"order": order}},
+		}
+	}
+	if pagination != nil {
+		body["from"] = pagination.StartIndex - 1
+		body["size"] = pagination.Count
+	}
+
+	raw, err := gojson.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+
+	var sr struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	url := fmt.Sprintf("%s/%s/_search", e.baseURL, e.index)
+	if err := e.do(ctx, http.MethodPost, url, raw, &sr); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: sr.Hits.Total.Value}
+	for _, hit := range sr.Hits.Hits {
+		result.IDs = append(result.IDs, hit.ID)
+	}
+	return result, nil
+}
+
+// do performs a single REST call against the cluster, decoding the response into out when out is not
+// nil. Non-2xx responses are reported as internal errors carrying the status code.
+func (e *elasticIndexer) do(ctx context.Context, method string, url string, body []byte, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", spec.ErrInternal, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: search index responded with status %d", spec.ErrInternal, resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := gojson.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("%w: %v", spec.ErrInternal, err)
+		}
+	}
+
+	return nil
+}
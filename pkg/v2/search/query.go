@@ -0,0 +1,214 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// newTranslator returns a translator that translates compiled SCIM filters to Elasticsearch query
+// DSL for the given resource type.
+func newTranslator(resourceType *spec.ResourceType) *translator {
+	return &translator{
+		resourceType: resourceType,
+		superAttr:    resourceType.SuperAttribute(true),
+	}
+}
+
+// translator translates a compiled SCIM filter to an Elasticsearch bool query. The translation
+// relies on the index holding documents in the same shape as the resource JSON serialization, so
+// that attribute paths double as document field paths.
+type translator struct {
+	resourceType *spec.ResourceType
+	superAttr    *spec.Attribute
+}
+
+func (t *translator) translate(root *expr.Expression) (map[string]interface{}, error) {
+	switch root.Token() {
+	case expr.And:
+		left, err := t.translate(root.Left())
+		if err != nil {
+			return nil, err
+		}
+		right, err := t.translate(root.Right())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"must": []interface{}{left, right}},
+		}, nil
+	case expr.Or:
+		left, err := t.translate(root.Left())
+		if err != nil {
+			return nil, err
+		}
+		right, err := t.translate(root.Right())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               []interface{}{left, right},
+				"minimum_should_match": 1,
+			},
+		}, nil
+	case expr.Not:
+		left, err := t.translate(root.Left())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"must_not": []interface{}{left}},
+		}, nil
+	default:
+		return t.translateRelational(root.Left(), root, root.Right())
+	}
+}
+
+func (t *translator) translateRelational(path *expr.Expression, op *expr.Expression, value *expr.Expression) (map[string]interface{}, error) {
+	attr, field, err := t.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Token() {
+	case expr.Eq:
+		return t.eqQuery(attr, field, value)
+	case expr.Ne:
+		eq, err := t.eqQuery(attr, field, value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"must_not": []interface{}{eq}},
+		}, nil
+	case expr.Sw:
+		return t.wildcardQuery(attr, field, unquote(value.Token())+"*", op.Token())
+	case expr.Ew:
+		return t.wildcardQuery(attr, field, "*"+unquote(value.Token()), op.Token())
+	case expr.Co:
+		return t.wildcardQuery(attr, field, "*"+unquote(value.Token())+"*", op.Token())
+	case expr.Gt, expr.Ge, expr.Lt, expr.Le:
+		v, err := t.parseValue(value.Token(), attr)
+		if err != nil {
+			return nil, err
+		}
+		bound := map[string]string{expr.Gt: "gt", expr.Ge: "gte", expr.Lt: "lt", expr.Le: "lte"}[op.Token()]
+		return map[string]interface{}{
+			"range": map[string]interface{}{field: map[string]interface{}{bound: v}},
+		}, nil
+	case expr.Pr:
+		return map[string]interface{}{
+			"exists": map[string]interface{}{"field": field},
+		}, nil
+	default:
+		panic("invalid relational operator")
+	}
+}
+
+// resolve walks the attribute structure along the path and returns the target attribute together
+// with the document field path. Elasticsearch flattens arrays, hence multiValued attributes need no
+// special path treatment.
+func (t *translator) resolve(path *expr.Expression) (*spec.Attribute, string, error) {
+	cursorAttr := t.superAttr
+	field := ""
+
+	// skip the first token in the path when it is the id of the resource type's default schema
+	if path != nil && path.Token() == t.resourceType.Schema().ID() {
+		path = path.Next()
+	}
+
+	for path != nil {
+		if cursorAttr.MultiValued() {
+			cursorAttr = cursorAttr.DeriveElementAttribute()
+		}
+		cursorAttr = cursorAttr.SubAttributeForName(path.Token())
+		if cursorAttr == nil {
+			return nil, "", fmt.Errorf("%w: no path for '%s'", spec.ErrInvalidFilter, path.Token())
+		}
+		if len(field) > 0 {
+			field += "."
+		}
+		field += cursorAttr.Name()
+		path = path.Next()
+	}
+
+	if len(field) == 0 {
+		return nil, "", fmt.Errorf("%w: filter lacks a target path", spec.ErrInvalidFilter)
+	}
+
+	return cursorAttr, field, nil
+}
+
+func (t *translator) eqQuery(attr *spec.Attribute, field string, value *expr.Expression) (map[string]interface{}, error) {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference, spec.TypeBinary:
+		// match respects the field analyzer, which handles the case insensitive comparison of
+		// caseExact=false attributes under the standard analyzer
+		return map[string]interface{}{
+			"match": map[string]interface{}{field: unquote(value.Token())},
+		}, nil
+	default:
+		v, err := t.parseValue(value.Token(), attr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"term": map[string]interface{}{field: v},
+		}, nil
+	}
+}
+
+func (t *translator) wildcardQuery(attr *spec.Attribute, field string, pattern string, op string) (map[string]interface{}, error) {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference:
+	default:
+		return nil, fmt.Errorf("%w: operator '%s' is not applicable to '%s'", spec.ErrInvalidFilter, op, attr.Path())
+	}
+
+	wildcard := map[string]interface{}{"value": pattern}
+	if !attr.CaseExact() {
+		wildcard["case_insensitive"] = true
+	}
+	return map[string]interface{}{
+		"wildcard": map[string]interface{}{field: wildcard},
+	}, nil
+}
+
+// parseValue parses the raw literal to the appropriate data type according to the attribute.
+func (t *translator) parseValue(raw string, attr *spec.Attribute) (interface{}, error) {
+	switch attr.Type() {
+	case spec.TypeString, spec.TypeReference, spec.TypeBinary, spec.TypeDateTime:
+		return unquote(raw), nil
+	case spec.TypeBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value in filter incompatible with '%s'", spec.ErrInvalidFilter, attr.Path())
+		}
+		return b, nil
+	case spec.TypeInteger:
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value in filter incompatible with '%s'", spec.ErrInvalidFilter, attr.Path())
+		}
+		return i, nil
+	case spec.TypeDecimal:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value in filter incompatible with '%s'", spec.ErrInvalidFilter, attr.Path())
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("%w: operations cannot be applied to complex attribute", spec.ErrInvalidFilter)
+	}
+}
+
+func unquote(raw string) string {
+	uq, err := strconv.Unquote(raw)
+	if err != nil {
+		return raw
+	}
+	return uq
+}
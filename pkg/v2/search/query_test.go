@@ -0,0 +1,182 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud/expr"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTranslateQuery(t *testing.T) {
+	s := new(TranslateQueryTestSuite)
+	suite.Run(t, s)
+}
+
+type TranslateQueryTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *TranslateQueryTestSuite) TestTranslate() {
+	tests := []struct {
+		name   string
+		filter string
+		expect func(t *testing.T, queryJson string, err error)
+	}{
+		{
+			name:   "co becomes a wildcard query",
+			filter: "userName co \"foo\"",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"wildcard":{"userName":{"value":"*foo*","case_insensitive":true}}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "sw on second level path",
+			filter: "emails.value sw \"foo\"",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"wildcard":{"emails.value":{"value":"foo*","case_insensitive":true}}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "string eq becomes a match query",
+			filter: "userName eq \"imulab\"",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"match":{"userName":"imulab"}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "boolean eq becomes a term query",
+			filter: "active eq true",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"term":{"active":true}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "logical operators become a bool query",
+			filter: "(userName co \"foo\") and not (active eq true)",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"bool":{"must":[
+					{"wildcard":{"userName":{"value":"*foo*","case_insensitive":true}}},
+					{"bool":{"must_not":[{"term":{"active":true}}]}}
+				]}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "pr becomes an exists query",
+			filter: "name.givenName pr",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.Nil(t, err)
+				expect := `{"exists":{"field":"name.givenName"}}`
+				assert.JSONEq(t, expect, queryJson)
+			},
+		},
+		{
+			name:   "co on non string target is invalid",
+			filter: "active co \"ru\"",
+			expect: func(t *testing.T, queryJson string, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidFilter))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			root, err := expr.CompileFilter(test.filter)
+			require.Nil(t, err)
+
+			query, err := newTranslator(s.resourceType).translate(root)
+			if err != nil {
+				test.expect(t, "", err)
+				return
+			}
+
+			raw, err := json.Marshal(query)
+			require.Nil(t, err)
+			test.expect(t, string(raw), err)
+		})
+	}
+}
+
+func (s *TranslateQueryTestSuite) TestRouteToIndex() {
+	tests := []struct {
+		name   string
+		filter string
+		expect bool
+	}{
+		{name: "co routes to the index", filter: "userName co \"foo\"", expect: true},
+		{name: "nested sw routes to the index", filter: "(active eq true) and (emails.value sw \"foo\")", expect: true},
+		{name: "eq stays on the primary store", filter: "userName eq \"imulab\"", expect: false},
+		{name: "invalid filter stays on the primary store", filter: "userName haha \"imulab\"", expect: false},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, routeToIndex(test.filter))
+		})
+	}
+}
+
+func (s *TranslateQueryTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
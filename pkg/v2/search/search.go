@@ -0,0 +1,71 @@
+package search
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// Indexer is the abstraction for a secondary search index holding a mirrored copy of resources.
+// Implementations must be safe for concurrent use.
+type Indexer interface {
+	// Index stores or overwrites the resource in the index.
+	Index(ctx context.Context, resource *prop.Resource) error
+	// Delete removes the resource with the given id from the index. Deleting an absent resource is
+	// not an error.
+	Delete(ctx context.Context, id string) error
+	// Search evaluates the SCIM filter against the index and returns the matching resource ids along
+	// with the total match count before pagination.
+	Search(ctx context.Context, filter string, sort *crud.Sort, pagination *crud.Pagination) (*Result, error)
+}
+
+// Result is the outcome of an Indexer search.
+type Result struct {
+	// IDs are the ids of the matching resources, in result order.
+	IDs []string
+	// Total is the number of matching resources before pagination was applied.
+	Total int
+}
+
+// Mirror consumes resource change events from the source channel and applies them onto the indexer,
+// keeping the index in sync with the primary store. Events of other resource types are skipped;
+// indexing errors are reported to onError (which may be nil) and do not stop the mirror. Mirror
+// returns when the source channel closes or the context is cancelled. It is typically run on its own
+// goroutine against a subscription of events.InProcessPublisher.
+func Mirror(ctx context.Context, resourceType *spec.ResourceType, source <-chan *events.Event, indexer Indexer, onError func(err error)) {
+	report := func(err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-source:
+			if !ok {
+				return
+			}
+			if event.ResourceType != resourceType.Name() {
+				continue
+			}
+
+			switch event.Type {
+			case events.TypeCreated, events.TypeUpdated:
+				resource := prop.NewResource(resourceType)
+				if err := json.Deserialize(event.Data, resource); err != nil {
+					report(err)
+					continue
+				}
+				report(indexer.Index(ctx, resource))
+			case events.TypeDeleted:
+				report(indexer.Delete(ctx, event.ResourceID))
+			}
+		}
+	}
+}
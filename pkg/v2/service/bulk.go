@@ -0,0 +1,380 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// BulkService returns a bulk processing service. Operations are decoded one at a time from the request
+// payload instead of buffering the whole message, and their results are streamed to the response sink
+// in request order as they complete. Up to concurrency operations are processed at the same time; the
+// decoder exerts back-pressure on the payload source when all workers are busy. A concurrency less
+// than one is treated as one.
+// The concurrency level and operation limits are read off the given configuration.
+//
+// Because results are streamed, the failOnErrors directive is honored when it precedes Operations in
+// the payload, which is the layout RFC 7644 uses. Structural errors, such as a malformed payload or
+// exceeding the configured maxOperations, abort processing and are returned from Do; callers that
+// need atomic error semantics should buffer the sink.
+func BulkService(config *scim.Config, backends ...*BulkBackend) Bulk {
+	return &bulkService{
+		config:   config,
+		backends: backends,
+	}
+}
+
+type (
+	// Bulk resource service
+	Bulk interface {
+		Do(ctx context.Context, req *BulkRequest) (resp *BulkResponse, err error)
+	}
+	// Bulk resource request
+	BulkRequest struct {
+		PayloadSource io.Reader // source to stream the bulk request payload from
+		ResponseSink  io.Writer // sink that the bulk response message is streamed to
+	}
+	// Bulk resource response
+	BulkResponse struct {
+		Operations int // total number of operations processed
+		Failures   int // number of operations that failed
+	}
+	// BulkBackend groups the per resource type services that the bulk service dispatches operations to.
+	// Services that are nil cause the corresponding bulk method to fail for that resource type.
+	BulkBackend struct {
+		ResourceType *spec.ResourceType
+		Create       Create
+		Replace      Replace
+		Patch        Patch
+		Delete       Delete
+	}
+)
+
+type bulkService struct {
+	config   *scim.Config
+	backends []*BulkBackend
+}
+
+// bulkOperation is the decoded form of a single operation in the bulk request payload.
+type bulkOperation struct {
+	Method  string          `json:"method"`
+	Path    string          `json:"path"`
+	BulkId  string          `json:"bulkId"`
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// bulkResult is the rendering of a single operation result in the bulk response payload.
+type bulkResult struct {
+	Method   string          `json:"method"`
+	BulkId   string          `json:"bulkId,omitempty"`
+	Location string          `json:"location,omitempty"`
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+
+	failed bool
+}
+
+func (s *bulkService) Do(ctx context.Context, req *BulkRequest) (resp *BulkResponse, err error) {
+	ctx, span := trace.StartSpan(ctx, "service.bulk")
+	defer func() { span.SetError(err); span.End() }()
+
+	if req == nil || req.PayloadSource == nil || req.ResponseSink == nil {
+		err = fmt.Errorf("%w: no payload source or response sink for bulk service", spec.ErrInternal)
+		return
+	}
+
+	decoder := json.NewDecoder(req.PayloadSource)
+	if err = expectDelim(decoder, '{'); err != nil {
+		return
+	}
+
+	var (
+		failOnErrors          int
+		processed, failures   int
+		operationsEncountered bool
+	)
+	for decoder.More() {
+		var key string
+		if key, err = nextKey(decoder); err != nil {
+			return
+		}
+		switch key {
+		case "failOnErrors":
+			if err = decoder.Decode(&failOnErrors); err != nil {
+				err = fmt.Errorf("%w: invalid failOnErrors in bulk payload", spec.ErrInvalidSyntax)
+				return
+			}
+		case "Operations":
+			operationsEncountered = true
+			if processed, failures, err = s.processOperations(ctx, decoder, req.ResponseSink, failOnErrors); err != nil {
+				return
+			}
+		default:
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				err = fmt.Errorf("%w: invalid bulk payload", spec.ErrInvalidSyntax)
+				return
+			}
+		}
+	}
+
+	if !operationsEncountered {
+		err = fmt.Errorf("%w: bulk payload contains no Operations", spec.ErrInvalidSyntax)
+		return
+	}
+
+	resp = &BulkResponse{Operations: processed, Failures: failures}
+	return
+}
+
+// processOperations streams operations off the decoder, positioned at the start of the Operations
+// array, through a pool of workers and writes their results to the sink in request order.
+func (s *bulkService) processOperations(ctx context.Context, decoder *json.Decoder, sink io.Writer, failOnErrors int) (processed int, failures int, err error) {
+	if err = expectDelim(decoder, '['); err != nil {
+		return
+	}
+
+	concurrency := s.config.BulkConcurrency()
+	if _, err = io.WriteString(sink, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:BulkResponse"],"Operations":[`); err != nil {
+		return
+	}
+
+	var (
+		jobs       = make(chan *bulkJob)
+		ordered    = make(chan chan *bulkResult, concurrency)
+		writerDone = make(chan struct{})
+		aborted    int32
+		writeErr   error
+		workers    sync.WaitGroup
+	)
+
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				job.done <- s.apply(ctx, job.op)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(writerDone)
+		first := true
+		for done := range ordered {
+			result := <-done
+			if result.failed {
+				failures++
+				if failOnErrors > 0 && failures >= failOnErrors {
+					atomic.StoreInt32(&aborted, 1)
+				}
+			}
+			raw, marshalErr := json.Marshal(result)
+			if marshalErr == nil {
+				if !first {
+					_, marshalErr = io.WriteString(sink, ",")
+				}
+				if marshalErr == nil {
+					_, marshalErr = sink.Write(raw)
+				}
+				first = false
+			}
+			if marshalErr != nil && writeErr == nil {
+				writeErr = marshalErr
+			}
+		}
+	}()
+
+	for decoder.More() {
+		if atomic.LoadInt32(&aborted) == 1 {
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				err = fmt.Errorf("%w: invalid bulk operation", spec.ErrInvalidSyntax)
+				break
+			}
+			continue
+		}
+
+		var op bulkOperation
+		if err = decoder.Decode(&op); err != nil {
+			err = fmt.Errorf("%w: invalid bulk operation", spec.ErrInvalidSyntax)
+			break
+		}
+
+		processed++
+		if maxOp := s.config.BulkMaxOperations(); maxOp > 0 && processed > maxOp {
+			err = fmt.Errorf("%w: number of bulk operations exceeds maxOperations (%d)", spec.ErrTooMany, maxOp)
+			break
+		}
+
+		done := make(chan *bulkResult, 1)
+		ordered <- done
+		jobs <- &bulkJob{op: op, done: done}
+	}
+
+	close(jobs)
+	workers.Wait()
+	close(ordered)
+	<-writerDone
+
+	if err != nil {
+		return
+	}
+	if writeErr != nil {
+		err = writeErr
+		return
+	}
+	if err = expectDelim(decoder, ']'); err != nil {
+		return
+	}
+	_, err = io.WriteString(sink, "]}")
+	return
+}
+
+type bulkJob struct {
+	op   bulkOperation
+	done chan *bulkResult
+}
+
+// apply carries out a single bulk operation and renders its result. Operation failures are captured
+// in the result and never surface as errors, so that the remaining operations may proceed.
+func (s *bulkService) apply(ctx context.Context, op bulkOperation) *bulkResult {
+	var (
+		status   int
+		resource *prop.Resource
+	)
+
+	backend, id, err := s.route(op.Path)
+	if err == nil {
+		switch strings.ToUpper(op.Method) {
+		case http.MethodPost:
+			if backend.Create == nil {
+				err = fmt.Errorf("%w: bulk create is not available for '%s'", spec.ErrInvalidValue, backend.ResourceType.Name())
+			} else if r, createErr := backend.Create.Do(ctx, &CreateRequest{PayloadSource: bytes.NewReader(op.Data)}); createErr != nil {
+				err = createErr
+			} else {
+				status, resource = http.StatusCreated, r.Resource
+			}
+		case http.MethodPut:
+			if backend.Replace == nil {
+				err = fmt.Errorf("%w: bulk replace is not available for '%s'", spec.ErrInvalidValue, backend.ResourceType.Name())
+			} else if len(id) == 0 {
+				err = fmt.Errorf("%w: bulk path '%s' does not target a resource", spec.ErrInvalidPath, op.Path)
+			} else if r, replaceErr := backend.Replace.Do(ctx, &ReplaceRequest{ResourceID: id, PayloadSource: bytes.NewReader(op.Data)}); replaceErr != nil {
+				err = replaceErr
+			} else {
+				status, resource = http.StatusOK, r.Resource
+			}
+		case http.MethodPatch:
+			if backend.Patch == nil {
+				err = fmt.Errorf("%w: bulk patch is not available for '%s'", spec.ErrInvalidValue, backend.ResourceType.Name())
+			} else if len(id) == 0 {
+				err = fmt.Errorf("%w: bulk path '%s' does not target a resource", spec.ErrInvalidPath, op.Path)
+			} else if r, patchErr := backend.Patch.Do(ctx, &PatchRequest{ResourceID: id, PayloadSource: bytes.NewReader(op.Data)}); patchErr != nil {
+				err = patchErr
+			} else {
+				status, resource = http.StatusOK, r.Resource
+			}
+		case http.MethodDelete:
+			if backend.Delete == nil {
+				err = fmt.Errorf("%w: bulk delete is not available for '%s'", spec.ErrInvalidValue, backend.ResourceType.Name())
+			} else if len(id) == 0 {
+				err = fmt.Errorf("%w: bulk path '%s' does not target a resource", spec.ErrInvalidPath, op.Path)
+			} else if _, deleteErr := backend.Delete.Do(ctx, &DeleteRequest{ResourceID: id}); deleteErr != nil {
+				err = deleteErr
+			} else {
+				status = http.StatusNoContent
+			}
+		default:
+			err = fmt.Errorf("%w: unsupported bulk method '%s'", spec.ErrInvalidValue, op.Method)
+		}
+	}
+
+	result := &bulkResult{
+		Method: strings.ToUpper(op.Method),
+		BulkId: op.BulkId,
+	}
+	if err != nil {
+		result.failed = true
+		result.Status, result.Response = errorRendering(err)
+	} else {
+		result.Status = strconv.Itoa(status)
+		if resource != nil {
+			result.Location = resource.MetaLocationOrEmpty()
+		}
+	}
+	return result
+}
+
+// route matches the operation path against the endpoints of the registered backends. It returns the
+// matched backend and the resource id portion of the path, which is empty when the path only targets
+// the endpoint itself.
+func (s *bulkService) route(path string) (*BulkBackend, string, error) {
+	for _, backend := range s.backends {
+		endpoint := strings.TrimSuffix(backend.ResourceType.Endpoint(), "/")
+		if path == endpoint {
+			return backend, "", nil
+		}
+		if strings.HasPrefix(path, endpoint+"/") {
+			return backend, strings.TrimPrefix(path, endpoint+"/"), nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w: no resource endpoint matches bulk path '%s'", spec.ErrInvalidPath, path)
+}
+
+// errorRendering renders the error as a SCIM error message, in the same way handlerutil would,
+// returning the status to report and the rendered payload.
+func errorRendering(err error) (string, json.RawMessage) {
+	var scimError *spec.Error
+	if !errors.As(err, &scimError) {
+		scimError = spec.ErrInternal
+	}
+	raw, _ := json.Marshal(struct {
+		Schemas  []string `json:"schemas"`
+		Status   int      `json:"status"`
+		ScimType string   `json:"scimType"`
+		Detail   string   `json:"detail"`
+	}{
+		Schemas:  []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+		Status:   scimError.Status,
+		ScimType: scimError.Type,
+		Detail:   err.Error(),
+	})
+	return strconv.Itoa(scimError.Status), raw
+}
+
+// expectDelim reads the next token off the decoder and fails unless it is the given delimiter.
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil || token != delim {
+		return fmt.Errorf("%w: invalid bulk payload", spec.ErrInvalidSyntax)
+	}
+	return nil
+}
+
+// nextKey reads the next token off the decoder and returns it as an object key.
+func nextKey(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid bulk payload", spec.ErrInvalidSyntax)
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: invalid bulk payload", spec.ErrInvalidSyntax)
+	}
+	return key, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBulkService(t *testing.T) {
+	s := new(BulkServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type BulkServiceTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+	config       *scim.Config
+}
+
+func (s *BulkServiceTestSuite) TestDo() {
+	defaultSetup := func(t *testing.T, concurrency int) Bulk {
+		memoryDB := db.Memory()
+		createService := CreateService(s.config, s.resourceType, memoryDB, []filter.ByResource{
+			filter.ByPropertyToByResource(
+				filter.ReadOnlyFilter(),
+				filter.UUIDFilter(),
+			),
+			filter.MetaFilter(),
+			filter.ByPropertyToByResource(filter.ValidationFilter(memoryDB)),
+		})
+		deleteService := DeleteService(s.config, memoryDB)
+		return BulkService(scim.NewConfig(scim.WithServiceProviderConfig(s.config.ServiceProviderConfig()), scim.WithBulkConcurrency(concurrency)), &BulkBackend{
+			ResourceType: s.resourceType,
+			Create:       createService,
+			Delete:       deleteService,
+		})
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T) Bulk
+		payload string
+		expect  func(t *testing.T, resp *BulkResponse, results []bulkResult, err error)
+	}{
+		{
+			name: "mixed outcomes are streamed in order",
+			setup: func(t *testing.T) Bulk {
+				return defaultSetup(t, 2)
+			},
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:BulkRequest"],
+  "Operations": [
+    {
+      "method": "POST",
+      "path": "/Users",
+      "bulkId": "qwerty",
+      "data": {
+        "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+        "userName": "foo",
+        "emails": [{"value": "foo@bar.com", "primary": true}]
+      }
+    },
+    {
+      "method": "POST",
+      "path": "/Users",
+      "bulkId": "ytrewq",
+      "data": {
+        "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"]
+      }
+    },
+    {
+      "method": "DELETE",
+      "path": "/Users/does-not-exist"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, resp *BulkResponse, results []bulkResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 3, resp.Operations)
+				assert.Equal(t, 2, resp.Failures)
+				require.Len(t, results, 3)
+				assert.Equal(t, "qwerty", results[0].BulkId)
+				assert.Equal(t, "201", results[0].Status)
+				assert.NotEmpty(t, results[0].Location)
+				assert.Equal(t, "ytrewq", results[1].BulkId)
+				assert.Equal(t, "400", results[1].Status)
+				assert.NotEmpty(t, results[1].Response)
+				assert.Equal(t, "404", results[2].Status)
+			},
+		},
+		{
+			name: "failOnErrors stops processing",
+			setup: func(t *testing.T) Bulk {
+				return defaultSetup(t, 1)
+			},
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:BulkRequest"],
+  "failOnErrors": 1,
+  "Operations": [
+    {
+      "method": "DELETE",
+      "path": "/Users/does-not-exist"
+    },
+    {
+      "method": "POST",
+      "path": "/Users",
+      "data": {
+        "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+        "userName": "bar",
+        "emails": [{"value": "bar@foo.com", "primary": true}]
+      }
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, resp *BulkResponse, results []bulkResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, resp.Failures)
+				require.Len(t, results, 1)
+				assert.Equal(t, "404", results[0].Status)
+			},
+		},
+		{
+			name: "unknown endpoint fails the operation only",
+			setup: func(t *testing.T) Bulk {
+				return defaultSetup(t, 1)
+			},
+			payload: `
+{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:BulkRequest"],
+  "Operations": [
+    {
+      "method": "POST",
+      "path": "/Unknown",
+      "data": {}
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, resp *BulkResponse, results []bulkResult, err error) {
+				assert.Nil(t, err)
+				require.Len(t, results, 1)
+				assert.Equal(t, "400", results[0].Status)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			service := test.setup(t)
+			var sink bytes.Buffer
+			resp, err := service.Do(context.Background(), &BulkRequest{
+				PayloadSource: strings.NewReader(test.payload),
+				ResponseSink:  &sink,
+			})
+
+			var results []bulkResult
+			if err == nil {
+				var message struct {
+					Schemas    []string     `json:"schemas"`
+					Operations []bulkResult `json:"Operations"`
+				}
+				require.Nil(t, json.Unmarshal(sink.Bytes(), &message))
+				assert.Equal(t, []string{"urn:ietf:params:scim:api:messages:2.0:BulkResponse"}, message.Schemas)
+				results = message.Operations
+			}
+			test.expect(t, resp, results, err)
+		})
+	}
+}
+
+func (s *BulkServiceTestSuite) SetupSuite() {
+	spc := new(spec.ServiceProviderConfig)
+	spc.Bulk.Supported = true
+	spc.Bulk.MaxOp = 100
+	s.config = scim.NewConfig(scim.WithServiceProviderConfig(spc))
+
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+)
+
+// CountStrategy decides how the total number of resources matching a query filter is obtained. The
+// exact strategy, which issues a count to db.DB on every request, is the default and always correct,
+// but may become too slow for very large collections. Alternative strategies may trade accuracy for
+// speed. The strategy is configured per QueryService, hence per resource type, through
+// QueryServiceWithCountStrategy.
+type CountStrategy interface {
+	// Count returns the total number of resources in the database matching the given SCIM filter.
+	Count(ctx context.Context, database db.DB, filter string) (int, error)
+}
+
+// CountStrategyFunc is the functional adapter of CountStrategy.
+type CountStrategyFunc func(ctx context.Context, database db.DB, filter string) (int, error)
+
+func (f CountStrategyFunc) Count(ctx context.Context, database db.DB, filter string) (int, error) {
+	return f(ctx, database, filter)
+}
+
+// ExactCount returns the default CountStrategy, which issues a count to the database on every request.
+func ExactCount() CountStrategy {
+	return CountStrategyFunc(func(ctx context.Context, database db.DB, filter string) (int, error) {
+		return database.Count(ctx, filter)
+	})
+}
+
+// CachedCount returns a CountStrategy that remembers the results of the delegate strategy per filter
+// for the duration of maxAge. Totals served from the cache are approximate: they may lag behind the
+// database by up to maxAge, which is usually an acceptable trade for the totalResults of large
+// paginated listings where an exact count on every page is too slow. A non-positive maxAge disables
+// caching and the returned strategy behaves just like the delegate.
+func CachedCount(delegate CountStrategy, maxAge time.Duration) CountStrategy {
+	if maxAge <= 0 {
+		return delegate
+	}
+	return &cachedCount{
+		delegate: delegate,
+		maxAge:   maxAge,
+		cached:   map[string]cachedTotal{},
+	}
+}
+
+type cachedCount struct {
+	sync.RWMutex
+	delegate CountStrategy
+	maxAge   time.Duration
+	cached   map[string]cachedTotal
+}
+
+type cachedTotal struct {
+	total    int
+	cachedAt time.Time
+}
+
+func (c *cachedCount) Count(ctx context.Context, database db.DB, filter string) (int, error) {
+	c.RLock()
+	entry, ok := c.cached[filter]
+	c.RUnlock()
+	if ok && time.Since(entry.cachedAt) < c.maxAge {
+		return entry.total, nil
+	}
+
+	total, err := c.delegate.Count(ctx, database, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Lock()
+	c.cached[filter] = cachedTotal{total: total, cachedAt: time.Now()}
+	c.Unlock()
+	return total, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCountStrategy(t *testing.T) {
+	s := new(CountStrategyTestSuite)
+	suite.Run(t, s)
+}
+
+type CountStrategyTestSuite struct {
+	suite.Suite
+	config       *scim.Config
+	resourceType *spec.ResourceType
+}
+
+func (s *CountStrategyTestSuite) TestExactCount() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user001"})))
+
+	n, err := ExactCount().Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user002"})))
+
+	n, err = ExactCount().Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+}
+
+func (s *CountStrategyTestSuite) TestCachedCount() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user001"})))
+
+	strategy := CachedCount(ExactCount(), time.Minute)
+
+	n, err := strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+
+	// the cached total lags behind the database until maxAge elapses
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user002"})))
+	n, err = strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+
+	// a different filter is counted separately
+	n, err = strategy.Count(context.TODO(), database, `id eq "user002"`)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+}
+
+func (s *CountStrategyTestSuite) TestCachedCountExpiry() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user001"})))
+
+	strategy := CachedCount(ExactCount(), time.Millisecond)
+
+	n, err := strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user002"})))
+	time.Sleep(10 * time.Millisecond)
+
+	n, err = strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+}
+
+func (s *CountStrategyTestSuite) TestNonPositiveMaxAgeDisablesCaching() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user001"})))
+
+	strategy := CachedCount(ExactCount(), 0)
+	_, ok := strategy.(*cachedCount)
+	assert.False(s.T(), ok)
+
+	n, err := strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+
+	// nothing is cached: new resources are counted right away
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user002"})))
+	n, err = strategy.Count(context.TODO(), database, "id pr")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+}
+
+func (s *CountStrategyTestSuite) TestQueryServiceWithCountStrategy() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{"id": "user001"})))
+
+	svc := QueryServiceWithCountStrategy(s.config, database, CountStrategyFunc(
+		func(_ context.Context, _ db.DB, _ string) (int, error) {
+			return 42, nil
+		},
+	))
+
+	resp, err := svc.Do(context.TODO(), &QueryRequest{Filter: "id pr"})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 42, resp.TotalResults)
+	assert.Len(s.T(), resp.Resources, 1)
+}
+
+func (s *CountStrategyTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(data).Error())
+	return r
+}
+
+func (s *CountStrategyTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+
+	spc := new(spec.ServiceProviderConfig)
+	require.Nil(s.T(), json.Unmarshal([]byte(`
+{
+  "filter": {
+    "supported": true
+  }
+}
+`), spc))
+	s.config = scim.NewConfig(scim.WithServiceProviderConfig(spc))
+}
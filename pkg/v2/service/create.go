@@ -6,18 +6,21 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 	"io"
 	"io/ioutil"
 )
 
 // Create returns a create resource service.
-func CreateService(resourceType *spec.ResourceType, database db.DB, filters []filter.ByResource) Create {
+func CreateService(config *scim.Config, resourceType *spec.ResourceType, database db.DB, filters []filter.ByResource) Create {
 	return &createService{
 		resourceType: resourceType,
 		filters:      filters,
 		database:     database,
+		config:       config,
 	}
 }
 
@@ -28,11 +31,14 @@ type (
 	}
 	// Create resource request
 	CreateRequest struct {
-		PayloadSource io.Reader // reader source to read resource payload from
+		PayloadSource  io.Reader // reader source to read resource payload from
+		IdempotencyKey string    // optional client supplied key identifying the logical operation (see IdempotentCreateService)
 	}
 	// Create resource response
 	CreateResponse struct {
 		Resource *prop.Resource // the created resource
+		Replaced bool           // true if an existing resource was updated in place instead of created (see UpsertCreateService)
+		Replayed bool           // true if the response replays an earlier creation recorded under the same idempotency key (see IdempotentCreateService)
 	}
 )
 
@@ -40,15 +46,26 @@ type createService struct {
 	resourceType *spec.ResourceType
 	filters      []filter.ByResource
 	database     db.DB
+	config       *scim.Config
 }
 
 func (s *createService) Do(ctx context.Context, req *CreateRequest) (resp *CreateResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.create")
+	span.SetTag("scim.resourceType", s.resourceType.Name())
+	defer func() { span.SetError(err); span.End() }()
+
 	resource, err := s.parseResource(req)
 	if err != nil {
 		return
 	}
 
 	for _, f := range s.filters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
 		if err = f.Filter(ctx, resource); err != nil {
 			return
 		}
@@ -58,7 +75,7 @@ func (s *createService) Do(ctx context.Context, req *CreateRequest) (resp *Creat
 		return
 	}
 
-	resp = &CreateResponse{Resource: resource}
+	resp = &CreateResponse{Resource: maskResponse(ctx, s.config, scrubResponse(resource))}
 	return
 }
 
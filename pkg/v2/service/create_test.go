@@ -7,6 +7,7 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +32,7 @@ type CreateServiceTestSuite struct {
 func (s *CreateServiceTestSuite) TestDo() {
 	defaultSetup := func(t *testing.T) Create {
 		memoryDB := db.Memory()
-		return CreateService(s.resourceType, memoryDB, []filter.ByResource{
+		return CreateService(scim.NewConfig(), s.resourceType, memoryDB, []filter.ByResource{
 			filter.ByPropertyToByResource(
 				filter.ReadOnlyFilter(),
 				filter.UUIDFilter(),
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 )
 
 // DeleteService returns a delete resource service
-func DeleteService(config *spec.ServiceProviderConfig, database db.DB) Delete {
+func DeleteService(config *scim.Config, database db.DB) Delete {
 	return &deleteService{
 		Database: database,
 		Config:   config,
@@ -34,16 +36,23 @@ type (
 
 type deleteService struct {
 	Database db.DB
-	Config   *spec.ServiceProviderConfig
+	Config   *scim.Config
 }
 
 func (s *deleteService) Do(ctx context.Context, req *DeleteRequest) (resp *DeleteResponse, err error) {
+	ctx, cancel := s.Config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.delete")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
 	resource, err := s.Database.Get(ctx, req.ResourceID, nil)
 	if err != nil {
 		return
 	}
 
-	if s.Config.ETag.Supported && req.MatchCriteria != nil {
+	if s.Config.ETagSupported() && req.MatchCriteria != nil {
 		if !req.MatchCriteria(resource) {
 			err = fmt.Errorf("%w: resource does not meet pre condition", spec.ErrConflict)
 			return
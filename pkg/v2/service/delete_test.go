@@ -7,6 +7,7 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,7 +42,7 @@ func (s *DeleteServiceTestSuite) TestDo() {
 					"id": "foobar",
 				}))
 				require.Nil(t, err)
-				return DeleteService(&spec.ServiceProviderConfig{}, database)
+				return DeleteService(scim.NewConfig(), database)
 			},
 			getRequest: func() *DeleteRequest {
 				return &DeleteRequest{
@@ -55,7 +56,7 @@ func (s *DeleteServiceTestSuite) TestDo() {
 		{
 			name: "delete non-existing",
 			setup: func(t *testing.T) Delete {
-				return DeleteService(&spec.ServiceProviderConfig{}, db.Memory())
+				return DeleteService(scim.NewConfig(), db.Memory())
 			},
 			getRequest: func() *DeleteRequest {
 				return &DeleteRequest{
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// SchemasService returns a schema discovery service enumerating the schemas registered with
+// spec.Schemas, including extension schemas. The core schema is an internal artifact and is never
+// enumerated.
+func SchemasService(config *scim.Config, visible Visibility) Schemas {
+	return &schemasService{config: config, visible: visible}
+}
+
+// ResourceTypesService returns a resource type discovery service enumerating the given resource types.
+func ResourceTypesService(config *scim.Config, visible Visibility, resourceTypes ...*spec.ResourceType) ResourceTypes {
+	return &resourceTypesService{config: config, visible: visible, resourceTypes: resourceTypes}
+}
+
+type (
+	// Visibility decides whether the schema or resource type identified by id is visible to the
+	// request context. It allows deployments to scope the discovery endpoints to what the current
+	// tenant has enabled. A nil Visibility makes everything visible.
+	Visibility func(ctx context.Context, id string) bool
+	// Schemas discovery service
+	Schemas interface {
+		Do(ctx context.Context, req *SchemasRequest) (resp *QueryResponse, err error)
+	}
+	// Schemas discovery request
+	SchemasRequest struct {
+		SchemaID   string           // when non-empty, only the schema with this id is returned
+		Projection *crud.Projection // attributes or excludedAttributes to honor at render time
+	}
+	// ResourceTypes discovery service
+	ResourceTypes interface {
+		Do(ctx context.Context, req *ResourceTypesRequest) (resp *QueryResponse, err error)
+	}
+	// ResourceTypes discovery request
+	ResourceTypesRequest struct {
+		ResourceTypeID string           // when non-empty, only the resource type with this id is returned
+		Projection     *crud.Projection // attributes or excludedAttributes to honor at render time
+	}
+)
+
+// TenantVisibility returns a Visibility backed by a static map of tenant id to enabled schema or
+// resource type ids. Requests carrying a tenant id absent from the map, including requests carrying
+// no tenant id at all, see everything.
+func TenantVisibility(enabled map[string][]string) Visibility {
+	index := map[string]map[string]struct{}{}
+	for tenantId, ids := range enabled {
+		index[tenantId] = map[string]struct{}{}
+		for _, id := range ids {
+			index[tenantId][id] = struct{}{}
+		}
+	}
+	return func(ctx context.Context, id string) bool {
+		ids, ok := index[tenant.FromContext(ctx)]
+		if !ok {
+			return true
+		}
+		_, ok = ids[id]
+		return ok
+	}
+}
+
+type schemasService struct {
+	config  *scim.Config
+	visible Visibility
+}
+
+func (s *schemasService) Do(ctx context.Context, req *SchemasRequest) (resp *QueryResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.schemas")
+	defer func() { span.SetError(err); span.End() }()
+
+	resources := make([]json.Serializable, 0)
+	_ = spec.Schemas().ForEachSchema(func(schema *spec.Schema) error {
+		switch {
+		case schema.ID() == spec.CoreSchemaId:
+		case len(req.SchemaID) > 0 && schema.ID() != req.SchemaID:
+		case s.visible != nil && !s.visible(ctx, schema.ID()):
+		default:
+			resources = append(resources, json.SchemaToSerializable(schema))
+		}
+		return nil
+	})
+
+	if len(req.SchemaID) > 0 && len(resources) == 0 {
+		err = fmt.Errorf("%w: schema is not found", spec.ErrNotFound)
+		return
+	}
+
+	resp = &QueryResponse{
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+		Projection:   req.Projection,
+	}
+	return
+}
+
+type resourceTypesService struct {
+	config        *scim.Config
+	visible       Visibility
+	resourceTypes []*spec.ResourceType
+}
+
+func (s *resourceTypesService) Do(ctx context.Context, req *ResourceTypesRequest) (resp *QueryResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.resourceTypes")
+	defer func() { span.SetError(err); span.End() }()
+
+	resources := make([]json.Serializable, 0)
+	for _, resourceType := range s.resourceTypes {
+		switch {
+		case len(req.ResourceTypeID) > 0 && resourceType.ID() != req.ResourceTypeID:
+		case s.visible != nil && !s.visible(ctx, resourceType.ID()):
+		default:
+			resources = append(resources, json.ResourceTypeToSerializable(resourceType))
+		}
+	}
+
+	if len(req.ResourceTypeID) > 0 && len(resources) == 0 {
+		err = fmt.Errorf("%w: resource type is not found", spec.ErrNotFound)
+		return
+	}
+
+	resp = &QueryResponse{
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+		Projection:   req.Projection,
+	}
+	return
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestDiscoveryServices(t *testing.T) {
+	s := new(DiscoveryServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type DiscoveryServiceTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+	config       *scim.Config
+}
+
+func (s *DiscoveryServiceTestSuite) TestSchemas() {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T) Schemas
+		getRequest func() *SchemasRequest
+		expect     func(t *testing.T, resp *QueryResponse, err error)
+	}{
+		{
+			name: "list includes extension schemas",
+			setup: func(t *testing.T) Schemas {
+				return SchemasService(s.config, nil)
+			},
+			getRequest: func() *SchemasRequest {
+				return &SchemasRequest{}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				ids := s.serializedIds(resp)
+				assert.Contains(t, ids, "urn:ietf:params:scim:schemas:core:2.0:User")
+				assert.Contains(t, ids, "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User")
+				assert.NotContains(t, ids, spec.CoreSchemaId)
+			},
+		},
+		{
+			name: "get by id",
+			setup: func(t *testing.T) Schemas {
+				return SchemasService(s.config, nil)
+			},
+			getRequest: func() *SchemasRequest {
+				return &SchemasRequest{SchemaID: "urn:ietf:params:scim:schemas:core:2.0:User"}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				assert.Len(t, resp.Resources, 1)
+			},
+		},
+		{
+			name: "get by unknown id returns not found",
+			setup: func(t *testing.T) Schemas {
+				return SchemasService(s.config, nil)
+			},
+			getRequest: func() *SchemasRequest {
+				return &SchemasRequest{SchemaID: "urn:ietf:params:scim:schemas:core:2.0:Foobar"}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.True(t, errors.Is(err, spec.ErrNotFound))
+			},
+		},
+		{
+			name: "projection is carried to the response",
+			setup: func(t *testing.T) Schemas {
+				return SchemasService(s.config, nil)
+			},
+			getRequest: func() *SchemasRequest {
+				return &SchemasRequest{Projection: &crud.Projection{Attributes: []string{"id", "name"}}}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				require.NotNil(t, resp.Projection)
+				assert.Equal(t, []string{"id", "name"}, resp.Projection.Attributes)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			svc := test.setup(t)
+			resp, err := svc.Do(context.Background(), test.getRequest())
+			test.expect(t, resp, err)
+		})
+	}
+}
+
+func (s *DiscoveryServiceTestSuite) TestResourceTypes() {
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T) ResourceTypes
+		getContext func() context.Context
+		getRequest func() *ResourceTypesRequest
+		expect     func(t *testing.T, resp *QueryResponse, err error)
+	}{
+		{
+			name: "list all",
+			setup: func(t *testing.T) ResourceTypes {
+				return ResourceTypesService(s.config, nil, s.resourceType)
+			},
+			getContext: context.Background,
+			getRequest: func() *ResourceTypesRequest {
+				return &ResourceTypesRequest{}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, resp.TotalResults)
+			},
+		},
+		{
+			name: "get by unknown id returns not found",
+			setup: func(t *testing.T) ResourceTypes {
+				return ResourceTypesService(s.config, nil, s.resourceType)
+			},
+			getContext: context.Background,
+			getRequest: func() *ResourceTypesRequest {
+				return &ResourceTypesRequest{ResourceTypeID: "Foobar"}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.True(t, errors.Is(err, spec.ErrNotFound))
+			},
+		},
+		{
+			name: "tenant without the resource type enabled sees nothing",
+			setup: func(t *testing.T) ResourceTypes {
+				return ResourceTypesService(s.config, TenantVisibility(map[string][]string{
+					"tenant1": {},
+				}), s.resourceType)
+			},
+			getContext: func() context.Context {
+				return tenant.WithContext(context.Background(), "tenant1")
+			},
+			getRequest: func() *ResourceTypesRequest {
+				return &ResourceTypesRequest{}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 0, resp.TotalResults)
+			},
+		},
+		{
+			name: "tenant not configured sees everything",
+			setup: func(t *testing.T) ResourceTypes {
+				return ResourceTypesService(s.config, TenantVisibility(map[string][]string{
+					"tenant1": {},
+				}), s.resourceType)
+			},
+			getContext: func() context.Context {
+				return tenant.WithContext(context.Background(), "tenant2")
+			},
+			getRequest: func() *ResourceTypesRequest {
+				return &ResourceTypesRequest{}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 1, resp.TotalResults)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			svc := test.setup(t)
+			resp, err := svc.Do(test.getContext(), test.getRequest())
+			test.expect(t, resp, err)
+		})
+	}
+}
+
+func (s *DiscoveryServiceTestSuite) serializedIds(resp *QueryResponse) []string {
+	var ids []string
+	for _, serializable := range resp.Resources {
+		raw, err := scimjson.Serialize(serializable)
+		require.Nil(s.T(), err)
+		var parsed struct {
+			Id string `json:"id"`
+		}
+		require.Nil(s.T(), json.Unmarshal(raw, &parsed))
+		ids = append(ids, parsed.Id)
+	}
+	return ids
+}
+
+func (s *DiscoveryServiceTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+
+	s.config = scim.NewConfig()
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/scope"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// GetByExternalIdService returns a service that looks up a single resource by its externalId. Because
+// externalId is scoped to the provisioning client that assigned it, the lookup is restricted to resources
+// provisioned by the client carried on the context (see the scope package), when one is present.
+func GetByExternalIdService(database db.DB) GetByExternalId {
+	return &getByExternalIdService{database: database}
+}
+
+type (
+	// GetByExternalId resource service
+	GetByExternalId interface {
+		Do(ctx context.Context, externalId string) (resp *GetResponse, err error)
+	}
+)
+
+type getByExternalIdService struct {
+	database db.DB
+}
+
+func (s *getByExternalIdService) Do(ctx context.Context, externalId string) (resp *GetResponse, err error) {
+	ctx, span := trace.StartSpan(ctx, "service.getByExternalId")
+	span.SetTag("scim.externalId", externalId)
+	defer func() { span.SetError(err); span.End() }()
+
+	if len(externalId) == 0 {
+		err = fmt.Errorf("%w: externalId is required", spec.ErrInvalidValue)
+		return
+	}
+
+	filter := fmt.Sprintf("externalId eq %s", strconv.Quote(externalId))
+	if clientId := scope.ClientFromContext(ctx); len(clientId) > 0 {
+		filter = fmt.Sprintf("(%s) and (meta.clientId eq %s)", filter, strconv.Quote(clientId))
+	}
+
+	resources, err := s.database.Query(ctx, filter, nil, nil, nil)
+	if err != nil {
+		return
+	}
+
+	switch len(resources) {
+	case 0:
+		err = fmt.Errorf("%w: no resource with externalId '%s'", spec.ErrNotFound, externalId)
+	case 1:
+		resp = &GetResponse{Resource: resources[0]}
+	default:
+		// should not happen when the uniqueness filter is active, but guard against ambiguity
+		// from resources created before externalId became unique.
+		err = fmt.Errorf("%w: externalId '%s' is ambiguous", spec.ErrConflict, externalId)
+	}
+	return
+}
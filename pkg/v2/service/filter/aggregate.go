@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// AggregateValidation returns a ByResource that, like ByPropertyToByResource, iterates each property in
+// the resource and invokes the list of ByProperty filters on it, but keeps visiting after a validation
+// violation instead of failing fast. Violations are collected and returned together as a ValidationErrors,
+// so that a client correcting a payload learns every failing path in a single round trip. Errors that are
+// not validation violations, such as internal or database errors, still abort the visit immediately.
+func AggregateValidation(filters ...ByProperty) ByResource {
+	return aggregateResourceFilter{byPropertyFilters: filters}
+}
+
+type aggregateResourceFilter struct {
+	byPropertyFilters []ByProperty
+}
+
+func (f aggregateResourceFilter) Filter(ctx context.Context, resource *prop.Resource) error {
+	c := new(violationCollector)
+	err := Visit(ctx, resource, c.wrap(f.byPropertyFilters)...)
+	return c.result(err)
+}
+
+func (f aggregateResourceFilter) FilterRef(ctx context.Context, resource *prop.Resource, ref *prop.Resource) error {
+	c := new(violationCollector)
+	err := VisitWithRef(ctx, resource, ref, c.wrap(f.byPropertyFilters)...)
+	return c.result(err)
+}
+
+// ValidationErrors aggregates two or more validation violations raised against a single resource. It
+// unwraps to the first violation, so that callers testing the error chain with errors.Is or errors.As,
+// such as handlerutil.WriteError, resolve the SCIM error status as usual; the message lists every
+// violation.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	details := make([]string, 0, len(e))
+	for _, each := range e {
+		details = append(details, each.Error())
+	}
+	return strings.Join(details, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[0]
+}
+
+type violationCollector struct {
+	violations []error
+}
+
+func (c *violationCollector) wrap(filters []ByProperty) []ByProperty {
+	wrapped := make([]ByProperty, 0, len(filters))
+	for _, f := range filters {
+		wrapped = append(wrapped, &collectingPropertyFilter{inner: f, collector: c})
+	}
+	return wrapped
+}
+
+func (c *violationCollector) collect(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, violation := range []error{spec.ErrInvalidValue, spec.ErrMutability, spec.ErrUniqueness} {
+		if errors.Is(err, violation) {
+			c.violations = append(c.violations, err)
+			return nil
+		}
+	}
+	return err
+}
+
+func (c *violationCollector) result(err error) error {
+	if err != nil {
+		return err
+	}
+	switch len(c.violations) {
+	case 0:
+		return nil
+	case 1:
+		return c.violations[0]
+	default:
+		return ValidationErrors(c.violations)
+	}
+}
+
+type collectingPropertyFilter struct {
+	inner     ByProperty
+	collector *violationCollector
+}
+
+func (f *collectingPropertyFilter) Supports(attribute *spec.Attribute) bool {
+	return f.inner.Supports(attribute)
+}
+
+func (f *collectingPropertyFilter) Filter(ctx context.Context, resourceType *spec.ResourceType, nav prop.Navigator) error {
+	return f.collector.collect(f.inner.Filter(ctx, resourceType, nav))
+}
+
+func (f *collectingPropertyFilter) FilterRef(ctx context.Context, resourceType *spec.ResourceType, nav prop.Navigator, refNav prop.Navigator) error {
+	return f.collector.collect(f.inner.FilterRef(ctx, resourceType, nav, refNav))
+}
@@ -0,0 +1,137 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAggregateValidation(t *testing.T) {
+	var resourceType *spec.ResourceType
+	{
+		for _, each := range []struct {
+			filepath  string
+			structure interface{}
+			post      func(parsed interface{})
+		}{
+			{
+				filepath:  "../../../../public/schemas/core_schema.json",
+				structure: new(spec.Schema),
+				post: func(parsed interface{}) {
+					spec.Schemas().Register(parsed.(*spec.Schema))
+				},
+			},
+			{
+				filepath:  "../../../../public/schemas/user_schema.json",
+				structure: new(spec.Schema),
+				post: func(parsed interface{}) {
+					spec.Schemas().Register(parsed.(*spec.Schema))
+				},
+			},
+			{
+				filepath:  "../../../../public/schemas/user_enterprise_extension_schema.json",
+				structure: new(spec.Schema),
+				post: func(parsed interface{}) {
+					spec.Schemas().Register(parsed.(*spec.Schema))
+				},
+			},
+			{
+				filepath:  "../../../../public/resource_types/user_resource_type.json",
+				structure: new(spec.ResourceType),
+				post: func(parsed interface{}) {
+					resourceType = parsed.(*spec.ResourceType)
+				},
+			},
+		} {
+			f, err := os.Open(each.filepath)
+			require.Nil(t, err)
+			raw, err := ioutil.ReadAll(f)
+			require.Nil(t, err)
+			err = json.Unmarshal(raw, each.structure)
+			require.Nil(t, err)
+			if each.post != nil {
+				each.post(each.structure)
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		getResource func(t *testing.T) *prop.Resource
+		expect      func(t *testing.T, err error)
+	}{
+		{
+			name: "all violations are reported together",
+			getResource: func(t *testing.T) *prop.Resource {
+				return prop.NewResource(resourceType)
+			},
+			expect: func(t *testing.T, err error) {
+				require.NotNil(t, err)
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+
+				var violations ValidationErrors
+				require.True(t, errors.As(err, &violations))
+				assert.True(t, len(violations) >= 3)
+				for _, path := range []string{"schemas", "userName", "emails"} {
+					assert.Contains(t, err.Error(), path)
+				}
+			},
+		},
+		{
+			name: "a single violation is returned as is",
+			getResource: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(resourceType)
+				require.False(t, r.Navigator().Replace(map[string]interface{}{
+					"schemas": []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+					"id":      "u1",
+					"emails": []interface{}{
+						map[string]interface{}{"value": "imulab@foo.com"},
+					},
+				}).HasError())
+				return r
+			},
+			expect: func(t *testing.T, err error) {
+				require.NotNil(t, err)
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+
+				var violations ValidationErrors
+				assert.False(t, errors.As(err, &violations))
+				assert.Contains(t, err.Error(), "userName")
+			},
+		},
+		{
+			name: "a valid resource passes",
+			getResource: func(t *testing.T) *prop.Resource {
+				r := prop.NewResource(resourceType)
+				require.False(t, r.Navigator().Replace(map[string]interface{}{
+					"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+					"id":       "u1",
+					"userName": "imulab",
+					"emails": []interface{}{
+						map[string]interface{}{"value": "imulab@foo.com"},
+					},
+				}).HasError())
+				return r
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := AggregateValidation(ValidationFilter(db.Memory()))
+			err := f.Filter(context.Background(), test.getResource(t))
+			test.expect(t, err)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+// IdGenerator generates resource identifiers for properties annotated with @UUID. The default
+// generator produces random version 4 UUIDs, which is the right choice for most deployments.
+// Stores that sort or shard by id may prefer k-ordered identifiers instead, such as the ones
+// produced by ULIDGenerator, or plug in their own format (i.e. KSUID, snowflake) by implementing
+// this interface.
+type IdGenerator interface {
+	// Generate returns a new unique identifier.
+	Generate() string
+}
+
+// IdGeneratorFunc is the functional adapter of IdGenerator.
+type IdGeneratorFunc func() string
+
+func (f IdGeneratorFunc) Generate() string {
+	return f()
+}
+
+// UUIDGenerator returns the default IdGenerator producing random version 4 UUIDs.
+func UUIDGenerator() IdGenerator {
+	return IdGeneratorFunc(func() string {
+		return uuid.NewV4().String()
+	})
+}
+
+// ULIDGenerator returns an IdGenerator producing ULIDs: 26 character Crockford base32 encoded
+// identifiers composed of a 48 bit millisecond timestamp followed by 80 bits of randomness. ULIDs
+// generated in different milliseconds sort lexicographically in generation order, making them
+// suitable for stores that sort and shard by id.
+func ULIDGenerator() IdGenerator {
+	return IdGeneratorFunc(func() string {
+		var b [16]byte
+		now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+		for i := 0; i < 6; i++ {
+			b[i] = byte(now >> (40 - 8*i))
+		}
+		_, _ = rand.Read(b[6:])
+		return encodeCrockford(b)
+	})
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// encodeCrockford renders the 128 bits as 26 Crockford base32 characters, reading the bytes as a
+// big-endian bit stream padded with two leading zero bits.
+func encodeCrockford(b [16]byte) string {
+	var out [26]byte
+	for i := 0; i < 26; i++ {
+		var v byte
+		for j := 0; j < 5; j++ {
+			pos := i*5 + j - 2
+			v <<= 1
+			if pos >= 0 && b[pos/8]&(1<<uint(7-pos%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockfordAlphabet[v]
+	}
+	return string(out[:])
+}
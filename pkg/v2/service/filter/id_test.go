@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULIDGenerator(t *testing.T) {
+	generator := ULIDGenerator()
+
+	first := generator.Generate()
+	assert.Len(t, first, 26)
+	for _, c := range first {
+		assert.Contains(t, crockfordAlphabet, string(c))
+	}
+
+	// identifiers generated in different milliseconds sort in generation order
+	time.Sleep(2 * time.Millisecond)
+	second := generator.Generate()
+	assert.NotEqual(t, first, second)
+
+	ids := []string{second, first}
+	sort.Strings(ids)
+	assert.Equal(t, []string{first, second}, ids)
+}
+
+func TestIdFilter(t *testing.T) {
+	attr := new(spec.Attribute)
+	require.Nil(t, json.Unmarshal([]byte(`
+{
+  "id": "id",
+  "name": "id",
+  "type": "string",
+  "_annotations": {
+    "@UUID": {}
+  }
+}
+`), attr))
+
+	filter := IdFilter(IdGeneratorFunc(func() string {
+		return "generated-id"
+	}))
+	assert.True(t, filter.Supports(attr))
+
+	property := prop.NewProperty(attr)
+	err := filter.Filter(context.Background(), nil, prop.Navigate(property))
+	assert.Nil(t, err)
+	assert.Equal(t, "generated-id", property.Raw())
+
+	// an assigned property is left untouched
+	_, err = property.Replace("foobar")
+	require.Nil(t, err)
+	err = filter.Filter(context.Background(), nil, prop.Navigate(property))
+	assert.Nil(t, err)
+	assert.Equal(t, "foobar", property.Raw())
+}
@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+const enterpriseUserUrn = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+// EnterpriseManagerFilter returns a ByResource filter that enriches the manager complex property of
+// the enterprise User extension. When manager.value is set, the referenced User is looked up in the
+// given database and manager.$ref and manager.displayName are populated from it, so clients only
+// need to send the manager's id. A manager.value that does not reference an existing User fails the
+// filter. Resources without the enterprise extension, or without an assigned manager.value, are left
+// untouched.
+func EnterpriseManagerFilter(database db.DB) ByResource {
+	return managerFilter{database: database}
+}
+
+type managerFilter struct {
+	database db.DB
+}
+
+func (f managerFilter) Filter(ctx context.Context, resource *prop.Resource) error {
+	return f.enrich(ctx, resource)
+}
+
+func (f managerFilter) FilterRef(ctx context.Context, resource *prop.Resource, _ *prop.Resource) error {
+	return f.enrich(ctx, resource)
+}
+
+func (f managerFilter) enrich(ctx context.Context, resource *prop.Resource) error {
+	if resource.RootAttribute().SubAttributeForName(enterpriseUserUrn) == nil {
+		return nil
+	}
+
+	nav := resource.Navigator().Dot(enterpriseUserUrn).Dot("manager")
+	if nav.HasError() {
+		return nil
+	}
+
+	valueProp, err := nav.Current().ChildAtIndex("value")
+	if err != nil || valueProp.IsUnassigned() {
+		return nil
+	}
+	managerId, ok := valueProp.Raw().(string)
+	if !ok || len(managerId) == 0 {
+		return nil
+	}
+
+	manager, err := f.database.Get(ctx, managerId, nil)
+	if err != nil {
+		if errors.Is(err, spec.ErrNotFound) {
+			return fmt.Errorf("%w: manager '%s' does not reference an existing User", spec.ErrInvalidValue, managerId)
+		}
+		return err
+	}
+
+	ref := strings.TrimSuffix(manager.ResourceType().Endpoint(), "/") + "/" + managerId
+	if refProp, err := nav.Current().ChildAtIndex("$ref"); err == nil {
+		if raw, ok := refProp.Raw().(string); !ok || raw != ref {
+			if _, err := refProp.Replace(ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	displayName := f.displayNameOf(manager)
+	if len(displayName) > 0 {
+		if displayProp, err := nav.Current().ChildAtIndex("displayName"); err == nil {
+			if raw, ok := displayProp.Raw().(string); !ok || raw != displayName {
+				if _, err := displayProp.Replace(displayName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// displayNameOf returns the display name of the manager, falling back to the user name when the
+// displayName attribute is unassigned.
+func (f managerFilter) displayNameOf(manager *prop.Resource) string {
+	for _, path := range []string{"displayName", "userName"} {
+		nav := manager.Navigator().Dot(path)
+		if nav.HasError() || nav.Current().IsUnassigned() {
+			continue
+		}
+		if name, ok := nav.Current().Raw().(string); ok && len(name) > 0 {
+			return name
+		}
+	}
+	return ""
+}
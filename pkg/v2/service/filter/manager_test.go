@@ -0,0 +1,200 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEnterpriseManagerFilter(t *testing.T) {
+	s := new(EnterpriseManagerFilterTestSuite)
+	suite.Run(t, s)
+}
+
+type EnterpriseManagerFilterTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *EnterpriseManagerFilterTestSuite) TestFilter() {
+	tests := []struct {
+		name        string
+		getDatabase func(t *testing.T) db.DB
+		getResource func(t *testing.T) *prop.Resource
+		expect      func(t *testing.T, resource *prop.Resource, err error)
+	}{
+		{
+			name: "populates $ref and displayName from referenced user",
+			getDatabase: func(t *testing.T) db.DB {
+				database := db.Memory()
+				require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"id":          "user002",
+					"userName":    "boss",
+					"displayName": "The Boss",
+				})))
+				return database
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user002",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().
+					Dot("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User").
+					Dot("manager")
+				require.False(t, nav.HasError())
+				assert.Equal(t, map[string]interface{}{
+					"value":       "user002",
+					"$ref":        "/Users/user002",
+					"displayName": "The Boss",
+				}, nav.Current().Raw())
+			},
+		},
+		{
+			name: "displayName falls back to userName",
+			getDatabase: func(t *testing.T) db.DB {
+				database := db.Memory()
+				require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"id":       "user002",
+					"userName": "boss",
+				})))
+				return database
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user002",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().
+					Dot("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User").
+					Dot("manager").
+					Dot("displayName")
+				require.False(t, nav.HasError())
+				assert.Equal(t, "boss", nav.Current().Raw())
+			},
+		},
+		{
+			name: "rejects manager that does not exist",
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user404",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+			},
+		},
+		{
+			name: "leaves resource without manager untouched",
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id":       "user001",
+					"userName": "imulab",
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			filter := EnterpriseManagerFilter(test.getDatabase(t))
+			resource := test.getResource(t)
+			err := filter.Filter(context.Background(), resource)
+			test.expect(t, resource, err)
+		})
+	}
+}
+
+func (s *EnterpriseManagerFilterTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(data).Error())
+	return r
+}
+
+func (s *EnterpriseManagerFilterTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scope"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"math/rand"
 	"strings"
@@ -17,9 +18,28 @@ func MetaFilter() ByResource {
 	return metaFilter{}
 }
 
-type metaFilter struct{}
+// MetaFilterWithLocation returns a ByResource filter that behaves the same as the filter returned by MetaFilter,
+// except that meta.location is produced by the supplied formatter instead of the default of joining the resource
+// type endpoint and the resource id. Use this to render tenant scoped or absolute location URLs.
+func MetaFilterWithLocation(formatter func(ctx context.Context, resourceType *spec.ResourceType, id string) string) ByResource {
+	return metaFilter{location: formatter}
+}
+
+// MetaFilterWithVersion returns a ByResource filter that behaves the same as the filter returned by
+// MetaFilter, except that meta.version is produced by the supplied generator instead of the default
+// of hashing the resource id together with a random number. Use this to make versions deterministic
+// and comparable across replicas (i.e. HashVersionGenerator, CounterVersionGenerator) or to adopt a
+// revision provided by the database.
+func MetaFilterWithVersion(generator VersionGenerator) ByResource {
+	return metaFilter{version: generator}
+}
+
+type metaFilter struct {
+	location func(ctx context.Context, resourceType *spec.ResourceType, id string) string
+	version  VersionGenerator
+}
 
-func (f metaFilter) Filter(_ context.Context, resource *prop.Resource) error {
+func (f metaFilter) Filter(ctx context.Context, resource *prop.Resource) error {
 	nav := resource.Navigator()
 	if nav.Dot("meta").HasError() {
 		return nav.Error()
@@ -34,17 +54,20 @@ func (f metaFilter) Filter(_ context.Context, resource *prop.Resource) error {
 	if err := f.assignLastModifiedToNow(nav); err != nil {
 		return err
 	}
-	if err := f.assignLocation(nav, resource); err != nil {
+	if err := f.assignLocation(ctx, nav, resource); err != nil {
 		return err
 	}
-	if err := f.assignNewVersion(nav, resource); err != nil {
+	if err := f.assignNewVersion(ctx, nav, resource); err != nil {
+		return err
+	}
+	if err := f.assignClientId(ctx, nav); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (f metaFilter) FilterRef(_ context.Context, resource *prop.Resource, ref *prop.Resource) error {
+func (f metaFilter) FilterRef(ctx context.Context, resource *prop.Resource, ref *prop.Resource) error {
 	if resource.Hash() == ref.Hash() {
 		return nil
 	}
@@ -57,7 +80,7 @@ func (f metaFilter) FilterRef(_ context.Context, resource *prop.Resource, ref *p
 	if err := f.assignLastModifiedToNow(nav); err != nil {
 		return err
 	}
-	if err := f.assignNewVersion(nav, resource); err != nil {
+	if err := f.assignNewVersion(ctx, nav, resource); err != nil {
 		return err
 	}
 
@@ -91,7 +114,7 @@ func (f metaFilter) assignLastModifiedToNow(nav prop.Navigator) error {
 	return nav.Replace(time.Now().Format(spec.ISO8601)).Error()
 }
 
-func (f metaFilter) assignLocation(nav prop.Navigator, resource *prop.Resource) error {
+func (f metaFilter) assignLocation(ctx context.Context, nav prop.Navigator, resource *prop.Resource) error {
 	if nav.Dot("location").HasError() {
 		return nav.Error()
 	}
@@ -102,16 +125,43 @@ func (f metaFilter) assignLocation(nav prop.Navigator, resource *prop.Resource)
 		return fmt.Errorf("%w: empty id", spec.ErrInternal)
 	}
 
-	location := strings.TrimSuffix(resource.ResourceType().Endpoint(), "/") + "/" + id
+	var location string
+	if f.location != nil {
+		location = f.location(ctx, resource.ResourceType(), id)
+	} else {
+		location = strings.TrimSuffix(resource.ResourceType().Endpoint(), "/") + "/" + id
+	}
 	return nav.Replace(location).Error()
 }
 
-func (f metaFilter) assignNewVersion(nav prop.Navigator, resource *prop.Resource) error {
+func (f metaFilter) assignClientId(ctx context.Context, nav prop.Navigator) error {
+	clientId := scope.ClientFromContext(ctx)
+	if len(clientId) == 0 {
+		return nil
+	}
+
+	if nav.Dot("clientId").HasError() {
+		return nav.Error()
+	}
+	defer nav.Retract()
+
+	return nav.Replace(clientId).Error()
+}
+
+func (f metaFilter) assignNewVersion(ctx context.Context, nav prop.Navigator, resource *prop.Resource) error {
 	if nav.Dot("version").HasError() {
 		return nav.Error()
 	}
 	defer nav.Retract()
 
+	if f.version != nil {
+		version, err := f.version.Generate(ctx, resource)
+		if err != nil {
+			return err
+		}
+		return nav.Replace(version).Error()
+	}
+
 	id := resource.IdOrEmpty()
 	if len(id) == 0 {
 		return fmt.Errorf("%w: empty id", spec.ErrInternal)
@@ -28,8 +28,9 @@ func IsOutOfSync(property prop.Property) bool {
 //
 // This navigator does not need an initializing Property to start with and can fully Retract to an empty stack.
 type flexNavigator struct {
-	stack []prop.Property
-	err   error
+	stack       []prop.Property
+	err         error
+	checkpoints []int
 }
 
 func (n *flexNavigator) Error() error {
@@ -44,6 +45,31 @@ func (n *flexNavigator) ClearError() {
 	n.err = nil
 }
 
+func (n *flexNavigator) Errors() []error {
+	if n.err != nil {
+		return []error{n.err}
+	}
+	return nil
+}
+
+func (n *flexNavigator) Checkpoint() prop.Navigator {
+	n.checkpoints = append(n.checkpoints, n.Depth())
+	return n
+}
+
+func (n *flexNavigator) Reset() prop.Navigator {
+	depth := 0
+	if k := len(n.checkpoints); k > 0 {
+		depth = n.checkpoints[k-1]
+		n.checkpoints = n.checkpoints[:k-1]
+	}
+	for n.Depth() > depth {
+		n.Retract()
+	}
+	n.err = nil
+	return n
+}
+
 func (n *flexNavigator) Depth() int {
 	return len(n.stack)
 }
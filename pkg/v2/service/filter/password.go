@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/annotation"
+	"github.com/imulab/go-scim/pkg/v2/password"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// PasswordFilter returns a ByProperty filter that hashes incoming values with the given hasher for
+// singular string properties annotated with @Password (or with the legacy @BCrypt annotation, so
+// existing schemas keep working without modification). If the property is unassigned, or has the
+// same value as the reference property (which can be assumed to have been hashed already), the
+// filter does nothing. The plaintext value is replaced in place and never persisted.
+func PasswordFilter(hasher password.Hasher) ByProperty {
+	return passwordPropertyFilter{hasher: hasher}
+}
+
+type passwordPropertyFilter struct {
+	hasher password.Hasher
+}
+
+func (f passwordPropertyFilter) Supports(attribute *spec.Attribute) bool {
+	_, pw := attribute.Annotation(annotation.Password)
+	_, bc := attribute.Annotation(annotation.BCrypt)
+	if !pw && !bc {
+		return false
+	}
+	return !attribute.MultiValued() && attribute.Type() == spec.TypeString
+}
+
+func (f passwordPropertyFilter) Filter(_ context.Context, _ *spec.ResourceType, nav prop.Navigator) error {
+	if nav.HasError() {
+		return nav.Error()
+	}
+
+	if nav.Current().IsUnassigned() {
+		return nil
+	}
+
+	return f.hashAndReplace(nav)
+}
+
+func (f passwordPropertyFilter) FilterRef(_ context.Context, _ *spec.ResourceType, nav prop.Navigator, refNav prop.Navigator) error {
+	if nav.HasError() {
+		return nav.Error()
+	}
+
+	if nav.Current().IsUnassigned() {
+		return nil
+	}
+
+	if refNav != nil && nav.Current().Raw() == refNav.Current().Raw() {
+		// property value is the same as reference value. Reference value
+		// are values from database that can be assumed to have undergone
+		// this filter already. Values being the same indicates the value
+		// is not new and needs no hashing.
+		return nil
+	}
+
+	return f.hashAndReplace(nav)
+}
+
+func (f passwordPropertyFilter) hashAndReplace(nav prop.Navigator) error {
+	hashed, err := f.hasher.Hash([]byte(nav.Current().Raw().(string)))
+	if err != nil {
+		return err
+	}
+
+	_, err = nav.Current().Replace(hashed)
+	return err
+}
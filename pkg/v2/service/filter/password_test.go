@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/password"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordFilter(t *testing.T) {
+	attr := new(spec.Attribute)
+	require.Nil(t, json.Unmarshal([]byte(`
+{
+  "id": "password",
+  "name": "password",
+  "type": "string",
+  "_annotations": {
+    "@Password": {}
+  }
+}
+`), attr))
+
+	tests := []struct {
+		name         string
+		getProperty  func() prop.Property
+		getReference func() prop.Property
+		expect       func(t *testing.T, p prop.Property, err error)
+	}{
+		{
+			name: "unassigned property does not hash",
+			getProperty: func() prop.Property {
+				return prop.NewProperty(attr)
+			},
+			getReference: func() prop.Property {
+				return nil
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.True(t, p.IsUnassigned())
+			},
+		},
+		{
+			name: "assigned property is hashed and verifiable",
+			getProperty: func() prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("s3cret")
+				assert.Nil(t, err)
+				return p
+			},
+			getReference: func() prop.Property {
+				return nil
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.False(t, p.IsUnassigned())
+				assert.NotEqual(t, "s3cret", p.Raw())
+				assert.Nil(t, password.Verify(p.Raw().(string), []byte("s3cret")))
+			},
+		},
+		{
+			name: "same value as reference does not hash",
+			getProperty: func() prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("pretending_to_have_been_hashed")
+				assert.Nil(t, err)
+				return p
+			},
+			getReference: func() prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("pretending_to_have_been_hashed")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "pretending_to_have_been_hashed", p.Raw())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := PasswordFilter(password.Argon2id())
+
+			property := test.getProperty()
+			reference := test.getReference()
+			assert.True(t, filter.Supports(property.Attribute()))
+
+			var err error
+			if reference == nil {
+				err = filter.Filter(context.Background(),
+					nil, prop.Navigate(property))
+			} else {
+				err = filter.FilterRef(context.Background(),
+					nil, prop.Navigate(property), prop.Navigate(reference))
+			}
+
+			test.expect(t, property, err)
+		})
+	}
+}
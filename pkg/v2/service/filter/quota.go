@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/tenant"
+)
+
+// Quota describes resource volume limits enforced by QuotaFilter. A zero or negative limit disables
+// the corresponding check, hence the zero value of Quota enforces nothing.
+type Quota struct {
+	// MaxResources caps the total number of resources of the filtered resource type. It is checked on
+	// creation only, as modifications do not change the resource count.
+	MaxResources int
+	// MaxValues caps the number of elements of multiValued attributes, keyed by attribute path (i.e.
+	// "members"). It is checked on creation and modification alike, so patch operations adding elements
+	// are subject to the same limit. Paths that do not resolve on the filtered resource type are ignored.
+	MaxValues map[string]int
+}
+
+// QuotaProvider resolves the Quota that applies to a tenant. The tenant id is empty when the server
+// does not run in multi-tenant mode, or the request is not associated with any tenant.
+type QuotaProvider func(tenantId string) Quota
+
+// UniformQuota returns a QuotaProvider that applies the same Quota to every tenant. Because databases
+// are partitioned by tenant, each tenant still consumes its own allowance.
+func UniformQuota(quota Quota) QuotaProvider {
+	return func(_ string) Quota {
+		return quota
+	}
+}
+
+// QuotaFilter returns a ByResource filter that enforces the Quota resolved for the requesting tenant,
+// rejecting operations that would exceed it with a quotaExceeded error. The resource count is compared
+// against the given database, which is expected to be the same database the filtered service writes to,
+// so that tenant partitioning is accounted for.
+func QuotaFilter(database db.DB, provider QuotaProvider) ByResource {
+	return quotaFilter{database: database, provider: provider}
+}
+
+type quotaFilter struct {
+	database db.DB
+	provider QuotaProvider
+}
+
+func (f quotaFilter) Filter(ctx context.Context, resource *prop.Resource) error {
+	quota := f.provider(tenant.FromContext(ctx))
+
+	if quota.MaxResources > 0 {
+		n, err := f.database.Count(ctx, "")
+		if err != nil {
+			return err
+		}
+		if n >= quota.MaxResources {
+			return spec.QuotaExceeded("", fmt.Sprintf(
+				"creating another '%s' resource would exceed the quota of %d",
+				resource.ResourceType().Name(), quota.MaxResources,
+			))
+		}
+	}
+
+	return f.checkValues(resource, quota)
+}
+
+func (f quotaFilter) FilterRef(ctx context.Context, resource *prop.Resource, _ *prop.Resource) error {
+	return f.checkValues(resource, f.provider(tenant.FromContext(ctx)))
+}
+
+func (f quotaFilter) checkValues(resource *prop.Resource, quota Quota) error {
+	for path, max := range quota.MaxValues {
+		if max <= 0 {
+			continue
+		}
+
+		nav := resource.Navigator()
+		for _, step := range strings.Split(path, ".") {
+			nav = nav.Dot(step)
+		}
+		if nav.HasError() {
+			continue
+		}
+
+		if !nav.Current().Attribute().MultiValued() {
+			continue
+		}
+		if n := nav.Current().CountChildren(); n > max {
+			return spec.QuotaExceeded(path, fmt.Sprintf(
+				"'%s' contains %d values, exceeding the quota of %d", path, n, max,
+			))
+		}
+	}
+
+	return nil
+}
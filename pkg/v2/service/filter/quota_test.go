@@ -0,0 +1,222 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestQuotaFilter(t *testing.T) {
+	s := new(QuotaFilterTestSuite)
+	suite.Run(t, s)
+}
+
+type QuotaFilterTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *QuotaFilterTestSuite) TestFilter() {
+	tests := []struct {
+		name        string
+		quota       Quota
+		getDatabase func(t *testing.T) db.DB
+		getResource func(t *testing.T) *prop.Resource
+		expect      func(t *testing.T, err error)
+	}{
+		{
+			name:  "zero value quota enforces nothing",
+			quota: Quota{},
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{"id": "group001"})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:  "creation below resource quota passes",
+			quota: Quota{MaxResources: 2},
+			getDatabase: func(t *testing.T) db.DB {
+				database := db.Memory()
+				require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"id": "group001",
+				})))
+				return database
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{"id": "group002"})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:  "creation at resource quota is rejected",
+			quota: Quota{MaxResources: 1},
+			getDatabase: func(t *testing.T) db.DB {
+				database := db.Memory()
+				require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"id": "group001",
+				})))
+				return database
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{"id": "group002"})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrQuotaExceeded, errors.Unwrap(err))
+			},
+		},
+		{
+			name:  "members within value quota passes",
+			quota: Quota{MaxValues: map[string]int{"members": 2}},
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "group001",
+					"members": []interface{}{
+						map[string]interface{}{"value": "user001"},
+						map[string]interface{}{"value": "user002"},
+					},
+				})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+		{
+			name:  "members exceeding value quota is rejected",
+			quota: Quota{MaxValues: map[string]int{"members": 1}},
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "group001",
+					"members": []interface{}{
+						map[string]interface{}{"value": "user001"},
+						map[string]interface{}{"value": "user002"},
+					},
+				})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrQuotaExceeded, errors.Unwrap(err))
+			},
+		},
+		{
+			name:  "unresolvable value quota path is ignored",
+			quota: Quota{MaxValues: map[string]int{"foobar": 1}},
+			getDatabase: func(t *testing.T) db.DB {
+				return db.Memory()
+			},
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{"id": "group001"})
+			},
+			expect: func(t *testing.T, err error) {
+				assert.Nil(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			filter := QuotaFilter(test.getDatabase(t), UniformQuota(test.quota))
+			err := filter.Filter(context.Background(), test.getResource(t))
+			test.expect(t, err)
+		})
+	}
+}
+
+func (s *QuotaFilterTestSuite) TestFilterRef() {
+	// modifications do not change the resource count, hence only value quotas apply
+	filter := QuotaFilter(db.Memory(), UniformQuota(Quota{
+		MaxResources: 1,
+		MaxValues:    map[string]int{"members": 1},
+	}))
+
+	resource := s.resourceOf(s.T(), map[string]interface{}{
+		"id": "group001",
+		"members": []interface{}{
+			map[string]interface{}{"value": "user001"},
+		},
+	})
+	assert.Nil(s.T(), filter.FilterRef(context.Background(), resource, resource))
+
+	over := s.resourceOf(s.T(), map[string]interface{}{
+		"id": "group001",
+		"members": []interface{}{
+			map[string]interface{}{"value": "user001"},
+			map[string]interface{}{"value": "user002"},
+		},
+	})
+	err := filter.FilterRef(context.Background(), over, resource)
+	assert.NotNil(s.T(), err)
+	assert.Equal(s.T(), spec.ErrQuotaExceeded, errors.Unwrap(err))
+}
+
+func (s *QuotaFilterTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(data).Error())
+	return r
+}
+
+func (s *QuotaFilterTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
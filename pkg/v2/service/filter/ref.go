@@ -0,0 +1,179 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// NewReferenceResolver returns an empty ReferenceResolver. Resource types that may be referenced
+// shall be registered along with their database before use.
+func NewReferenceResolver() *ReferenceResolver {
+	return &ReferenceResolver{
+		types:    map[string]*spec.ResourceType{},
+		dbs:      map[string]db.DB{},
+		attrMaps: map[string][]string{},
+	}
+}
+
+// ReferenceResolver resolves reference properties (i.e. members.$ref, manager.$ref) to the resource
+// type and database of the resources they point at. Candidate resource types for an attribute are
+// determined by its referenceTypes, or by an explicit mapping registered through MapAttribute; when
+// neither is declared, all registered resource types are candidates.
+type ReferenceResolver struct {
+	types    map[string]*spec.ResourceType
+	dbs      map[string]db.DB
+	attrMaps map[string][]string
+}
+
+// Register adds a resource type and its database to the resolver.
+func (r *ReferenceResolver) Register(resourceType *spec.ResourceType, database db.DB) *ReferenceResolver {
+	r.types[resourceType.Name()] = resourceType
+	r.dbs[resourceType.Name()] = database
+	return r
+}
+
+// MapAttribute explicitly maps the attribute with the given id to one or more resource type names,
+// for reference attributes that do not declare referenceTypes in the schema.
+func (r *ReferenceResolver) MapAttribute(attrID string, resourceTypeNames ...string) *ReferenceResolver {
+	r.attrMaps[attrID] = resourceTypeNames
+	return r
+}
+
+// candidates returns the registered resource types that the given reference attribute may point at.
+func (r *ReferenceResolver) candidates(attr *spec.Attribute) []*spec.ResourceType {
+	var names []string
+	attr.ForEachReferenceTypes(func(referenceType string) {
+		names = append(names, referenceType)
+	})
+	if len(names) == 0 {
+		names = r.attrMaps[attr.ID()]
+	}
+
+	if len(names) == 0 {
+		result := make([]*spec.ResourceType, 0, len(r.types))
+		for _, resourceType := range r.types {
+			result = append(result, resourceType)
+		}
+		return result
+	}
+
+	result := make([]*spec.ResourceType, 0, len(names))
+	for _, name := range names {
+		if resourceType, ok := r.types[name]; ok {
+			result = append(result, resourceType)
+		}
+	}
+	return result
+}
+
+// lookup returns the resource type of the candidate that contains a resource with the given id, or
+// nil when none of the candidates does.
+func (r *ReferenceResolver) lookup(ctx context.Context, candidates []*spec.ResourceType, id string) (*spec.ResourceType, error) {
+	for _, candidate := range candidates {
+		_, err := r.dbs[candidate.Name()].Get(ctx, id, nil)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, spec.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// ReferenceFilter returns a ByResource filter that canonicalizes $ref properties from their sibling
+// value property and the resolved resource type. Complex properties containing both a value and a
+// $ref sub property (i.e. members, groups, manager) have their $ref filled in as
+// "<endpoint>/<value>" so clients only need to send the id. When validate is true, the referenced
+// resource is also required to exist, and an error is returned otherwise.
+func ReferenceFilter(resolver *ReferenceResolver, validate bool) ByResource {
+	return refFilter{resolver: resolver, validate: validate}
+}
+
+type refFilter struct {
+	resolver *ReferenceResolver
+	validate bool
+}
+
+func (f refFilter) Filter(ctx context.Context, resource *prop.Resource) error {
+	return f.visit(ctx, resource.RootProperty())
+}
+
+func (f refFilter) FilterRef(ctx context.Context, resource *prop.Resource, _ *prop.Resource) error {
+	return f.visit(ctx, resource.RootProperty())
+}
+
+func (f refFilter) visit(ctx context.Context, property prop.Property) error {
+	if f.isReferenceContainer(property) {
+		if err := f.canonicalize(ctx, property); err != nil {
+			return err
+		}
+	}
+
+	if property.Attribute().Type() != spec.TypeComplex && !property.Attribute().MultiValued() {
+		return nil
+	}
+	return property.ForEachChild(func(_ int, child prop.Property) error {
+		return f.visit(ctx, child)
+	})
+}
+
+// isReferenceContainer returns true if the property is a singular complex property containing both
+// a value and a $ref sub attribute.
+func (f refFilter) isReferenceContainer(property prop.Property) bool {
+	attr := property.Attribute()
+	if attr.Type() != spec.TypeComplex || attr.MultiValued() {
+		return false
+	}
+	return attr.SubAttributeForName("value") != nil && attr.SubAttributeForName("$ref") != nil
+}
+
+func (f refFilter) canonicalize(ctx context.Context, property prop.Property) error {
+	valueProp, err := property.ChildAtIndex("value")
+	if err != nil || valueProp.IsUnassigned() {
+		return nil
+	}
+	id, ok := valueProp.Raw().(string)
+	if !ok || len(id) == 0 {
+		return nil
+	}
+
+	refProp, err := property.ChildAtIndex("$ref")
+	if err != nil {
+		return nil
+	}
+
+	candidates := f.resolver.candidates(refProp.Attribute())
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var target *spec.ResourceType
+	if f.validate || len(candidates) > 1 {
+		if target, err = f.resolver.lookup(ctx, candidates, id); err != nil {
+			return err
+		}
+		if target == nil {
+			if f.validate {
+				return fmt.Errorf("%w: '%s' does not reference an existing resource",
+					spec.ErrInvalidValue, property.Attribute().Path())
+			}
+			return nil
+		}
+	} else {
+		target = candidates[0]
+	}
+
+	ref := strings.TrimSuffix(target.Endpoint(), "/") + "/" + id
+	if raw, ok := refProp.Raw().(string); ok && raw == ref {
+		return nil
+	}
+	_, err = refProp.Replace(ref)
+	return err
+}
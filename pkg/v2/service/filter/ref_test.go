@@ -0,0 +1,187 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReferenceFilter(t *testing.T) {
+	s := new(ReferenceFilterTestSuite)
+	suite.Run(t, s)
+}
+
+type ReferenceFilterTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ReferenceFilterTestSuite) TestFilter() {
+	const managerRefAttrID = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.$ref"
+
+	tests := []struct {
+		name        string
+		getResolver func(t *testing.T) *ReferenceResolver
+		validate    bool
+		getResource func(t *testing.T) *prop.Resource
+		expect      func(t *testing.T, resource *prop.Resource, err error)
+	}{
+		{
+			name: "canonicalizes $ref from value",
+			getResolver: func(t *testing.T) *ReferenceResolver {
+				return NewReferenceResolver().
+					Register(s.resourceType, db.Memory()).
+					MapAttribute(managerRefAttrID, "User")
+			},
+			validate: false,
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user002",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().
+					Dot("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User").
+					Dot("manager").
+					Dot("$ref")
+				require.False(t, nav.HasError())
+				assert.Equal(t, "/Users/user002", nav.Current().Raw())
+			},
+		},
+		{
+			name: "validate rejects dangling reference",
+			getResolver: func(t *testing.T) *ReferenceResolver {
+				return NewReferenceResolver().
+					Register(s.resourceType, db.Memory()).
+					MapAttribute(managerRefAttrID, "User")
+			},
+			validate: true,
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user404",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.True(t, errors.Is(err, spec.ErrInvalidValue))
+			},
+		},
+		{
+			name: "validate accepts existing reference",
+			getResolver: func(t *testing.T) *ReferenceResolver {
+				database := db.Memory()
+				require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"id": "user002",
+				})))
+				return NewReferenceResolver().
+					Register(s.resourceType, database).
+					MapAttribute(managerRefAttrID, "User")
+			},
+			validate: true,
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"id": "user001",
+					"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": map[string]interface{}{
+						"manager": map[string]interface{}{
+							"value": "user002",
+						},
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				nav := resource.Navigator().
+					Dot("urn:ietf:params:scim:schemas:extension:enterprise:2.0:User").
+					Dot("manager").
+					Dot("$ref")
+				require.False(t, nav.HasError())
+				assert.Equal(t, "/Users/user002", nav.Current().Raw())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			filter := ReferenceFilter(test.getResolver(t), test.validate)
+			resource := test.getResource(t)
+			err := filter.Filter(context.Background(), resource)
+			test.expect(t, resource, err)
+		})
+	}
+}
+
+func (s *ReferenceFilterTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(data).Error())
+	return r
+}
+
+func (s *ReferenceFilterTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
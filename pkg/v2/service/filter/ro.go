@@ -2,6 +2,7 @@ package filter
 
 import (
 	"context"
+	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
@@ -16,7 +17,29 @@ func ReadOnlyFilter() ByProperty {
 	return readOnlyPropertyFilter{}
 }
 
-type readOnlyPropertyFilter struct{}
+// ReadOnlyFilterWithTrustedId returns a ByProperty filter like ReadOnlyFilter, except that, during creation, a client
+// supplied value for a readOnly property that is also annotated with @UUID (i.e. the resource id) is trusted and
+// preserved instead of being reset. Since UUIDFilter only generates values for unassigned properties, this lets
+// trusted clients (i.e. migration tools replicating resources from another SCIM server) supply resource ids on create,
+// while absent ids are still generated as usual. Uniqueness of the supplied id remains enforced by the database.
+// Modification requests are not affected: the reference based reset and copy behaviour stays intact.
+func ReadOnlyFilterWithTrustedId() ByProperty {
+	return readOnlyPropertyFilter{trustId: true}
+}
+
+// StrictReadOnlyFilter returns a ByProperty filter like ReadOnlyFilter, except that, during modification, a client
+// supplied value for a readOnly property that differs from the value of the existing resource is rejected with a
+// mutability error naming the offending attribute, instead of being silently discarded. Payloads that omit readOnly
+// properties or echo their stored values back unchanged, as clients replaying the result of a previous retrieval
+// commonly do, still pass and have the stored values restored as usual.
+func StrictReadOnlyFilter() ByProperty {
+	return readOnlyPropertyFilter{reject: true}
+}
+
+type readOnlyPropertyFilter struct {
+	trustId bool
+	reject  bool
+}
 
 func (f readOnlyPropertyFilter) Supports(attribute *spec.Attribute) bool {
 	if _, ok := attribute.Annotation(annotation.ReadOnly); !ok {
@@ -30,6 +53,12 @@ func (f readOnlyPropertyFilter) Filter(_ context.Context, _ *spec.ResourceType,
 		return nav.Error()
 	}
 
+	if f.trustId && !nav.Current().IsUnassigned() {
+		if _, ok := nav.Current().Attribute().Annotation(annotation.UUID); ok {
+			return nil
+		}
+	}
+
 	if err := f.tryReset(nav); err != nil {
 		return err
 	}
@@ -42,6 +71,10 @@ func (f readOnlyPropertyFilter) FilterRef(_ context.Context, _ *spec.ResourceTyp
 		return nav.Error()
 	}
 
+	if err := f.tryReject(nav, refNav); err != nil {
+		return err
+	}
+
 	if err := f.tryReset(nav); err != nil {
 		return err
 	}
@@ -53,6 +86,26 @@ func (f readOnlyPropertyFilter) FilterRef(_ context.Context, _ *spec.ResourceTyp
 	return nil
 }
 
+func (f readOnlyPropertyFilter) tryReject(nav prop.Navigator, refNav prop.Navigator) error {
+	if !f.reject {
+		return nil
+	}
+
+	if nav.Current().IsUnassigned() {
+		return nil
+	}
+
+	if refNav == nil || IsOutOfSync(refNav.Current()) {
+		return nil
+	}
+
+	if nav.Current().Matches(refNav.Current()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: '%s' is readOnly and cannot be modified", spec.ErrMutability, nav.Current().Attribute().Path())
+}
+
 func (f readOnlyPropertyFilter) tryReset(nav prop.Navigator) error {
 	attr := nav.Current().Attribute()
 	params, _ := attr.Annotation(annotation.ReadOnly)
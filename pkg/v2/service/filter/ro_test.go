@@ -3,6 +3,7 @@ package filter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
@@ -102,3 +103,186 @@ func TestReadOnlyFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestReadOnlyFilterWithTrustedId(t *testing.T) {
+	idAttrJson := `
+{
+  "id": "id",
+  "name": "id",
+  "type": "string",
+  "mutability": "readOnly",
+  "_annotations": {
+    "@ReadOnly": {
+      "reset": true,
+      "copy": true
+    },
+    "@UUID": {}
+  }
+}
+`
+
+	tests := []struct {
+		name        string
+		attrJson    string
+		getProperty func(attr *spec.Attribute) prop.Property
+		expect      func(t *testing.T, p prop.Property, err error)
+	}{
+		{
+			name:     "client supplied id is preserved on creation",
+			attrJson: idAttrJson,
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("dbf6c563-78da-45b8-958e-f2a85562419c")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "dbf6c563-78da-45b8-958e-f2a85562419c", p.Raw())
+			},
+		},
+		{
+			name:     "unassigned id is left for generation",
+			attrJson: idAttrJson,
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				return prop.NewProperty(attr)
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.True(t, p.IsUnassigned())
+			},
+		},
+		{
+			name: "readOnly property without @UUID is still cleared",
+			attrJson: `
+{
+  "id": "meta.created",
+  "name": "created",
+  "type": "dateTime",
+  "mutability": "readOnly",
+  "_path": "meta.created",
+  "_annotations": {
+    "@ReadOnly": {
+      "reset": true,
+      "copy": true
+    }
+  }
+}
+`,
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("2020-01-19T15:15:00")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.True(t, p.IsUnassigned())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attr := new(spec.Attribute)
+			assert.Nil(t, json.Unmarshal([]byte(test.attrJson), attr))
+
+			property := test.getProperty(attr)
+			err := ReadOnlyFilterWithTrustedId().Filter(context.Background(), nil, prop.Navigate(property))
+			test.expect(t, property, err)
+		})
+	}
+}
+
+func TestStrictReadOnlyFilter(t *testing.T) {
+	attrJson := `
+{
+  "id": "groups",
+  "name": "groups",
+  "type": "string",
+  "mutability": "readOnly",
+  "_path": "groups",
+  "_annotations": {
+    "@ReadOnly": {
+      "reset": true,
+      "copy": true
+    }
+  }
+}
+`
+
+	tests := []struct {
+		name         string
+		getProperty  func(attr *spec.Attribute) prop.Property
+		getReference func(attr *spec.Attribute) prop.Property
+		expect       func(t *testing.T, p prop.Property, err error)
+	}{
+		{
+			name: "changed readOnly value is rejected",
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("foobar")
+				assert.Nil(t, err)
+				return p
+			},
+			getReference: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("original")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrMutability, errors.Unwrap(err))
+			},
+		},
+		{
+			name: "echoed stored value passes and is restored",
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("original")
+				assert.Nil(t, err)
+				return p
+			},
+			getReference: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("original")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "original", p.Raw())
+			},
+		},
+		{
+			name: "omitted readOnly value passes and is restored",
+			getProperty: func(attr *spec.Attribute) prop.Property {
+				return prop.NewProperty(attr)
+			},
+			getReference: func(attr *spec.Attribute) prop.Property {
+				p := prop.NewProperty(attr)
+				_, err := p.Replace("original")
+				assert.Nil(t, err)
+				return p
+			},
+			expect: func(t *testing.T, p prop.Property, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "original", p.Raw())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attr := new(spec.Attribute)
+			assert.Nil(t, json.Unmarshal([]byte(attrJson), attr))
+
+			property := test.getProperty(attr)
+			reference := test.getReference(attr)
+
+			err := StrictReadOnlyFilter().FilterRef(context.Background(), nil, prop.Navigate(property), prop.Navigate(reference))
+			test.expect(t, property, err)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// SchemaFilter returns a ByResource filter that maintains the schemas core attribute. It first rejects
+// any declared schema that is neither the main schema nor a registered schema extension of the resource
+// type. It then recomputes the value of schemas from the actual state of the resource: the main schema
+// id, followed by the ids of the schema extensions whose container property is assigned. As a result,
+// the schemas attribute always truthfully reflects the data the resource carries, regardless of what
+// the payload declared, and clients cannot smuggle in unregistered schema urns.
+func SchemaFilter() ByResource {
+	return schemaFilter{}
+}
+
+type schemaFilter struct{}
+
+func (f schemaFilter) Filter(ctx context.Context, resource *prop.Resource) error {
+	return f.sync(resource)
+}
+
+func (f schemaFilter) FilterRef(ctx context.Context, resource *prop.Resource, ref *prop.Resource) error {
+	return f.sync(resource)
+}
+
+func (f schemaFilter) sync(resource *prop.Resource) error {
+	if err := f.rejectUnregistered(resource); err != nil {
+		return err
+	}
+
+	schemas := []interface{}{resource.MainSchemaId()}
+	_ = resource.ResourceType().ForEachExtension(func(extension *spec.Schema, _ bool) error {
+		nav := resource.Navigator().Dot(extension.ID())
+		if !nav.HasError() && !nav.Current().IsUnassigned() {
+			schemas = append(schemas, extension.ID())
+		}
+		return nil
+	})
+
+	nav := resource.Navigator().Dot("schemas")
+	if nav.Replace(schemas).HasError() {
+		return nav.Error()
+	}
+
+	return nil
+}
+
+// rejectUnregistered returns an error when the schemas property contains an id that does not
+// correspond to the main schema or a registered schema extension of the resource type.
+func (f schemaFilter) rejectUnregistered(resource *prop.Resource) error {
+	registered := map[string]struct{}{resource.MainSchemaId(): {}}
+	_ = resource.ResourceType().ForEachExtension(func(extension *spec.Schema, _ bool) error {
+		registered[extension.ID()] = struct{}{}
+		return nil
+	})
+
+	nav := resource.Navigator().Dot("schemas")
+	if nav.HasError() {
+		return nav.Error()
+	}
+
+	return nav.ForEachChild(func(_ int, child prop.Property) error {
+		id, ok := child.Raw().(string)
+		if !ok {
+			return nil
+		}
+		if _, ok := registered[id]; !ok {
+			return fmt.Errorf("%w: schema '%s' is not registered with resource type '%s'",
+				spec.ErrInvalidValue, id, resource.ResourceType().Name())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const (
+	testUserSchemaId       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	testEnterpriseSchemaId = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+)
+
+func TestSchemaFilter(t *testing.T) {
+	s := new(SchemaFilterTestSuite)
+	suite.Run(t, s)
+}
+
+type SchemaFilterTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *SchemaFilterTestSuite) TestFilter() {
+	tests := []struct {
+		name        string
+		getResource func(t *testing.T) *prop.Resource
+		expect      func(t *testing.T, resource *prop.Resource, err error)
+	}{
+		{
+			name: "omitted schemas is recomputed from the main schema",
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"userName": "foobar",
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []interface{}{testUserSchemaId}, s.schemasOf(t, resource))
+			},
+		},
+		{
+			name: "assigned extension data adds the extension schema",
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"schemas":  []interface{}{testUserSchemaId},
+					"userName": "foobar",
+					testEnterpriseSchemaId: map[string]interface{}{
+						"employeeNumber": "6500",
+					},
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []interface{}{testUserSchemaId, testEnterpriseSchemaId}, s.schemasOf(t, resource))
+			},
+		},
+		{
+			name: "declared extension without data is dropped",
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"schemas":  []interface{}{testUserSchemaId, testEnterpriseSchemaId},
+					"userName": "foobar",
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, []interface{}{testUserSchemaId}, s.schemasOf(t, resource))
+			},
+		},
+		{
+			name: "unregistered schema is rejected",
+			getResource: func(t *testing.T) *prop.Resource {
+				return s.resourceOf(t, map[string]interface{}{
+					"schemas":  []interface{}{testUserSchemaId, "urn:unregistered:Extension"},
+					"userName": "foobar",
+				})
+			},
+			expect: func(t *testing.T, resource *prop.Resource, err error) {
+				assert.Error(t, err)
+				assert.Equal(t, spec.ErrInvalidValue, errors.Unwrap(err))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resource := test.getResource(t)
+			err := SchemaFilter().Filter(context.Background(), resource)
+			test.expect(t, resource, err)
+		})
+	}
+}
+
+func (s *SchemaFilterTestSuite) TestFilterRef() {
+	resource := s.resourceOf(s.T(), map[string]interface{}{
+		"schemas":  []interface{}{testUserSchemaId, testEnterpriseSchemaId},
+		"userName": "foobar",
+	})
+	ref := s.resourceOf(s.T(), map[string]interface{}{
+		"schemas":  []interface{}{testUserSchemaId, testEnterpriseSchemaId},
+		"userName": "foobar",
+		testEnterpriseSchemaId: map[string]interface{}{
+			"employeeNumber": "6500",
+		},
+	})
+
+	assert.Nil(s.T(), SchemaFilter().FilterRef(context.Background(), resource, ref))
+	assert.Equal(s.T(), []interface{}{testUserSchemaId}, s.schemasOf(s.T(), resource))
+}
+
+func (s *SchemaFilterTestSuite) resourceOf(t *testing.T, data map[string]interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.False(t, r.Navigator().Replace(data).HasError())
+	return r
+}
+
+func (s *SchemaFilterTestSuite) schemasOf(t *testing.T, resource *prop.Resource) interface{} {
+	nav := resource.Navigator().Dot("schemas")
+	require.False(t, nav.HasError())
+	return nav.Current().Raw()
+}
+
+func (s *SchemaFilterTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/prop"
 	"github.com/imulab/go-scim/pkg/v2/spec"
-	"github.com/satori/go.uuid"
 )
 
 // UUIDFilter returns a ByProperty filter that generates a UUID for string property that is annotated with @UUID. The
 // generation only happens when the target property is currently unassigned. The generated value will trigger event
-// propagation.
+// propagation. It is equivalent to IdFilter with the default UUIDGenerator.
 func UUIDFilter() ByProperty {
-	return uuidPropertyFilter{}
+	return IdFilter(UUIDGenerator())
 }
 
-type uuidPropertyFilter struct{}
+// IdFilter returns a ByProperty filter like UUIDFilter, except that the identifier is produced by the given
+// IdGenerator instead of the default random version 4 UUID, so that deployments may choose k-ordered identifier
+// formats (i.e. ULIDGenerator) or plug in their own.
+func IdFilter(generator IdGenerator) ByProperty {
+	return uuidPropertyFilter{generator: generator}
+}
+
+type uuidPropertyFilter struct {
+	generator IdGenerator
+}
 
 func (f uuidPropertyFilter) Supports(attribute *spec.Attribute) bool {
 	_, ok := attribute.Annotation(annotation.UUID)
@@ -34,7 +42,7 @@ func (f uuidPropertyFilter) Filter(_ context.Context, _ *spec.ResourceType, nav
 		return nil
 	}
 
-	return nav.Replace(uuid.NewV4().String()).Error()
+	return nav.Replace(f.generator.Generate()).Error()
 }
 
 func (f uuidPropertyFilter) FilterRef(_ context.Context, _ *spec.ResourceType, _ prop.Navigator, _ prop.Navigator) error {
@@ -3,12 +3,12 @@ package filter
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scope"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 )
 
@@ -25,10 +25,11 @@ import (
 // property value, if one exists. It does not check for readOnly attributes because the logic is largely handled
 // by ReadOnlyFilter.
 //
-// The uniqueness check fails when the property value already exists in the database. It formulates the query
-// (id ne <id>) and (<path> eq <value>), where <id> is the resource id, <path> is the unique attribute path, and
-// <value> is the property value. The database returns the number of records matching this filter. If the count is
-// greater than 0, the check fails. Note this check only handles the uniqueness=server case.
+// The uniqueness check fails when the property value already exists in the database. It formulates the structured
+// predicates (id ne <id>) and (<path> eq <value>), where <id> is the resource id, <path> is the unique attribute
+// path, and <value> is the property value, and counts matching records through db.CountMatch, so that values
+// containing quotes or filter metacharacters cannot subvert the query. If the count is greater than 0, the check
+// fails. Note this check only handles the uniqueness=server case.
 //
 // Error is returned to caller if any of these check fails.
 func ValidationFilter(database db.DB) ByProperty {
@@ -162,12 +163,19 @@ func (f *validationPropertyFilter) validateUniqueness(ctx context.Context, nav p
 
 	// We may run into problem where the uniqueness=server attribute is 'id' itself. However, as of
 	// now, 'id' is defined as uniqueness=global by assigning a UUID to it.
-	filter := fmt.Sprintf("(id ne %s) and (%s eq %s)",
-		strconv.Quote(id),
-		property.Attribute().Path(),
-		strconv.Quote(fmt.Sprintf("%v", property.Raw())),
-	)
-	n, err := f.database.Count(ctx, filter)
+	predicates := []db.Predicate{
+		{Path: "id", Op: db.PredicateNe, Value: id},
+		{Path: property.Attribute().Path(), Op: db.PredicateEq, Value: property.Raw()},
+	}
+	// externalId is assigned by and unique to the provisioning client. When the request is
+	// associated with a client, restrict the uniqueness check to resources provisioned by
+	// the same client so that different clients may assign the same externalId.
+	if property.Attribute().ID() == "externalId" {
+		if clientId := scope.ClientFromContext(ctx); len(clientId) > 0 {
+			predicates = append(predicates, db.Predicate{Path: "meta.clientId", Op: db.PredicateEq, Value: clientId})
+		}
+	}
+	n, err := db.CountMatch(ctx, f.database, predicates)
 	if err != nil {
 		return err
 	} else if n > 0 {
@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+)
+
+// VersionGenerator produces the value assigned to meta.version. The resource handed to Generate
+// already reflects the state being persisted, except for meta.version itself, which still holds the
+// previous value. The returned value is expected to be a weak entity tag (i.e. `W/"1"`).
+type VersionGenerator interface {
+	Generate(ctx context.Context, resource *prop.Resource) (string, error)
+}
+
+// VersionGeneratorFunc is the functional adapter for VersionGenerator.
+type VersionGeneratorFunc func(ctx context.Context, resource *prop.Resource) (string, error)
+
+func (f VersionGeneratorFunc) Generate(ctx context.Context, resource *prop.Resource) (string, error) {
+	return f(ctx, resource)
+}
+
+// HashVersionGenerator returns a VersionGenerator that derives the version from a sha256 hash over
+// the deterministic serialization of the resource, excluding the meta attribute. Equal resource
+// states therefore produce equal versions, regardless of which replica computed them, making the
+// version comparable across replicas and reproducible for conditional requests.
+func HashVersionGenerator() VersionGenerator {
+	return VersionGeneratorFunc(func(_ context.Context, resource *prop.Resource) (string, error) {
+		raw, err := json.Serialize(resource, json.Deterministic(), json.Exclude("meta"))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("W/\"%x\"", sha256.Sum256(raw)), nil
+	})
+}
+
+// CounterVersionGenerator returns a VersionGenerator that produces a per-resource monotonic counter:
+// the first version is `W/"1"` and every subsequent modification increments the number parsed from
+// the previous version. A previous version not produced by this generator restarts the counter.
+func CounterVersionGenerator() VersionGenerator {
+	return VersionGeneratorFunc(func(_ context.Context, resource *prop.Resource) (string, error) {
+		var count int64
+		previous := resource.MetaVersionOrEmpty()
+		if strings.HasPrefix(previous, `W/"`) && strings.HasSuffix(previous, `"`) {
+			count, _ = strconv.ParseInt(previous[3:len(previous)-1], 10, 64)
+		}
+		return fmt.Sprintf("W/\"%d\"", count+1), nil
+	})
+}
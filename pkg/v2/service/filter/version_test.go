@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestVersionGenerator(t *testing.T) {
+	s := new(VersionGeneratorTestSuite)
+	suite.Run(t, s)
+}
+
+type VersionGeneratorTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *VersionGeneratorTestSuite) TestHashVersionGenerator() {
+	generator := HashVersionGenerator()
+
+	first, err := generator.Generate(context.Background(), s.resourceOf("userName", "foobar"))
+	assert.Nil(s.T(), err)
+	assert.Regexp(s.T(), `^W/"[0-9a-f]+"$`, first)
+
+	// equal resource states produce equal versions
+	same, err := generator.Generate(context.Background(), s.resourceOf("userName", "foobar"))
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), first, same)
+
+	// different resource states produce different versions
+	other, err := generator.Generate(context.Background(), s.resourceOf("userName", "changed"))
+	assert.Nil(s.T(), err)
+	assert.NotEqual(s.T(), first, other)
+}
+
+func (s *VersionGeneratorTestSuite) TestCounterVersionGenerator() {
+	generator := CounterVersionGenerator()
+
+	resource := s.resourceOf("userName", "foobar")
+	version, err := generator.Generate(context.Background(), resource)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), `W/"1"`, version)
+
+	require.False(s.T(), resource.Navigator().Dot("meta").Dot("version").Replace(version).HasError())
+	version, err = generator.Generate(context.Background(), resource)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), `W/"2"`, version)
+
+	// a previous version not produced by this generator restarts the counter
+	require.False(s.T(), resource.Navigator().Dot("meta").Dot("version").Replace(`W/"5e6717"`).HasError())
+	version, err = generator.Generate(context.Background(), resource)
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), `W/"1"`, version)
+}
+
+func (s *VersionGeneratorTestSuite) TestMetaFilterWithVersion() {
+	resource := s.resourceOf("userName", "foobar")
+	assert.Nil(s.T(), MetaFilterWithVersion(CounterVersionGenerator()).Filter(context.Background(), resource))
+	assert.Equal(s.T(), `W/"1"`, resource.MetaVersionOrEmpty())
+}
+
+func (s *VersionGeneratorTestSuite) resourceOf(field string, value string) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.False(s.T(), r.Navigator().Replace(map[string]interface{}{
+		"id":  "C37527A1-B60F-4E30-8FD9-162A1740BDB6",
+		field: value,
+	}).HasError())
+	return r
+}
+
+func (s *VersionGeneratorTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
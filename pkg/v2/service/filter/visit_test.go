@@ -63,6 +63,7 @@ func (s *VisitorTestSuite) TestUnsupportedFilterDoesNotPreventRemainingFiltersFr
 					"meta.lastModified",
 					"meta.location",
 					"meta.version",
+					"meta.clientId",
 					"urn:ietf:params:scim:schemas:core:2.0:User:userName",
 					"urn:ietf:params:scim:schemas:core:2.0:User:name",
 					"urn:ietf:params:scim:schemas:core:2.0:User:name.formatted",
@@ -158,6 +159,7 @@ func (s *VisitorTestSuite) TestVisit() {
 					"meta.lastModified",
 					"meta.location",
 					"meta.version",
+					"meta.clientId",
 					"urn:ietf:params:scim:schemas:core:2.0:User:userName",
 					"urn:ietf:params:scim:schemas:core:2.0:User:name",
 					"urn:ietf:params:scim:schemas:core:2.0:User:name.formatted",
@@ -342,6 +344,7 @@ func (s *VisitorTestSuite) TestVisitWithRef() {
 					{prop: "meta.lastModified", ref: "meta.lastModified"},
 					{prop: "meta.location", ref: "meta.location"},
 					{prop: "meta.version", ref: "meta.version"},
+					{prop: "meta.clientId", ref: "meta.clientId"},
 					{prop: "urn:ietf:params:scim:schemas:core:2.0:User:userName", ref: "urn:ietf:params:scim:schemas:core:2.0:User:userName"},
 					{prop: "urn:ietf:params:scim:schemas:core:2.0:User:name", ref: "urn:ietf:params:scim:schemas:core:2.0:User:name"},
 					{prop: "urn:ietf:params:scim:schemas:core:2.0:User:name.formatted", ref: "urn:ietf:params:scim:schemas:core:2.0:User:name.formatted"},
@@ -5,11 +5,13 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 )
 
 // GetService returns a get resource service.
-func GetService(database db.DB) Get {
-	return &getService{database: database}
+func GetService(config *scim.Config, database db.DB) Get {
+	return &getService{database: database, config: config}
 }
 
 type (
@@ -30,14 +32,22 @@ type (
 
 type getService struct {
 	database db.DB
+	config   *scim.Config
 }
 
 func (s *getService) Do(ctx context.Context, req *GetRequest) (resp *GetResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.get")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
 	resource, err := s.database.Get(ctx, req.ResourceID, req.Projection)
 	if err != nil {
 		return
 	}
 
-	resp = &GetResponse{Resource: resource}
+	resp = &GetResponse{Resource: maskResponse(ctx, s.config, scrubResponse(resource))}
 	return
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,7 +42,7 @@ func (s *GetServiceTestSuite) TestDo() {
 					"id": "foobar",
 				}))
 				require.Nil(t, err)
-				return GetService(database)
+				return GetService(scim.NewConfig(), database)
 			},
 			getRequest: func() *GetRequest {
 				return &GetRequest{
@@ -56,7 +57,7 @@ func (s *GetServiceTestSuite) TestDo() {
 		{
 			name: "get non-existing",
 			setup: func(t *testing.T) Get {
-				return GetService(db.Memory())
+				return GetService(scim.NewConfig(), db.Memory())
 			},
 			getRequest: func() *GetRequest {
 				return &GetRequest{
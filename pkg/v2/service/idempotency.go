@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore is the transient storage behind IdempotentCreateService. It records the resource
+// that resulted from a create request under the idempotency key the client supplied, so that a
+// retried request with the same key can be answered with the original result. Records are expected
+// to be short-lived; implementations decide the retention. Implementations must be safe for
+// concurrent use.
+type IdempotencyStore interface {
+	// Load returns the resource previously recorded under key, or nil when the key is unknown or
+	// the record has expired.
+	Load(ctx context.Context, key string) *prop.Resource
+	// Save records the resource created under key.
+	Save(ctx context.Context, key string, resource *prop.Resource)
+}
+
+// memoryIdempotencyRecordCap caps the number of records held by MemoryIdempotencyStore. Keys are
+// client-supplied header values, so without a bound the store is a memory exhaustion vector; once
+// the cap is reached, the records closest to expiry are evicted to make room.
+const memoryIdempotencyRecordCap = 65536
+
+// MemoryIdempotencyStore returns an IdempotencyStore that keeps records in process memory for the
+// given ttl. Expired records are swept out periodically as new records are saved, and the total
+// number of records is capped, so that client-supplied keys cannot grow the store without bound.
+// The store is local to the process; deployments with multiple replicas that require replay
+// protection across replicas should provide a shared implementation instead.
+func MemoryIdempotencyStore(ttl time.Duration) IdempotencyStore {
+	return &memoryIdempotencyStore{ttl: ttl, cap: memoryIdempotencyRecordCap, records: map[string]memoryIdempotencyRecord{}}
+}
+
+// IdempotentCreateService returns a create service that answers retried create requests from the
+// given store. When the request carries an idempotency key that the store recognizes, the recorded
+// resource is returned without invoking the wrapped service, so that a client retrying a POST after
+// a lost response does not create a duplicate. Requests without a key, or with an unknown key, go
+// through the wrapped service, and their successful outcome is recorded under the key. The service
+// does not verify that the retried payload matches the original; the key is trusted to identify the
+// logical operation. Requests sharing a key are serialized, so that of two concurrent creates with
+// the same key, one creates and the other replays the recorded result.
+func IdempotentCreateService(store IdempotencyStore, create Create) Create {
+	return &idempotentCreateService{store: store, create: create, keys: NewResourceLock()}
+}
+
+type idempotentCreateService struct {
+	store  IdempotencyStore
+	create Create
+	keys   ResourceLock
+}
+
+func (s *idempotentCreateService) Do(ctx context.Context, req *CreateRequest) (resp *CreateResponse, err error) {
+	if req == nil || len(req.IdempotencyKey) == 0 {
+		return s.create.Do(ctx, req)
+	}
+
+	ctx, span := trace.StartSpan(ctx, "service.idempotentCreate")
+	defer func() { span.SetError(err); span.End() }()
+
+	// hold the key for the whole load-create-save cycle, so that a concurrent create with the
+	// same key waits and then replays the recorded result instead of creating a duplicate
+	if err = s.keys.Lock(ctx, req.IdempotencyKey); err != nil {
+		return
+	}
+	defer s.keys.Unlock(req.IdempotencyKey)
+
+	if recorded := s.store.Load(ctx, req.IdempotencyKey); recorded != nil {
+		span.SetTag("scim.idempotentReplay", "true")
+		resp = &CreateResponse{Resource: recorded, Replayed: true}
+		return
+	}
+
+	resp, err = s.create.Do(ctx, req)
+	if err == nil && resp != nil && resp.Resource != nil {
+		s.store.Save(ctx, req.IdempotencyKey, resp.Resource)
+	}
+	return
+}
+
+type memoryIdempotencyStore struct {
+	ttl       time.Duration
+	cap       int
+	lock      sync.Mutex
+	records   map[string]memoryIdempotencyRecord
+	nextSweep time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	resource *prop.Resource
+	expires  time.Time
+}
+
+func (s *memoryIdempotencyStore) Load(_ context.Context, key string) *prop.Resource {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(record.expires) {
+		delete(s.records, key)
+		return nil
+	}
+	return record.resource.Clone()
+}
+
+func (s *memoryIdempotencyStore) Save(_ context.Context, key string, resource *prop.Resource) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	if !now.Before(s.nextSweep) {
+		s.sweep(now)
+		s.nextSweep = now.Add(s.ttl)
+	}
+	if _, ok := s.records[key]; !ok && len(s.records) >= s.cap {
+		s.evictEarliest(len(s.records) - s.cap + 1)
+	}
+
+	s.records[key] = memoryIdempotencyRecord{
+		resource: resource.Clone(),
+		expires:  now.Add(s.ttl),
+	}
+}
+
+// sweep removes all expired records. The caller must hold the lock.
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	for key, record := range s.records {
+		if !now.Before(record.expires) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// evictEarliest removes the n records closest to expiry to make room under the cap. The caller
+// must hold the lock.
+func (s *memoryIdempotencyStore) evictEarliest(n int) {
+	for ; n > 0 && len(s.records) > 0; n-- {
+		var (
+			earliestKey string
+			earliest    time.Time
+		)
+		for key, record := range s.records {
+			if len(earliestKey) == 0 || record.expires.Before(earliest) {
+				earliestKey = key
+				earliest = record.expires
+			}
+		}
+		delete(s.records, earliestKey)
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotentCreateService(t *testing.T) {
+	s := new(IdempotentCreateServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type IdempotentCreateServiceTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestReplay() {
+	database := db.Memory()
+	service := IdempotentCreateService(MemoryIdempotencyStore(time.Minute), s.createService(database))
+
+	first, err := service.Do(context.Background(), s.requestOf("group001", "key-1"))
+	require.Nil(s.T(), err)
+	assert.False(s.T(), first.Replayed)
+
+	// the retried request carries a different payload, but the recorded result wins
+	replayed, err := service.Do(context.Background(), s.requestOf("group002", "key-1"))
+	assert.Nil(s.T(), err)
+	assert.True(s.T(), replayed.Replayed)
+	assert.Equal(s.T(), "group001", replayed.Resource.IdOrEmpty())
+
+	n, err := database.Count(context.Background(), "")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestDistinctKeysCreateSeparately() {
+	database := db.Memory()
+	service := IdempotentCreateService(MemoryIdempotencyStore(time.Minute), s.createService(database))
+
+	_, err := service.Do(context.Background(), s.requestOf("group001", "key-1"))
+	require.Nil(s.T(), err)
+	_, err = service.Do(context.Background(), s.requestOf("group002", "key-2"))
+	require.Nil(s.T(), err)
+
+	n, err := database.Count(context.Background(), "")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestRequestsWithoutKeyAreNotRecorded() {
+	database := db.Memory()
+	service := IdempotentCreateService(MemoryIdempotencyStore(time.Minute), s.createService(database))
+
+	_, err := service.Do(context.Background(), s.requestOf("group001", ""))
+	require.Nil(s.T(), err)
+	resp, err := service.Do(context.Background(), s.requestOf("group002", ""))
+	require.Nil(s.T(), err)
+	assert.False(s.T(), resp.Replayed)
+
+	n, err := database.Count(context.Background(), "")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, n)
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestExpiredKeyCreatesAgain() {
+	database := db.Memory()
+	service := IdempotentCreateService(MemoryIdempotencyStore(time.Millisecond), s.createService(database))
+
+	_, err := service.Do(context.Background(), s.requestOf("group001", "key-1"))
+	require.Nil(s.T(), err)
+
+	time.Sleep(5 * time.Millisecond)
+
+	resp, err := service.Do(context.Background(), s.requestOf("group002", "key-1"))
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), resp.Replayed)
+	assert.Equal(s.T(), "group002", resp.Resource.IdOrEmpty())
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestConcurrentRequestsWithSameKeyCreateOnce() {
+	database := db.Memory()
+	service := IdempotentCreateService(MemoryIdempotencyStore(time.Minute), s.createService(database))
+
+	var (
+		wg       sync.WaitGroup
+		replayed int32
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := service.Do(context.Background(), s.requestOf(fmt.Sprintf("group%03d", i), "key-1"))
+			assert.Nil(s.T(), err)
+			if resp.Replayed {
+				atomic.AddInt32(&replayed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(s.T(), int32(4), atomic.LoadInt32(&replayed))
+
+	n, err := database.Count(context.Background(), "")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, n)
+}
+
+func (s *IdempotentCreateServiceTestSuite) TestRecordsOverCapEvictEarliestExpiry() {
+	database := db.Memory()
+	store := &memoryIdempotencyStore{ttl: time.Minute, cap: 2, records: map[string]memoryIdempotencyRecord{}}
+	service := IdempotentCreateService(store, s.createService(database))
+
+	for i := 1; i <= 3; i++ {
+		_, err := service.Do(context.Background(), s.requestOf(fmt.Sprintf("group%03d", i), fmt.Sprintf("key-%d", i)))
+		require.Nil(s.T(), err)
+		time.Sleep(time.Millisecond) // keep the expiry times strictly ordered
+	}
+
+	assert.Len(s.T(), store.records, 2)
+	assert.Nil(s.T(), store.Load(context.Background(), "key-1"))
+	assert.NotNil(s.T(), store.Load(context.Background(), "key-3"))
+}
+
+func (s *IdempotentCreateServiceTestSuite) createService(database db.DB) Create {
+	return CreateService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{})
+}
+
+func (s *IdempotentCreateServiceTestSuite) requestOf(id string, key string) *CreateRequest {
+	return &CreateRequest{
+		PayloadSource: strings.NewReader(fmt.Sprintf(
+			`{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"id":"%s","displayName":"Test Group"}`, id)),
+		IdempotencyKey: key,
+	}
+}
+
+func (s *IdempotentCreateServiceTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
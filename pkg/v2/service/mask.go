@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/scope"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// maskResponse applies the configured attribute masking rules to a response resource, based on the
+// scopes carried on the request context. A rule is skipped when the caller holds one of its
+// privileged scopes. When at least one rule applies, the returned resource is a masked clone, so
+// that the original resource stays intact for persistence and events. Values whose masked form
+// cannot be assigned back are removed instead of leaking the clear value.
+func maskResponse(ctx context.Context, config *scim.Config, resource *prop.Resource) *prop.Resource {
+	if resource == nil || config == nil {
+		return resource
+	}
+
+	rules := applicableMaskRules(ctx, config)
+	if len(rules) == 0 {
+		return resource
+	}
+
+	masked := resource.Clone()
+	for _, rule := range rules {
+		for _, target := range collectMaskTargets(masked.RootProperty(), rule.Path) {
+			if rule.Mask != nil {
+				if replacement := rule.Mask(target.Raw()); replacement != nil {
+					if _, err := target.Replace(replacement); err == nil {
+						continue
+					}
+				}
+			}
+			_, _ = target.Delete()
+		}
+	}
+	return masked
+}
+
+// applicableMaskRules returns the configured rules not exempted by the caller's scopes.
+func applicableMaskRules(ctx context.Context, config *scim.Config) []scim.MaskRule {
+	var rules []scim.MaskRule
+	scopes := scope.ScopesFromContext(ctx)
+	for _, rule := range config.MaskRules() {
+		if !holdsAnyScope(scopes, rule.Scopes) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func holdsAnyScope(held []string, privileged []string) bool {
+	for _, p := range privileged {
+		for _, h := range held {
+			if h == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collectMaskTargets returns the assigned properties whose attribute path matches the rule path.
+// Attribute paths compare case insensitively, as SCIM attribute names are case insensitive.
+func collectMaskTargets(property prop.Property, path string) []prop.Property {
+	if spec.SameAttributeName(property.Attribute().Path(), path) && !property.IsUnassigned() {
+		return []prop.Property{property}
+	}
+
+	var targets []prop.Property
+	_ = property.ForEachChild(func(_ int, child prop.Property) error {
+		targets = append(targets, collectMaskTargets(child, path)...)
+		return nil
+	})
+	return targets
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/scope"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMaskResponse(t *testing.T) {
+	s := new(MaskResponseTestSuite)
+	suite.Run(t, s)
+}
+
+type MaskResponseTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *MaskResponseTestSuite) config() *scim.Config {
+	return scim.NewConfig(scim.WithMaskRules(
+		scim.MaskRule{
+			Path:   "phoneNumbers.value",
+			Scopes: []string{"pii:read"},
+			Mask:   scim.PartialMask(4),
+		},
+		scim.MaskRule{
+			Path:   "addresses",
+			Scopes: []string{"pii:read"},
+		},
+	))
+}
+
+func (s *MaskResponseTestSuite) resource() *prop.Resource {
+	resource := prop.NewResource(s.resourceType)
+	require.False(s.T(), resource.Navigator().Replace(map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "u1",
+		"userName": "imulab",
+		"phoneNumbers": []interface{}{
+			map[string]interface{}{"value": "604-555-7890"},
+		},
+		"addresses": []interface{}{
+			map[string]interface{}{"locality": "Vancouver"},
+		},
+	}).HasError())
+	return resource
+}
+
+func (s *MaskResponseTestSuite) TestMasksForUnprivilegedCaller() {
+	resource := s.resource()
+
+	masked := maskResponse(context.Background(), s.config(), resource)
+
+	assert.Equal(s.T(), "********7890",
+		masked.Navigator().Dot("phoneNumbers").At(0).Dot("value").Current().Raw())
+	assert.True(s.T(), masked.Navigator().Dot("addresses").Current().IsUnassigned())
+	assert.Equal(s.T(), "imulab", masked.Navigator().Dot("userName").Current().Raw())
+
+	// the original resource stays intact for persistence and events
+	assert.Equal(s.T(), "604-555-7890",
+		resource.Navigator().Dot("phoneNumbers").At(0).Dot("value").Current().Raw())
+	assert.False(s.T(), resource.Navigator().Dot("addresses").Current().IsUnassigned())
+}
+
+func (s *MaskResponseTestSuite) TestPrivilegedCallerSeesClearValues() {
+	ctx := scope.WithScopes(context.Background(), "pii:read")
+
+	masked := maskResponse(ctx, s.config(), s.resource())
+
+	assert.Equal(s.T(), "604-555-7890",
+		masked.Navigator().Dot("phoneNumbers").At(0).Dot("value").Current().Raw())
+	assert.False(s.T(), masked.Navigator().Dot("addresses").Current().IsUnassigned())
+}
+
+func (s *MaskResponseTestSuite) TestNoRulesReturnsResourceAsIs() {
+	resource := s.resource()
+	assert.True(s.T(), resource == maskResponse(context.Background(), scim.NewConfig(), resource))
+}
+
+func (s *MaskResponseTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
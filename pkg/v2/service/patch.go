@@ -6,21 +6,23 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/crud/expr"
 	"github.com/imulab/go-scim/pkg/v2/db"
-	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 )
 
 // PatchService returns a patch resource service. preFilters will run after resource fetched from database and before
 // resource is patched. postFilters will run after resource has been patched and before resource is saved back to database.
 func PatchService(
-	config *spec.ServiceProviderConfig,
+	config *scim.Config,
 	database db.DB,
 	preFilters []filter.ByResource,
 	postFilters []filter.ByResource,
@@ -57,9 +59,11 @@ type (
 	}
 	// Patch resource response
 	PatchResponse struct {
-		Patched  bool           // true if the resource was patched; false if the resource was not patched but there was no error
-		Ref      *prop.Resource // reference resource (the before state)
-		Resource *prop.Resource // patched resource (the after state)
+		Patched        bool           // true if the resource was patched; false if the resource was not patched but there was no error
+		Ref            *prop.Resource // reference resource (the before state); nil when the request was served by the member fast path
+		Resource       *prop.Resource // patched resource (the after state); nil when the request was served by the member fast path
+		MembersAdded   []string       // values of the members added when the request was served by the member fast path
+		MembersRemoved []string       // values of the members removed when the request was served by the member fast path
 	}
 )
 
@@ -67,10 +71,17 @@ type patchService struct {
 	preFilters  []filter.ByResource
 	postFilters []filter.ByResource
 	database    db.DB
-	config      *spec.ServiceProviderConfig
+	config      *scim.Config
 }
 
 func (s *patchService) Do(ctx context.Context, req *PatchRequest) (resp *PatchResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.patch")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
 	if err = s.checkSupport(); err != nil {
 		return
 	}
@@ -83,12 +94,21 @@ func (s *patchService) Do(ctx context.Context, req *PatchRequest) (resp *PatchRe
 		return
 	}
 
+	if handled, added, removed, fastErr := s.tryMemberFastPath(ctx, req, patch); handled {
+		if fastErr != nil {
+			err = fastErr
+			return
+		}
+		resp = &PatchResponse{Patched: true, MembersAdded: added, MembersRemoved: removed}
+		return
+	}
+
 	resource, err := s.database.Get(ctx, req.ResourceID, nil)
 	if err != nil {
 		return
 	}
 
-	if s.config.ETag.Supported && req.MatchCriteria != nil {
+	if s.config.ETagSupported() && req.MatchCriteria != nil {
 		if !req.MatchCriteria(resource) {
 			err = fmt.Errorf("%w: resource does not meet pre condition", spec.ErrConflict)
 			return
@@ -101,33 +121,26 @@ func (s *patchService) Do(ctx context.Context, req *PatchRequest) (resp *PatchRe
 	ref := resource.Clone()
 
 	for _, f := range s.preFilters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
 		if err = f.FilterRef(ctx, resource, ref); err != nil {
 			return
 		}
 	}
 
+	patchOps := make([]crud.PatchOperation, 0, len(patch.Operations))
 	for _, patchOp := range patch.Operations {
-		switch strings.ToLower(patchOp.Op) {
-		case "add":
-			if valueToAdd, err := patchOp.ParseValue(resource); err != nil {
-				return nil, err
-			} else if err := crud.Add(resource, patchOp.Path, valueToAdd); err != nil {
-				return nil, err
-			}
-		case "replace":
-			if valueToReplace, err := patchOp.ParseValue(resource); err != nil {
-				return nil, err
-			} else if err := crud.Replace(resource, patchOp.Path, valueToReplace); err != nil {
-				return nil, err
-			}
-		case "remove":
-			if err := crud.Delete(resource, patchOp.Path); err != nil {
-				return nil, err
-			}
-		}
+		patchOps = append(patchOps, crud.PatchOperation{Op: patchOp.Op, Path: patchOp.Path, Value: patchOp.Value})
+	}
+	if _, err = crud.ApplyPatch(resource, patchOps); err != nil {
+		return
 	}
 
 	for _, f := range s.postFilters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
 		if err = f.FilterRef(ctx, resource, ref); err != nil {
 			return
 		}
@@ -151,14 +164,172 @@ func (s *patchService) Do(ctx context.Context, req *PatchRequest) (resp *PatchRe
 
 	resp = &PatchResponse{
 		Patched:  true,
-		Resource: resource,
+		Resource: maskResponse(ctx, s.config, scrubResponse(resource)),
 		Ref:      ref,
 	}
 	return
 }
 
+// tryMemberFastPath attempts to serve the patch request through the optional db.MemberUpdater
+// capability, which applies single member additions and removals as targeted database updates
+// instead of a read-modify-write of the whole resource. The fast path only engages when the
+// database implements the capability, no version pre condition was requested, and every operation
+// in the payload is a plain member addition or removal; otherwise handled is false and the regular
+// path must run. Operations served by the fast path bypass the resource filters: meta maintenance
+// is part of the db.MemberUpdater contract, but the validating filters do not run, which is
+// acceptable only because the operations are constrained to member entries. The response carries
+// no resource rendering; instead, the values of the members added and removed are reported, so
+// that callers reacting to membership changes need not compare resource snapshots.
+func (s *patchService) tryMemberFastPath(ctx context.Context, req *PatchRequest, patch *PatchPayload) (handled bool, added []string, removed []string, err error) {
+	updater, ok := s.database.(db.MemberUpdater)
+	if !ok || req.MatchCriteria != nil {
+		return false, nil, nil, nil
+	}
+
+	type memberOp struct {
+		add      map[string]interface{}
+		addValue string
+		remove   string
+	}
+	ops := make([]memberOp, 0, len(patch.Operations))
+	for _, op := range patch.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add":
+			if !spec.SameAttributeName(op.Path, "members") {
+				return false, nil, nil, nil
+			}
+			var single map[string]interface{}
+			if json.Unmarshal(op.Value, &single) == nil {
+				memberValue, ok := memberAddValue(single)
+				if !ok {
+					return false, nil, nil, nil
+				}
+				ops = append(ops, memberOp{add: single, addValue: memberValue})
+				continue
+			}
+			var many []map[string]interface{}
+			if json.Unmarshal(op.Value, &many) != nil {
+				return false, nil, nil, nil
+			}
+			for _, member := range many {
+				memberValue, ok := memberAddValue(member)
+				if !ok {
+					return false, nil, nil, nil
+				}
+				ops = append(ops, memberOp{add: member, addValue: memberValue})
+			}
+		case "remove":
+			if len(op.Value) > 0 {
+				// the value-identified form: {"op": "remove", "path": "members", "value": [{"value": "X"}]}
+				if !spec.SameAttributeName(op.Path, "members") {
+					return false, nil, nil, nil
+				}
+				memberValues, ok := memberRemoveValues(op.Value)
+				if !ok {
+					return false, nil, nil, nil
+				}
+				for _, memberValue := range memberValues {
+					ops = append(ops, memberOp{remove: memberValue})
+				}
+				continue
+			}
+			memberValue, ok := memberRemoveTarget(op.Path)
+			if !ok {
+				return false, nil, nil, nil
+			}
+			ops = append(ops, memberOp{remove: memberValue})
+		default:
+			return false, nil, nil, nil
+		}
+	}
+
+	for _, op := range ops {
+		if op.add != nil {
+			if err = updater.AddMember(ctx, req.ResourceID, op.add); err != nil {
+				return true, nil, nil, err
+			}
+			added = append(added, op.addValue)
+		} else {
+			if err = updater.RemoveMember(ctx, req.ResourceID, op.remove); err != nil {
+				return true, nil, nil, err
+			}
+			removed = append(removed, op.remove)
+		}
+	}
+
+	return true, added, removed, nil
+}
+
+// memberAddValue extracts the value sub attribute from the member to add, honoring the case
+// insensitivity of attribute names. Members carrying no value cannot be reported in the response
+// delta and are left to the regular path.
+func memberAddValue(member map[string]interface{}) (string, bool) {
+	for name, value := range member {
+		if spec.SameAttributeName(name, "value") {
+			memberValue, ok := value.(string)
+			return memberValue, ok && len(memberValue) > 0
+		}
+	}
+	return "", false
+}
+
+// memberRemoveValues parses remove operation values of the form {"value": "X"}, or an array thereof,
+// and returns the member values to remove. Criteria carrying any other assigned field are rejected, as
+// they require the full equality semantics of the regular path.
+func memberRemoveValues(raw json.RawMessage) ([]string, bool) {
+	var criteria []map[string]interface{}
+	if json.Unmarshal(raw, &criteria) != nil {
+		var single map[string]interface{}
+		if json.Unmarshal(raw, &single) != nil {
+			return nil, false
+		}
+		criteria = []map[string]interface{}{single}
+	}
+
+	memberValues := make([]string, 0, len(criteria))
+	for _, criterion := range criteria {
+		if len(criterion) != 1 {
+			return nil, false
+		}
+		memberValue, ok := criterion["value"].(string)
+		if !ok {
+			return nil, false
+		}
+		memberValues = append(memberValues, memberValue)
+	}
+	return memberValues, true
+}
+
+// memberRemoveTarget parses remove paths of the exact form 'members[value eq "X"]' and returns X.
+func memberRemoveTarget(path string) (string, bool) {
+	head, err := expr.CompilePath(path)
+	if err != nil || head == nil || !head.IsPath() || !spec.SameAttributeName(head.Token(), "members") {
+		return "", false
+	}
+
+	f := head.Next()
+	if f == nil || !f.IsRootOfFilter() || f.Next() != nil {
+		return "", false
+	}
+	if strings.ToLower(f.Token()) != expr.Eq || f.Left() == nil || f.Right() == nil {
+		return "", false
+	}
+	if !f.Left().IsPath() || !spec.SameAttributeName(f.Left().Token(), "value") || f.Left().Next() != nil {
+		return "", false
+	}
+	if !f.Right().IsLiteral() {
+		return "", false
+	}
+
+	memberValue, unquoteErr := strconv.Unquote(f.Right().Token())
+	if unquoteErr != nil {
+		return "", false
+	}
+	return memberValue, true
+}
+
 func (s *patchService) checkSupport() error {
-	if !s.config.Patch.Supported {
+	if !s.config.PatchSupported() {
 		return fmt.Errorf("%w: patch operation is not supported", spec.ErrInternal)
 	}
 	return nil
@@ -183,7 +354,7 @@ func (s *patchService) parseRequest(req *PatchRequest) (*PatchPayload, error) {
 }
 
 func (p *PatchPayload) Validate() error {
-	if len(p.Schemas) != 1 && p.Schemas[0] != "urn:ietf:params:scim:api:messages:2.0:PatchOp" {
+	if len(p.Schemas) != 1 || !strings.EqualFold(p.Schemas[0], "urn:ietf:params:scim:api:messages:2.0:PatchOp") {
 		return fmt.Errorf("%w: invalid patch operation schema", spec.ErrInvalidSyntax)
 	}
 
@@ -198,10 +369,10 @@ func (p *PatchPayload) Validate() error {
 				return fmt.Errorf("%w: no value for replace operation", spec.ErrInvalidSyntax)
 			}
 		case "remove":
+			// a value is permitted for remove operations: it identifies the elements
+			// to remove from the multiValued property at path (see crud.ApplyPatch)
 			if len(each.Path) == 0 {
 				return fmt.Errorf("%w: no path for remove operation", spec.ErrInvalidSyntax)
-			} else if len(each.Value) > 0 {
-				return fmt.Errorf("%w: value is unnecessary for remove operation", spec.ErrInvalidSyntax)
 			}
 		default:
 			return fmt.Errorf("%w: invalid patch operation", spec.ErrInvalidSyntax)
@@ -211,48 +382,8 @@ func (p *PatchPayload) Validate() error {
 	return nil
 }
 
+// ParseValue deserializes the raw JSON value of the operation against the attribute targeted by the
+// operation path, and returns the value in Golang's native type.
 func (o *PatchOperation) ParseValue(resource *prop.Resource) (interface{}, error) {
-	var (
-		head *expr.Expression
-		err  error
-	)
-	{
-		if len(o.Path) > 0 {
-			head, err = expr.CompilePath(o.Path)
-			if err != nil {
-				return nil, err
-			}
-			if head.IsPath() && head.Token() == resource.ResourceType().ID() {
-				head = head.Next()
-			}
-		}
-	}
-
-	attr := o.getTargetAttribute(resource.RootAttribute(), head)
-	if attr == nil {
-		return nil, fmt.Errorf("%w: path '%s' is invalid", spec.ErrInvalidPath, o.Path)
-	}
-
-	p := prop.NewProperty(attr)
-	if err := scimjson.DeserializeProperty(o.Value, p, strings.ToLower(o.Op) == "add"); err != nil {
-		return nil, err
-	}
-
-	return p.Raw(), nil
-}
-
-func (o *PatchOperation) getTargetAttribute(parentAttr *spec.Attribute, cursor *expr.Expression) *spec.Attribute {
-	if cursor == nil {
-		return parentAttr
-	}
-
-	if parentAttr == nil {
-		return nil
-	}
-
-	if cursor.IsRootOfFilter() {
-		return o.getTargetAttribute(parentAttr, cursor.Next())
-	}
-
-	return o.getTargetAttribute(parentAttr.SubAttributeForName(cursor.Token()), cursor.Next())
+	return crud.PatchOperation{Op: o.Op, Path: o.Path, Value: o.Value}.ParseValue(resource)
 }
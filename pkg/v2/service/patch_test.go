@@ -6,12 +6,12 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	"golang.org/x/crypto/bcrypt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -26,7 +26,7 @@ func TestPatchService(t *testing.T) {
 type PatchServiceTestSuite struct {
 	suite.Suite
 	resourceType *spec.ResourceType
-	config       *spec.ServiceProviderConfig
+	config       *scim.Config
 }
 
 func (s *PatchServiceTestSuite) TestDo() {
@@ -257,10 +257,121 @@ func (s *PatchServiceTestSuite) TestDo() {
 				assert.Nil(t, err)
 				assert.True(t, resp.Patched)
 				assert.Equal(t, "bar", resp.Resource.Navigator().Dot("userName").Current().Raw())
-				assert.Nil(t, bcrypt.CompareHashAndPassword(
-					[]byte(resp.Resource.Navigator().Dot("password").Current().Raw().(string)),
-					[]byte("a-new-password"),
-				))
+				// writeOnly values never leave the service
+				assert.True(t, resp.Resource.Navigator().Dot("password").Current().IsUnassigned())
+			},
+		},
+		{
+			name: "patch with case-insensitive paths",
+			setup: func(t *testing.T) Patch {
+				database := db.Memory()
+				err := database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+					"id":       "foo",
+					"userName": "foo",
+					"timezone": "Asia/Shanghai",
+					"emails": []interface{}{
+						map[string]interface{}{
+							"value": "foo@bar.com",
+							"type":  "home",
+						},
+					},
+				}))
+				require.Nil(t, err)
+				return PatchService(s.config, database, nil, []filter.ByResource{
+					filter.ByPropertyToByResource(
+						filter.ReadOnlyFilter(),
+						filter.BCryptFilter(),
+					),
+					filter.ByPropertyToByResource(filter.ValidationFilter(database)),
+					filter.MetaFilter(),
+				})
+			},
+			getRequest: func() *PatchRequest {
+				return &PatchRequest{
+					ResourceID: "foo",
+					PayloadSource: strings.NewReader(`
+		{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{
+					"Op": "Add",
+					"path": "urn:ietf:params:scim:schemas:core:2.0:USER:UserName",
+					"value": "foobar"
+				},
+				{
+					"op": "replace",
+					"path": "EMAILS[value eq \"foo@bar.com\"].Type",
+					"value": "work"
+				},
+				{
+					"op": "remove",
+					"path": "TimeZone"
+				}
+			]
+		}
+		`),
+				}
+			},
+			expect: func(t *testing.T, resp *PatchResponse, err error) {
+				assert.Nil(t, err)
+				assert.True(t, resp.Patched)
+				assert.Equal(t, "foobar", resp.Resource.Navigator().Dot("userName").Current().Raw())
+				assert.True(t, resp.Resource.Navigator().Dot("timezone").Current().IsUnassigned())
+				assert.Equal(t, "work", resp.Resource.Navigator().Dot("emails").At(0).Dot("type").Current().Raw())
+			},
+		},
+		{
+			name: "remove multiValued elements identified by value",
+			setup: func(t *testing.T) Patch {
+				database := db.Memory()
+				err := database.Insert(context.TODO(), s.resourceOf(t, map[string]interface{}{
+					"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+					"id":       "foo",
+					"userName": "foo",
+					"emails": []interface{}{
+						map[string]interface{}{
+							"value": "foo@bar.com",
+							"type":  "home",
+						},
+						map[string]interface{}{
+							"value": "foo@corp.com",
+							"type":  "work",
+						},
+					},
+				}))
+				require.Nil(t, err)
+				return PatchService(s.config, database, nil, []filter.ByResource{
+					filter.ByPropertyToByResource(
+						filter.ReadOnlyFilter(),
+						filter.BCryptFilter(),
+					),
+					filter.ByPropertyToByResource(filter.ValidationFilter(database)),
+					filter.MetaFilter(),
+				})
+			},
+			getRequest: func() *PatchRequest {
+				return &PatchRequest{
+					ResourceID: "foo",
+					PayloadSource: strings.NewReader(`
+		{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{
+					"op": "remove",
+					"path": "emails",
+					"value": [{"value": "foo@bar.com"}]
+				}
+			]
+		}
+		`),
+				}
+			},
+			expect: func(t *testing.T, resp *PatchResponse, err error) {
+				assert.Nil(t, err)
+				assert.True(t, resp.Patched)
+				assert.Equal(t, 1, resp.Resource.Navigator().Dot("emails").Current().CountChildren())
+				assert.Equal(t, "foo@corp.com", resp.Resource.Navigator().Dot("emails").At(0).Dot("value").Current().Raw())
 			},
 		},
 		{
@@ -384,12 +495,13 @@ func (s *PatchServiceTestSuite) SetupSuite() {
 		}
 	}
 
-	s.config = new(spec.ServiceProviderConfig)
+	spc := new(spec.ServiceProviderConfig)
 	require.Nil(s.T(), json.Unmarshal([]byte(`
 {
   "patch": {
     "supported": true
   }
 }
-`), s.config))
+`), spc))
+	s.config = scim.NewConfig(scim.WithServiceProviderConfig(spc))
 }
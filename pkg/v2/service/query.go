@@ -7,15 +7,25 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud/expr"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 )
 
 // QueryService returns a query resource service. This service is only capable of performing querying on a single type
 // of resource. This does not handle root query.
-func QueryService(config *spec.ServiceProviderConfig, database db.DB) Query {
+func QueryService(config *scim.Config, database db.DB) Query {
+	return QueryServiceWithCountStrategy(config, database, ExactCount())
+}
+
+// QueryServiceWithCountStrategy returns a query resource service like QueryService, except that the total number
+// of matching resources is obtained through the given CountStrategy instead of an exact database count on every
+// request. See CachedCount for an alternative strategy suitable for very large collections.
+func QueryServiceWithCountStrategy(config *scim.Config, database db.DB, strategy CountStrategy) Query {
 	return &queryService{
 		database: database,
 		config:   config,
+		count:    strategy,
 	}
 }
 
@@ -23,6 +33,9 @@ type (
 	// Query resource service
 	Query interface {
 		Do(ctx context.Context, req *QueryRequest) (resp *QueryResponse, err error)
+		// Explain describes how the query request would be executed without executing it. It is a
+		// debug facility meant for operators investigating slow queries.
+		Explain(ctx context.Context, req *QueryRequest) (resp *QueryExplainResponse, err error)
 	}
 	// Query resource request
 	QueryRequest struct {
@@ -39,14 +52,35 @@ type (
 		Resources    []json.Serializable
 		Projection   *crud.Projection // included so that caller may render properly
 	}
+	// Query explanation response
+	QueryExplainResponse struct {
+		Filter           *ExplainedExpression `json:"filter"`           // compiled filter expression tree
+		Strategy         string               `json:"strategy"`         // description of the backend execution strategy
+		EstimatedResults int                  `json:"estimatedResults"` // number of resources the filter currently matches
+	}
+	// ExplainedExpression is a JSON friendly rendering of a compiled filter expression tree.
+	ExplainedExpression struct {
+		Token string               `json:"token"`
+		Type  string               `json:"type"`
+		Left  *ExplainedExpression `json:"left,omitempty"`
+		Right *ExplainedExpression `json:"right,omitempty"`
+	}
 )
 
 type queryService struct {
 	database db.DB
-	config   *spec.ServiceProviderConfig
+	config   *scim.Config
+	count    CountStrategy
 }
 
 func (s *queryService) Do(ctx context.Context, req *QueryRequest) (resp *QueryResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.query")
+	span.SetTag("scim.filter", req.Filter)
+	defer func() { span.SetError(err); span.End() }()
+
 	if err = s.checkSupport(req); err != nil {
 		return
 	}
@@ -58,21 +92,22 @@ func (s *queryService) Do(ctx context.Context, req *QueryRequest) (resp *QueryRe
 	resp = new(QueryResponse)
 	resp.Projection = req.Projection
 
+	req.Pagination = req.Pagination.Normalize(s.config.DefaultPageSize(), s.config.MaxPageSize())
 	if req.Pagination != nil {
 		resp.StartIndex = req.Pagination.StartIndex
 	}
 
-	if resp.TotalResults, err = s.database.Count(ctx, req.Filter); err != nil {
+	if resp.TotalResults, err = s.count.Count(ctx, s.database, req.Filter); err != nil {
 		return
 	}
 	if req.Pagination != nil && req.Pagination.Count == 0 {
 		return
 	}
 
-	if s.config.Filter.MaxResults > 0 {
-		if (req.Pagination == nil && resp.TotalResults > s.config.Filter.MaxResults) ||
-			(req.Pagination != nil && req.Pagination.Count > s.config.Filter.MaxResults) {
-			err = spec.ErrTooMany
+	if s.config.FilterMaxResults() > 0 {
+		if (req.Pagination == nil && resp.TotalResults > s.config.FilterMaxResults()) ||
+			(req.Pagination != nil && req.Pagination.Count > s.config.FilterMaxResults()) {
+			err = fmt.Errorf("%w: query exceeds the maximum of %d results", spec.ErrTooMany, s.config.FilterMaxResults())
 			return
 		}
 	}
@@ -82,21 +117,84 @@ func (s *queryService) Do(ctx context.Context, req *QueryRequest) (resp *QueryRe
 		return
 	}
 	for _, r := range resources {
-		resp.Resources = append(resp.Resources, r)
+		resp.Resources = append(resp.Resources, maskResponse(ctx, s.config, scrubResponse(r)))
 	}
 
 	resp.ItemsPerPage = len(resp.Resources)
 	return
 }
 
+func (s *queryService) Explain(ctx context.Context, req *QueryRequest) (resp *QueryExplainResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.query.explain")
+	span.SetTag("scim.filter", req.Filter)
+	defer func() { span.SetError(err); span.End() }()
+
+	if err = s.checkSupport(req); err != nil {
+		return
+	}
+
+	if err = req.ValidateAndDefault(); err != nil {
+		return
+	}
+
+	compiled, err := expr.CompileFilter(req.Filter)
+	if err != nil {
+		return
+	}
+
+	resp = &QueryExplainResponse{Filter: explainExpression(compiled)}
+
+	if planner, ok := s.database.(db.QueryPlanner); ok {
+		if resp.Strategy, err = planner.QueryPlan(ctx, req.Filter); err != nil {
+			return
+		}
+	} else {
+		resp.Strategy = "unknown: database does not describe its query plan"
+	}
+
+	if resp.EstimatedResults, err = s.count.Count(ctx, s.database, req.Filter); err != nil {
+		return
+	}
+
+	return
+}
+
+// explainExpression renders the compiled expression tree in a JSON friendly form.
+func explainExpression(expression *expr.Expression) *ExplainedExpression {
+	if expression == nil {
+		return nil
+	}
+	explained := &ExplainedExpression{
+		Token: expression.Token(),
+		Left:  explainExpression(expression.Left()),
+		Right: explainExpression(expression.Right()),
+	}
+	switch {
+	case expression.IsPath():
+		explained.Type = "path"
+	case expression.IsLogicalOperator():
+		explained.Type = "logicalOperator"
+	case expression.IsRelationalOperator():
+		explained.Type = "relationalOperator"
+	case expression.IsLiteral():
+		explained.Type = "literal"
+	default:
+		explained.Type = "unknown"
+	}
+	return explained
+}
+
 func (s *queryService) checkSupport(request *QueryRequest) error {
-	if !s.config.Filter.Supported {
+	if !s.config.FilterSupported() {
 		if len(request.Filter) > 0 {
 			return fmt.Errorf("%w: filter is not supported", spec.ErrInvalidSyntax)
 		}
 	}
 
-	if !s.config.Sort.Supported {
+	if !s.config.SortSupported() {
 		if request.Sort != nil && len(request.Sort.By) > 0 {
 			return fmt.Errorf("%w: sorting is not supported", spec.ErrInvalidSyntax)
 		}
@@ -131,6 +229,19 @@ func (q *QueryRequest) ValidateAndDefault() error {
 		default:
 			return fmt.Errorf("%w: invalid sortOrder", spec.ErrInvalidSyntax)
 		}
+		for _, then := range q.Sort.Then {
+			if len(then.By) > 0 {
+				if _, err := expr.CompilePath(then.By); err != nil {
+					return err
+				}
+			}
+			switch then.Order {
+			case "", crud.SortAsc, crud.SortDesc:
+			default:
+				return fmt.Errorf("%w: invalid sortOrder", spec.ErrInvalidSyntax)
+			}
+		}
+		q.appendSortTieBreaker()
 	}
 	if q.Projection != nil {
 		if len(q.Projection.Attributes) > 0 && len(q.Projection.ExcludedAttributes) > 0 {
@@ -153,3 +264,19 @@ func (q *QueryRequest) ValidateAndDefault() error {
 	}
 	return nil
 }
+
+// appendSortTieBreaker appends a lowest priority sort key on id, unless id is already among the sort
+// keys. Because id is unique, the resulting sort is total, so paginated traversals never skip or
+// duplicate resources when the primary sort key has ties. The tie breaker follows the order of the
+// primary key, keeping a reversed sort an exact reversal.
+func (q *QueryRequest) appendSortTieBreaker() {
+	if spec.SameAttributeName(q.Sort.By, "id") {
+		return
+	}
+	for _, then := range q.Sort.Then {
+		if spec.SameAttributeName(then.By, "id") {
+			return
+		}
+	}
+	q.Sort.Then = append(q.Sort.Then, crud.Sort{By: "id", Order: q.Sort.Order})
+}
@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,7 +24,7 @@ func TestQueryService(t *testing.T) {
 
 type QueryServiceTestSuite struct {
 	suite.Suite
-	config       *spec.ServiceProviderConfig
+	config       *scim.Config
 	resourceType *spec.ResourceType
 }
 
@@ -160,6 +162,38 @@ func (s *QueryServiceTestSuite) TestDo() {
 				}
 			},
 		},
+		{
+			name: "exceeding maxResults returns tooMany",
+			setup: func(t *testing.T) Query {
+				database := db.Memory()
+				for _, userData := range []interface{}{
+					map[string]interface{}{"id": "user001"},
+					map[string]interface{}{"id": "user002"},
+					map[string]interface{}{"id": "user003"},
+				} {
+					require.Nil(t, database.Insert(context.TODO(), s.resourceOf(t, userData)))
+				}
+
+				spc := new(spec.ServiceProviderConfig)
+				require.Nil(t, json.Unmarshal([]byte(`
+{
+  "filter": {
+    "supported": true,
+    "maxResults": 2
+  }
+}
+`), spc))
+				return QueryService(scim.NewConfig(scim.WithServiceProviderConfig(spc)), database)
+			},
+			getRequest: func() *QueryRequest {
+				return &QueryRequest{
+					Filter: "id pr",
+				}
+			},
+			expect: func(t *testing.T, resp *QueryResponse, err error) {
+				assert.True(t, errors.Is(err, spec.ErrTooMany))
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -171,6 +205,116 @@ func (s *QueryServiceTestSuite) TestDo() {
 	}
 }
 
+func (s *QueryServiceTestSuite) TestExplain() {
+	database := db.Memory()
+	for _, userData := range []interface{}{
+		map[string]interface{}{"id": "user001", "userName": "user001"},
+		map[string]interface{}{"id": "user002", "userName": "user002"},
+		map[string]interface{}{"id": "user003", "userName": "user003"},
+	} {
+		require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), userData)))
+	}
+
+	resp, err := QueryService(s.config, database).Explain(context.TODO(), &QueryRequest{
+		Filter: "userName ew \"003\" and id pr",
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, resp.EstimatedResults)
+	assert.Contains(s.T(), resp.Strategy, "full scan")
+
+	require.NotNil(s.T(), resp.Filter)
+	assert.Equal(s.T(), "and", resp.Filter.Token)
+	assert.Equal(s.T(), "logicalOperator", resp.Filter.Type)
+	require.NotNil(s.T(), resp.Filter.Left)
+	assert.Equal(s.T(), "ew", resp.Filter.Left.Token)
+	assert.Equal(s.T(), "userName", resp.Filter.Left.Left.Token)
+	assert.Equal(s.T(), "path", resp.Filter.Left.Left.Type)
+	assert.Equal(s.T(), "literal", resp.Filter.Left.Right.Type)
+	require.NotNil(s.T(), resp.Filter.Right)
+	assert.Equal(s.T(), "pr", resp.Filter.Right.Token)
+}
+
+func (s *QueryServiceTestSuite) TestSortTieBreaker() {
+	// an id tie breaker is appended to non-id sorts, unless id is already among the sort keys
+	req := &QueryRequest{Filter: "id pr", Sort: &crud.Sort{By: "userName", Order: crud.SortDesc}}
+	require.Nil(s.T(), req.ValidateAndDefault())
+	assert.Equal(s.T(), []crud.Sort{{By: "id", Order: crud.SortDesc}}, req.Sort.Then)
+	require.Nil(s.T(), req.ValidateAndDefault())
+	assert.Len(s.T(), req.Sort.Then, 1)
+
+	req = &QueryRequest{Filter: "id pr", Sort: &crud.Sort{By: "id"}}
+	require.Nil(s.T(), req.ValidateAndDefault())
+	assert.Empty(s.T(), req.Sort.Then)
+
+	// with the tie breaker, paginated traversal over a primary key full of ties visits every
+	// resource exactly once
+	database := db.Memory()
+	for _, userData := range []interface{}{
+		map[string]interface{}{"id": "user003", "title": "engineer"},
+		map[string]interface{}{"id": "user001", "title": "engineer"},
+		map[string]interface{}{"id": "user004", "title": "engineer"},
+		map[string]interface{}{"id": "user002", "title": "engineer"},
+	} {
+		require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), userData)))
+	}
+	service := QueryService(s.config, database)
+
+	var collected []string
+	for startIndex := 1; startIndex <= 4; startIndex += 2 {
+		resp, err := service.Do(context.Background(), &QueryRequest{
+			Filter:     "title pr",
+			Sort:       &crud.Sort{By: "title"},
+			Pagination: &crud.Pagination{StartIndex: startIndex, Count: 2},
+		})
+		require.Nil(s.T(), err)
+		for _, r := range resp.Resources {
+			collected = append(collected, r.(*prop.Resource).IdOrEmpty())
+		}
+	}
+	assert.Equal(s.T(), []string{"user001", "user002", "user003", "user004"}, collected)
+}
+
+func (s *QueryServiceTestSuite) TestPageSizeDefaultsAndBounds() {
+	database := db.Memory()
+	for _, userData := range []interface{}{
+		map[string]interface{}{"id": "user001"},
+		map[string]interface{}{"id": "user002"},
+		map[string]interface{}{"id": "user003"},
+		map[string]interface{}{"id": "user004"},
+	} {
+		require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), userData)))
+	}
+	config := scim.NewConfig(
+		scim.WithServiceProviderConfig(s.config.ServiceProviderConfig()),
+		scim.WithPageSize(2, 3),
+	)
+	service := QueryService(config, database)
+
+	// a query omitting pagination entirely is paginated at the default page size
+	resp, err := service.Do(context.Background(), &QueryRequest{Filter: "id pr"})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 4, resp.TotalResults)
+	assert.Equal(s.T(), 1, resp.StartIndex)
+	assert.Equal(s.T(), 2, resp.ItemsPerPage)
+
+	// a requested count beyond the maximum page size is clamped
+	resp, err = service.Do(context.Background(), &QueryRequest{
+		Filter:     "id pr",
+		Pagination: &crud.Pagination{StartIndex: 1, Count: 10},
+	})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 3, resp.ItemsPerPage)
+
+	// an explicit count of 0 still requests the total only
+	resp, err = service.Do(context.Background(), &QueryRequest{
+		Filter:     "id pr",
+		Pagination: &crud.Pagination{StartIndex: 1, Count: 0},
+	})
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 4, resp.TotalResults)
+	assert.Empty(s.T(), resp.Resources)
+}
+
 func (s *QueryServiceTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
 	r := prop.NewResource(s.resourceType)
 	require.Nil(t, r.Navigator().Replace(data).Error())
@@ -227,7 +371,7 @@ func (s *QueryServiceTestSuite) SetupSuite() {
 		}
 	}
 
-	s.config = new(spec.ServiceProviderConfig)
+	spc := new(spec.ServiceProviderConfig)
 	require.Nil(s.T(), json.Unmarshal([]byte(`
 {
   "filter": {
@@ -237,5 +381,6 @@ func (s *QueryServiceTestSuite) SetupSuite() {
     "supported": true
   }
 }
-`), s.config))
+`), spc))
+	s.config = scim.NewConfig(scim.WithServiceProviderConfig(spc))
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/scimfilter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+const (
+	// ReferenceIntegrityReject rejects the delete with a conflict error when inbound references exist.
+	ReferenceIntegrityReject = "reject"
+	// ReferenceIntegrityCascade removes the inbound references before carrying out the delete.
+	ReferenceIntegrityCascade = "cascade"
+)
+
+// InboundReference describes a multiValued complex attribute on another resource type whose value
+// sub property may reference the resource being deleted (i.e. {Group, groupDatabase, "members"}
+// when deleting a User).
+type InboundReference struct {
+	ResourceType *spec.ResourceType
+	Database     db.DB
+	Path         string
+}
+
+// DeleteWithReferenceIntegrity returns a Delete service decorated to enforce reference integrity
+// over the given inbound references. In ReferenceIntegrityReject mode, the delete fails with a
+// conflict error while referencing resources exist; in ReferenceIntegrityCascade mode, the
+// referencing entries are removed from their resources before the delete is carried out.
+func DeleteWithReferenceIntegrity(delegate Delete, mode string, refs ...InboundReference) Delete {
+	return &refGuardedDelete{delegate: delegate, mode: mode, refs: refs}
+}
+
+type refGuardedDelete struct {
+	delegate Delete
+	mode     string
+	refs     []InboundReference
+}
+
+func (s *refGuardedDelete) Do(ctx context.Context, req *DeleteRequest) (resp *DeleteResponse, err error) {
+	for _, ref := range s.refs {
+		if err = s.enforce(ctx, ref, req.ResourceID); err != nil {
+			return
+		}
+	}
+	return s.delegate.Do(ctx, req)
+}
+
+func (s *refGuardedDelete) enforce(ctx context.Context, ref InboundReference, id string) error {
+	filter, err := scimfilter.Eq(ref.Path+".value", id).Render()
+	if err != nil {
+		return err
+	}
+
+	switch s.mode {
+	case ReferenceIntegrityReject:
+		n, err := ref.Database.Count(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: resource is referenced by '%s' of %d %s resource(s)",
+				spec.ErrConflict, ref.Path, n, ref.ResourceType.Name())
+		}
+		return nil
+	case ReferenceIntegrityCascade:
+		referencing, err := ref.Database.Query(ctx, filter, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, resource := range referencing {
+			original := resource.Clone()
+			path, err := scimfilter.Eq("value", id).Render()
+			if err != nil {
+				return err
+			}
+			if err := crud.Delete(resource, fmt.Sprintf("%s[%s]", ref.Path, path)); err != nil {
+				return err
+			}
+			if err := ref.Database.Replace(ctx, original, resource); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown reference integrity mode '%s'", spec.ErrInternal, s.mode)
+	}
+}
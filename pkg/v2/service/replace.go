@@ -6,15 +6,17 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
 	"io"
 	"io/ioutil"
 )
 
 // ReplaceService returns a replace service.
 func ReplaceService(
-	config *spec.ServiceProviderConfig,
+	config *scim.Config,
 	resourceType *spec.ResourceType,
 	database db.DB,
 	filters []filter.ByResource,
@@ -50,16 +52,23 @@ type replaceService struct {
 	resourceType *spec.ResourceType
 	filters      []filter.ByResource
 	database     db.DB
-	config       *spec.ServiceProviderConfig
+	config       *scim.Config
 }
 
 func (s *replaceService) Do(ctx context.Context, req *ReplaceRequest) (resp *ReplaceResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.replace")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	defer func() { span.SetError(err); span.End() }()
+
 	ref, err := s.database.Get(ctx, req.ResourceID, nil)
 	if err != nil {
 		return
 	}
 
-	if s.config.ETag.Supported && req.MatchCriteria != nil {
+	if s.config.ETagSupported() && req.MatchCriteria != nil {
 		if !req.MatchCriteria(ref) {
 			err = fmt.Errorf("%w: resource does not meet pre condition", spec.ErrConflict)
 			return
@@ -72,6 +81,9 @@ func (s *replaceService) Do(ctx context.Context, req *ReplaceRequest) (resp *Rep
 	}
 
 	for _, f := range s.filters {
+		if err = ctx.Err(); err != nil {
+			return
+		}
 		if err = f.FilterRef(ctx, replacement, ref); err != nil {
 			return
 		}
@@ -95,7 +107,7 @@ func (s *replaceService) Do(ctx context.Context, req *ReplaceRequest) (resp *Rep
 
 	resp = &ReplaceResponse{
 		Replaced: true,
-		Resource: replacement,
+		Resource: maskResponse(ctx, s.config, scrubResponse(replacement)),
 		Ref:      ref,
 	}
 	return
@@ -7,6 +7,7 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/crud"
 	"github.com/imulab/go-scim/pkg/v2/db"
 	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
 	"github.com/imulab/go-scim/pkg/v2/service/filter"
 	"github.com/imulab/go-scim/pkg/v2/spec"
 	"github.com/stretchr/testify/assert"
@@ -45,7 +46,7 @@ func (s *ReplaceServiceTestSuite) TestDo() {
 					"userName": "foo",
 				}))
 				require.Nil(t, err)
-				return ReplaceService(&spec.ServiceProviderConfig{}, s.resourceType, database, []filter.ByResource{
+				return ReplaceService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{
 					filter.ByPropertyToByResource(
 						filter.ReadOnlyFilter(),
 						filter.BCryptFilter(),
@@ -94,7 +95,7 @@ func (s *ReplaceServiceTestSuite) TestDo() {
 					},
 				}))
 				require.Nil(t, err)
-				return ReplaceService(&spec.ServiceProviderConfig{}, s.resourceType, database, []filter.ByResource{
+				return ReplaceService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{
 					filter.ByPropertyToByResource(
 						filter.ReadOnlyFilter(),
 						filter.BCryptFilter(),
@@ -142,7 +143,7 @@ func (s *ReplaceServiceTestSuite) TestDo() {
 					},
 				}))
 				require.Nil(t, err)
-				return ReplaceService(&spec.ServiceProviderConfig{}, s.resourceType, database, []filter.ByResource{
+				return ReplaceService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{
 					filter.ByPropertyToByResource(
 						filter.ReadOnlyFilter(),
 						filter.BCryptFilter(),
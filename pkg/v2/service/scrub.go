@@ -0,0 +1,43 @@
+package service
+
+import (
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+)
+
+// scrubResponse returns the resource with every property whose attribute is declared returned=never
+// or mutability=writeOnly removed. The serializer already skips such properties, but each service
+// scrubs its response resource as a centralized guard, so that response paths which do not go
+// through the serializer, such as bulk sub-responses or payload echoes, cannot leak them either.
+// When the resource holds no such values, it is returned as is; otherwise a scrubbed clone is
+// returned, leaving the original intact for persistence and events.
+func scrubResponse(resource *prop.Resource) *prop.Resource {
+	if resource == nil || len(collectScrubTargets(resource.RootProperty())) == 0 {
+		return resource
+	}
+
+	scrubbed := resource.Clone()
+	for _, target := range collectScrubTargets(scrubbed.RootProperty()) {
+		_, _ = target.Delete()
+	}
+	return scrubbed
+}
+
+// collectScrubTargets returns the assigned properties in the subtree whose attribute must never be
+// returned. Subtrees of such properties are not descended into, as deleting the root covers them.
+func collectScrubTargets(property prop.Property) []prop.Property {
+	attr := property.Attribute()
+	if attr.Returned() == spec.ReturnedNever || attr.Mutability() == spec.MutabilityWriteOnly {
+		if property.IsUnassigned() {
+			return nil
+		}
+		return []prop.Property{property}
+	}
+
+	var targets []prop.Property
+	_ = property.ForEachChild(func(_ int, child prop.Property) error {
+		targets = append(targets, collectScrubTargets(child)...)
+		return nil
+	})
+	return targets
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestScrubResponse(t *testing.T) {
+	s := new(ScrubResponseTestSuite)
+	suite.Run(t, s)
+}
+
+type ScrubResponseTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ScrubResponseTestSuite) TestScrubRemovesWriteOnlyValues() {
+	resource := prop.NewResource(s.resourceType)
+	require.False(s.T(), resource.Navigator().Replace(map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "u1",
+		"userName": "imulab",
+		"password": "s3cret",
+	}).HasError())
+
+	scrubbed := scrubResponse(resource)
+
+	assert.True(s.T(), scrubbed.Navigator().Dot("password").Current().IsUnassigned())
+	assert.Equal(s.T(), "imulab", scrubbed.Navigator().Dot("userName").Current().Raw())
+
+	// the original resource is left intact for persistence and events
+	assert.Equal(s.T(), "s3cret", resource.Navigator().Dot("password").Current().Raw())
+}
+
+func (s *ScrubResponseTestSuite) TestResourceWithNothingToScrubIsReturnedAsIs() {
+	resource := prop.NewResource(s.resourceType)
+	require.False(s.T(), resource.Navigator().Replace(map[string]interface{}{
+		"schemas":  []interface{}{"urn:ietf:params:scim:schemas:core:2.0:User"},
+		"id":       "u1",
+		"userName": "imulab",
+	}).HasError())
+
+	assert.True(s.T(), resource == scrubResponse(resource))
+}
+
+func (s *ScrubResponseTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		if each.post != nil {
+			each.post(each.structure)
+		}
+	}
+}
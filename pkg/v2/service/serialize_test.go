@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSerializedPatchService(t *testing.T) {
+	s := new(SerializedPatchServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type SerializedPatchServiceTestSuite struct {
+	suite.Suite
+}
+
+func (s *SerializedPatchServiceTestSuite) TestConcurrentPatchesAreSerialized() {
+	var (
+		active  int32
+		overlap int32
+	)
+	service := SerializedPatchService(NewResourceLock(), patchFunc(func(_ context.Context, _ *PatchRequest) (*PatchResponse, error) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.StoreInt32(&overlap, 1)
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return &PatchResponse{Patched: true}, nil
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := service.Do(context.Background(), &PatchRequest{ResourceID: "foo"})
+			assert.Nil(s.T(), err)
+			assert.True(s.T(), resp.Patched)
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(s.T(), atomic.LoadInt32(&overlap), "patches to the same resource must not overlap")
+}
+
+func (s *SerializedPatchServiceTestSuite) TestDistinctResourcesPatchInParallel() {
+	var (
+		lock    = NewResourceLock()
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+	service := SerializedPatchService(lock, patchFunc(func(_ context.Context, _ *PatchRequest) (*PatchResponse, error) {
+		entered <- struct{}{}
+		<-release
+		return &PatchResponse{Patched: true}, nil
+	}))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = service.Do(context.Background(), &PatchRequest{ResourceID: "foo"})
+	}()
+	<-entered
+	go func() {
+		defer wg.Done()
+		_, _ = service.Do(context.Background(), &PatchRequest{ResourceID: "bar"})
+	}()
+	<-entered // the patch on the other resource entered while the first still holds its lock
+
+	close(release)
+	wg.Wait()
+}
+
+func (s *SerializedPatchServiceTestSuite) TestDoneContextAbandonsWait() {
+	lock := NewResourceLock()
+	require.Nil(s.T(), lock.Lock(context.Background(), "foo"))
+	defer lock.Unlock("foo")
+
+	service := SerializedPatchService(lock, patchFunc(func(_ context.Context, _ *PatchRequest) (*PatchResponse, error) {
+		s.FailNow("the wrapped service should not be reached")
+		return nil, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := service.Do(ctx, &PatchRequest{ResourceID: "foo"})
+	assert.Equal(s.T(), context.Canceled, err)
+}
+
+func (s *SerializedPatchServiceTestSuite) TestLocksAreReclaimed() {
+	lock := NewResourceLock()
+
+	require.Nil(s.T(), lock.Lock(context.Background(), "foo"))
+	lock.Unlock("foo")
+
+	assert.Empty(s.T(), lock.(*memoryResourceLock).entries)
+}
+
+type patchFunc func(ctx context.Context, req *PatchRequest) (*PatchResponse, error)
+
+func (f patchFunc) Do(ctx context.Context, req *PatchRequest) (*PatchResponse, error) {
+	return f(ctx, req)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/scope"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+)
+
+// UpsertCreateService returns a create service with upsert semantics on externalId. When the payload
+// carries an externalId already assigned to an existing resource, the existing resource is updated in
+// place through the replace service instead of failing the uniqueness check, and the response reports
+// Replaced as true so that callers can respond with 200 instead of 201. This accommodates provisioning
+// pipelines that key resources by externalId and cannot track SCIM ids. Because externalId is scoped
+// to the provisioning client that assigned it, the lookup is restricted to resources provisioned by
+// the client carried on the context (see the scope package), when one is present. Payloads without an
+// externalId, or whose externalId is unclaimed, go through the wrapped create service unchanged.
+func UpsertCreateService(config *scim.Config, resourceType *spec.ResourceType, database db.DB, create Create, replace Replace) Create {
+	return &upsertCreateService{
+		config:       config,
+		resourceType: resourceType,
+		database:     database,
+		create:       create,
+		replace:      replace,
+	}
+}
+
+type upsertCreateService struct {
+	config       *scim.Config
+	resourceType *spec.ResourceType
+	database     db.DB
+	create       Create
+	replace      Replace
+}
+
+func (s *upsertCreateService) Do(ctx context.Context, req *CreateRequest) (resp *CreateResponse, err error) {
+	ctx, span := trace.StartSpan(ctx, "service.upsertCreate")
+	defer func() { span.SetError(err); span.End() }()
+
+	if req == nil || req.PayloadSource == nil {
+		err = fmt.Errorf("%w: no payload for create service", spec.ErrInternal)
+		return
+	}
+
+	raw, err := ioutil.ReadAll(req.PayloadSource)
+	if err != nil {
+		err = fmt.Errorf("%w: failed to read request body", spec.ErrInternal)
+		return
+	}
+
+	externalId, err := s.peekExternalId(raw)
+	if err != nil {
+		return
+	}
+
+	if len(externalId) > 0 {
+		var existing *prop.Resource
+		if existing, err = s.findByExternalId(ctx, externalId); err != nil {
+			return
+		}
+		if existing != nil {
+			var replaceResp *ReplaceResponse
+			replaceResp, err = s.replace.Do(ctx, &ReplaceRequest{
+				ResourceID:    existing.IdOrEmpty(),
+				PayloadSource: bytes.NewReader(raw),
+			})
+			if err != nil {
+				return
+			}
+			resp = &CreateResponse{Replaced: true}
+			if replaceResp.Replaced {
+				resp.Resource = replaceResp.Resource
+			} else {
+				resp.Resource = maskResponse(ctx, s.config, scrubResponse(replaceResp.Ref))
+			}
+			return
+		}
+	}
+
+	return s.create.Do(ctx, &CreateRequest{PayloadSource: bytes.NewReader(raw)})
+}
+
+// peekExternalId deserializes the payload just far enough to read the externalId off it. Malformed
+// payloads fail here with the same error the wrapped create service would have produced.
+func (s *upsertCreateService) peekExternalId(raw []byte) (string, error) {
+	resource := prop.NewResource(s.resourceType)
+	if err := json.Deserialize(raw, resource); err != nil {
+		return "", err
+	}
+
+	nav := resource.Navigator().Dot("externalId")
+	if nav.HasError() || nav.Current().IsUnassigned() {
+		return "", nil
+	}
+
+	externalId, _ := nav.Current().Raw().(string)
+	return externalId, nil
+}
+
+func (s *upsertCreateService) findByExternalId(ctx context.Context, externalId string) (*prop.Resource, error) {
+	filter := fmt.Sprintf("externalId eq %s", strconv.Quote(externalId))
+	if clientId := scope.ClientFromContext(ctx); len(clientId) > 0 {
+		filter = fmt.Sprintf("(%s) and (meta.clientId eq %s)", filter, strconv.Quote(clientId))
+	}
+
+	resources, err := s.database.Query(ctx, filter, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(resources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return resources[0], nil
+	default:
+		return nil, fmt.Errorf("%w: externalId '%s' is ambiguous", spec.ErrConflict, externalId)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/service/filter"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUpsertCreateService(t *testing.T) {
+	s := new(UpsertCreateServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type UpsertCreateServiceTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *UpsertCreateServiceTestSuite) TestDo() {
+	newService := func(t *testing.T) Create {
+		database := db.Memory()
+		created := CreateService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{
+			filter.ByPropertyToByResource(
+				filter.ReadOnlyFilter(),
+				filter.UUIDFilter(),
+				filter.BCryptFilter(),
+			),
+			filter.MetaFilter(),
+			filter.ByPropertyToByResource(filter.ValidationFilter(database)),
+		})
+		replaced := ReplaceService(scim.NewConfig(), s.resourceType, database, []filter.ByResource{
+			filter.ByPropertyToByResource(
+				filter.ReadOnlyFilter(),
+				filter.BCryptFilter(),
+			),
+			filter.ByPropertyToByResource(filter.ValidationFilter(database)),
+			filter.MetaFilter(),
+		})
+		return UpsertCreateService(scim.NewConfig(), s.resourceType, database, created, replaced)
+	}
+	payload := func(externalId string, userName string) *CreateRequest {
+		var externalIdLine string
+		if len(externalId) > 0 {
+			externalIdLine = fmt.Sprintf(`"externalId": "%s",`, externalId)
+		}
+		return &CreateRequest{
+			PayloadSource: strings.NewReader(fmt.Sprintf(`
+{
+  "schemas": [
+    "urn:ietf:params:scim:schemas:core:2.0:User"
+  ],
+  %s
+  "userName": "%s",
+  "emails": [
+    {
+      "value": "%s@bar.com",
+      "primary": true
+    }
+  ]
+}
+`, externalIdLine, userName, userName)),
+		}
+	}
+
+	s.T().Run("unclaimed externalId creates a new resource", func(t *testing.T) {
+		service := newService(t)
+		resp, err := service.Do(context.Background(), payload("hr-1001", "foo"))
+		assert.Nil(t, err)
+		assert.False(t, resp.Replaced)
+		assert.Equal(t, "hr-1001", resp.Resource.Navigator().Dot("externalId").Current().Raw())
+	})
+
+	s.T().Run("existing externalId updates the resource in place", func(t *testing.T) {
+		service := newService(t)
+
+		first, err := service.Do(context.Background(), payload("hr-1001", "foo"))
+		require.Nil(t, err)
+		require.False(t, first.Replaced)
+
+		second, err := service.Do(context.Background(), payload("hr-1001", "bar"))
+		assert.Nil(t, err)
+		assert.True(t, second.Replaced)
+		assert.Equal(t, first.Resource.IdOrEmpty(), second.Resource.IdOrEmpty())
+		assert.Equal(t, "bar", second.Resource.Navigator().Dot("userName").Current().Raw())
+	})
+
+	s.T().Run("identical payload reports replaced without a new version", func(t *testing.T) {
+		service := newService(t)
+
+		first, err := service.Do(context.Background(), payload("hr-1001", "foo"))
+		require.Nil(t, err)
+
+		second, err := service.Do(context.Background(), payload("hr-1001", "foo"))
+		assert.Nil(t, err)
+		assert.True(t, second.Replaced)
+		assert.Equal(t, first.Resource.IdOrEmpty(), second.Resource.IdOrEmpty())
+	})
+
+	s.T().Run("payload without externalId creates normally", func(t *testing.T) {
+		service := newService(t)
+		resp, err := service.Do(context.Background(), payload("", "foo"))
+		assert.Nil(t, err)
+		assert.False(t, resp.Replaced)
+	})
+}
+
+func (s *UpsertCreateServiceTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/user_enterprise_extension_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/user_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/imulab/go-scim/pkg/v2/trace"
+	"strings"
+)
+
+// ValuesService returns a values service, which pages through the elements of a multiValued attribute
+// of a single resource. Very large multiValued attributes, such as the members of a huge Group, are
+// impractical to return in one response; this service lets clients retrieve the elements in pages
+// with the familiar startIndex and count semantics, without transferring the rest of the attribute.
+func ValuesService(config *scim.Config, database db.DB) Values {
+	return &valuesService{database: database, config: config}
+}
+
+type (
+	// Values service to page elements of a multiValued attribute
+	Values interface {
+		Do(ctx context.Context, req *ValuesRequest) (resp *ValuesResponse, err error)
+	}
+	// Values request
+	ValuesRequest struct {
+		ResourceID string           // id of the resource whose attribute is paged
+		Path       string           // dot separated path of the multiValued attribute (i.e. "members")
+		Pagination *crud.Pagination // pagination parameters, nil returns all elements unless a default page size is configured
+	}
+	// Values response
+	ValuesResponse struct {
+		TotalResults int           // total number of elements of the attribute
+		StartIndex   int           // 1-based start index of the returned page
+		ItemsPerPage int           // number of elements returned
+		Values       []interface{} // element values of the returned page
+	}
+)
+
+type valuesService struct {
+	database db.DB
+	config   *scim.Config
+}
+
+func (s *valuesService) Do(ctx context.Context, req *ValuesRequest) (resp *ValuesResponse, err error) {
+	ctx, cancel := s.config.OperationContext(ctx)
+	defer cancel()
+
+	ctx, span := trace.StartSpan(ctx, "service.values")
+	span.SetTag("scim.resourceId", req.ResourceID)
+	span.SetTag("scim.path", req.Path)
+	defer func() { span.SetError(err); span.End() }()
+
+	resource, err := s.database.Get(ctx, req.ResourceID, &crud.Projection{Attributes: []string{req.Path}})
+	if err != nil {
+		return
+	}
+	resource = maskResponse(ctx, s.config, scrubResponse(resource))
+
+	nav := resource.Navigator()
+	for _, step := range strings.Split(req.Path, ".") {
+		if nav.Dot(step).HasError() {
+			err = nav.Error()
+			return
+		}
+	}
+	if !nav.Current().Attribute().MultiValued() {
+		err = fmt.Errorf("%w: '%s' does not target a multiValued attribute", spec.ErrInvalidPath, req.Path)
+		return
+	}
+
+	total := nav.Current().CountChildren()
+
+	pagination := req.Pagination.Normalize(s.config.DefaultPageSize(), s.config.MaxPageSize())
+	if pagination == nil {
+		pagination = &crud.Pagination{StartIndex: 1, Count: total}
+	}
+
+	resp = &ValuesResponse{
+		TotalResults: total,
+		StartIndex:   pagination.StartIndex,
+		Values:       []interface{}{},
+	}
+	if err = nav.ForEachChild(func(index int, child prop.Property) error {
+		ordinal := index + 1
+		if ordinal < pagination.StartIndex || ordinal >= pagination.StartIndex+pagination.Count {
+			return nil
+		}
+		resp.Values = append(resp.Values, child.Raw())
+		return nil
+	}); err != nil {
+		resp = nil
+		return
+	}
+	resp.ItemsPerPage = len(resp.Values)
+
+	return
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/imulab/go-scim/pkg/v2/crud"
+	"github.com/imulab/go-scim/pkg/v2/db"
+	"github.com/imulab/go-scim/pkg/v2/prop"
+	"github.com/imulab/go-scim/pkg/v2/scim"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestValuesService(t *testing.T) {
+	s := new(ValuesServiceTestSuite)
+	suite.Run(t, s)
+}
+
+type ValuesServiceTestSuite struct {
+	suite.Suite
+	resourceType *spec.ResourceType
+}
+
+func (s *ValuesServiceTestSuite) TestDo() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{
+		"id":          "huge",
+		"displayName": "Huge Group",
+		"members": []interface{}{
+			map[string]interface{}{"value": "user001"},
+			map[string]interface{}{"value": "user002"},
+			map[string]interface{}{"value": "user003"},
+			map[string]interface{}{"value": "user004"},
+			map[string]interface{}{"value": "user005"},
+		},
+	})))
+
+	tests := []struct {
+		name       string
+		getRequest func() *ValuesRequest
+		expect     func(t *testing.T, resp *ValuesResponse, err error)
+	}{
+		{
+			name: "first page",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "huge",
+					Path:       "members",
+					Pagination: &crud.Pagination{StartIndex: 1, Count: 2},
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 5, resp.TotalResults)
+				assert.Equal(t, 1, resp.StartIndex)
+				assert.Equal(t, 2, resp.ItemsPerPage)
+				assert.Equal(t, "user001", resp.Values[0].(map[string]interface{})["value"])
+				assert.Equal(t, "user002", resp.Values[1].(map[string]interface{})["value"])
+			},
+		},
+		{
+			name: "last partial page",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "huge",
+					Path:       "members",
+					Pagination: &crud.Pagination{StartIndex: 5, Count: 2},
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 5, resp.TotalResults)
+				assert.Equal(t, 1, resp.ItemsPerPage)
+				assert.Equal(t, "user005", resp.Values[0].(map[string]interface{})["value"])
+			},
+		},
+		{
+			name: "page beyond range is empty",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "huge",
+					Path:       "members",
+					Pagination: &crud.Pagination{StartIndex: 10, Count: 2},
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 5, resp.TotalResults)
+				assert.Equal(t, 0, resp.ItemsPerPage)
+				assert.Empty(t, resp.Values)
+			},
+		},
+		{
+			name: "omitted pagination returns all elements",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "huge",
+					Path:       "members",
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, 5, resp.TotalResults)
+				assert.Equal(t, 5, resp.ItemsPerPage)
+			},
+		},
+		{
+			name: "non multiValued path is rejected",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "huge",
+					Path:       "displayName",
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(err, spec.ErrInvalidPath))
+			},
+		},
+		{
+			name: "non-existing resource",
+			getRequest: func() *ValuesRequest {
+				return &ValuesRequest{
+					ResourceID: "missing",
+					Path:       "members",
+				}
+			},
+			expect: func(t *testing.T, resp *ValuesResponse, err error) {
+				assert.NotNil(t, err)
+				assert.Equal(t, spec.ErrNotFound, errors.Unwrap(err))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resp, err := ValuesService(scim.NewConfig(), database).Do(context.Background(), test.getRequest())
+			test.expect(t, resp, err)
+		})
+	}
+}
+
+func (s *ValuesServiceTestSuite) TestDoWithConfiguredPageSize() {
+	database := db.Memory()
+	require.Nil(s.T(), database.Insert(context.TODO(), s.resourceOf(s.T(), map[string]interface{}{
+		"id": "huge",
+		"members": []interface{}{
+			map[string]interface{}{"value": "user001"},
+			map[string]interface{}{"value": "user002"},
+			map[string]interface{}{"value": "user003"},
+		},
+	})))
+
+	config := scim.NewConfig(scim.WithPageSize(2, 2))
+	resp, err := ValuesService(config, database).Do(context.Background(), &ValuesRequest{
+		ResourceID: "huge",
+		Path:       "members",
+	})
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 3, resp.TotalResults)
+	assert.Equal(s.T(), 1, resp.StartIndex)
+	assert.Equal(s.T(), 2, resp.ItemsPerPage)
+}
+
+func (s *ValuesServiceTestSuite) resourceOf(t *testing.T, data interface{}) *prop.Resource {
+	r := prop.NewResource(s.resourceType)
+	require.Nil(t, r.Navigator().Replace(data).Error())
+	return r
+}
+
+func (s *ValuesServiceTestSuite) SetupSuite() {
+	for _, each := range []struct {
+		filepath  string
+		structure interface{}
+		post      func(parsed interface{})
+	}{
+		{
+			filepath:  "../../../public/schemas/core_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/schemas/group_schema.json",
+			structure: new(spec.Schema),
+			post: func(parsed interface{}) {
+				spec.Schemas().Register(parsed.(*spec.Schema))
+			},
+		},
+		{
+			filepath:  "../../../public/resource_types/group_resource_type.json",
+			structure: new(spec.ResourceType),
+			post: func(parsed interface{}) {
+				s.resourceType = parsed.(*spec.ResourceType)
+				crud.Register(s.resourceType)
+			},
+		},
+	} {
+		f, err := os.Open(each.filepath)
+		require.Nil(s.T(), err)
+
+		raw, err := ioutil.ReadAll(f)
+		require.Nil(s.T(), err)
+
+		err = json.Unmarshal(raw, each.structure)
+		require.Nil(s.T(), err)
+
+		each.post(each.structure)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/imulab/go-scim/pkg/v2/spec/internal"
 	"reflect"
 	"sort"
-	"strings"
 )
 
 // Attribute models a superset of defined SCIM attributes. It serves as the basic unit that describes data requirement
@@ -76,6 +75,7 @@ type Attribute struct {
 	index           int                               // relative index in ascending order
 	path            string                            // SCIM path name from the root attribute
 	annotations     map[string]map[string]interface{} // annotations that provide additional processing hint
+	vendorFields    map[string]interface{}            // non-standard fields preserved from the schema JSON
 }
 
 // ID returns the id of the attribute that globally identifies the attribute.
@@ -218,8 +218,8 @@ func (attr *Attribute) CountSubAttributes() int {
 
 // GoesBy returns true if this attribute can be addressed by the given name.
 func (attr *Attribute) GoesBy(name string) bool {
-	switch strings.ToLower(name) {
-	case strings.ToLower(attr.id), strings.ToLower(attr.path), strings.ToLower(attr.name):
+	switch FoldAttributeName(name) {
+	case FoldAttributeName(attr.id), FoldAttributeName(attr.path), FoldAttributeName(attr.name):
 		return true
 	default:
 		return false
@@ -235,12 +235,25 @@ func (attr *Attribute) DFS(callback func(attr *Attribute)) {
 }
 
 // Annotation returns the annotation parameters by the given name (case sensitive) and a boolean indicating whether
-// this annotations exists.
+// this annotations exists. Annotations are declared in the schema JSON under "_annotations" and, by convention, are
+// named with a leading "@". Apart from the internally recognized annotations (see the annotation package), users are
+// free to declare their own annotations and drive custom filters off them.
 func (attr *Attribute) Annotation(name string) (params map[string]interface{}, ok bool) {
 	params, ok = attr.annotations[name]
 	return
 }
 
+// HasAnnotation returns true if the annotation with the given name (case sensitive) exists on this attribute.
+func (attr *Attribute) HasAnnotation(name string) bool {
+	_, ok := attr.annotations[name]
+	return ok
+}
+
+// CountAnnotations returns the total number of annotations on this attribute.
+func (attr *Attribute) CountAnnotations() int {
+	return len(attr.annotations)
+}
+
 // ForEachAnnotation iterates through annotations and invoke callback.
 func (attr *Attribute) ForEachAnnotation(callback func(annotation string, params map[string]interface{})) {
 	for k, v := range attr.annotations {
@@ -248,6 +261,34 @@ func (attr *Attribute) ForEachAnnotation(callback func(annotation string, params
 	}
 }
 
+// VendorField returns the value of the vendor extension field by the given name (case sensitive) and a boolean
+// indicating whether this field exists. Vendor extension fields are fields in the schema JSON that are not defined
+// by the specification and not recognized as one of the internal extensions (i.e. "_annotations"). They are
+// preserved as is through parsing and serialization so that custom metadata declared on attributes survives
+// schema discovery.
+func (attr *Attribute) VendorField(name string) (value interface{}, ok bool) {
+	value, ok = attr.vendorFields[name]
+	return
+}
+
+// HasVendorField returns true if the vendor extension field with the given name (case sensitive) exists on this attribute.
+func (attr *Attribute) HasVendorField(name string) bool {
+	_, ok := attr.vendorFields[name]
+	return ok
+}
+
+// CountVendorFields returns the total number of vendor extension fields on this attribute.
+func (attr *Attribute) CountVendorFields() int {
+	return len(attr.vendorFields)
+}
+
+// ForEachVendorField iterates through vendor extension fields and invoke callback.
+func (attr *Attribute) ForEachVendorField(callback func(name string, value interface{})) {
+	for k, v := range attr.vendorFields {
+		callback(k, v)
+	}
+}
+
 // IsElementAttributeOf returns true if this attribute is the derived element attribute of the other attribute.
 func (attr *Attribute) IsElementAttributeOf(other *Attribute) bool {
 	return fmt.Sprintf("%s%s", other.ID(), elemSuffix) == attr.ID()
@@ -305,6 +346,7 @@ func (attr *Attribute) DeriveElementAttribute() *Attribute {
 		index:           attr.index,
 		path:            attr.path,
 		annotations:     map[string]map[string]interface{}{},
+		vendorFields:    attr.vendorFields,
 	}
 
 	if param, ok := attr.Annotation(annotation.ElementAnnotations); ok {
@@ -358,7 +400,7 @@ func (attr *Attribute) MarshalJSON() ([]byte, error) {
 		SubAttributes: []*internal.AttributeMarshaler{},
 	}
 	attr.convertToMarshaler(&m)
-	return json.Marshal(m)
+	return json.Marshal(&m)
 }
 
 func (attr *Attribute) convertToMarshaler(m *internal.AttributeMarshaler) {
@@ -373,6 +415,12 @@ func (attr *Attribute) convertToMarshaler(m *internal.AttributeMarshaler) {
 	m.Returned = attr.returned.String()
 	m.Uniqueness = attr.uniqueness.String()
 	m.ReferenceTypes = attr.referenceTypes
+	if len(attr.annotations) > 0 {
+		m.Annotations = attr.annotations
+	}
+	if len(attr.vendorFields) > 0 {
+		m.VendorFields = attr.vendorFields
+	}
 
 	for _, subAttr := range attr.subAttributes {
 		sm := internal.AttributeMarshaler{
@@ -411,6 +459,7 @@ func (attr *Attribute) convertFromUnmarshaler(um *internal.AttributeUnmarshaler)
 	attr.index = um.Index
 	attr.path = um.Path
 	attr.annotations = um.Annotations
+	attr.vendorFields = um.VendorFields
 	attr.subAttributes = []*Attribute{}
 
 	for _, subum := range um.SubAttributes {
@@ -80,7 +80,11 @@ func (s *AttributeTestSuite) TestMarshal() {
   "caseExact": false,
   "mutability": "readWrite",
   "returned": "default",
-  "uniqueness": "none"
+  "uniqueness": "none",
+  "_annotations": {
+    "@AutoCompact": {},
+    "@ExclusivePrimary": {}
+  }
 }
 `
 	assert.JSONEq(s.T(), expect, string(raw))
@@ -250,3 +254,55 @@ func (s *AttributeTestSuite) TestDeriveElementAttribute() {
 		assert.True(s.T(), ok)
 	}
 }
+
+func (s *AttributeTestSuite) TestVendorFieldsRoundTrip() {
+	raw := `
+{
+  "id": "urn:ietf:params:scim:schemas:core:2.0:User:userName",
+  "name": "userName",
+  "type": "string",
+  "multiValued": false,
+  "required": true,
+  "caseExact": false,
+  "mutability": "readWrite",
+  "returned": "default",
+  "uniqueness": "server",
+  "_path": "userName",
+  "_index": 100,
+  "x-origin": "hr-system",
+  "urn:vendor:params:scim:sensitivity": {
+    "level": "high"
+  }
+}
+`
+
+	attr := new(Attribute)
+	require.Nil(s.T(), json.Unmarshal([]byte(raw), attr))
+
+	assert.Equal(s.T(), 2, attr.CountVendorFields())
+	assert.True(s.T(), attr.HasVendorField("x-origin"))
+	origin, ok := attr.VendorField("x-origin")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), "hr-system", origin)
+
+	marshaled, err := json.Marshal(attr)
+	assert.Nil(s.T(), err)
+
+	expect := `
+{
+  "name": "userName",
+  "type": "string",
+  "multiValued": false,
+  "required": true,
+  "caseExact": false,
+  "mutability": "readWrite",
+  "returned": "default",
+  "uniqueness": "server",
+  "x-origin": "hr-system",
+  "urn:vendor:params:scim:sensitivity": {
+    "level": "high"
+  }
+}
+`
+	assert.JSONEq(s.T(), expect, string(marshaled))
+}
@@ -6,6 +6,9 @@ type ServiceProviderConfig struct {
 	DocURI  string   `json:"documentationUri"`
 	Patch   struct {
 		Supported bool `json:"supported"`
+		// NoContent is a non-standard extension of RFC 7643. When true, a successful patch responds with
+		// 204 No Content instead of 200 with the updated resource, as permitted by RFC 7644 Section 3.5.2.
+		NoContent bool `json:"noContent"`
 	} `json:"patch"`
 	Bulk struct {
 		Supported  bool `json:"supported"`
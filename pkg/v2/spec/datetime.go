@@ -0,0 +1,60 @@
+package spec
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateTimeLayouts are the accepted dateTime layouts, tried in order. SCIM clients send xsd:dateTime
+// values in several shapes: the plain ISO8601 layout this project renders, RFC 3339 with a 'Z'
+// suffix or a numeric offset, and either of them with fractional seconds.
+var dateTimeLayouts = []string{
+	ISO8601,
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// DateTime is a SCIM dateTime value normalized to a canonical instant. Comparing and sorting
+// through DateTime operates on the instant, so that values of mixed precision or offsets order
+// correctly, which a raw string comparison does not guarantee.
+type DateTime struct {
+	t time.Time
+}
+
+// ParseDateTime parses the value against the accepted dateTime layouts and returns the normalized
+// DateTime, or an error wrapping ErrInvalidValue when no layout matches. The instant is normalized
+// to UTC, so that values carrying an offset render canonically.
+func ParseDateTime(value string) (DateTime, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateTime{t: t.UTC()}, nil
+		}
+	}
+	return DateTime{}, fmt.Errorf("%w: '%s' does not conform to any accepted dateTime layout", ErrInvalidValue, value)
+}
+
+// Time returns the instant of the dateTime.
+func (d DateTime) Time() time.Time {
+	return d.t
+}
+
+// Equal returns true if the two dateTime values denote the same instant.
+func (d DateTime) Equal(other DateTime) bool {
+	return d.t.Equal(other.t)
+}
+
+// Before returns true if this dateTime denotes an earlier instant than the other.
+func (d DateTime) Before(other DateTime) bool {
+	return d.t.Before(other.t)
+}
+
+// After returns true if this dateTime denotes a later instant than the other.
+func (d DateTime) After(other DateTime) bool {
+	return d.t.After(other.t)
+}
+
+// String renders the dateTime in the canonical ISO8601 layout.
+func (d DateTime) String() string {
+	return d.t.Format(ISO8601)
+}
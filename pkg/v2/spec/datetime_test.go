@@ -0,0 +1,97 @@
+package spec
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+func TestDateTime(t *testing.T) {
+	s := new(DateTimeTestSuite)
+	suite.Run(t, s)
+}
+
+type DateTimeTestSuite struct {
+	suite.Suite
+}
+
+func (s *DateTimeTestSuite) TestParse() {
+	tests := []struct {
+		name   string
+		value  string
+		expect func(t *testing.T, dt DateTime, err error)
+	}{
+		{
+			name:  "plain ISO8601",
+			value: "2019-01-01T10:00:00",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC), dt.Time())
+			},
+		},
+		{
+			name:  "RFC3339 with Z suffix",
+			value: "2019-01-01T10:00:00Z",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC), dt.Time())
+			},
+		},
+		{
+			name:  "offset is normalized to UTC",
+			value: "2019-01-01T12:00:00+02:00",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, "2019-01-01T10:00:00", dt.String())
+			},
+		},
+		{
+			name:  "fractional seconds with offset",
+			value: "2019-01-01T10:00:00.500-05:00",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, time.Date(2019, 1, 1, 15, 0, 0, 500000000, time.UTC), dt.Time())
+			},
+		},
+		{
+			name:  "fractional seconds without offset",
+			value: "2019-01-01T10:00:00.123",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, time.Date(2019, 1, 1, 10, 0, 0, 123000000, time.UTC), dt.Time())
+			},
+		},
+		{
+			name:  "unrecognized value",
+			value: "January 1st, 2019",
+			expect: func(t *testing.T, dt DateTime, err error) {
+				assert.True(t, errors.Is(err, ErrInvalidValue))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			dt, err := ParseDateTime(test.value)
+			test.expect(t, dt, err)
+		})
+	}
+}
+
+func (s *DateTimeTestSuite) TestCompare() {
+	// the same instant expressed with different precision and offsets
+	a, err := ParseDateTime("2019-01-01T10:00:00Z")
+	require.Nil(s.T(), err)
+	b, err := ParseDateTime("2019-01-01T12:00:00.000+02:00")
+	require.Nil(s.T(), err)
+	c, err := ParseDateTime("2019-01-01T10:00:01")
+	require.Nil(s.T(), err)
+
+	assert.True(s.T(), a.Equal(b))
+	assert.True(s.T(), a.Before(c))
+	assert.True(s.T(), c.After(b))
+	assert.False(s.T(), a.After(b))
+}
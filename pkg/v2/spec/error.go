@@ -1,5 +1,10 @@
 package spec
 
+import (
+	"fmt"
+	"sync"
+)
+
 // Error prototypes
 var (
 	// The specified filter syntax was invalid, or the specified attribute and filter comparison combination is not supported.
@@ -37,6 +42,17 @@ var (
 	// The resource is in conflict with some pre conditions.
 	ErrConflict = &Error{Status: 412, Type: "conflict"}
 
+	// The client has issued too many requests in a given amount of time.
+	ErrTooManyRequests = &Error{Status: 429, Type: "tooManyRequests"}
+
+	// The request body exceeds the maximum size the server is willing to process. The scimType is not
+	// among the types defined in RFC 7644, but is served in the standard error message structure.
+	ErrPayloadTooLarge = &Error{Status: 413, Type: "payloadTooLarge"}
+
+	// The operation would exceed a resource quota configured on the server. The scimType is not among the
+	// types defined in RFC 7644, but is served in the standard error message structure.
+	ErrQuotaExceeded = &Error{Status: 403, Type: "quotaExceeded"}
+
 	// Server encountered internal error.
 	ErrInternal = &Error{Status: 500, Type: "internal"}
 )
@@ -44,7 +60,8 @@ var (
 // A SCIM error message.
 // The structure is left completely open for convenience, but it is not recommended to create Error directly.
 // To create an error, use the error prototypes (i.e. ErrInvalidFilter). If needed, wrap the error prototype
-// by fmt.Errorf("additional detail: %w", err).
+// by fmt.Errorf("additional detail: %w", err), or use the helper constructors (i.e. InvalidValue) to attach
+// structured fields (see ErrorDetail).
 type Error struct {
 	Status int
 	Type   string
@@ -54,6 +71,162 @@ func (s Error) Error() string {
 	return s.Type
 }
 
+// ErrorDetail is a structured error based on one of the error prototypes. In addition to the scimType
+// and status carried by the prototype, it records the attribute path the error relates to and a human
+// readable detail, so that integrators can extract these fields through errors.As instead of matching
+// on the error message. ErrorDetail unwraps to its prototype, hence
+//	errors.Is(err, spec.ErrInvalidValue)
+// continues to work for errors created through the helper constructors.
+type ErrorDetail struct {
+	prototype *Error
+	Path      string // path of the attribute the error relates to, or empty when not applicable
+	Detail    string // human readable detail
+}
+
+// Status returns the HTTP status code of the underlying error prototype.
+func (e *ErrorDetail) Status() int {
+	return e.prototype.Status
+}
+
+// ScimType returns the SCIM error type of the underlying error prototype.
+func (e *ErrorDetail) ScimType() string {
+	return e.prototype.Type
+}
+
+func (e *ErrorDetail) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("%s: '%s': %s", e.prototype.Type, e.Path, e.Detail)
+	}
+	return fmt.Sprintf("%s: %s", e.prototype.Type, e.Detail)
+}
+
+func (e *ErrorDetail) Unwrap() error {
+	return e.prototype
+}
+
+// NewError returns a structured error based on the given error prototype. path relates the error to an
+// attribute and may be left empty when the error is not about any particular attribute. The prototype
+// is normally one of the catalog entries in this package, but may also be a custom prototype that was
+// added to the catalog through Errors().Register.
+func NewError(prototype *Error, path string, detail string) error {
+	return &ErrorDetail{prototype: prototype, Path: path, Detail: detail}
+}
+
+// InvalidValue returns a structured error based on ErrInvalidValue.
+func InvalidValue(path string, detail string) error {
+	return NewError(ErrInvalidValue, path, detail)
+}
+
+// InvalidPath returns a structured error based on ErrInvalidPath.
+func InvalidPath(path string, detail string) error {
+	return NewError(ErrInvalidPath, path, detail)
+}
+
+// InvalidFilter returns a structured error based on ErrInvalidFilter.
+func InvalidFilter(path string, detail string) error {
+	return NewError(ErrInvalidFilter, path, detail)
+}
+
+// InvalidSyntax returns a structured error based on ErrInvalidSyntax.
+func InvalidSyntax(path string, detail string) error {
+	return NewError(ErrInvalidSyntax, path, detail)
+}
+
+// NoTarget returns a structured error based on ErrNoTarget.
+func NoTarget(path string, detail string) error {
+	return NewError(ErrNoTarget, path, detail)
+}
+
+// MutabilityViolation returns a structured error based on ErrMutability. The name deviates from the
+// other constructors because Mutability is taken by the attribute mutability type.
+func MutabilityViolation(path string, detail string) error {
+	return NewError(ErrMutability, path, detail)
+}
+
+// UniquenessViolation returns a structured error based on ErrUniqueness. The name deviates from the
+// other constructors because Uniqueness is taken by the attribute uniqueness type.
+func UniquenessViolation(path string, detail string) error {
+	return NewError(ErrUniqueness, path, detail)
+}
+
+// NotFound returns a structured error based on ErrNotFound.
+func NotFound(path string, detail string) error {
+	return NewError(ErrNotFound, path, detail)
+}
+
+// Conflict returns a structured error based on ErrConflict.
+func Conflict(path string, detail string) error {
+	return NewError(ErrConflict, path, detail)
+}
+
+// QuotaExceeded returns a structured error based on ErrQuotaExceeded.
+func QuotaExceeded(path string, detail string) error {
+	return NewError(ErrQuotaExceeded, path, detail)
+}
+
+// Internal returns a structured error based on ErrInternal.
+func Internal(path string, detail string) error {
+	return NewError(ErrInternal, path, detail)
+}
+
+var (
+	errorReg     *errorRegistry
+	errorRegOnce sync.Once
+)
+
+type errorRegistry struct {
+	db map[string]*Error
+}
+
+// Register relates the error prototype with its scimType in the catalog, making it resolvable through
+// Get. This method does not check existence of the scimType and may overwrite existing entries if abused.
+func (r *errorRegistry) Register(prototype *Error) {
+	r.db[prototype.Type] = prototype
+}
+
+// Get returns the error prototype that is related to a scimType, or nil, along with a boolean
+// indicating if the prototype exists.
+func (r *errorRegistry) Get(scimType string) (prototype *Error, ok bool) {
+	prototype, ok = r.db[scimType]
+	return
+}
+
+// ForEachError iterates the catalog and invokes the callback on each error prototype.
+func (r *errorRegistry) ForEachError(callback func(prototype *Error)) {
+	for _, prototype := range r.db {
+		callback(prototype)
+	}
+}
+
+// Errors returns the catalog of error prototypes keyed by their scimType, pre-populated with the
+// prototypes defined in this package. Integrators may register additional prototypes to extend it.
+func Errors() *errorRegistry {
+	errorRegOnce.Do(func() {
+		errorReg = &errorRegistry{db: map[string]*Error{}}
+		for _, prototype := range []*Error{
+			ErrInvalidFilter,
+			ErrTooMany,
+			ErrUniqueness,
+			ErrMutability,
+			ErrInvalidSyntax,
+			ErrInvalidPath,
+			ErrNoTarget,
+			ErrInvalidValue,
+			ErrNotFound,
+			ErrSensitive,
+			ErrConflict,
+			ErrTooManyRequests,
+			ErrPayloadTooLarge,
+			ErrQuotaExceeded,
+			ErrInternal,
+		} {
+			errorReg.Register(prototype)
+		}
+	})
+	return errorReg
+}
+
 var (
 	_ error = (*Error)(nil)
+	_ error = (*ErrorDetail)(nil)
 )
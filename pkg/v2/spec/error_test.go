@@ -0,0 +1,84 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestError(t *testing.T) {
+	s := new(ErrorTestSuite)
+	suite.Run(t, s)
+}
+
+type ErrorTestSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorTestSuite) TestConstructors() {
+	tests := []struct {
+		name      string
+		err       error
+		prototype *Error
+		message   string
+	}{
+		{
+			name:      "invalid value with path",
+			err:       InvalidValue("userName", "value is required"),
+			prototype: ErrInvalidValue,
+			message:   "invalidValue: 'userName': value is required",
+		},
+		{
+			name:      "invalid path",
+			err:       InvalidPath("foobar", "no such attribute"),
+			prototype: ErrInvalidPath,
+			message:   "invalidPath: 'foobar': no such attribute",
+		},
+		{
+			name:      "not found without path",
+			err:       NotFound("", "resource not found"),
+			prototype: ErrNotFound,
+			message:   "notFound: resource not found",
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			assert.True(t, errors.Is(test.err, test.prototype))
+			assert.Equal(t, test.message, test.err.Error())
+
+			var prototype *Error
+			assert.True(t, errors.As(test.err, &prototype))
+			assert.Equal(t, test.prototype.Status, prototype.Status)
+			assert.Equal(t, test.prototype.Type, prototype.Type)
+		})
+	}
+}
+
+func (s *ErrorTestSuite) TestStructuredFields() {
+	err := fmt.Errorf("outer context: %w", InvalidValue("emails.value", "not an email"))
+
+	var detail *ErrorDetail
+	assert.True(s.T(), errors.As(err, &detail))
+	assert.Equal(s.T(), "emails.value", detail.Path)
+	assert.Equal(s.T(), "not an email", detail.Detail)
+	assert.Equal(s.T(), 400, detail.Status())
+	assert.Equal(s.T(), "invalidValue", detail.ScimType())
+	assert.True(s.T(), errors.Is(err, ErrInvalidValue))
+}
+
+func (s *ErrorTestSuite) TestCatalog() {
+	prototype, ok := Errors().Get("invalidValue")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), ErrInvalidValue, prototype)
+
+	custom := &Error{Status: 400, Type: "testCustom"}
+	Errors().Register(custom)
+	got, ok := Errors().Get("testCustom")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), custom, got)
+	assert.True(s.T(), errors.Is(NewError(custom, "", "boom"), custom))
+}
@@ -1,19 +1,47 @@
 package internal
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // adapter to marshal the attribute
 type AttributeMarshaler struct {
-	Name            string                `json:"name"`
-	Description     string                `json:"description,omitempty"`
-	Type            string                `json:"type"`
-	SubAttributes   []*AttributeMarshaler `json:"subAttributes,omitempty"`
-	CanonicalValues []string              `json:"canonicalValues,omitempty"`
-	MultiValued     bool                  `json:"multiValued"`
-	Required        bool                  `json:"required"`
-	CaseExact       bool                  `json:"caseExact"`
-	Mutability      string                `json:"mutability"`
-	Returned        string                `json:"returned"`
-	Uniqueness      string                `json:"uniqueness"`
-	ReferenceTypes  []string              `json:"referenceTypes,omitempty"`
+	Name            string                            `json:"name"`
+	Description     string                            `json:"description,omitempty"`
+	Type            string                            `json:"type"`
+	SubAttributes   []*AttributeMarshaler             `json:"subAttributes,omitempty"`
+	CanonicalValues []string                          `json:"canonicalValues,omitempty"`
+	MultiValued     bool                              `json:"multiValued"`
+	Required        bool                              `json:"required"`
+	CaseExact       bool                              `json:"caseExact"`
+	Mutability      string                            `json:"mutability"`
+	Returned        string                            `json:"returned"`
+	Uniqueness      string                            `json:"uniqueness"`
+	ReferenceTypes  []string                          `json:"referenceTypes,omitempty"`
+	Annotations     map[string]map[string]interface{} `json:"_annotations,omitempty"`
+	VendorFields    map[string]interface{}            `json:"-"`
+}
+
+func (m *AttributeMarshaler) MarshalJSON() ([]byte, error) {
+	type alias AttributeMarshaler
+	raw, err := json.Marshal((*alias)(m))
+	if err != nil || len(m.VendorFields) == 0 {
+		return raw, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range m.VendorFields {
+		vendorRaw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = vendorRaw
+	}
+	return json.Marshal(fields)
 }
 
 // adapter to unmarshal the attribute
@@ -34,4 +62,47 @@ type AttributeUnmarshaler struct {
 	Index           int                               `json:"_index"`
 	Path            string                            `json:"_path"`
 	Annotations     map[string]map[string]interface{} `json:"_annotations"`
+	VendorFields    map[string]interface{}            `json:"-"`
+}
+
+func (um *AttributeUnmarshaler) UnmarshalJSON(raw []byte) error {
+	type alias AttributeUnmarshaler
+	if err := json.Unmarshal(raw, (*alias)(um)); err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	for name := range fields {
+		if _, ok := attributeKnownFields[strings.ToLower(name)]; ok {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		um.VendorFields = fields
+	}
+	return nil
+}
+
+// attributeKnownFields indexes the lowercased JSON names of all defined attribute fields; any other
+// field encountered while unmarshalling an attribute is retained as a vendor extension field.
+var attributeKnownFields = map[string]struct{}{
+	"id":              {},
+	"name":            {},
+	"description":     {},
+	"type":            {},
+	"subattributes":   {},
+	"canonicalvalues": {},
+	"multivalued":     {},
+	"required":        {},
+	"caseexact":       {},
+	"mutability":      {},
+	"returned":        {},
+	"uniqueness":      {},
+	"referencetypes":  {},
+	"_index":          {},
+	"_path":           {},
+	"_annotations":    {},
 }
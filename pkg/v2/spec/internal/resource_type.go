@@ -1,15 +1,25 @@
 package internal
 
 type ResourceTypeJsonAdapter struct {
-	ID          string             `json:"id"`
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Endpoint    string             `json:"endpoint"`
-	Schema      string             `json:"schema"`
-	Extensions  []*SchemaExtension `json:"schemaExtensions,omitempty"`
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Endpoint    string               `json:"endpoint"`
+	Schema      string               `json:"schema"`
+	Extensions  []*SchemaExtension   `json:"schemaExtensions,omitempty"`
+	Overrides   []*AttributeOverride `json:"attributeOverrides,omitempty"`
 }
 
 type SchemaExtension struct {
 	Schema   string `json:"schema"`
 	Required bool   `json:"required"`
 }
+
+type AttributeOverride struct {
+	Path       string  `json:"path"`
+	Required   *bool   `json:"required,omitempty"`
+	CaseExact  *bool   `json:"caseExact,omitempty"`
+	Mutability *string `json:"mutability,omitempty"`
+	Returned   *string `json:"returned,omitempty"`
+	Uniqueness *string `json:"uniqueness,omitempty"`
+}
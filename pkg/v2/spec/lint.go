@@ -0,0 +1,119 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic describes a single problem detected in a schema definition. Rule identifies the
+// violated rule and is stable across releases; Path locates the offending attribute, or is empty
+// when the problem concerns the schema as a whole.
+type Diagnostic struct {
+	// Rule is the identifier of the violated rule.
+	Rule string
+	// Path is the path of the offending attribute.
+	Path string
+	// Detail is the human-readable description of the problem.
+	Detail string
+}
+
+func (d Diagnostic) String() string {
+	if len(d.Path) == 0 {
+		return fmt.Sprintf("[%s] %s", d.Rule, d.Detail)
+	}
+	return fmt.Sprintf("[%s] %s: %s", d.Rule, d.Path, d.Detail)
+}
+
+// Rules reported by Lint.
+const (
+	// RuleDuplicateName reports attributes sharing a name, compared case insensitively, within the
+	// same scope. Attribute names are matched case insensitively throughout, hence duplicates make
+	// one of the attributes unaddressable.
+	RuleDuplicateName = "duplicate-name"
+	// RuleSubAttributesOnSimpleType reports sub-attributes declared on a non-complex attribute,
+	// where they are silently ignored.
+	RuleSubAttributesOnSimpleType = "sub-attributes-on-simple-type"
+	// RuleWriteOnlyReturned reports writeOnly attributes that are not declared returned=never. A
+	// writeOnly value can never be rendered, so any other returned setting is contradictory.
+	RuleWriteOnlyReturned = "write-only-returned"
+	// RuleMissingCanonicalSubAttributes reports multi-valued complex attributes missing any of the
+	// conventional "value", "type" and "primary" sub-attributes, which the specification and much of
+	// the surrounding machinery, such as primary exclusivity and element matching, expect.
+	RuleMissingCanonicalSubAttributes = "missing-canonical-sub-attributes"
+)
+
+// Lint checks the schema definition for common problems and returns a diagnostic for each one
+// detected. An empty result means the schema is free of the problems Lint looks for. The rules
+// applied are documented on the Rule constants.
+func Lint(schema *Schema) []Diagnostic {
+	var diagnostics []Diagnostic
+	var attributes []*Attribute
+	_ = schema.ForEachAttribute(func(attr *Attribute) error {
+		attributes = append(attributes, attr)
+		return nil
+	})
+	lintScope(attributes, &diagnostics)
+	return diagnostics
+}
+
+// lintScope lints the attributes sharing a scope, i.e. the top level attributes of a schema, or the
+// sub-attributes of a complex attribute, and recurses into each attribute's sub-attributes.
+func lintScope(attributes []*Attribute, diagnostics *[]Diagnostic) {
+	seen := map[string]string{}
+	for _, attr := range attributes {
+		name := FoldAttributeName(attr.Name())
+		if first, ok := seen[name]; ok {
+			*diagnostics = append(*diagnostics, Diagnostic{
+				Rule:   RuleDuplicateName,
+				Path:   attr.Path(),
+				Detail: fmt.Sprintf("name '%s' duplicates that of '%s'", attr.Name(), first),
+			})
+		} else {
+			seen[name] = attr.Path()
+		}
+		lintAttribute(attr, diagnostics)
+	}
+}
+
+func lintAttribute(attr *Attribute, diagnostics *[]Diagnostic) {
+	if attr.Type() != TypeComplex && attr.CountSubAttributes() > 0 {
+		*diagnostics = append(*diagnostics, Diagnostic{
+			Rule:   RuleSubAttributesOnSimpleType,
+			Path:   attr.Path(),
+			Detail: fmt.Sprintf("sub-attributes declared on attribute of type '%s'", attr.Type().String()),
+		})
+	}
+
+	if attr.Mutability() == MutabilityWriteOnly && attr.Returned() != ReturnedNever {
+		*diagnostics = append(*diagnostics, Diagnostic{
+			Rule:   RuleWriteOnlyReturned,
+			Path:   attr.Path(),
+			Detail: fmt.Sprintf("writeOnly attribute is declared returned=%s instead of returned=never", attr.Returned().String()),
+		})
+	}
+
+	if attr.MultiValued() && attr.Type() == TypeComplex {
+		var missing []string
+		for _, name := range []string{"value", "type", "primary"} {
+			if attr.SubAttributeForName(name) == nil {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			*diagnostics = append(*diagnostics, Diagnostic{
+				Rule:   RuleMissingCanonicalSubAttributes,
+				Path:   attr.Path(),
+				Detail: fmt.Sprintf("multi-valued complex attribute is missing sub-attributes: %s", strings.Join(missing, ", ")),
+			})
+		}
+	}
+
+	if attr.Type() == TypeComplex {
+		var subAttributes []*Attribute
+		_ = attr.ForEachSubAttribute(func(subAttribute *Attribute) error {
+			subAttributes = append(subAttributes, subAttribute)
+			return nil
+		})
+		lintScope(subAttributes, diagnostics)
+	}
+}
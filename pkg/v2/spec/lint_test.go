@@ -0,0 +1,217 @@
+package spec
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	s := new(LintTestSuite)
+	suite.Run(t, s)
+}
+
+type LintTestSuite struct {
+	suite.Suite
+}
+
+func (s *LintTestSuite) TestLint() {
+	tests := []struct {
+		name       string
+		schemaJson string
+		expect     func(t *testing.T, diagnostics []Diagnostic)
+	}{
+		{
+			name: "clean schema yields no diagnostics",
+			schemaJson: `
+{
+  "id": "urn:test:Clean",
+  "name": "Clean",
+  "attributes": [
+    {
+      "id": "urn:test:Clean:userName",
+      "name": "userName",
+      "type": "string",
+      "_index": 0,
+      "_path": "userName"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				assert.Len(t, diagnostics, 0)
+			},
+		},
+		{
+			name: "duplicate names are detected case insensitively",
+			schemaJson: `
+{
+  "id": "urn:test:Dup",
+  "name": "Dup",
+  "attributes": [
+    {
+      "id": "urn:test:Dup:userName",
+      "name": "userName",
+      "type": "string",
+      "_index": 0,
+      "_path": "userName"
+    },
+    {
+      "id": "urn:test:Dup:username2",
+      "name": "USERNAME",
+      "type": "string",
+      "_index": 1,
+      "_path": "USERNAME"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				require.Len(t, diagnostics, 1)
+				assert.Equal(t, RuleDuplicateName, diagnostics[0].Rule)
+				assert.Equal(t, "USERNAME", diagnostics[0].Path)
+			},
+		},
+		{
+			name: "sub-attributes on a non-complex type are detected",
+			schemaJson: `
+{
+  "id": "urn:test:Sub",
+  "name": "Sub",
+  "attributes": [
+    {
+      "id": "urn:test:Sub:title",
+      "name": "title",
+      "type": "string",
+      "_index": 0,
+      "_path": "title",
+      "subAttributes": [
+        {
+          "id": "urn:test:Sub:title.value",
+          "name": "value",
+          "type": "string",
+          "_index": 0,
+          "_path": "title.value"
+        }
+      ]
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				require.Len(t, diagnostics, 1)
+				assert.Equal(t, RuleSubAttributesOnSimpleType, diagnostics[0].Rule)
+				assert.Equal(t, "title", diagnostics[0].Path)
+			},
+		},
+		{
+			name: "writeOnly attribute not returned=never is detected",
+			schemaJson: `
+{
+  "id": "urn:test:WriteOnly",
+  "name": "WriteOnly",
+  "attributes": [
+    {
+      "id": "urn:test:WriteOnly:password",
+      "name": "password",
+      "type": "string",
+      "mutability": "writeOnly",
+      "_index": 0,
+      "_path": "password"
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				require.Len(t, diagnostics, 1)
+				assert.Equal(t, RuleWriteOnlyReturned, diagnostics[0].Rule)
+				assert.Equal(t, "password", diagnostics[0].Path)
+			},
+		},
+		{
+			name: "multi-valued complex missing canonical sub-attributes is detected",
+			schemaJson: `
+{
+  "id": "urn:test:Multi",
+  "name": "Multi",
+  "attributes": [
+    {
+      "id": "urn:test:Multi:emails",
+      "name": "emails",
+      "type": "complex",
+      "multiValued": true,
+      "_index": 0,
+      "_path": "emails",
+      "subAttributes": [
+        {
+          "id": "urn:test:Multi:emails.value",
+          "name": "value",
+          "type": "string",
+          "_index": 0,
+          "_path": "emails.value"
+        }
+      ]
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				require.Len(t, diagnostics, 1)
+				assert.Equal(t, RuleMissingCanonicalSubAttributes, diagnostics[0].Rule)
+				assert.Equal(t, "emails", diagnostics[0].Path)
+				assert.Contains(t, diagnostics[0].Detail, "type")
+				assert.Contains(t, diagnostics[0].Detail, "primary")
+				assert.NotContains(t, diagnostics[0].Detail, "value,")
+			},
+		},
+		{
+			name: "duplicate sub-attribute names are detected",
+			schemaJson: `
+{
+  "id": "urn:test:DupSub",
+  "name": "DupSub",
+  "attributes": [
+    {
+      "id": "urn:test:DupSub:name",
+      "name": "name",
+      "type": "complex",
+      "_index": 0,
+      "_path": "name",
+      "subAttributes": [
+        {
+          "id": "urn:test:DupSub:name.formatted",
+          "name": "formatted",
+          "type": "string",
+          "_index": 0,
+          "_path": "name.formatted"
+        },
+        {
+          "id": "urn:test:DupSub:name.formatted2",
+          "name": "Formatted",
+          "type": "string",
+          "_index": 1,
+          "_path": "name.Formatted"
+        }
+      ]
+    }
+  ]
+}
+`,
+			expect: func(t *testing.T, diagnostics []Diagnostic) {
+				require.Len(t, diagnostics, 1)
+				assert.Equal(t, RuleDuplicateName, diagnostics[0].Rule)
+				assert.Equal(t, "name.Formatted", diagnostics[0].Path)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			schema := new(Schema)
+			require.Nil(t, json.Unmarshal([]byte(test.schemaJson), schema))
+			test.expect(t, Lint(schema))
+		})
+	}
+}
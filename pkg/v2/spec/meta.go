@@ -480,3 +480,45 @@ func (m *metaAttr) ResourceTypeSchemaExtensionRequiredAttribute() *Attribute {
 	}
 	return m.resourceTypeSchemaExtensionRequired
 }
+
+// VendorFieldAttribute returns an ad hoc attribute describing a vendor extension field with the given
+// name and value, so that vendor fields preserved on schemas and attributes can be rendered through
+// the property system during schema discovery. The attribute structure mirrors the dynamic type of the
+// value: JSON strings, booleans and numbers map to string, boolean and decimal attributes; arrays map
+// to a multiValued attribute derived from their first element; objects map to a complex attribute whose
+// sub attributes are derived from each entry.
+func (m *metaAttr) VendorFieldAttribute(parentId string, name string, value interface{}) *Attribute {
+	attr := &Attribute{
+		id:   parentId + "." + name,
+		name: name,
+		path: name,
+	}
+
+	switch v := value.(type) {
+	case bool:
+		attr.typ = TypeBoolean
+	case float64:
+		attr.typ = TypeDecimal
+	case []interface{}:
+		attr.multiValued = true
+		attr.typ = TypeString
+		if len(v) > 0 {
+			elem := m.VendorFieldAttribute(parentId, name, v[0])
+			attr.typ = elem.typ
+			attr.subAttributes = elem.subAttributes
+		}
+	case map[string]interface{}:
+		attr.typ = TypeComplex
+		index := 0
+		for subName, subValue := range v {
+			subAttr := m.VendorFieldAttribute(attr.id, subName, subValue)
+			subAttr.index = index
+			index++
+			attr.subAttributes = append(attr.subAttributes, subAttr)
+		}
+	default:
+		attr.typ = TypeString
+	}
+
+	return attr
+}
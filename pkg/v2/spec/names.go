@@ -0,0 +1,18 @@
+package spec
+
+import "strings"
+
+// FoldAttributeName returns the canonical folded form of an attribute name, path segment or full
+// attribute path. SCIM attribute names are case insensitive; modules that index or compare attribute
+// names are expected to fold them through this function instead of ad hoc lowercase conversions, so
+// that every inbound path (JSON keys, filter paths, patch paths, attributes parameters and sortBy)
+// resolves names by the same rule.
+func FoldAttributeName(name string) string {
+	return strings.ToLower(name)
+}
+
+// SameAttributeName reports whether two attribute names, path segments or full paths refer to the
+// same attribute under the case insensitive comparison rule of FoldAttributeName.
+func SameAttributeName(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
@@ -0,0 +1,22 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldAttributeName(t *testing.T) {
+	assert.Equal(t, "username", FoldAttributeName("userName"))
+	assert.Equal(t, "name.familyname", FoldAttributeName("Name.FamilyName"))
+	assert.Equal(t,
+		"urn:ietf:params:scim:schemas:core:2.0:user:username",
+		FoldAttributeName("urn:ietf:params:scim:schemas:core:2.0:User:userName"),
+	)
+}
+
+func TestSameAttributeName(t *testing.T) {
+	assert.True(t, SameAttributeName("userName", "USERNAME"))
+	assert.True(t, SameAttributeName("meta.version", "Meta.Version"))
+	assert.False(t, SameAttributeName("userName", "displayName"))
+}
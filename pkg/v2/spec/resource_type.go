@@ -2,8 +2,11 @@ package spec
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/imulab/go-scim/pkg/v2/annotation"
 	"github.com/imulab/go-scim/pkg/v2/spec/internal"
+	"strings"
+	"sync"
 )
 
 // Resource type models the SCIM resource type. It is a collection of one main schema and zero or more schema extensions
@@ -32,6 +35,16 @@ import (
 //		}
 //	}
 //
+// The resource type definition may carry an optional "attributeOverrides" section to relax or tighten
+// attribute constraints for this resource type only, without editing the schema JSON shared with other
+// resource types. For example, to make emails optional for a service account resource type:
+//	"attributeOverrides": [
+//		{ "path": "emails", "required": false }
+//	]
+// Each override addresses one attribute by path and replaces only the constraint fields present in
+// the definition (required, caseExact, mutability, returned, uniqueness). The overrides take effect
+// on the super attribute generated for this resource type.
+//
 // ResourceType is currently being parsed to and from JSON using special adapters. This design is subject to change
 // when we move to treat ResourceType as just another resource.
 // See also:
@@ -44,6 +57,19 @@ type ResourceType struct {
 	schema      *Schema
 	extensions  []*Schema
 	required    map[string]bool // schema id to boolean to indicate whether schema extension is required
+	overrides   []*attributeOverride
+}
+
+// attributeOverride is a per-resource-type adjustment to the constraints of a single attribute,
+// parsed from the "attributeOverrides" section of the resource type definition. Only the fields
+// present in the definition are overridden; absent fields keep the value declared in the schema.
+type attributeOverride struct {
+	path       string
+	required   *bool
+	caseExact  *bool
+	mutability *Mutability
+	returned   *Returned
+	uniqueness *Uniqueness
 }
 
 // Return the id of the resource type
@@ -115,6 +141,26 @@ func (t *ResourceType) convertToAdapter(p *internal.ResourceTypeJsonAdapter) {
 			Required: t.required[ext.id],
 		})
 	}
+	for _, ov := range t.overrides {
+		adapted := &internal.AttributeOverride{
+			Path:      ov.path,
+			Required:  ov.required,
+			CaseExact: ov.caseExact,
+		}
+		if ov.mutability != nil {
+			v := ov.mutability.String()
+			adapted.Mutability = &v
+		}
+		if ov.returned != nil {
+			v := ov.returned.String()
+			adapted.Returned = &v
+		}
+		if ov.uniqueness != nil {
+			v := ov.uniqueness.String()
+			adapted.Uniqueness = &v
+		}
+		p.Overrides = append(p.Overrides, adapted)
+	}
 }
 
 func (t *ResourceType) UnmarshalJSON(raw []byte) error {
@@ -123,7 +169,42 @@ func (t *ResourceType) UnmarshalJSON(raw []byte) error {
 		return err
 	}
 	t.convertFromAdapter(&adapter)
-	return nil
+	return t.checkAttributeConflicts()
+}
+
+// checkAttributeConflicts returns an error when a schema extension declares a top level attribute whose
+// name collides, case-insensitively, with a top level attribute of the core schema, the main schema, or
+// another extension of this resource type. Unqualified attribute paths deterministically resolve against
+// the core and main schema attributes; extension attributes are only addressable under their schema URN.
+// A colliding extension attribute would therefore shadow nothing and surprise at runtime, so the conflict
+// is reported at registration instead.
+func (t *ResourceType) checkAttributeConflicts() error {
+	declared := map[string]string{} // lower case attribute name to the id of the declaring schema
+	collect := func(schema *Schema) error {
+		return schema.ForEachAttribute(func(attr *Attribute) error {
+			name := FoldAttributeName(attr.name)
+			if owner, ok := declared[name]; ok {
+				return fmt.Errorf(
+					"attribute '%s' of schema '%s' conflicts with the attribute of the same name in schema '%s' for resource type '%s'",
+					attr.name, schema.id, owner, t.name,
+				)
+			}
+			declared[name] = schema.id
+			return nil
+		})
+	}
+
+	if core, ok := Schemas().Get(CoreSchemaId); ok {
+		if err := collect(core); err != nil {
+			return err
+		}
+	}
+	if err := collect(t.schema); err != nil {
+		return err
+	}
+	return t.ForEachExtension(func(extension *Schema, _ bool) error {
+		return collect(extension)
+	})
 }
 
 func (t *ResourceType) convertFromAdapter(p *internal.ResourceTypeJsonAdapter) {
@@ -138,6 +219,27 @@ func (t *ResourceType) convertFromAdapter(p *internal.ResourceTypeJsonAdapter) {
 		t.extensions = append(t.extensions, Schemas().mustGet(ext.Schema))
 		t.required[ext.Schema] = ext.Required
 	}
+	t.overrides = nil
+	for _, ov := range p.Overrides {
+		parsed := &attributeOverride{
+			path:      ov.Path,
+			required:  ov.Required,
+			caseExact: ov.CaseExact,
+		}
+		if ov.Mutability != nil {
+			v := mustParseMutability(*ov.Mutability)
+			parsed.mutability = &v
+		}
+		if ov.Returned != nil {
+			v := mustParseReturned(*ov.Returned)
+			parsed.returned = &v
+		}
+		if ov.Uniqueness != nil {
+			v := mustParseUniqueness(*ov.Uniqueness)
+			parsed.uniqueness = &v
+		}
+		t.overrides = append(t.overrides, parsed)
+	}
 }
 
 // SuperAttribute return a virtual complex attribute that contains all schema attributes as its sub attributes.
@@ -184,5 +286,121 @@ func (t *ResourceType) SuperAttribute(includeCore bool) *Attribute {
 		return nil
 	})
 
+	for _, ov := range t.overrides {
+		replaced, ok := overrideAttributes(super.subAttributes, t.overridePathSegments(ov.path), ov)
+		if !ok {
+			panic(fmt.Errorf("attribute override path '%s' does not resolve against resource type '%s'", ov.path, t.name))
+		}
+		super.subAttributes = replaced
+	}
+
 	return &super
 }
+
+// overridePathSegments splits an attribute override path into attribute names relative to the super
+// attribute. The path may be prefixed by the main schema id, in which case the remainder resolves
+// among the top level attributes, or by an extension schema id, in which case the extension container
+// attribute becomes the first segment. Un-prefixed paths resolve from the top level directly.
+func (t *ResourceType) overridePathSegments(path string) []string {
+	prefix := t.schema.id + ":"
+	if len(path) > len(prefix) && SameAttributeName(path[:len(prefix)], prefix) {
+		return strings.Split(path[len(prefix):], ".")
+	}
+	for _, extension := range t.extensions {
+		if SameAttributeName(path, extension.id) {
+			return []string{extension.id}
+		}
+		prefix = extension.id + ":"
+		if len(path) > len(prefix) && SameAttributeName(path[:len(prefix)], prefix) {
+			return append([]string{extension.id}, strings.Split(path[len(prefix):], ".")...)
+		}
+	}
+	return strings.Split(path, ".")
+}
+
+// overrideAttributes returns a copy of attrs with the override applied to the attribute addressed by
+// names. Only the attributes on the path are copied, so the schema definitions shared with other
+// resource types stay untouched. The second return is false when the path does not resolve.
+func overrideAttributes(attrs []*Attribute, names []string, ov *attributeOverride) ([]*Attribute, bool) {
+	for i, attr := range attrs {
+		if !SameAttributeName(attr.name, names[0]) {
+			continue
+		}
+
+		copied := *attr
+		if len(names) > 1 {
+			sub, ok := overrideAttributes(attr.subAttributes, names[1:], ov)
+			if !ok {
+				return nil, false
+			}
+			copied.subAttributes = sub
+		} else {
+			copied.applyOverride(ov)
+		}
+
+		replaced := make([]*Attribute, len(attrs))
+		copy(replaced, attrs)
+		replaced[i] = &copied
+		return replaced, true
+	}
+	return nil, false
+}
+
+var (
+	resourceTypeReg          *resourceTypeRegistry
+	resourceTypeRegistryOnce sync.Once
+)
+
+type resourceTypeRegistry struct {
+	db map[string]*ResourceType
+}
+
+// Register relates the resource type with the id of its main schema in the registry. This method does not
+// check existence of the id and may overwrite existing registrations.
+func (r *resourceTypeRegistry) Register(resourceType *ResourceType) {
+	r.db[resourceType.schema.id] = resourceType
+}
+
+// Get returns the resource type whose main schema carries the given id, or false.
+func (r *resourceTypeRegistry) Get(mainSchemaId string) (resourceType *ResourceType, ok bool) {
+	resourceType, ok = r.db[mainSchemaId]
+	return
+}
+
+// ForEachResourceType iterates through all registered resource types and invokes the callback.
+func (r *resourceTypeRegistry) ForEachResourceType(callback func(resourceType *ResourceType) error) error {
+	for _, resourceType := range r.db {
+		if err := callback(resourceType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResourceTypes return the resource type registry that holds all registered resource types, keyed by the
+// id of their main schema, which is how resources declare their type in the "schemas" attribute. Use Get
+// and Register to operate the registry.
+func ResourceTypes() *resourceTypeRegistry {
+	resourceTypeRegistryOnce.Do(func() {
+		resourceTypeReg = &resourceTypeRegistry{db: map[string]*ResourceType{}}
+	})
+	return resourceTypeReg
+}
+
+func (attr *Attribute) applyOverride(ov *attributeOverride) {
+	if ov.required != nil {
+		attr.required = *ov.required
+	}
+	if ov.caseExact != nil {
+		attr.caseExact = *ov.caseExact
+	}
+	if ov.mutability != nil {
+		attr.mutability = *ov.mutability
+	}
+	if ov.returned != nil {
+		attr.returned = *ov.returned
+	}
+	if ov.uniqueness != nil {
+		attr.uniqueness = *ov.uniqueness
+	}
+}
@@ -59,3 +59,143 @@ func (s *ResourceTypeTestSuite) TestUnmarshal() {
 	assert.NotNil(s.T(), rt.Schema())
 	assert.Len(s.T(), rt.extensions, 1)
 }
+
+func (s *ResourceTypeTestSuite) TestUnmarshalConflictingExtension() {
+	Schemas().Register(&Schema{
+		id: "urn:test:conflict:Main",
+		attributes: []*Attribute{
+			{id: "urn:test:conflict:Main:userName", name: "userName", path: "userName", typ: TypeString},
+		},
+	})
+	Schemas().Register(&Schema{
+		id: "urn:test:conflict:Ext",
+		attributes: []*Attribute{
+			{id: "urn:test:conflict:Ext:userName", name: "UserName", path: "UserName", typ: TypeString},
+		},
+	})
+
+	raw := `
+{
+  "id": "Conflicted",
+  "name": "Conflicted",
+  "endpoint": "/v2/Conflicted",
+  "schema": "urn:test:conflict:Main",
+  "schemaExtensions": [
+    {
+      "schema": "urn:test:conflict:Ext",
+      "required": false
+    }
+  ]
+}
+`
+
+	err := json.Unmarshal([]byte(raw), new(ResourceType))
+	assert.NotNil(s.T(), err)
+	assert.Contains(s.T(), err.Error(), "conflicts")
+}
+
+func (s *ResourceTypeTestSuite) TestAttributeOverrides() {
+	mainSchema := &Schema{
+		id: "urn:test:override:Main",
+		attributes: []*Attribute{
+			{
+				id:       "urn:test:override:Main:emails",
+				name:     "emails",
+				path:     "emails",
+				typ:      TypeString,
+				required: true,
+			},
+		},
+	}
+	extensionSchema := &Schema{
+		id: "urn:test:override:Ext",
+		attributes: []*Attribute{
+			{
+				id:         "urn:test:override:Ext:employeeNumber",
+				name:       "employeeNumber",
+				path:       "employeeNumber",
+				typ:        TypeString,
+				mutability: MutabilityReadWrite,
+			},
+		},
+	}
+	Schemas().Register(mainSchema)
+	Schemas().Register(extensionSchema)
+
+	raw := `
+{
+  "id": "ServiceAccount",
+  "name": "ServiceAccount",
+  "endpoint": "/v2/ServiceAccounts",
+  "schema": "urn:test:override:Main",
+  "schemaExtensions": [
+    {
+      "schema": "urn:test:override:Ext",
+      "required": false
+    }
+  ],
+  "attributeOverrides": [
+    {
+      "path": "emails",
+      "required": false
+    },
+    {
+      "path": "urn:test:override:Ext:employeeNumber",
+      "mutability": "readOnly"
+    }
+  ]
+}
+`
+
+	rt := new(ResourceType)
+	err := json.Unmarshal([]byte(raw), rt)
+	assert.Nil(s.T(), err)
+	assert.Len(s.T(), rt.overrides, 2)
+
+	super := rt.SuperAttribute(false)
+
+	emails := super.subAttributes[0]
+	assert.Equal(s.T(), "emails", emails.Name())
+	assert.False(s.T(), emails.Required())
+
+	extension := super.subAttributes[1]
+	assert.Equal(s.T(), "urn:test:override:Ext", extension.Name())
+	employeeNumber := extension.subAttributes[0]
+	assert.Equal(s.T(), "employeeNumber", employeeNumber.Name())
+	assert.Equal(s.T(), MutabilityReadOnly, employeeNumber.Mutability())
+
+	// the shared schema definitions must stay untouched
+	assert.True(s.T(), mainSchema.attributes[0].Required())
+	assert.Equal(s.T(), MutabilityReadWrite, extensionSchema.attributes[0].Mutability())
+
+	// round trip through JSON preserves the overrides
+	marshaled, err := json.Marshal(rt)
+	assert.Nil(s.T(), err)
+	assert.Contains(s.T(), string(marshaled), `"attributeOverrides"`)
+}
+
+func (s *ResourceTypeTestSuite) TestAttributeOverrideUnresolved() {
+	Schemas().Register(&Schema{id: "urn:test:override:Empty"})
+
+	raw := `
+{
+  "id": "Bad",
+  "name": "Bad",
+  "endpoint": "/v2/Bads",
+  "schema": "urn:test:override:Empty",
+  "attributeOverrides": [
+    {
+      "path": "noSuchAttribute",
+      "required": true
+    }
+  ]
+}
+`
+
+	rt := new(ResourceType)
+	err := json.Unmarshal([]byte(raw), rt)
+	assert.Nil(s.T(), err)
+	assert.Panics(s.T(), func() {
+		rt.SuperAttribute(false)
+	})
+}
@@ -2,6 +2,7 @@ package spec
 
 import (
 	"encoding/json"
+	"strings"
 	"sync"
 )
 
@@ -19,10 +20,11 @@ const CoreSchemaId = "core"
 // See also:
 //	issue https://github.com/imulab/go-scim/issues/40
 type Schema struct {
-	id          string
-	name        string
-	description string
-	attributes  []*Attribute
+	id           string
+	name         string
+	description  string
+	attributes   []*Attribute
+	vendorFields map[string]interface{} // non-standard fields preserved from the schema JSON
 }
 
 // ID returns the id of the schema.
@@ -40,6 +42,33 @@ func (s *Schema) Description() string {
 	return s.description
 }
 
+// VendorField returns the value of the vendor extension field by the given name (case sensitive) and a boolean
+// indicating whether this field exists. Vendor extension fields are top level fields in the schema JSON that are
+// not defined by the specification. They are preserved as is through parsing and serialization so that custom
+// metadata declared on schemas survives schema discovery.
+func (s *Schema) VendorField(name string) (value interface{}, ok bool) {
+	value, ok = s.vendorFields[name]
+	return
+}
+
+// HasVendorField returns true if the vendor extension field with the given name (case sensitive) exists on this schema.
+func (s *Schema) HasVendorField(name string) bool {
+	_, ok := s.vendorFields[name]
+	return ok
+}
+
+// CountVendorFields returns the total number of vendor extension fields on this schema.
+func (s *Schema) CountVendorFields() int {
+	return len(s.vendorFields)
+}
+
+// ForEachVendorField iterates through vendor extension fields and invoke callback.
+func (s *Schema) ForEachVendorField(callback func(name string, value interface{})) {
+	for k, v := range s.vendorFields {
+		callback(k, v)
+	}
+}
+
 // ForEachAttribute iterate all attributes in this schema and invoke callback function.
 func (s *Schema) ForEachAttribute(callback func(attr *Attribute) error) error {
 	for _, attr := range s.attributes {
@@ -62,12 +91,28 @@ func (s *Schema) ResourceLocation() string {
 }
 
 func (s *Schema) MarshalJSON() ([]byte, error) {
-	return json.Marshal(schemaJsonAdapter{
+	raw, err := json.Marshal(schemaJsonAdapter{
 		ID:          s.id,
 		Name:        s.name,
 		Description: s.description,
 		Attributes:  s.attributes,
 	})
+	if err != nil || len(s.vendorFields) == 0 {
+		return raw, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range s.vendorFields {
+		vendorRaw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = vendorRaw
+	}
+	return json.Marshal(fields)
 }
 
 func (s *Schema) UnmarshalJSON(raw []byte) error {
@@ -80,6 +125,19 @@ func (s *Schema) UnmarshalJSON(raw []byte) error {
 	s.name = adapter.Name
 	s.description = adapter.Description
 	s.attributes = adapter.Attributes
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	for name := range fields {
+		if _, ok := schemaKnownFields[strings.ToLower(name)]; ok {
+			delete(fields, name)
+		}
+	}
+	if len(fields) > 0 {
+		s.vendorFields = fields
+	}
 	return nil
 }
 
@@ -90,6 +148,15 @@ type schemaJsonAdapter struct {
 	Attributes  []*Attribute `json:"attributes"`
 }
 
+// schemaKnownFields indexes the lowercased JSON names of all defined top level schema fields; any other
+// field encountered while unmarshalling a schema is retained as a vendor extension field.
+var schemaKnownFields = map[string]struct{}{
+	"id":          {},
+	"name":        {},
+	"description": {},
+	"attributes":  {},
+}
+
 var (
 	schemaReg          *schemaRegistry
 	schemaRegistryOnce sync.Once
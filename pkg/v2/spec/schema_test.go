@@ -80,3 +80,28 @@ func (s *SchemaTestSuite) TestUnmarshal() {
 	assert.Equal(s.T(), "User", schema.Name())
 	assert.Len(s.T(), schema.attributes, 1)
 }
+
+func (s *SchemaTestSuite) TestVendorFieldsRoundTrip() {
+	raw := `
+{
+  "id": "urn:ietf:params:scim:schemas:core:2.0:User",
+  "name": "User",
+  "description": "User schema",
+  "attributes": [],
+  "x-documentation": "https://vendor.example.com/docs/user"
+}
+`
+
+	schema := new(Schema)
+	assert.Nil(s.T(), json.Unmarshal([]byte(raw), schema))
+
+	assert.Equal(s.T(), 1, schema.CountVendorFields())
+	assert.True(s.T(), schema.HasVendorField("x-documentation"))
+	doc, ok := schema.VendorField("x-documentation")
+	assert.True(s.T(), ok)
+	assert.Equal(s.T(), "https://vendor.example.com/docs/user", doc)
+
+	marshaled, err := json.Marshal(schema)
+	assert.Nil(s.T(), err)
+	assert.JSONEq(s.T(), raw, string(marshaled))
+}
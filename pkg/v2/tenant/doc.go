@@ -0,0 +1,7 @@
+// This package provides the tenant abstraction for multi-tenant deployments.
+//
+// A tenant is identified by a plain string id which is resolved at the transport layer (i.e. from a request header or
+// a path prefix) and carried on the context.Context for the rest of the request processing. Components that wish to
+// scope their behaviour per tenant (i.e. db.Partition, filter.MetaFilterWithLocation) extract the id from the context
+// using FromContext.
+package tenant
@@ -0,0 +1,26 @@
+package tenant
+
+import "context"
+
+type contextKey int
+
+// tenantContextKey is the context key under which the tenant id is stored.
+const tenantContextKey contextKey = iota
+
+// WithContext returns a copy of the parent context carrying the given tenant id. An empty id returns the
+// parent context unmodified.
+func WithContext(parent context.Context, id string) context.Context {
+	if len(id) == 0 {
+		return parent
+	}
+	return context.WithValue(parent, tenantContextKey, id)
+}
+
+// FromContext extracts the tenant id carried on the context. It returns an empty string when the request
+// is not associated with any tenant.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantContextKey).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,10 @@
+// This package provides a vendor neutral tracing hook for the SCIM processing pipeline.
+//
+// The package itself does not depend on any particular tracing backend. Instead, callers register a Provider
+// implementation (i.e. a bridge to OpenTelemetry, OpenTracing or a home grown tracer) via SetProvider during
+// initialization. When no provider is registered, all spans are no-ops and incur negligible overhead.
+//
+// Spans are started around service operations (Create/Get/Patch/Replace/Delete/Query) and, when the database
+// is wrapped with db.Traced, around individual database calls. Span tags carry the resource type, the filter
+// text and result counts where applicable.
+package trace
@@ -0,0 +1,62 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/imulab/go-scim/pkg/v2/requestid"
+)
+
+// Provider is the abstraction of a tracing backend capable of starting spans.
+type Provider interface {
+	// StartSpan starts a span with the given operation name and returns a context carrying the span,
+	// along with the span itself. The returned context should be used for downstream operations so
+	// that child spans are properly parented.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single unit of traced work.
+type Span interface {
+	// SetTag annotates the span with a key value pair.
+	SetTag(key string, value interface{})
+	// SetError marks the span as failed with the given error. Implementations should treat a nil error
+	// as a no-op so that callers may invoke this method unconditionally in a deferred function.
+	SetError(err error)
+	// End completes the span.
+	End()
+}
+
+var provider Provider = noOpProvider{}
+
+// SetProvider registers the global tracing provider. This method is not safe for concurrent use and is
+// expected to be called once during initialization, before any requests are served.
+func SetProvider(p Provider) {
+	if p == nil {
+		p = noOpProvider{}
+	}
+	provider = p
+}
+
+// StartSpan starts a span using the registered provider. When no provider was registered, the returned
+// span is a no-op and the context is returned unmodified. When the context carries a request id, the
+// span is tagged with it, so that traces can be correlated with logs and error responses.
+func StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	ctx, span := provider.StartSpan(ctx, name)
+	if id := requestid.FromContext(ctx); len(id) > 0 {
+		span.SetTag("requestId", id)
+	}
+	return ctx, span
+}
+
+type noOpProvider struct{}
+
+func (_ noOpProvider) StartSpan(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noOpSpan{}
+}
+
+type noOpSpan struct{}
+
+func (_ noOpSpan) SetTag(_ string, _ interface{}) {}
+
+func (_ noOpSpan) SetError(_ error) {}
+
+func (_ noOpSpan) End() {}
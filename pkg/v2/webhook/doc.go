@@ -0,0 +1,9 @@
+// This package delivers resource change notifications to webhook endpoints.
+//
+// Relay consumes resource change events (see the events package) and POSTs each event as a JSON
+// notification to every configured Endpoint, so external systems can react to provisioning changes
+// without polling the server. Payloads are signed with HMAC-SHA256 when the endpoint carries a
+// secret, allowing receivers to authenticate the notification through Sign. Failed deliveries are
+// retried with exponential backoff; deliveries that exhaust their attempts are handed to a dead
+// letter callback for logging or durable storage.
+package webhook
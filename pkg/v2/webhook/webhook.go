@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/imulab/go-scim/pkg/v2/log"
+)
+
+// Headers set on webhook notification requests.
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body, as produced by Sign. It
+	// is only set when the endpoint carries a secret.
+	SignatureHeader = "X-Scim-Signature"
+	// EventTypeHeader carries the type of the delivered event.
+	EventTypeHeader = "X-Scim-Event"
+)
+
+// Endpoint describes a webhook destination.
+type Endpoint struct {
+	// URL that notifications are POSTed to.
+	URL string
+	// Secret is the HMAC-SHA256 signing key shared with the receiver. An empty secret disables signing.
+	Secret string
+}
+
+// Options tunes the delivery behaviour of Relay. The zero value is ready to use with sensible defaults.
+type Options struct {
+	// Client used to POST notifications. Defaults to a client with a 10 second timeout.
+	Client *http.Client
+	// MaxAttempts is the total number of delivery attempts per endpoint, including the first. Defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt; it doubles after each subsequent failure.
+	// Defaults to one second.
+	InitialBackoff time.Duration
+	// OnDeadLetter is invoked with the last error after all delivery attempts to an endpoint were
+	// exhausted. It may be nil, in which case the dead letter is reported through Logger instead.
+	OnDeadLetter func(endpoint Endpoint, event *events.Event, err error)
+	// Logger receives an error record for each dead letter when OnDeadLetter is nil. Defaults to the
+	// no-op logger, in which case the event is dropped for that endpoint silently.
+	Logger log.Logger
+}
+
+func (opt *Options) complete() *Options {
+	completed := &Options{}
+	if opt != nil {
+		*completed = *opt
+	}
+	if completed.Client == nil {
+		completed.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if completed.MaxAttempts < 1 {
+		completed.MaxAttempts = 3
+	}
+	if completed.InitialBackoff <= 0 {
+		completed.InitialBackoff = time.Second
+	}
+	if completed.Logger == nil {
+		completed.Logger = log.None()
+	}
+	if completed.OnDeadLetter == nil {
+		logger := completed.Logger
+		completed.OnDeadLetter = func(endpoint Endpoint, event *events.Event, err error) {
+			logger.Log(log.LevelError, "webhook notification dead lettered", map[string]interface{}{
+				"endpoint":   endpoint.URL,
+				"event":      event.Type,
+				"resourceId": event.ResourceID,
+				"error":      err.Error(),
+			})
+		}
+	}
+	return completed
+}
+
+// Sign returns the value of SignatureHeader for the payload: the hex encoded HMAC-SHA256 of the
+// payload under the secret, prefixed with "sha256=". Receivers recompute it over the raw request
+// body to authenticate the notification.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Relay consumes resource change events from the source channel and POSTs each event as a JSON
+// notification to every endpoint. Failed deliveries are retried with exponential backoff up to the
+// configured number of attempts; deliveries that exhaust their attempts are handed to the dead
+// letter callback. Relay returns when the source channel closes or the context is cancelled. It is
+// typically run on its own goroutine against a subscription of events.InProcessPublisher.
+func Relay(ctx context.Context, source <-chan *events.Event, endpoints []Endpoint, options *Options) {
+	opt := options.complete()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-source:
+			if !ok {
+				return
+			}
+
+			payload, err := json.Marshal(event)
+			if err != nil {
+				for _, endpoint := range endpoints {
+					opt.OnDeadLetter(endpoint, event, err)
+				}
+				continue
+			}
+
+			for _, endpoint := range endpoints {
+				deliver(ctx, opt, endpoint, event, payload)
+			}
+		}
+	}
+}
+
+func deliver(ctx context.Context, opt *Options, endpoint Endpoint, event *events.Event, payload []byte) {
+	var (
+		backoff = opt.InitialBackoff
+		lastErr error
+	)
+
+	for attempt := 0; attempt < opt.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				opt.OnDeadLetter(endpoint, event, ctx.Err())
+				return
+			case <-time.After(backoff):
+				backoff *= 2
+			}
+		}
+
+		if lastErr = post(ctx, opt.Client, endpoint, event, payload); lastErr == nil {
+			return
+		}
+	}
+
+	opt.OnDeadLetter(endpoint, event, lastErr)
+}
+
+func post(ctx context.Context, client *http.Client, endpoint Endpoint, event *events.Event, payload []byte) error {
+	request, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventTypeHeader, event.Type)
+	if len(endpoint.Secret) > 0 {
+		request.Header.Set(SignatureHeader, Sign(endpoint.Secret, payload))
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint received status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imulab/go-scim/pkg/v2/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayDeliversSignedNotification(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   [][]byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.Nil(t, err)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	source := make(chan *events.Event, 1)
+	source <- &events.Event{Type: events.TypeCreated, ResourceID: "user001", ResourceType: "User"}
+	close(source)
+
+	Relay(context.Background(), source, []Endpoint{{URL: server.URL, Secret: "s3cret"}}, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "application/json", received[0].Header.Get("Content-Type"))
+	assert.Equal(t, events.TypeCreated, received[0].Header.Get(EventTypeHeader))
+	assert.Equal(t, Sign("s3cret", bodies[0]), received[0].Header.Get(SignatureHeader))
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	source := make(chan *events.Event, 1)
+	source <- &events.Event{Type: events.TypeUpdated, ResourceID: "user001", ResourceType: "User"}
+	close(source)
+
+	deadLettered := false
+	Relay(context.Background(), source, []Endpoint{{URL: server.URL}}, &Options{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnDeadLetter: func(_ Endpoint, _ *events.Event, _ error) {
+			deadLettered = true
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, attempts)
+	assert.False(t, deadLettered)
+}
+
+func TestRelayDeadLettersExhaustedDeliveries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	source := make(chan *events.Event, 1)
+	source <- &events.Event{Type: events.TypeDeleted, ResourceID: "user001", ResourceType: "User"}
+	close(source)
+
+	var (
+		deadEndpoint Endpoint
+		deadEvent    *events.Event
+		deadErr      error
+	)
+	Relay(context.Background(), source, []Endpoint{{URL: server.URL}}, &Options{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		OnDeadLetter: func(endpoint Endpoint, event *events.Event, err error) {
+			deadEndpoint = endpoint
+			deadEvent = event
+			deadErr = err
+		},
+	})
+
+	assert.Equal(t, server.URL, deadEndpoint.URL)
+	require.NotNil(t, deadEvent)
+	assert.Equal(t, "user001", deadEvent.ResourceID)
+	assert.NotNil(t, deadErr)
+}